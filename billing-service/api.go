@@ -2,9 +2,12 @@ package billing
 
 import (
 	"context"
+	"net/http"
 
 	"encore.app/dto"
 	"encore.app/handlers"
+
+	"encore.dev/beta/errs"
 )
 
 // Customer endpoints
@@ -21,6 +24,56 @@ func (s *Service) CreateCustomer(ctx context.Context, req *dto.CreateCustomerReq
 func (s *Service) GetCustomer(ctx context.Context, req *dto.GetCustomerRequest) (*dto.GetCustomerResponse, error) {
 	h := handlers.GetCustomerHandler{
 		CustomerRepo: s.customerRepo,
+		LedgerRepo:   s.ledgerRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/update
+func (s *Service) UpdateCustomer(ctx context.Context, req *dto.UpdateCustomerRequest) (*dto.CustomerSummary, error) {
+	h := handlers.UpdateCustomerHandler{
+		CustomerRepo: s.customerRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/list
+func (s *Service) ListCustomers(ctx context.Context, req *dto.ListCustomersRequest) (*dto.ListCustomersResponse, error) {
+	h := handlers.ListCustomersHandler{
+		CustomerRepo: s.customerRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/archive
+func (s *Service) ArchiveCustomer(ctx context.Context, req *dto.ArchiveCustomerRequest) (*dto.CustomerSummary, error) {
+	h := handlers.ArchiveCustomerHandler{
+		CustomerRepo: s.customerRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/merge
+func (s *Service) MergeCustomers(ctx context.Context, req *dto.MergeCustomersRequest) (*dto.MergeCustomersResponse, error) {
+	h := handlers.MergeCustomersHandler{
+		CustomerRepo: s.customerRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/billing-profile/update
+func (s *Service) UpdateBillingProfile(ctx context.Context, req *dto.UpdateBillingProfileRequest) (*dto.BillingProfileResponse, error) {
+	h := handlers.UpdateBillingProfileHandler{
+		CustomerRepo:       s.customerRepo,
+		BillingProfileRepo: s.billingProfileRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/billing-profile/get
+func (s *Service) GetBillingProfile(ctx context.Context, req *dto.GetBillingProfileRequest) (*dto.BillingProfileResponse, error) {
+	h := handlers.GetBillingProfileHandler{
+		BillingProfileRepo: s.billingProfileRepo,
 	}
 	return h.Handle(ctx, req)
 }
@@ -30,9 +83,13 @@ func (s *Service) GetCustomer(ctx context.Context, req *dto.GetCustomerRequest)
 //encore:api public method=POST path=/v1/bill/create
 func (s *Service) CreateBill(ctx context.Context, req *dto.CreateBillRequest) (*dto.CreateBillResponse, error) {
 	h := handlers.CreateBillHandler{
-		BillRepo:       s.billRepo,
-		CustomerRepo:   s.customerRepo,
-		TemporalClient: s.temporalClient,
+		BillRepo:         s.billRepo,
+		CustomerRepo:     s.customerRepo,
+		TemporalClient:   s.temporalClient,
+		LargeChargeCents: s.cfg.Notifications.LargeChargeCents,
+		PaymentTermsDays: s.cfg.Payments.TermsDays,
+		Settlement:       s.cfg.SettlementAccounts(),
+		DefaultCurrency:  s.cfg.BillingCurrency(),
 	}
 	return h.Handle(ctx, req)
 }
@@ -43,6 +100,7 @@ func (s *Service) AddLineItem(ctx context.Context, req *dto.AddLineItemRequest)
 		BillRepo:       s.billRepo,
 		LineItemRepo:   s.lineItemRepo,
 		TemporalClient: s.temporalClient,
+		FXRates:        s.cfg.FXRateProvider(),
 	}
 	return h.Handle(ctx, req)
 }
@@ -50,7 +108,8 @@ func (s *Service) AddLineItem(ctx context.Context, req *dto.AddLineItemRequest)
 //encore:api public method=POST path=/v1/bill/get
 func (s *Service) GetBill(ctx context.Context, req *dto.GetBillRequest) (*dto.GetBillResponse, error) {
 	h := handlers.GetBillHandler{
-		BillRepo: s.billRepo,
+		BillRepo:       s.billRepo,
+		TemporalClient: s.temporalClient,
 	}
 	return h.Handle(ctx, req)
 }
@@ -64,6 +123,15 @@ func (s *Service) CloseBill(ctx context.Context, req *dto.CloseBillRequest) (*dt
 	return h.Handle(ctx, req)
 }
 
+//encore:api public method=POST path=/v1/bill/void
+func (s *Service) VoidBill(ctx context.Context, req *dto.VoidBillRequest) (*dto.VoidBillResponse, error) {
+	h := handlers.VoidBillHandler{
+		BillRepo:       s.billRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
 //encore:api public method=POST path=/v1/bill/list
 func (s *Service) ListBills(ctx context.Context, req *dto.ListBillsRequest) (*dto.ListBillsResponse, error) {
 	h := handlers.ListBillsHandler{
@@ -90,3 +158,226 @@ func (s *Service) ReverseLineItem(ctx context.Context, req *dto.ReverseLineItemR
 	}
 	return h.Handle(ctx, req)
 }
+
+//encore:api public method=POST path=/v1/bill/credit-note
+func (s *Service) CreateCreditNote(ctx context.Context, req *dto.CreateCreditNoteRequest) (*dto.CreateCreditNoteResponse, error) {
+	h := handlers.CreateCreditNoteHandler{
+		BillRepo:       s.billRepo,
+		LineItemRepo:   s.lineItemRepo,
+		CreditNoteRepo: s.creditNoteRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+// GetInvoice serves a bill's invoice as stored bytes, so it is a raw endpoint.
+// Query parameters: uuid, and format (json, html or pdf; default json).
+//
+//encore:api public raw method=GET path=/v1/bill/invoice
+func (s *Service) GetInvoice(w http.ResponseWriter, req *http.Request) {
+	h := handlers.GetInvoiceHandler{
+		InvoiceRepo: s.invoiceRepo,
+		BlobStore:   s.invoiceStore,
+	}
+
+	query := req.URL.Query()
+	resp, err := h.Handle(req.Context(), &dto.GetInvoiceRequest{
+		UUID:   query.Get("uuid"),
+		Format: query.Get("format"),
+	})
+	if err != nil {
+		errs.HTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", resp.ContentType)
+	w.Header().Set("X-Invoice-Number", resp.InvoiceNumber)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(resp.Content)
+}
+
+//encore:api public method=POST path=/v1/bill/failed-line-items
+func (s *Service) ListFailedLineItems(ctx context.Context, req *dto.ListFailedLineItemsRequest) (*dto.ListFailedLineItemsResponse, error) {
+	h := handlers.ListFailedLineItemsHandler{
+		BillRepo:       s.billRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/bill/retry-line-item
+func (s *Service) RetryLineItem(ctx context.Context, req *dto.RetryLineItemRequest) (*dto.RetryLineItemResponse, error) {
+	h := handlers.RetryLineItemHandler{
+		BillRepo:       s.billRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/bill/discard-line-item
+func (s *Service) DiscardLineItem(ctx context.Context, req *dto.DiscardLineItemRequest) (*dto.DiscardLineItemResponse, error) {
+	h := handlers.DiscardLineItemHandler{
+		BillRepo:       s.billRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/bill/record-payment
+func (s *Service) RecordPayment(ctx context.Context, req *dto.RecordPaymentRequest) (*dto.RecordPaymentResponse, error) {
+	h := handlers.RecordPaymentHandler{
+		BillRepo:       s.billRepo,
+		PaymentRepo:    s.paymentRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/bill/list-payments
+func (s *Service) ListPayments(ctx context.Context, req *dto.ListPaymentsRequest) (*dto.ListPaymentsResponse, error) {
+	h := handlers.ListPaymentsHandler{
+		BillRepo:       s.billRepo,
+		PaymentRepo:    s.paymentRepo,
+		SettlementRepo: s.settlementRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+// Usage endpoints
+
+//encore:api public method=POST path=/v1/usage/record
+func (s *Service) RecordUsage(ctx context.Context, req *dto.RecordUsageRequest) (*dto.RecordUsageResponse, error) {
+	h := handlers.RecordUsageHandler{
+		BillRepo:  s.billRepo,
+		UsageRepo: s.usageRepo,
+		Catalog:   s.usageCatalog,
+	}
+	return h.Handle(ctx, req)
+}
+
+// Catalog and subscription endpoints
+
+//encore:api public method=POST path=/v1/catalog/product/create
+func (s *Service) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*dto.CreateProductResponse, error) {
+	h := handlers.CreateProductHandler{
+		PriceRepo: s.priceRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/catalog/price/create
+func (s *Service) CreatePrice(ctx context.Context, req *dto.CreatePriceRequest) (*dto.CreatePriceResponse, error) {
+	h := handlers.CreatePriceHandler{
+		PriceRepo: s.priceRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/subscription/create
+func (s *Service) CreateSubscription(ctx context.Context, req *dto.CreateSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	h := handlers.CreateSubscriptionHandler{
+		CustomerRepo:     s.customerRepo,
+		PriceRepo:        s.priceRepo,
+		SubscriptionRepo: s.subscriptionRepo,
+		BillRepo:         s.billRepo,
+		TemporalClient:   s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/subscription/cancel
+func (s *Service) CancelSubscription(ctx context.Context, req *dto.CancelSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	h := handlers.CancelSubscriptionHandler{
+		SubscriptionRepo: s.subscriptionRepo,
+		BillRepo:         s.billRepo,
+		TemporalClient:   s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+// Promotion and credit endpoints
+
+//encore:api public method=POST path=/v1/promotion/create
+func (s *Service) CreatePromotion(ctx context.Context, req *dto.CreatePromotionRequest) (*dto.CreatePromotionResponse, error) {
+	h := handlers.CreatePromotionHandler{
+		PromotionRepo: s.promotionRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/bill/apply-promo
+func (s *Service) ApplyPromoCode(ctx context.Context, req *dto.ApplyPromoCodeRequest) (*dto.ApplyPromoCodeResponse, error) {
+	h := handlers.ApplyPromoCodeHandler{
+		BillRepo:      s.billRepo,
+		PromotionRepo: s.promotionRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/customer/grant-credit
+func (s *Service) GrantCredit(ctx context.Context, req *dto.GrantCreditRequest) (*dto.GrantCreditResponse, error) {
+	h := handlers.GrantCreditHandler{
+		CustomerRepo: s.customerRepo,
+		CreditRepo:   s.creditRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+// Webhook endpoints
+
+//encore:api public method=POST path=/v1/webhook/subscribe
+func (s *Service) SubscribeWebhook(ctx context.Context, req *dto.SubscribeWebhookRequest) (*dto.SubscribeWebhookResponse, error) {
+	h := handlers.SubscribeWebhookHandler{
+		WebhookRepo: s.webhookRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/webhook/list
+func (s *Service) ListWebhooks(ctx context.Context) (*dto.ListWebhooksResponse, error) {
+	h := handlers.ListWebhooksHandler{
+		WebhookRepo: s.webhookRepo,
+	}
+	return h.Handle(ctx)
+}
+
+//encore:api public method=POST path=/v1/webhook/delete
+func (s *Service) DeleteWebhook(ctx context.Context, req *dto.DeleteWebhookRequest) (*dto.DeleteWebhookResponse, error) {
+	h := handlers.DeleteWebhookHandler{
+		WebhookRepo: s.webhookRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/webhook/deliveries
+func (s *Service) ListWebhookDeliveries(ctx context.Context, req *dto.ListWebhookDeliveriesRequest) (*dto.ListWebhookDeliveriesResponse, error) {
+	h := handlers.ListWebhookDeliveriesHandler{
+		WebhookRepo: s.webhookRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/webhook/replay
+func (s *Service) ReplayWebhookDelivery(ctx context.Context, req *dto.ReplayWebhookDeliveryRequest) (*dto.ReplayWebhookDeliveryResponse, error) {
+	h := handlers.ReplayWebhookDeliveryHandler{
+		WebhookRepo:    s.webhookRepo,
+		TemporalClient: s.temporalClient,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/admin/reconciliation
+func (s *Service) ListReconciliationFindings(ctx context.Context, req *dto.ListReconciliationFindingsRequest) (*dto.ListReconciliationFindingsResponse, error) {
+	h := handlers.ListReconciliationFindingsHandler{
+		ReconciliationRepo: s.reconciliationRepo,
+	}
+	return h.Handle(ctx, req)
+}
+
+//encore:api public method=POST path=/v1/admin/ledger/trial-balance
+func (s *Service) GetTrialBalance(ctx context.Context, req *dto.GetTrialBalanceRequest) (*dto.GetTrialBalanceResponse, error) {
+	h := handlers.GetTrialBalanceHandler{
+		LedgerRepo: s.ledgerRepo,
+	}
+	return h.Handle(ctx, req)
+}
@@ -4,6 +4,95 @@ TemporalPort:      7233
 TemporalNamespace: "default"
 BillingCurrency:   "USD"
 
+// FX rates converting line items added in another currency than their bill's
+FXRates: [
+    {From: "EUR", To: "USD", Rate: "1.08"},
+    {From: "USD", To: "GEL", Rate: "2.70"},
+    {From: "EUR", To: "GEL", Rate: "2.92"},
+]
+
+// Tax charged on fee type subtotals at bill close, in basis points
+TaxRates: [
+    {Currency: "GEL", FeeType: "MONTHLY_FEE", BasisPoints: 1800},
+]
+
+// Subscriptions starting or ending mid-period are charged by the day, a day
+// they cover at all counts in full
+ProrationMode: "DAY"
+
+// Metered usage recorded through /v1/usage/record, aggregated into one USAGE
+// line item per meter at bill close. Prices are per PerUnits units; TIERED
+// charges each band at its price, VOLUME all units at the band the total reaches.
+Meters: [
+    {
+        Name: "api_calls"
+        Unit: "calls"
+        Prices: [
+            {Currency: "USD", Model: "TIERED", PerUnits: 1000, Tiers: [
+                {UpTo: 1000000, UnitPriceCents: 10},
+                {UpTo: 0, UnitPriceCents: 5},
+            ]},
+        ]
+    },
+    {
+        Name: "storage_gb"
+        Unit: "GB"
+        Prices: [
+            {Currency: "USD", Model: "VOLUME", PerUnits: 1, Tiers: [
+                {UpTo: 100, UnitPriceCents: 25},
+                {UpTo: 0, UnitPriceCents: 20},
+            ]},
+            {Currency: "GEL", Model: "FLAT", PerUnits: 1, UnitPriceCents: 65},
+        ]
+    },
+]
+
+// Customer notifications on bill events. Locally mail goes to a catch-all
+// SMTP stand-in; switch Channel to "email" once one is running.
+Notifications: {
+    Channel:          "log"
+    SMTPAddr:         "127.0.0.1:1025"
+    SMTPFrom:         "billing@localhost"
+    WebhookURL:       ""
+    LargeChargeCents: 100000
+}
+
+// Billing events relayed from the outbox. Webhook subscriptions always get
+// them; set Sink to "kafka" or "file" to publish them there as well.
+Outbox: {
+    Sink:               ""
+    KafkaURL:           ""
+    KafkaTopic:         "billing-events"
+    FilePath:           ""
+    BatchSize:          100
+    PollIntervalMillis: 1000
+}
+
+// Closed bills are due TermsDays after close and followed up by dunning
+// reminders until paid. Zero disables due dates and dunning.
+Payments: {
+    TermsDays: 30
+}
+
+// Bills created with a settlement account are collected by bank transfer into
+// TargetAccount through the money transfer worker. Empty TargetAccount
+// disables settlement.
+Settlement: {
+    TargetAccount: "43-812"
+    TaskQueue:     "TRANSFER_MONEY_TASK_QUEUE"
+}
+
+// Open bills and those closed within ClosedWithinDays are checked every
+// IntervalMinutes for a total_cents, line item sum or workflow total that
+// disagree; findings are listed at /v1/admin/reconciliation. AutoRepair sets
+// total_cents to the line item sum instead of only recording the finding.
+Reconciliation: {
+    IntervalMinutes:  60
+    ClosedWithinDays: 7
+    BatchSize:        100
+    AutoRepair:       false
+}
+
 // Environment-specific overrides
 if #Meta.Environment.Type == "production" {
     TemporalHost: "temporal.internal"
@@ -1,6 +1,19 @@
 package billing
 
-import "encore.dev/config"
+import (
+	"fmt"
+	"time"
+
+	"encore.app/currency"
+	"encore.app/entity"
+	"encore.app/notify"
+	"encore.app/proration"
+	"encore.app/temporal/bill"
+	"encore.app/temporal/outbox"
+	"encore.app/temporal/reconcile"
+	"encore.app/usage"
+	"encore.dev/config"
+)
 
 type Config struct {
 	// Temporal
@@ -9,7 +22,257 @@ type Config struct {
 	TemporalNamespace config.String
 
 	// App-level
+	// BillingCurrency is the currency of bills whose customer has no default
 	BillingCurrency config.String
+
+	// FXRates convert line items submitted in another currency than their bill's
+	FXRates []FXRate
+
+	// TaxRates are applied to each fee type subtotal when a bill closes
+	TaxRates []TaxRate
+
+	// ProrationMode measures the part of a bill period subscriptions are
+	// charged for when they start or end mid-period: DAY or SECOND
+	ProrationMode string
+
+	// Meters are the kinds of usage that can be recorded against bills and
+	// their prices, billed as USAGE line items when a bill closes
+	Meters []MeterConfig
+
+	// Notifications configures the customer notifications sent on bill events
+	Notifications NotificationConfig
+
+	// Outbox configures the relay publishing billing events from the outbox
+	Outbox OutboxConfig
+
+	// Payments configures payment terms and dunning of closed bills
+	Payments PaymentConfig
+
+	// Settlement configures collecting closed bills by bank transfer
+	Settlement SettlementConfig
+
+	// Reconciliation configures the job comparing bill totals with their line items
+	Reconciliation ReconciliationConfig
+}
+
+// NotificationConfig selects the notification channel and its settings.
+type NotificationConfig struct {
+	// Channel is email, webhook or log; empty disables notifications
+	Channel string
+
+	// SMTPAddr and SMTPFrom configure the email channel
+	SMTPAddr string
+	SMTPFrom string
+
+	// WebhookURL receives notifications on the webhook channel
+	WebhookURL string
+
+	// LargeChargeCents is the line item amount from which customers are told
+	// about the charge, zero disables large charge notifications
+	LargeChargeCents int64
+}
+
+// OutboxConfig selects where the relay publishes billing events besides the
+// webhook subscriptions, which always receive them.
+type OutboxConfig struct {
+	// Sink is kafka or file; empty publishes to webhook subscriptions only
+	Sink string
+
+	// KafkaURL is the Kafka REST proxy the kafka sink produces to KafkaTopic through
+	KafkaURL   string
+	KafkaTopic string
+
+	// FilePath is the file the file sink appends events to
+	FilePath string
+
+	// BatchSize and PollIntervalMillis tune the relay, zero uses its defaults
+	BatchSize          int
+	PollIntervalMillis int
+}
+
+// PaymentConfig sets the terms closed bills are due under.
+type PaymentConfig struct {
+	// TermsDays is the time from close to the due date, zero disables due
+	// dates and dunning
+	TermsDays int
+}
+
+// SettlementConfig points bill settlement at the money transfer worker.
+type SettlementConfig struct {
+	// TargetAccount receives the settled amounts, empty disables settlement
+	TargetAccount string
+
+	// TaskQueue is the task queue the money transfer worker listens on
+	TaskQueue string
+}
+
+// ReconciliationConfig schedules the reconciliation job, which records bills
+// whose total_cents, line items and workflow total disagree.
+type ReconciliationConfig struct {
+	// IntervalMinutes is the pause between passes, ClosedWithinDays how long
+	// closed bills are still checked; zero uses the job's defaults
+	IntervalMinutes  int
+	ClosedWithinDays int
+	BatchSize        int
+
+	// AutoRepair sets total_cents to the sum of the line items where they
+	// disagree, instead of only recording the finding
+	AutoRepair bool
+}
+
+// FXRate is the price of one unit of From in To, as a decimal string. The
+// inverse pair is derived when it is not listed.
+type FXRate struct {
+	From string
+	To   string
+	Rate string
+}
+
+// TaxRate is the rate charged on one fee type for bills in one currency.
+type TaxRate struct {
+	Currency    string
+	FeeType     string
+	BasisPoints int64 // 1800 is 18%
+}
+
+// MeterConfig is a kind of usage and its price in each currency it is sold in.
+type MeterConfig struct {
+	Name   string
+	Unit   string
+	Prices []MeterPrice
+}
+
+// MeterPrice prices a meter in one currency. Model is FLAT, TIERED or VOLUME;
+// FLAT uses UnitPriceCents, the others Tiers. Prices are per PerUnits units.
+type MeterPrice struct {
+	Currency       string
+	Model          string
+	PerUnits       int64
+	UnitPriceCents int64
+	Tiers          []MeterTier
+}
+
+// MeterTier is a price band, UpTo zero marks the unbounded last one.
+type MeterTier struct {
+	UpTo           int64
+	UnitPriceCents int64
+}
+
+// UsageCatalog builds the meter catalog, nil when no meters are configured and
+// usage metering is disabled.
+func (c *Config) UsageCatalog() (usage.Catalog, error) {
+	if len(c.Meters) == 0 {
+		return nil, nil
+	}
+
+	catalog := make(usage.Catalog, len(c.Meters))
+	for _, m := range c.Meters {
+		meter := usage.Meter{Name: m.Name, Unit: m.Unit, Prices: make(map[string]usage.Price, len(m.Prices))}
+		for _, p := range m.Prices {
+			price := usage.Price{
+				Model:          usage.PricingModel(p.Model),
+				PerUnits:       p.PerUnits,
+				UnitPriceCents: p.UnitPriceCents,
+			}
+			for _, tier := range p.Tiers {
+				price.Tiers = append(price.Tiers, usage.Tier{UpTo: tier.UpTo, UnitPriceCents: tier.UnitPriceCents})
+			}
+			if err := price.Validate(); err != nil {
+				return nil, fmt.Errorf("meter %s in %s: %w", m.Name, p.Currency, err)
+			}
+			meter.Prices[p.Currency] = price
+		}
+		catalog[m.Name] = meter
+	}
+	return catalog, nil
+}
+
+// ProrationCalculator builds the calculator subscription fees are prorated
+// with, measuring to the second unless days are configured.
+func (c *Config) ProrationCalculator() (proration.Calculator, error) {
+	mode := proration.Mode(c.ProrationMode)
+	if mode == "" {
+		mode = proration.ModeSecond
+	}
+	if !mode.IsValid() {
+		return proration.Calculator{}, fmt.Errorf("unknown proration mode %q", c.ProrationMode)
+	}
+	return proration.Calculator{Mode: mode}, nil
+}
+
+// TaxRateTable converts the configured tax rates into the bill workflow's rate table.
+func (c *Config) TaxRateTable() bill.TaxRateTable {
+	table := make(bill.TaxRateTable)
+	for _, rate := range c.TaxRates {
+		if table[rate.Currency] == nil {
+			table[rate.Currency] = make(map[entity.FeeType]int64)
+		}
+		table[rate.Currency][entity.FeeType(rate.FeeType)] = rate.BasisPoints
+	}
+	return table
+}
+
+// SettlementAccounts converts the settlement settings into the accounts bills
+// are settled into; the source account comes with each bill.
+func (c *Config) SettlementAccounts() bill.SettlementAccounts {
+	return bill.SettlementAccounts{
+		TargetAccount: c.Settlement.TargetAccount,
+		TaskQueue:     c.Settlement.TaskQueue,
+	}
+}
+
+// FXRateProvider builds the static FX rate table, nil when no rates are
+// configured and currency conversion is disabled.
+func (c *Config) FXRateProvider() currency.FXRateProvider {
+	if len(c.FXRates) == 0 {
+		return nil
+	}
+	rates := make(currency.StaticRates, len(c.FXRates))
+	for _, rate := range c.FXRates {
+		rates[rate.From+"/"+rate.To] = rate.Rate
+	}
+	return rates
+}
+
+// Notifier builds the configured customer notifier, nil when notifications are disabled.
+func (c *Config) Notifier() (notify.Notifier, error) {
+	if c.Notifications.Channel == "" {
+		return nil, nil
+	}
+	return notify.New(c.Notifications.Channel, notify.EmailConfig{
+		Addr: c.Notifications.SMTPAddr,
+		From: c.Notifications.SMTPFrom,
+	}, c.Notifications.WebhookURL)
+}
+
+// OutboxSink builds the configured outbox sink, nil when events only go to webhooks.
+func (c *Config) OutboxSink() (outbox.Sink, error) {
+	if c.Outbox.Sink == "" {
+		return nil, nil
+	}
+	return outbox.NewSink(c.Outbox.Sink, outbox.SinkConfig{
+		KafkaURL:   c.Outbox.KafkaURL,
+		KafkaTopic: c.Outbox.KafkaTopic,
+		FilePath:   c.Outbox.FilePath,
+	})
+}
+
+// RelayInput converts the outbox settings into the relay workflow's input.
+func (c *Config) RelayInput() outbox.RelayWorkflowInput {
+	return outbox.RelayWorkflowInput{
+		BatchSize:    c.Outbox.BatchSize,
+		PollInterval: time.Duration(c.Outbox.PollIntervalMillis) * time.Millisecond,
+	}
+}
+
+// ReconciliationInput converts the reconciliation settings into the job's input.
+func (c *Config) ReconciliationInput() reconcile.ReconciliationWorkflowInput {
+	return reconcile.ReconciliationWorkflowInput{
+		BatchSize:    c.Reconciliation.BatchSize,
+		Interval:     time.Duration(c.Reconciliation.IntervalMinutes) * time.Minute,
+		ClosedWithin: time.Duration(c.Reconciliation.ClosedWithinDays) * 24 * time.Hour,
+		AutoRepair:   c.Reconciliation.AutoRepair,
+	}
 }
 
 var cfg = config.Load[*Config]()
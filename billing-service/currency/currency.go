@@ -0,0 +1,82 @@
+// Package currency knows the ISO-4217 currencies bills are kept in and
+// converts amounts between them.
+package currency
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnknownCurrency is returned for codes that are not in the registry.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Currency is an ISO-4217 currency. Amounts are kept in its minor units, so
+// MinorUnits is the number of decimals between them and the major unit.
+type Currency struct {
+	Code       string
+	MinorUnits int
+}
+
+// registry holds the currencies billing accepts, keyed by code.
+var registry = map[string]Currency{}
+
+func init() {
+	for _, c := range []Currency{
+		{Code: "AED", MinorUnits: 2},
+		{Code: "AUD", MinorUnits: 2},
+		{Code: "BHD", MinorUnits: 3},
+		{Code: "BRL", MinorUnits: 2},
+		{Code: "CAD", MinorUnits: 2},
+		{Code: "CHF", MinorUnits: 2},
+		{Code: "CLP", MinorUnits: 0},
+		{Code: "CNY", MinorUnits: 2},
+		{Code: "CZK", MinorUnits: 2},
+		{Code: "DKK", MinorUnits: 2},
+		{Code: "EUR", MinorUnits: 2},
+		{Code: "GBP", MinorUnits: 2},
+		{Code: "GEL", MinorUnits: 2},
+		{Code: "HKD", MinorUnits: 2},
+		{Code: "HUF", MinorUnits: 2},
+		{Code: "ILS", MinorUnits: 2},
+		{Code: "INR", MinorUnits: 2},
+		{Code: "ISK", MinorUnits: 0},
+		{Code: "JOD", MinorUnits: 3},
+		{Code: "JPY", MinorUnits: 0},
+		{Code: "KRW", MinorUnits: 0},
+		{Code: "KWD", MinorUnits: 3},
+		{Code: "MXN", MinorUnits: 2},
+		{Code: "NOK", MinorUnits: 2},
+		{Code: "NZD", MinorUnits: 2},
+		{Code: "OMR", MinorUnits: 3},
+		{Code: "PLN", MinorUnits: 2},
+		{Code: "SEK", MinorUnits: 2},
+		{Code: "SGD", MinorUnits: 2},
+		{Code: "TRY", MinorUnits: 2},
+		{Code: "UAH", MinorUnits: 2},
+		{Code: "USD", MinorUnits: 2},
+		{Code: "VND", MinorUnits: 0},
+		{Code: "ZAR", MinorUnits: 2},
+	} {
+		registry[c.Code] = c
+	}
+}
+
+// Lookup returns the currency with the given code, codes are upper case.
+func Lookup(code string) (Currency, error) {
+	c, ok := registry[code]
+	if !ok {
+		return Currency{}, ErrUnknownCurrency
+	}
+	return c, nil
+}
+
+// IsValid reports whether code is a currency bills can be kept in.
+func IsValid(code string) bool {
+	_, ok := registry[code]
+	return ok
+}
+
+// Normalize upper-cases and trims a currency code from a request.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
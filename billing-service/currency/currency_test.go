@@ -0,0 +1,79 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	jpy, err := Lookup("JPY")
+	require.NoError(t, err)
+	assert.Equal(t, 0, jpy.MinorUnits)
+
+	kwd, err := Lookup("KWD")
+	require.NoError(t, err)
+	assert.Equal(t, 3, kwd.MinorUnits)
+
+	_, err = Lookup("XYZ")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+
+	assert.True(t, IsValid("GEL"))
+	assert.False(t, IsValid("usd"))
+	assert.Equal(t, "USD", Normalize(" usd "))
+}
+
+func TestStaticRates(t *testing.T) {
+	rates := StaticRates{"EUR/USD": "1.25"}
+
+	rate, err := rates.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "1.25", FormatRate(rate))
+
+	inverse, err := rates.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "0.8", FormatRate(inverse))
+
+	same, err := rates.Rate(context.Background(), "GEL", "GEL")
+	require.NoError(t, err)
+	assert.Equal(t, "1", FormatRate(same))
+
+	_, err = rates.Rate(context.Background(), "EUR", "JPY")
+	assert.True(t, errors.Is(err, ErrRateUnavailable))
+
+	_, err = StaticRates{"EUR/USD": "-1"}.Rate(context.Background(), "EUR", "USD")
+	assert.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	usd, _ := Lookup("USD")
+	eur, _ := Lookup("EUR")
+	jpy, _ := Lookup("JPY")
+	kwd, _ := Lookup("KWD")
+
+	tests := []struct {
+		name     string
+		amount   int64
+		from, to Currency
+		rate     string
+		want     int64
+	}{
+		{name: "same minor units", amount: 1000, from: eur, to: usd, rate: "1.0850", want: 1085},
+		{name: "rounds half away from zero", amount: 1, from: eur, to: usd, rate: "1.5", want: 2},
+		{name: "negative amounts round symmetrically", amount: -1, from: eur, to: usd, rate: "1.5", want: -2},
+		{name: "into fewer minor units", amount: 1000, from: usd, to: jpy, rate: "150.25", want: 1503},
+		{name: "into more minor units", amount: 1500, from: jpy, to: usd, rate: "0.0066", want: 990},
+		{name: "into three minor units", amount: 10000, from: usd, to: kwd, rate: "0.3075", want: 30750},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Convert(tt.amount, tt.from, tt.to, rate))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrRateUnavailable is returned when a provider has no rate for a currency pair.
+var ErrRateUnavailable = errors.New("fx rate unavailable")
+
+// rateDecimals is the precision rates are stored and reported with.
+const rateDecimals = 10
+
+// FXRateProvider quotes exchange rates: the price of one unit of from in to.
+type FXRateProvider interface {
+	Rate(ctx context.Context, from, to string) (*big.Rat, error)
+}
+
+// StaticRates is an FXRateProvider backed by a fixed table, keyed by pair as
+// "EUR/USD" with decimal rates. A pair missing from the table is served from
+// its inverse when that is present.
+type StaticRates map[string]string
+
+// Ensure StaticRates implements FXRateProvider.
+var _ FXRateProvider = StaticRates(nil)
+
+func (s StaticRates) Rate(_ context.Context, from, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if rate, ok := s[from+"/"+to]; ok {
+		return ParseRate(rate)
+	}
+	if rate, ok := s[to+"/"+from]; ok {
+		inverse, err := ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		return inverse.Inv(inverse), nil
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+}
+
+// ParseRate parses a positive decimal rate.
+func ParseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid fx rate %q", rate)
+	}
+	return r, nil
+}
+
+// FormatRate renders a rate the way it is stored, without trailing zeros.
+func FormatRate(rate *big.Rat) string {
+	s := rate.FloatString(rateDecimals)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Convert converts an amount in from's minor units into to's minor units at
+// rate, rounding half away from zero.
+func Convert(amount int64, from, to Currency, rate *big.Rat) int64 {
+	converted := new(big.Rat).Mul(big.NewRat(amount, 1), rate)
+	converted.Mul(converted, scale(to.MinorUnits-from.MinorUnits))
+	return round(converted)
+}
+
+// scale returns 10^exp, which is a fraction for negative exponents.
+func scale(exp int) *big.Rat {
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), factor)
+	}
+	return new(big.Rat).SetInt(factor)
+}
+
+func round(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo.Int64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
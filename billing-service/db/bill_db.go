@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
@@ -9,59 +10,272 @@ import (
 	"encore.dev/storage/sqldb"
 )
 
+// billPaidCentsColumn derives a bill's paid amount from its payments.
+const billPaidCentsColumn = `(SELECT COALESCE(SUM(amount_cents), 0) FROM payments WHERE payments.bill_uuid = bills.uuid)`
+
 func FetchBillByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.BillEntity, error) {
 	query := `
 		SELECT
-			uuid, customer_uuid, currency, status, period_start, period_end, closed_at, total_cents, created_at, updated_at
+			uuid, customer_uuid, currency, status, period_start, period_end, closed_at, total_cents,
+			due_date, ` + billPaidCentsColumn + `, recurrence_cadence, recurrence_interval_days,
+			recurrence_anchor_day, created_at, updated_at
 		FROM bills
 			WHERE uuid = $1
 	`
 	b := &entity.BillEntity{}
 
+	var cadence *string
+	var intervalDays, anchorDay *int
 	err := db.QueryRow(ctx, query, uuid).
 		Scan(&b.UUID, &b.CustomerUUID, &b.Currency, &b.Status, &b.PeriodStart,
-			&b.PeriodEnd, &b.ClosedAt, &b.TotalCents, &b.CreatedAt, &b.UpdatedAt)
+			&b.PeriodEnd, &b.ClosedAt, &b.TotalCents, &b.DueDate, &b.PaidCents,
+			&cadence, &intervalDays, &anchorDay, &b.CreatedAt, &b.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+	if cadence != nil {
+		b.Recurrence = &entity.BillRecurrence{Cadence: entity.BillCadence(*cadence)}
+		if intervalDays != nil {
+			b.Recurrence.IntervalDays = *intervalDays
+		}
+		if anchorDay != nil {
+			b.Recurrence.AnchorDay = *anchorDay
+		}
+	}
 	return b, nil
 }
 
 func InsertBill(ctx context.Context, db *sqldb.Database, bill *entity.BillEntity) error {
-	_, insertErr := db.Exec(ctx, `
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"uuid", bill.UUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var cadence *string
+	var intervalDays, anchorDay *int
+	if r := bill.Recurrence; r != nil {
+		c := r.Cadence.String()
+		cadence = &c
+		if r.IntervalDays > 0 {
+			intervalDays = &r.IntervalDays
+		}
+		if r.AnchorDay > 0 {
+			anchorDay = &r.AnchorDay
+		}
+	}
+
+	// a bill opened for a merged customer, by a recurring series that closed
+	// before the merge, goes to the customer it was merged into; the key share
+	// lock makes a merge in progress wait for the bill or the bill for the merge
+	insertErr := tx.QueryRow(ctx, `
 		INSERT INTO bills
-			(uuid, customer_uuid, currency, period_start, period_end, total_cents)
+			(uuid, customer_uuid, currency, period_start, period_end, total_cents,
+			 recurrence_cadence, recurrence_interval_days, recurrence_anchor_day)
 		VALUES
-			($1, $2, $3, $4, $5, 0)
-	`, bill.UUID, bill.CustomerUUID, bill.Currency, bill.PeriodStart, bill.PeriodEnd)
+			($1, COALESCE((SELECT merged_into_uuid FROM customers WHERE uuid = $2 FOR KEY SHARE), $2), $3, $4, $5, 0,
+			 $6, $7, $8)
+		RETURNING customer_uuid
+	`, bill.UUID, bill.CustomerUUID, bill.Currency, bill.PeriodStart, bill.PeriodEnd,
+		cadence, intervalDays, anchorDay).Scan(&bill.CustomerUUID)
 	if insertErr != nil {
 		slog.ErrorContext(ctx, "error inserting bill",
 			"uuid", bill.UUID,
-			"err", insertErr.Error)
+			"err", insertErr.Error())
 
 		return insertErr
 	}
-	return nil
+
+	if err = insertBillStatusHistory(ctx, tx, bill.UUID, "", entity.BillStatusOpen, ""); err != nil {
+		return err
+	}
+
+	err = insertOutboxEvent(ctx, tx, bill.UUID, entity.WebhookEventBillCreated, entity.BillEventData{
+		UUID:         bill.UUID,
+		CustomerUUID: bill.CustomerUUID,
+		Currency:     bill.Currency,
+		PeriodStart:  &bill.PeriodStart,
+		PeriodEnd:    &bill.PeriodEnd,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func CloseBill(ctx context.Context, db *sqldb.Database, billUUID string, closedAt time.Time) error {
-	_, err := db.Exec(ctx, `
+// CloseBill locks the totals of a CLOSING bill, sets its due date and records
+// bill.closed in the same transaction. Closing an already closed bill is a no-op
+// so a retried activity does not fail.
+func CloseBill(ctx context.Context, db *sqldb.Database, billUUID string, closedAt time.Time, dueDate *time.Time) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var totalCents int64
+	err = tx.QueryRow(ctx, `
 		UPDATE bills
 		SET status = 'CLOSED',
 		    closed_at = $2,
-		    total_cents = (
-		        SELECT COALESCE(SUM(amount_cents), 0)
-		        FROM line_items
-		        WHERE bill_uuid = $1
-		    ),
+		    total_cents = (`+billLedgerTotal+`),
+		    due_date = $3,
 		    updated_at = $2
 		WHERE
-			uuid = $1 AND status = 'OPEN'
-	`, billUUID, closedAt)
+			uuid = $1 AND status = 'CLOSING'
+		RETURNING total_cents
+	`, billUUID, closedAt, dueDate).Scan(&totalCents)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
 		slog.ErrorContext(ctx, "error closing bill",
 			"uuid", billUUID,
-			"err", err.Error)
+			"err", err.Error())
+		return err
+	}
+
+	err = insertBillStatusHistory(ctx, tx, billUUID, entity.BillStatusClosing, entity.BillStatusClosed, "")
+	if err != nil {
+		return err
+	}
+
+	err = insertOutboxEvent(ctx, tx, billUUID, entity.WebhookEventBillClosed, entity.BillEventData{
+		UUID:       billUUID,
+		TotalCents: &totalCents,
+		ClosedAt:   &closedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// VoidBill cancels an OPEN bill, zeroing its total, taking back what its line
+// items posted to the ledger and recording the reason in the status history.
+// Voiding an already voided bill is a no-op so retries are safe; any other
+// status returns entity.ErrInvalidBillTransition.
+func VoidBill(ctx context.Context, db *sqldb.Database, billUUID string, reason string, voidedAt time.Time) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(ctx, `
+		UPDATE bills
+		SET status = 'VOIDED',
+		    total_cents = 0,
+		    updated_at = $2
+		WHERE
+			uuid = $1 AND status = 'OPEN'
+	`, billUUID, voidedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error voiding bill",
+			"uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		var current string
+		err = tx.QueryRow(ctx, `SELECT status FROM bills WHERE uuid = $1`, billUUID).Scan(&current)
+		if err != nil {
+			return err
+		}
+		if current == entity.BillStatusVoided.String() {
+			return nil
+		}
+		return entity.ErrInvalidBillTransition
+	}
+
+	err = insertBillStatusHistory(ctx, tx, billUUID, entity.BillStatusOpen, entity.BillStatusVoided, reason)
+	if err != nil {
+		return err
+	}
+
+	if err = postBillVoid(ctx, tx, billUUID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateBillStatus moves a bill from one status to another and records the
+// transition. A bill already in the target status is left untouched, so retries
+// are safe; any other status mismatch returns entity.ErrInvalidBillTransition.
+func UpdateBillStatus(ctx context.Context, db *sqldb.Database, billUUID string, from, to entity.BillStatus) error {
+	if !from.CanTransitionTo(to) {
+		return entity.ErrInvalidBillTransition
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(ctx, `
+		UPDATE bills
+		SET status = $3,
+		    updated_at = NOW()
+		WHERE
+			uuid = $1 AND status = $2
+	`, billUUID, from.String(), to.String())
+	if err != nil {
+		slog.ErrorContext(ctx, "error updating bill status",
+			"uuid", billUUID,
+			"from", from,
+			"to", to,
+			"err", err.Error())
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		var current string
+		err = tx.QueryRow(ctx, `SELECT status FROM bills WHERE uuid = $1`, billUUID).Scan(&current)
+		if err != nil {
+			return err
+		}
+		if current == to.String() {
+			return nil
+		}
+		return entity.ErrInvalidBillTransition
+	}
+
+	if err = insertBillStatusHistory(ctx, tx, billUUID, from, to, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertBillStatusHistory(ctx context.Context, tx *sqldb.Tx, billUUID string, from, to entity.BillStatus, reason string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO bill_status_history
+			(bill_uuid, from_status, to_status, reason)
+		VALUES
+			($1, NULLIF($2, ''), $3, NULLIF($4, ''))
+	`, billUUID, from.String(), to.String(), reason)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting bill status history",
+			"uuid", billUUID,
+			"to", to,
+			"err", err.Error())
 		return err
 	}
 	return nil
@@ -95,7 +309,8 @@ func FetchBills(ctx context.Context, db *sqldb.Database, params BillQueryParams)
 			// DESC with cursor
 			query = `
 				SELECT id, uuid, customer_uuid, currency, status, period_start,
-				       period_end, closed_at, total_cents, created_at, updated_at
+				       period_end, closed_at, total_cents, due_date, ` + billPaidCentsColumn + `,
+				       created_at, updated_at
 				FROM bills
 				WHERE (created_at, id) < ($1, $2)
 				  AND ($3 = '' OR customer_uuid = $3)
@@ -108,7 +323,8 @@ func FetchBills(ctx context.Context, db *sqldb.Database, params BillQueryParams)
 			// DESC first page
 			query = `
 				SELECT id, uuid, customer_uuid, currency, status, period_start,
-				       period_end, closed_at, total_cents, created_at, updated_at
+				       period_end, closed_at, total_cents, due_date, ` + billPaidCentsColumn + `,
+				       created_at, updated_at
 				FROM bills
 				WHERE ($1 = '' OR customer_uuid = $1)
 				  AND ($2 = '' OR status = $2)
@@ -122,7 +338,8 @@ func FetchBills(ctx context.Context, db *sqldb.Database, params BillQueryParams)
 			// ASC with cursor
 			query = `
 				SELECT id, uuid, customer_uuid, currency, status, period_start,
-				       period_end, closed_at, total_cents, created_at, updated_at
+				       period_end, closed_at, total_cents, due_date, ` + billPaidCentsColumn + `,
+				       created_at, updated_at
 				FROM bills
 				WHERE (created_at, id) > ($1, $2)
 				  AND ($3 = '' OR customer_uuid = $3)
@@ -135,7 +352,8 @@ func FetchBills(ctx context.Context, db *sqldb.Database, params BillQueryParams)
 			// ASC first page
 			query = `
 				SELECT id, uuid, customer_uuid, currency, status, period_start,
-				       period_end, closed_at, total_cents, created_at, updated_at
+				       period_end, closed_at, total_cents, due_date, ` + billPaidCentsColumn + `,
+				       created_at, updated_at
 				FROM bills
 				WHERE ($1 = '' OR customer_uuid = $1)
 				  AND ($2 = '' OR status = $2)
@@ -158,7 +376,7 @@ func FetchBills(ctx context.Context, db *sqldb.Database, params BillQueryParams)
 		b := &entity.BillEntity{}
 		err := rows.Scan(&b.ID, &b.UUID, &b.CustomerUUID, &b.Currency, &b.Status,
 			&b.PeriodStart, &b.PeriodEnd, &b.ClosedAt, &b.TotalCents,
-			&b.CreatedAt, &b.UpdatedAt)
+			&b.DueDate, &b.PaidCents, &b.CreatedAt, &b.UpdatedAt)
 		if err != nil {
 			slog.ErrorContext(ctx, "error scanning bill row", "err", err.Error())
 			return nil, err
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const billingProfileColumns = `
+	p.customer_uuid, p.address_line1, p.address_line2, p.city, p.postal_code, p.region, p.country,
+	p.tax_id, COALESCE(p.payment_terms, ''), p.invoice_delivery, c.currency, p.created_at, p.updated_at`
+
+// FetchBillingProfile fetches a customer's billing profile, sqldb.ErrNoRows
+// when the customer has none.
+func FetchBillingProfile(ctx context.Context, db *sqldb.Database, customerUUID string) (*entity.BillingProfileEntity, error) {
+	return scanBillingProfile(db.QueryRow(ctx, `
+		SELECT `+billingProfileColumns+`
+		FROM customer_billing_profiles p
+		JOIN customers c ON c.uuid = p.customer_uuid
+		WHERE p.customer_uuid = $1
+	`, customerUUID))
+}
+
+// UpsertBillingProfile stores a customer's billing profile, replacing the one
+// they had, and sets their default currency when profile has one, keeping the
+// customer's current one otherwise. The stored profile is loaded back into
+// profile.
+func UpsertBillingProfile(ctx context.Context, db *sqldb.Database, profile *entity.BillingProfileEntity) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"customer_uuid", profile.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE customers SET currency = COALESCE($2, currency), updated_at = NOW() WHERE uuid = $1
+	`, profile.CustomerUUID, profile.Currency)
+	if err != nil {
+		slog.ErrorContext(ctx, "error updating customer currency",
+			"customer_uuid", profile.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+
+	var paymentTerms *entity.PaymentTerms
+	if profile.PaymentTerms != "" {
+		paymentTerms = &profile.PaymentTerms
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO customer_billing_profiles
+			(customer_uuid, address_line1, address_line2, city, postal_code, region, country,
+			 tax_id, payment_terms, invoice_delivery)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (customer_uuid) DO UPDATE SET
+			address_line1    = EXCLUDED.address_line1,
+			address_line2    = EXCLUDED.address_line2,
+			city             = EXCLUDED.city,
+			postal_code      = EXCLUDED.postal_code,
+			region           = EXCLUDED.region,
+			country          = EXCLUDED.country,
+			tax_id           = EXCLUDED.tax_id,
+			payment_terms    = EXCLUDED.payment_terms,
+			invoice_delivery = EXCLUDED.invoice_delivery,
+			updated_at       = NOW()
+	`, profile.CustomerUUID, profile.AddressLine1, profile.AddressLine2, profile.City, profile.PostalCode,
+		profile.Region, profile.Country, profile.TaxID, paymentTerms, profile.InvoiceDelivery)
+	if err != nil {
+		slog.ErrorContext(ctx, "error upserting billing profile",
+			"customer_uuid", profile.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+
+	stored, err := scanBillingProfile(tx.QueryRow(ctx, `
+		SELECT `+billingProfileColumns+`
+		FROM customer_billing_profiles p
+		JOIN customers c ON c.uuid = p.customer_uuid
+		WHERE p.customer_uuid = $1
+	`, profile.CustomerUUID))
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	*profile = *stored
+	return nil
+}
+
+func scanBillingProfile(row rowScanner) (*entity.BillingProfileEntity, error) {
+	p := &entity.BillingProfileEntity{}
+	err := row.Scan(&p.CustomerUUID, &p.AddressLine1, &p.AddressLine2, &p.City, &p.PostalCode, &p.Region,
+		&p.Country, &p.TaxID, &p.PaymentTerms, &p.InvoiceDelivery, &p.Currency, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const priceColumns = `
+	id, uuid, product_uuid, currency, amount_cents, effective_from, created_at`
+
+// InsertProduct stores a new product. Returns entity.ErrProductCodeTaken when
+// the code is already in use.
+func InsertProduct(ctx context.Context, db *sqldb.Database, product *entity.ProductEntity) error {
+	err := db.QueryRow(ctx, `
+		INSERT INTO products (uuid, code, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id, created_at
+	`, product.UUID, product.Code, product.Name).Scan(&product.ID, &product.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return entity.ErrProductCodeTaken
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting product",
+			"code", product.Code,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FetchProductByCode fetches a product, sqldb.ErrNoRows when it does not exist.
+func FetchProductByCode(ctx context.Context, db *sqldb.Database, code string) (*entity.ProductEntity, error) {
+	return scanProduct(db.QueryRow(ctx, `
+		SELECT id, uuid, code, name, created_at
+		FROM products
+		WHERE code = $1
+	`, code))
+}
+
+// FetchProductByUUID fetches a product, sqldb.ErrNoRows when it does not exist.
+func FetchProductByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.ProductEntity, error) {
+	return scanProduct(db.QueryRow(ctx, `
+		SELECT id, uuid, code, name, created_at
+		FROM products
+		WHERE uuid = $1
+	`, uuid))
+}
+
+// InsertPrice stores a product price. Returns entity.ErrPriceExists when the
+// product already has a price in the currency from the same effective time.
+func InsertPrice(ctx context.Context, db *sqldb.Database, price *entity.PriceEntity) error {
+	err := db.QueryRow(ctx, `
+		INSERT INTO prices (uuid, product_uuid, currency, amount_cents, effective_from)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_uuid, currency, effective_from) DO NOTHING
+		RETURNING id, created_at
+	`, price.UUID, price.ProductUUID, price.Currency, price.AmountCents, price.EffectiveFrom).
+		Scan(&price.ID, &price.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return entity.ErrPriceExists
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting price",
+			"product_uuid", price.ProductUUID,
+			"currency", price.Currency,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FetchEffectivePrice returns the price of a product in a currency in effect
+// at the given time, sqldb.ErrNoRows when the product has none yet.
+func FetchEffectivePrice(ctx context.Context, db *sqldb.Database, productUUID, currency string, at time.Time) (*entity.PriceEntity, error) {
+	p := &entity.PriceEntity{}
+	err := db.QueryRow(ctx, `
+		SELECT`+priceColumns+`
+		FROM prices
+		WHERE product_uuid = $1 AND currency = $2 AND effective_from <= $3
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`, productUUID, currency, at).
+		Scan(&p.ID, &p.UUID, &p.ProductUUID, &p.Currency, &p.AmountCents, &p.EffectiveFrom, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func scanProduct(row rowScanner) (*entity.ProductEntity, error) {
+	p := &entity.ProductEntity{}
+	err := row.Scan(&p.ID, &p.UUID, &p.Code, &p.Name, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const creditColumns = `
+	id, uuid, customer_uuid, idempotency_key, currency, amount_cents, remaining_cents, description, created_at`
+
+// InsertCredit grants credit to a customer and posts the grant to the ledger.
+// A grant whose idempotency key was already used for the customer is not
+// inserted again; credit is loaded with the stored row instead, callers
+// compare the UUID to tell the two apart.
+func InsertCredit(ctx context.Context, db *sqldb.Database, credit *entity.CreditEntity) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"customer_uuid", credit.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO customer_credits
+			(uuid, customer_uuid, idempotency_key, currency, amount_cents, remaining_cents, description)
+		VALUES
+			($1, $2, $3, $4, $5, $5, $6)
+		ON CONFLICT (customer_uuid, idempotency_key) DO NOTHING
+	`, credit.UUID, credit.CustomerUUID, credit.IdempotencyKey, credit.Currency, credit.AmountCents, credit.Description)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting customer credit",
+			"uuid", credit.UUID,
+			"customer_uuid", credit.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+	if result.RowsAffected() > 0 {
+		if err = postCreditGrant(ctx, tx, credit); err != nil {
+			return err
+		}
+	}
+
+	stored, err := scanCredit(tx.QueryRow(ctx, `
+		SELECT`+creditColumns+`
+		FROM customer_credits
+		WHERE customer_uuid = $1 AND idempotency_key = $2
+	`, credit.CustomerUUID, credit.IdempotencyKey))
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching customer credit",
+			"customer_uuid", credit.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "error committing transaction",
+			"customer_uuid", credit.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+	*credit = *stored
+	return nil
+}
+
+// FetchAvailableCredit returns the credit a customer has left in a currency.
+func FetchAvailableCredit(ctx context.Context, db *sqldb.Database, customerUUID, currency string) (int64, error) {
+	var remainingCents int64
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(remaining_cents), 0)
+		FROM customer_credits
+		WHERE customer_uuid = $1 AND currency = $2
+	`, customerUUID, currency).Scan(&remainingCents)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing customer credit",
+			"customer_uuid", customerUUID,
+			"err", err.Error())
+		return 0, err
+	}
+	return remainingCents, nil
+}
+
+// ApplyCredits consumes the customer's credit in the bill currency towards
+// dueCents, oldest credit first. The credits are locked while they are drawn
+// down, so two closing bills cannot spend the same credit. A bill consumes
+// credit once: when it already did, the stored applications are returned and
+// dueCents is ignored.
+func ApplyCredits(ctx context.Context, db *sqldb.Database, billUUID, customerUUID, currency string, dueCents int64) ([]*entity.CreditApplicationEntity, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	applications, err := fetchCreditApplications(ctx, tx, billUUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(applications) > 0 || dueCents <= 0 {
+		return applications, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT`+creditColumns+`
+		FROM customer_credits
+		WHERE customer_uuid = $1 AND currency = $2 AND remaining_cents > 0
+		ORDER BY created_at ASC, id ASC
+		FOR UPDATE
+	`, customerUUID, currency)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching customer credits",
+			"customer_uuid", customerUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	var credits []*entity.CreditEntity
+	for rows.Next() {
+		c, err := scanCredit(rows)
+		if err != nil {
+			rows.Close()
+			slog.ErrorContext(ctx, "error scanning customer credit row", "err", err.Error())
+			return nil, err
+		}
+		credits = append(credits, c)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, credit := range credits {
+		if dueCents == 0 {
+			break
+		}
+		amountCents := min(credit.RemainingCents, dueCents)
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO credit_applications (credit_uuid, bill_uuid, amount_cents)
+			VALUES ($1, $2, $3)
+		`, credit.UUID, billUUID, amountCents)
+		if err != nil {
+			slog.ErrorContext(ctx, "error inserting credit application",
+				"credit_uuid", credit.UUID,
+				"bill_uuid", billUUID,
+				"err", err.Error())
+			return nil, err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE customer_credits SET remaining_cents = remaining_cents - $2 WHERE uuid = $1
+		`, credit.UUID, amountCents)
+		if err != nil {
+			slog.ErrorContext(ctx, "error drawing down customer credit",
+				"credit_uuid", credit.UUID,
+				"err", err.Error())
+			return nil, err
+		}
+
+		applications = append(applications, &entity.CreditApplicationEntity{
+			CreditUUID:  credit.UUID,
+			BillUUID:    billUUID,
+			AmountCents: amountCents,
+		})
+		dueCents -= amountCents
+	}
+
+	if err = tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "error committing transaction",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func fetchCreditApplications(ctx context.Context, tx *sqldb.Tx, billUUID string) ([]*entity.CreditApplicationEntity, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT credit_uuid, bill_uuid, amount_cents
+		FROM credit_applications
+		WHERE bill_uuid = $1
+		ORDER BY id ASC
+	`, billUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching credit applications", "bill_uuid", billUUID, "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applications []*entity.CreditApplicationEntity
+	for rows.Next() {
+		a := &entity.CreditApplicationEntity{}
+		if err := rows.Scan(&a.CreditUUID, &a.BillUUID, &a.AmountCents); err != nil {
+			return nil, err
+		}
+		applications = append(applications, a)
+	}
+
+	return applications, rows.Err()
+}
+
+func scanCredit(row rowScanner) (*entity.CreditEntity, error) {
+	c := &entity.CreditEntity{}
+	err := row.Scan(&c.ID, &c.UUID, &c.CustomerUUID, &c.IdempotencyKey, &c.Currency, &c.AmountCents,
+		&c.RemainingCents, &c.Description, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
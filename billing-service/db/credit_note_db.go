@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const creditNoteColumns = `
+	id, uuid, bill_uuid, customer_uuid, idempotency_key, sequence, credit_note_number,
+	currency, amount_cents, reason, status, credit_uuid, issued_at, applied_at`
+
+// InsertCreditNote issues a credit note against a closed bill, numbering it
+// with the customer's next credit note sequence. Each line credits the given
+// amount of a bill line item, a zero amount credits what the line item has
+// left. The line items are locked while their earlier credit is summed, so
+// concurrent notes cannot credit the same amount twice.
+//
+// A note whose idempotency key was already used on the bill is not issued
+// again; note is loaded with the stored one instead, callers compare the UUID
+// to tell the two apart. Returns entity.ErrCreditNoteBillNotClosed when the
+// bill does not accept credit notes, entity.ErrCreditExceedsLineItem when a
+// line credits more than is left and sqldb.ErrNoRows when a line item is not
+// on the bill.
+func InsertCreditNote(ctx context.Context, db *sqldb.Database, note *entity.CreditNoteEntity) error {
+	stored, err := FetchCreditNoteByKey(ctx, db, note.BillUUID, note.IdempotencyKey)
+	if err == nil {
+		*note = *stored
+		return nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		return err
+	}
+
+	inserted, err := insertCreditNote(ctx, db, note)
+	if err != nil || inserted {
+		return err
+	}
+
+	// lost a race against a concurrent note with the same key
+	stored, err = FetchCreditNoteByKey(ctx, db, note.BillUUID, note.IdempotencyKey)
+	if err != nil {
+		return err
+	}
+	*note = *stored
+	return nil
+}
+
+func insertCreditNote(ctx context.Context, db *sqldb.Database, note *entity.CreditNoteEntity) (bool, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", note.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT customer_uuid, currency, status FROM bills WHERE uuid = $1 FOR SHARE
+	`, note.BillUUID).Scan(&note.CustomerUUID, &note.Currency, &status)
+	if err != nil {
+		return false, err
+	}
+	if !entity.BillStatus(status).AcceptsCreditNotes() {
+		return false, entity.ErrCreditNoteBillNotClosed
+	}
+
+	if err := creditNoteLines(ctx, tx, note); err != nil {
+		return false, err
+	}
+
+	// the row lock on the counter serializes credit note numbering per customer
+	err = tx.QueryRow(ctx, `
+		INSERT INTO customer_credit_note_sequences
+			(customer_uuid, last_sequence)
+		VALUES
+			($1, 1)
+		ON CONFLICT (customer_uuid)
+			DO UPDATE SET last_sequence = customer_credit_note_sequences.last_sequence + 1
+		RETURNING last_sequence
+	`, note.CustomerUUID).Scan(&note.Sequence)
+	if err != nil {
+		slog.ErrorContext(ctx, "error allocating credit note sequence",
+			"customer_uuid", note.CustomerUUID,
+			"err", err.Error())
+		return false, err
+	}
+	note.Number = CreditNoteNumber(note.CustomerUUID, note.Sequence)
+	note.Status = entity.CreditNoteStatusIssued
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO credit_notes
+			(uuid, bill_uuid, customer_uuid, idempotency_key, sequence, credit_note_number,
+			 currency, amount_cents, reason, status)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (bill_uuid, idempotency_key) DO NOTHING
+		RETURNING id, issued_at
+	`, note.UUID, note.BillUUID, note.CustomerUUID, note.IdempotencyKey, note.Sequence, note.Number,
+		note.Currency, note.AmountCents, note.Reason, note.Status).Scan(&note.ID, &note.IssuedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting credit note",
+			"bill_uuid", note.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+
+	lineItemUUIDs := make([]string, len(note.Lines))
+	amounts := make([]int64, len(note.Lines))
+	for i, line := range note.Lines {
+		lineItemUUIDs[i] = line.LineItemUUID
+		amounts[i] = line.AmountCents
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO credit_note_lines
+			(credit_note_uuid, line_item_uuid, amount_cents)
+		SELECT $1, l.line_item_uuid, l.amount_cents
+		FROM unnest($2::UUID[], $3::BIGINT[]) AS l(line_item_uuid, amount_cents)
+	`, note.UUID, lineItemUUIDs, amounts)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting credit note lines",
+			"credit_note_uuid", note.UUID,
+			"err", err.Error())
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "error committing transaction",
+			"credit_note_uuid", note.UUID,
+			"err", err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+// creditNoteLines locks the note's line items, checks each line against what
+// reversals and earlier notes left of its line item and sets the note amount.
+func creditNoteLines(ctx context.Context, tx *sqldb.Tx, note *entity.CreditNoteEntity) error {
+	note.AmountCents = 0
+	for _, line := range note.Lines {
+		var lineItemCents, reversedCents, creditedCents int64
+		err := tx.QueryRow(ctx, `
+			SELECT amount_cents, reversed_cents FROM line_items WHERE uuid = $1 AND bill_uuid = $2 FOR UPDATE
+		`, line.LineItemUUID, note.BillUUID).Scan(&lineItemCents, &reversedCents)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(amount_cents), 0) FROM credit_note_lines WHERE line_item_uuid = $1
+		`, line.LineItemUUID).Scan(&creditedCents)
+		if err != nil {
+			slog.ErrorContext(ctx, "error summing line item credit",
+				"line_item_uuid", line.LineItemUUID,
+				"err", err.Error())
+			return err
+		}
+
+		leftCents := lineItemCents - reversedCents - creditedCents
+		if line.AmountCents == 0 {
+			line.AmountCents = leftCents
+		}
+		if line.AmountCents <= 0 || line.AmountCents > leftCents {
+			return entity.ErrCreditExceedsLineItem
+		}
+		note.AmountCents += line.AmountCents
+	}
+	return nil
+}
+
+// FetchCreditNoteByUUID returns a credit note with its lines.
+func FetchCreditNoteByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.CreditNoteEntity, error) {
+	note, err := scanCreditNote(db.QueryRow(ctx, `
+		SELECT`+creditNoteColumns+`
+		FROM credit_notes
+		WHERE uuid = $1
+	`, uuid))
+	if err != nil {
+		return nil, err
+	}
+	return withCreditNoteLines(ctx, db, note)
+}
+
+// FetchCreditNoteByKey returns the credit note issued against a bill with the
+// idempotency key, with its lines.
+func FetchCreditNoteByKey(ctx context.Context, db *sqldb.Database, billUUID, idempotencyKey string) (*entity.CreditNoteEntity, error) {
+	note, err := scanCreditNote(db.QueryRow(ctx, `
+		SELECT`+creditNoteColumns+`
+		FROM credit_notes
+		WHERE bill_uuid = $1 AND idempotency_key = $2
+	`, billUUID, idempotencyKey))
+	if err != nil {
+		return nil, err
+	}
+	return withCreditNoteLines(ctx, db, note)
+}
+
+func withCreditNoteLines(ctx context.Context, db *sqldb.Database, note *entity.CreditNoteEntity) (*entity.CreditNoteEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT line_item_uuid, amount_cents
+		FROM credit_note_lines
+		WHERE credit_note_uuid = $1
+		ORDER BY id ASC
+	`, note.UUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching credit note lines",
+			"credit_note_uuid", note.UUID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := &entity.CreditNoteLineEntity{}
+		if err := rows.Scan(&line.LineItemUUID, &line.AmountCents); err != nil {
+			slog.ErrorContext(ctx, "error scanning credit note line row", "err", err.Error())
+			return nil, err
+		}
+		note.Lines = append(note.Lines, line)
+	}
+	return note, rows.Err()
+}
+
+// MarkCreditNoteApplied records the customer credit an issued note granted.
+// Notes that were already applied are left alone, so retries are harmless.
+func MarkCreditNoteApplied(ctx context.Context, db *sqldb.Database, uuid, creditUUID string, appliedAt time.Time) error {
+	_, err := db.Exec(ctx, `
+		UPDATE credit_notes
+		SET status = $2, credit_uuid = $3, applied_at = $4
+		WHERE uuid = $1 AND status = $5
+	`, uuid, entity.CreditNoteStatusApplied, creditUUID, appliedAt, entity.CreditNoteStatusIssued)
+	if err != nil {
+		slog.ErrorContext(ctx, "error marking credit note applied",
+			"credit_note_uuid", uuid,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// CreditNoteNumber prints a customer's credit note sequence, e.g. CN-1A2B3C4D-000007.
+func CreditNoteNumber(customerUUID string, sequence int64) string {
+	return documentNumber("CN", customerUUID, sequence)
+}
+
+func scanCreditNote(row rowScanner) (*entity.CreditNoteEntity, error) {
+	n := &entity.CreditNoteEntity{}
+	err := row.Scan(&n.ID, &n.UUID, &n.BillUUID, &n.CustomerUUID, &n.IdempotencyKey, &n.Sequence, &n.Number,
+		&n.Currency, &n.AmountCents, &n.Reason, &n.Status, &n.CreditUUID, &n.IssuedAt, &n.AppliedAt)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
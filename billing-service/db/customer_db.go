@@ -2,51 +2,369 @@ package db
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
+	"time"
 
 	"encore.app/entity"
 	"encore.dev/storage/sqldb"
 )
 
+const customerColumns = `
+	id, uuid, name, email, currency, status, archived_at, merged_into_uuid, created_at, updated_at`
+
+// CustomerQueryParams filters and pages the customer list, newest first. Name
+// and Email match case-insensitive prefixes.
+type CustomerQueryParams struct {
+	Name       string
+	Email      string
+	Status     string
+	CursorTime time.Time
+	CursorID   int64
+	Limit      int
+}
+
+// InsertCustomer stores a new customer. Returns entity.ErrEmailTaken when an
+// active customer already uses the email.
 func InsertCustomer(ctx context.Context, db *sqldb.Database, customer *entity.CustomerEntity) error {
-	_, insertErr := db.Exec(ctx, `
-		INSERT INTO customers (uuid, name, email)
-		VALUES ($1, $2, $3)
-	`, customer.UUID, customer.Name, customer.Email)
-	if insertErr != nil {
+	err := db.QueryRow(ctx, `
+		INSERT INTO customers (uuid, name, email, currency)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		RETURNING id, status, created_at, updated_at
+	`, customer.UUID, customer.Name, customer.Email, customer.Currency).
+		Scan(&customer.ID, &customer.Status, &customer.CreatedAt, &customer.UpdatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return entity.ErrEmailTaken
+	}
+	if err != nil {
 		slog.ErrorContext(ctx, "error inserting customer",
 			"uuid", customer.UUID,
-			"err", insertErr.Error)
+			"err", err.Error())
 
-		return insertErr
+		return err
 	}
 	return nil
 }
 
+// FetchCustomerByEmail fetches the active customer using the email.
 func FetchCustomerByEmail(ctx context.Context, db *sqldb.Database, email string) (*entity.CustomerEntity, error) {
-	query := `
-		SELECT uuid, name, email, created_at, updated_at
-		FROM customers WHERE email = $1
-  `
-	c := &entity.CustomerEntity{}
+	return scanCustomer(db.QueryRow(ctx, `
+		SELECT `+customerColumns+`
+		FROM customers WHERE email = $1 AND status = 'ACTIVE'
+	`, email))
+}
+
+func FetchCustomerByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.CustomerEntity, error) {
+	return scanCustomer(db.QueryRow(ctx, `
+		SELECT `+customerColumns+`
+		FROM customers WHERE uuid = $1
+	`, uuid))
+}
+
+// UpdateCustomer changes an active customer's name and email. Returns
+// sqldb.ErrNoRows when the customer does not exist, entity.ErrCustomerArchived
+// or entity.ErrCustomerMerged when it is not active and entity.ErrEmailTaken
+// when another active customer uses the email.
+func UpdateCustomer(ctx context.Context, db *sqldb.Database, customer *entity.CustomerEntity) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"uuid", customer.UUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var status entity.CustomerStatus
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM customers WHERE uuid = $1 FOR UPDATE
+	`, customer.UUID).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if err := status.NotActiveErr(); err != nil {
+		return err
+	}
+
+	var taken bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM customers WHERE email = $1 AND status = 'ACTIVE' AND uuid <> $2
+		)
+	`, customer.Email, customer.UUID).Scan(&taken)
+	if err != nil {
+		slog.ErrorContext(ctx, "error checking customer email",
+			"uuid", customer.UUID,
+			"err", err.Error())
+		return err
+	}
+	if taken {
+		return entity.ErrEmailTaken
+	}
+
+	updated, err := scanCustomer(tx.QueryRow(ctx, `
+		UPDATE customers
+		SET name = $2, email = $3, updated_at = NOW()
+		WHERE uuid = $1
+		RETURNING `+customerColumns, customer.UUID, customer.Name, customer.Email))
+	if err != nil {
+		slog.ErrorContext(ctx, "error updating customer",
+			"uuid", customer.UUID,
+			"err", err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	*customer = *updated
+	return nil
+}
 
-	err := db.QueryRow(ctx, query, email).
-		Scan(&c.UUID, &c.Name, &c.Email, &c.CreatedAt, &c.UpdatedAt)
+// FetchCustomers pages through the customers matching the params.
+func FetchCustomers(ctx context.Context, db *sqldb.Database, params CustomerQueryParams) ([]*entity.CustomerEntity, error) {
+	var query string
+	var args []any
+
+	name, email := likePrefix(params.Name), likePrefix(params.Email)
+	if params.CursorID > 0 {
+		query = `
+			SELECT ` + customerColumns + `
+			FROM customers
+			WHERE (created_at, id) < ($1, $2)
+			  AND ($3 = '' OR LOWER(name) LIKE $3)
+			  AND ($4 = '' OR LOWER(email) LIKE $4)
+			  AND ($5 = '' OR status = $5)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $6
+		`
+		args = []any{params.CursorTime, params.CursorID, name, email, params.Status, params.Limit}
+	} else {
+		// first page
+		query = `
+			SELECT ` + customerColumns + `
+			FROM customers
+			WHERE ($1 = '' OR LOWER(name) LIKE $1)
+			  AND ($2 = '' OR LOWER(email) LIKE $2)
+			  AND ($3 = '' OR status = $3)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $4
+		`
+		args = []any{name, email, params.Status, params.Limit}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
+		slog.ErrorContext(ctx, "error fetching customers", "err", err.Error())
 		return nil, err
 	}
-	return c, nil
+	defer rows.Close()
+
+	var customers []*entity.CustomerEntity
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "error scanning customer row", "err", err.Error())
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return customers, rows.Err()
 }
 
-func FetchCustomerByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.CustomerEntity, error) {
-	query := `
-		SELECT uuid, name, email, created_at, updated_at
-		FROM customers WHERE uuid = $1
-  `
-	c := &entity.CustomerEntity{}
+// likePrefix turns a search prefix into a lower-case LIKE pattern, an empty
+// prefix stays empty and matches everything.
+func likePrefix(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix))
+	return escaped + "%"
+}
+
+// ArchiveCustomer archives an active customer, archiving an archived customer
+// again returns it unchanged. Returns sqldb.ErrNoRows when the customer does
+// not exist and entity.ErrCustomerMerged when it was merged.
+func ArchiveCustomer(ctx context.Context, db *sqldb.Database, uuid string, archivedAt time.Time) (*entity.CustomerEntity, error) {
+	customer, err := scanCustomer(db.QueryRow(ctx, `
+		UPDATE customers
+		SET status = 'ARCHIVED', archived_at = $2, updated_at = NOW()
+		WHERE uuid = $1 AND status = 'ACTIVE'
+		RETURNING `+customerColumns, uuid, archivedAt))
+	if err == nil {
+		return customer, nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		slog.ErrorContext(ctx, "error archiving customer",
+			"uuid", uuid,
+			"err", err.Error())
+		return nil, err
+	}
+
+	customer, err = FetchCustomerByUUID(ctx, db, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if customer.Status == entity.CustomerStatusMerged {
+		return nil, entity.ErrCustomerMerged
+	}
+	return customer, nil
+}
+
+// MergeCustomers merges a duplicate customer into the target in one
+// transaction: their bills, credits and subscriptions move to the target, the
+// ledger moves their receivable and credit balances and the duplicate is
+// marked merged. Invoices and credit notes keep the customer they were issued
+// to.
+//
+// The target must be active and the source active or archived without open or
+// closing bills, entity.ErrCustomerHasOpenBills otherwise. Merging into the
+// same target again moves nothing. Returns sqldb.ErrNoRows when either
+// customer does not exist.
+func MergeCustomers(ctx context.Context, db *sqldb.Database, sourceUUID, targetUUID string) (*entity.CustomerMergeEntity, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"source_uuid", sourceUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	merge := &entity.CustomerMergeEntity{SourceUUID: sourceUUID, TargetUUID: targetUUID}
+
+	// locked in uuid order so merges running the other way do not deadlock;
+	// bills insert under a key share lock of their customer and wait for the merge
+	rows, err := tx.Query(ctx, `
+		SELECT uuid, status, merged_into_uuid
+		FROM customers
+		WHERE uuid IN ($1, $2)
+		ORDER BY uuid
+		FOR UPDATE
+	`, sourceUUID, targetUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error locking customers",
+			"source_uuid", sourceUUID,
+			"target_uuid", targetUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	var source, target *entity.CustomerEntity
+	for rows.Next() {
+		c := &entity.CustomerEntity{}
+		if err := rows.Scan(&c.UUID, &c.Status, &c.MergedIntoUUID); err != nil {
+			rows.Close()
+			slog.ErrorContext(ctx, "error scanning customer row", "err", err.Error())
+			return nil, err
+		}
+		if c.UUID == sourceUUID {
+			source = c
+		} else {
+			target = c
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if source == nil || target == nil {
+		return nil, sqldb.ErrNoRows
+	}
+
+	if source.Status == entity.CustomerStatusMerged {
+		if source.MergedIntoUUID != nil && *source.MergedIntoUUID == targetUUID {
+			return merge, nil
+		}
+		return nil, entity.ErrCustomerMerged
+	}
+	if err := target.Status.NotActiveErr(); err != nil {
+		return nil, err
+	}
+
+	var hasOpenBills bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM bills WHERE customer_uuid = $1 AND status IN ($2, $3)
+		)
+	`, sourceUUID, entity.BillStatusOpen, entity.BillStatusClosing).Scan(&hasOpenBills)
+	if err != nil {
+		slog.ErrorContext(ctx, "error checking open bills",
+			"customer_uuid", sourceUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	if hasOpenBills {
+		return nil, entity.ErrCustomerHasOpenBills
+	}
 
-	err := db.QueryRow(ctx, query, uuid).
-		Scan(&c.UUID, &c.Name, &c.Email, &c.CreatedAt, &c.UpdatedAt)
+	if merge.Bills, err = reparent(ctx, tx, `
+		UPDATE bills SET customer_uuid = $2, updated_at = NOW() WHERE customer_uuid = $1
+	`, sourceUUID, targetUUID); err != nil {
+		return nil, err
+	}
+
+	// a grant key the target already used is kept apart by prefixing the source
+	if merge.Credits, err = reparent(ctx, tx, `
+		UPDATE customer_credits c
+		SET customer_uuid = $2,
+		    idempotency_key = CASE WHEN EXISTS (
+		        SELECT 1 FROM customer_credits t
+		        WHERE t.customer_uuid = $2 AND t.idempotency_key = c.idempotency_key
+		    ) THEN LEFT($1 || ':' || c.idempotency_key, 255) ELSE c.idempotency_key END
+		WHERE c.customer_uuid = $1
+	`, sourceUUID, targetUUID); err != nil {
+		return nil, err
+	}
+
+	if merge.Subscriptions, err = reparent(ctx, tx, `
+		UPDATE subscriptions SET customer_uuid = $2, updated_at = NOW() WHERE customer_uuid = $1
+	`, sourceUUID, targetUUID); err != nil {
+		return nil, err
+	}
+
+	if err = postCustomerMerge(ctx, tx, sourceUUID, targetUUID); err != nil {
+		return nil, err
+	}
+
+	// customers merged into the source earlier now point at the target, so a
+	// merged customer is always one hop from the customer that is kept
+	_, err = tx.Exec(ctx, `
+		UPDATE customers
+		SET status = 'MERGED', merged_into_uuid = $2, updated_at = NOW()
+		WHERE uuid = $1 OR merged_into_uuid = $1
+	`, sourceUUID, targetUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error marking customer merged",
+			"source_uuid", sourceUUID,
+			"err", err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+// reparent runs one of the merge's updates, returning the rows it moved.
+func reparent(ctx context.Context, tx *sqldb.Tx, query, sourceUUID, targetUUID string) (int64, error) {
+	result, err := tx.Exec(ctx, query, sourceUUID, targetUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error moving customer rows",
+			"source_uuid", sourceUUID,
+			"target_uuid", targetUUID,
+			"err", err.Error())
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanCustomer(row rowScanner) (*entity.CustomerEntity, error) {
+	c := &entity.CustomerEntity{}
+	err := row.Scan(&c.ID, &c.UUID, &c.Name, &c.Email, &c.Currency, &c.Status, &c.ArchivedAt,
+		&c.MergedIntoUUID, &c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
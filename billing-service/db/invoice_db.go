@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+func FetchInvoiceByBillUUID(ctx context.Context, db *sqldb.Database, billUUID string) (*entity.InvoiceEntity, error) {
+	query := `
+		SELECT
+			id, bill_uuid, customer_uuid, sequence, invoice_number, issued_at
+		FROM invoices
+			WHERE bill_uuid = $1
+	`
+	inv := &entity.InvoiceEntity{}
+
+	err := db.QueryRow(ctx, query, billUUID).
+		Scan(&inv.ID, &inv.BillUUID, &inv.CustomerUUID, &inv.Sequence, &inv.Number, &inv.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// InsertInvoice issues the invoice of a bill, taking the customer's next
+// sequence number in the same transaction so a failed insert leaves no gap.
+// When the bill already has an invoice it is loaded into inv instead.
+func InsertInvoice(ctx context.Context, db *sqldb.Database, inv *entity.InvoiceEntity) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", inv.BillUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(ctx, `
+		SELECT
+			id, customer_uuid, sequence, invoice_number, issued_at
+		FROM invoices
+			WHERE bill_uuid = $1
+	`, inv.BillUUID).Scan(&inv.ID, &inv.CustomerUUID, &inv.Sequence, &inv.Number, &inv.IssuedAt)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		return err
+	}
+
+	// the row lock on the counter serializes invoice numbering per customer
+	err = tx.QueryRow(ctx, `
+		INSERT INTO customer_invoice_sequences
+			(customer_uuid, last_sequence)
+		VALUES
+			($1, 1)
+		ON CONFLICT (customer_uuid)
+			DO UPDATE SET last_sequence = customer_invoice_sequences.last_sequence + 1
+		RETURNING last_sequence
+	`, inv.CustomerUUID).Scan(&inv.Sequence)
+	if err != nil {
+		slog.ErrorContext(ctx, "error allocating invoice sequence",
+			"customer_uuid", inv.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+
+	inv.Number = InvoiceNumber(inv.CustomerUUID, inv.Sequence)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO invoices
+			(bill_uuid, customer_uuid, sequence, invoice_number)
+		VALUES
+			($1, $2, $3, $4)
+		RETURNING id, issued_at
+	`, inv.BillUUID, inv.CustomerUUID, inv.Sequence, inv.Number).Scan(&inv.ID, &inv.IssuedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting invoice",
+			"bill_uuid", inv.BillUUID,
+			"err", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InvoiceNumber prints a customer's invoice sequence, e.g. INV-1A2B3C4D-000042.
+// The customer prefix keeps numbers unique across customers.
+func InvoiceNumber(customerUUID string, sequence int64) string {
+	return documentNumber("INV", customerUUID, sequence)
+}
+
+// documentNumber prints a per-customer document sequence behind kind.
+func documentNumber(kind, customerUUID string, sequence int64) string {
+	prefix := strings.ToUpper(strings.ReplaceAll(customerUUID, "-", ""))
+	if len(prefix) > 8 {
+		prefix = prefix[:8]
+	}
+	return fmt.Sprintf("%s-%s-%06d", kind, prefix, sequence)
+}
@@ -0,0 +1,308 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// billLedgerTotal derives a bill's total from the ledger: what its line items
+// and a void posted to the customer's receivable. Takes the bill UUID as $1.
+var billLedgerTotal = ledgerTotalOf("$1")
+
+// ledgerTotalOf is billLedgerTotal for the bill UUID billUUIDExpr evaluates
+// to, a column when it is correlated with an outer query.
+func ledgerTotalOf(billUUIDExpr string) string {
+	return `
+	SELECT COALESCE(SUM(CASE e.direction WHEN 'DEBIT' THEN e.amount_cents ELSE -e.amount_cents END), 0)
+	FROM ledger_entries e
+	JOIN accounts a ON a.id = e.account_id
+	WHERE e.bill_uuid = ` + billUUIDExpr + `
+	  AND a.type = 'RECEIVABLE'
+	  AND e.source_type IN ('LINE_ITEM', 'BILL_VOID')`
+}
+
+// ledgerPosting is one side of a ledger transaction, debited when the amount
+// is positive and credited when it is negative.
+type ledgerPosting struct {
+	accountType  entity.AccountType
+	customerUUID string
+	signedCents  int64
+}
+
+// postLedgerTransaction appends the entries a source posts, in the caller's
+// transaction. The postings must sum to zero; the database rejects the commit
+// of a transaction whose debits and credits do not balance.
+func postLedgerTransaction(ctx context.Context, tx *sqldb.Tx, source entity.LedgerSource, sourceUUID string, billUUID *string, currency string, postings []ledgerPosting) error {
+	for _, posting := range postings {
+		if posting.signedCents == 0 {
+			continue
+		}
+
+		accountID, err := ledgerAccountID(ctx, tx, posting.accountType, posting.customerUUID, currency)
+		if err != nil {
+			return err
+		}
+
+		direction, amountCents := entity.EntryDebit, posting.signedCents
+		if amountCents < 0 {
+			direction, amountCents = entity.EntryCredit, -amountCents
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO ledger_entries
+				(source_type, source_uuid, bill_uuid, account_id, direction, amount_cents)
+			VALUES
+				($1, $2, $3, $4, $5, $6)
+		`, source, sourceUUID, billUUID, accountID, direction, amountCents)
+		if err != nil {
+			slog.ErrorContext(ctx, "error posting ledger entry",
+				"source_type", source,
+				"source_uuid", sourceUUID,
+				"err", err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// ledgerAccountID returns the account's id, opening the account on its first
+// posting. Existing accounts are only read, so postings do not queue on a lock
+// of the shared REVENUE or CASH accounts.
+func ledgerAccountID(ctx context.Context, tx *sqldb.Tx, accountType entity.AccountType, customerUUID, currency string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM accounts WHERE type = $1 AND customer_uuid = $2 AND currency = $3
+	`, accountType, customerUUID, currency).Scan(&id)
+	if err == nil || !errors.Is(err, sqldb.ErrNoRows) {
+		return id, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO accounts (type, customer_uuid, currency)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (type, customer_uuid, currency) DO NOTHING
+		RETURNING id
+	`, accountType, customerUUID, currency).Scan(&id)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		// opened concurrently
+		err = tx.QueryRow(ctx, `
+			SELECT id FROM accounts WHERE type = $1 AND customer_uuid = $2 AND currency = $3
+		`, accountType, customerUUID, currency).Scan(&id)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error opening ledger account",
+			"type", accountType,
+			"customer_uuid", customerUUID,
+			"currency", currency,
+			"err", err.Error())
+		return 0, err
+	}
+	return id, nil
+}
+
+// postLineItem posts a line item between the customer's receivable and the
+// account its fee type is booked to: taxes are owed to the tax authority,
+// consumed credit draws down the customer's credit and everything else,
+// discounts and reversals included, is revenue.
+func postLineItem(ctx context.Context, tx *sqldb.Tx, lineItem *entity.LineItemEntity, customerUUID, currency string) error {
+	counter := ledgerPosting{accountType: entity.AccountRevenue, signedCents: -lineItem.AmountCents}
+	switch entity.FeeType(lineItem.FeeType) {
+	case entity.FeeTypeTax:
+		counter.accountType = entity.AccountTaxPayable
+	case entity.FeeTypeCredit:
+		counter.accountType = entity.AccountCustomerCredit
+		counter.customerUUID = customerUUID
+	}
+
+	return postLedgerTransaction(ctx, tx, entity.LedgerSourceLineItem, lineItem.UUID, &lineItem.BillUUID, currency, []ledgerPosting{
+		{accountType: entity.AccountReceivable, customerUUID: customerUUID, signedCents: lineItem.AmountCents},
+		counter,
+	})
+}
+
+// postPayment posts a payment received against the customer's receivable.
+func postPayment(ctx context.Context, tx *sqldb.Tx, payment *entity.PaymentEntity, customerUUID string) error {
+	return postLedgerTransaction(ctx, tx, entity.LedgerSourcePayment, payment.UUID, &payment.BillUUID, payment.Currency, []ledgerPosting{
+		{accountType: entity.AccountCash, signedCents: payment.AmountCents},
+		{accountType: entity.AccountReceivable, customerUUID: customerUUID, signedCents: -payment.AmountCents},
+	})
+}
+
+// postCreditGrant posts credit granted to a customer, the line items that
+// consume it later draw it down.
+func postCreditGrant(ctx context.Context, tx *sqldb.Tx, credit *entity.CreditEntity) error {
+	return postLedgerTransaction(ctx, tx, entity.LedgerSourceCreditGrant, credit.UUID, nil, credit.Currency, []ledgerPosting{
+		{accountType: entity.AccountCreditsGranted, signedCents: credit.AmountCents},
+		{accountType: entity.AccountCustomerCredit, customerUUID: credit.CustomerUUID, signedCents: -credit.AmountCents},
+	})
+}
+
+// postBillVoid takes back what a voided bill's line items posted, account by
+// account, leaving the bill's line items themselves in place. Customer
+// accounts are those of the bill's current customer: a merge moved the
+// balances the line items posted to the customer the bill was moved to.
+func postBillVoid(ctx context.Context, tx *sqldb.Tx, billUUID string) error {
+	rows, err := tx.Query(ctx, `
+		SELECT a.type, CASE WHEN a.customer_uuid = '' THEN '' ELSE b.customer_uuid END, a.currency,
+		       SUM(CASE e.direction WHEN 'DEBIT' THEN e.amount_cents ELSE -e.amount_cents END)
+		FROM ledger_entries e
+		JOIN accounts a ON a.id = e.account_id
+		JOIN bills b ON b.uuid = e.bill_uuid
+		WHERE e.bill_uuid = $1 AND e.source_type = $2
+		GROUP BY 1, 2, 3
+		ORDER BY MIN(a.id)
+	`, billUUID, entity.LedgerSourceLineItem)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing bill ledger entries",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+
+	var (
+		currency string
+		postings []ledgerPosting
+	)
+	for rows.Next() {
+		var posting ledgerPosting
+		if err := rows.Scan(&posting.accountType, &posting.customerUUID, &currency, &posting.signedCents); err != nil {
+			rows.Close()
+			slog.ErrorContext(ctx, "error scanning bill ledger row", "err", err.Error())
+			return err
+		}
+		posting.signedCents = -posting.signedCents
+		postings = append(postings, posting)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return postLedgerTransaction(ctx, tx, entity.LedgerSourceBillVoid, billUUID, &billUUID, currency, postings)
+}
+
+// postCustomerMerge moves the source customer's receivable and credit
+// balances to the target's accounts, one transaction across currencies that
+// balances in each.
+func postCustomerMerge(ctx context.Context, tx *sqldb.Tx, sourceUUID, targetUUID string) error {
+	rows, err := tx.Query(ctx, `
+		SELECT a.type, a.currency,
+		       SUM(CASE e.direction WHEN 'DEBIT' THEN e.amount_cents ELSE -e.amount_cents END)
+		FROM ledger_entries e
+		JOIN accounts a ON a.id = e.account_id
+		WHERE a.customer_uuid = $1
+		GROUP BY a.id, a.type, a.currency
+		HAVING SUM(CASE e.direction WHEN 'DEBIT' THEN e.amount_cents ELSE -e.amount_cents END) <> 0
+		ORDER BY a.id
+	`, sourceUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing customer ledger accounts",
+			"customer_uuid", sourceUUID,
+			"err", err.Error())
+		return err
+	}
+
+	var currencies []string
+	postings := make(map[string][]ledgerPosting)
+	for rows.Next() {
+		var (
+			accountType entity.AccountType
+			currency    string
+			balance     int64
+		)
+		if err := rows.Scan(&accountType, &currency, &balance); err != nil {
+			rows.Close()
+			slog.ErrorContext(ctx, "error scanning customer ledger row", "err", err.Error())
+			return err
+		}
+		if _, ok := postings[currency]; !ok {
+			currencies = append(currencies, currency)
+		}
+		postings[currency] = append(postings[currency],
+			ledgerPosting{accountType: accountType, customerUUID: sourceUUID, signedCents: -balance},
+			ledgerPosting{accountType: accountType, customerUUID: targetUUID, signedCents: balance},
+		)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, currency := range currencies {
+		err := postLedgerTransaction(ctx, tx, entity.LedgerSourceCustomerMerge, sourceUUID, nil, currency, postings[currency])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchTrialBalance totals the ledger's debits and credits per currency and
+// account type, for one currency when it is set. Each currency balances when
+// its debits equal its credits.
+func FetchTrialBalance(ctx context.Context, db *sqldb.Database, currency string) ([]*entity.TrialBalanceLineEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT a.currency, a.type,
+		       COALESCE(SUM(e.amount_cents) FILTER (WHERE e.direction = 'DEBIT'), 0),
+		       COALESCE(SUM(e.amount_cents) FILTER (WHERE e.direction = 'CREDIT'), 0)
+		FROM accounts a
+		JOIN ledger_entries e ON e.account_id = a.id
+		WHERE ($1 = '' OR a.currency = $1)
+		GROUP BY a.currency, a.type
+		ORDER BY a.currency, a.type
+	`, currency)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching trial balance", "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*entity.TrialBalanceLineEntity
+	for rows.Next() {
+		line := &entity.TrialBalanceLineEntity{}
+		if err := rows.Scan(&line.Currency, &line.AccountType, &line.DebitCents, &line.CreditCents); err != nil {
+			slog.ErrorContext(ctx, "error scanning trial balance row", "err", err.Error())
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// FetchCustomerBalances derives a customer's balances from their ledger
+// accounts, one per currency they were billed or credited in.
+func FetchCustomerBalances(ctx context.Context, db *sqldb.Database, customerUUID string) ([]*entity.CustomerBalanceEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT a.currency,
+		       COALESCE(SUM(CASE e.direction WHEN 'DEBIT' THEN e.amount_cents ELSE -e.amount_cents END)
+		                FILTER (WHERE a.type = 'RECEIVABLE'), 0),
+		       COALESCE(SUM(CASE e.direction WHEN 'CREDIT' THEN e.amount_cents ELSE -e.amount_cents END)
+		                FILTER (WHERE a.type = 'CUSTOMER_CREDIT'), 0)
+		FROM accounts a
+		JOIN ledger_entries e ON e.account_id = a.id
+		WHERE a.customer_uuid = $1
+		GROUP BY a.currency
+		ORDER BY a.currency
+	`, customerUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching customer balances",
+			"customer_uuid", customerUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*entity.CustomerBalanceEntity
+	for rows.Next() {
+		balance := &entity.CustomerBalanceEntity{}
+		if err := rows.Scan(&balance.Currency, &balance.ReceivableCents, &balance.CreditCents); err != nil {
+			slog.ErrorContext(ctx, "error scanning customer balance row", "err", err.Error())
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
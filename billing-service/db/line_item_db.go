@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
@@ -13,14 +14,16 @@ func FetchLineItemByBillAndKey(ctx context.Context, db *sqldb.Database, billUUID
 	query := `
 		SELECT
 			uuid, bill_uuid, idempotency_key, fee_type,
-			description, amount_cents, reference_uuid, created_at
+			description, amount_cents, reference_uuid, created_at,
+			original_amount_cents, original_currency, fx_rate::TEXT, reversed_cents
 		FROM line_items
 			WHERE bill_uuid = $1 AND idempotency_key = $2
 	`
 	li := &entity.LineItemEntity{}
 
 	err := db.QueryRow(ctx, query, billUUID, idempotencyKey).
-		Scan(&li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt)
+		Scan(&li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt,
+			&li.OriginalAmountCents, &li.OriginalCurrency, &li.FXRate, &li.ReversedCents)
 	if err != nil {
 		return nil, err
 	}
@@ -58,22 +61,27 @@ func UpsertLineItem(ctx context.Context, db *sqldb.Database, lineItem *entity.Li
 func InsertLineItem(ctx context.Context, db *sqldb.Database, lineItem *entity.LineItemEntity) error {
 	_, insertErr := db.Exec(ctx, `
 		INSERT INTO line_items
-			(uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents)
+			(uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid)
 		VALUES
-			($1, $2, $3, $4, $5, $6)
-	`, lineItem.UUID, lineItem.BillUUID, lineItem.IdempotencyKey, lineItem.FeeType, lineItem.Description, lineItem.AmountCents)
+			($1, $2, $3, $4, $5, $6, $7)
+	`, lineItem.UUID, lineItem.BillUUID, lineItem.IdempotencyKey, lineItem.FeeType, lineItem.Description, lineItem.AmountCents, lineItem.ReferenceUUID)
 	if insertErr != nil {
 		slog.ErrorContext(ctx, "error inserting line item",
 			"uuid", lineItem.UUID,
-			"err", insertErr.Error)
+			"err", insertErr.Error())
 
 		return insertErr
 	}
 	return nil
 }
 
-// InsertLineItemWithBillUpdate inserts a line item and atomically updates the bill's total_cents
-// within a single transaction to maintain consistency. Uses ON CONFLICT DO NOTHING for idempotency.
+// InsertLineItemWithBillUpdate inserts a line item, posts it to the ledger and derives the bill's
+// total_cents from the ledger within a single transaction to maintain consistency. Uses ON CONFLICT
+// DO NOTHING for idempotency. A newly inserted item is posted and recorded in the outbox in the same
+// transaction, a duplicate is not.
+// A new REVERSAL adds its amount to the reversed amount of the line item it references, and is
+// not inserted with entity.ErrReversalExceedsLineItem when that would reverse more than the
+// line item's amount.
 func InsertLineItemWithBillUpdate(ctx context.Context, db *sqldb.Database, lineItem *entity.LineItemEntity) error {
 	tx, err := db.Begin(ctx)
 	if err != nil {
@@ -87,11 +95,13 @@ func InsertLineItemWithBillUpdate(ctx context.Context, db *sqldb.Database, lineI
 	// Insert the line item with ON CONFLICT DO NOTHING for idempotency
 	result, err := tx.Exec(ctx, `
 		INSERT INTO line_items
-			(uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents)
+			(uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid,
+			 original_amount_cents, original_currency, fx_rate)
 		VALUES
-			($1, $2, $3, $4, $5, $6)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::NUMERIC)
 		ON CONFLICT (bill_uuid, idempotency_key) DO NOTHING
-	`, lineItem.UUID, lineItem.BillUUID, lineItem.IdempotencyKey, lineItem.FeeType, lineItem.Description, lineItem.AmountCents)
+	`, lineItem.UUID, lineItem.BillUUID, lineItem.IdempotencyKey, lineItem.FeeType, lineItem.Description, lineItem.AmountCents,
+		lineItem.ReferenceUUID, lineItem.OriginalAmountCents, lineItem.OriginalCurrency, lineItem.FXRate)
 	if err != nil {
 		slog.ErrorContext(ctx, "error inserting line item in transaction",
 			"uuid", lineItem.UUID,
@@ -103,19 +113,51 @@ func InsertLineItemWithBillUpdate(ctx context.Context, db *sqldb.Database, lineI
 	rowsAffected := result.RowsAffected()
 
 	if rowsAffected > 0 {
-		// Update the bill's total_cents
+		if lineItem.FeeType == entity.FeeTypeReversal.String() && lineItem.ReferenceUUID != nil {
+			if err = reverseLineItem(ctx, tx, lineItem); err != nil {
+				return err
+			}
+		}
+
+		var customerUUID, currency string
+		err = tx.QueryRow(ctx, `
+			SELECT customer_uuid, currency FROM bills WHERE uuid = $1 FOR UPDATE
+		`, lineItem.BillUUID).Scan(&customerUUID, &currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "error locking bill",
+				"bill_uuid", lineItem.BillUUID,
+				"err", err.Error())
+			return err
+		}
+		if err = postLineItem(ctx, tx, lineItem, customerUUID, currency); err != nil {
+			return err
+		}
+
+		// the bill's total_cents follows its receivable in the ledger
 		_, err = tx.Exec(ctx, `
 			UPDATE bills
-			SET total_cents = COALESCE(total_cents, 0) + $2,
+			SET total_cents = (`+billLedgerTotal+`),
 			    updated_at = NOW()
 			WHERE uuid = $1
-		`, lineItem.BillUUID, lineItem.AmountCents)
+		`, lineItem.BillUUID)
 		if err != nil {
 			slog.ErrorContext(ctx, "error updating bill total_cents",
 				"bill_uuid", lineItem.BillUUID,
 				"err", err.Error())
 			return err
 		}
+
+		if err = insertOutboxEvent(ctx, tx, lineItem.BillUUID, lineItemEventType(lineItem), entity.LineItemEventData{
+			UUID:           lineItem.UUID,
+			BillUUID:       lineItem.BillUUID,
+			IdempotencyKey: lineItem.IdempotencyKey,
+			FeeType:        lineItem.FeeType,
+			Description:    lineItem.Description,
+			AmountCents:    lineItem.AmountCents,
+			ReferenceUUID:  lineItem.ReferenceUUID,
+		}); err != nil {
+			return err
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -128,6 +170,50 @@ func InsertLineItemWithBillUpdate(ctx context.Context, db *sqldb.Database, lineI
 	return nil
 }
 
+// reverseLineItem counts a reversal against the line item it references and
+// records where in the line item the reversal starts. The update takes the
+// original's row lock, so concurrent reversals of one line item are checked
+// against each other's running total.
+func reverseLineItem(ctx context.Context, tx *sqldb.Tx, reversal *entity.LineItemEntity) error {
+	var reversedFromCents int64
+	err := tx.QueryRow(ctx, `
+		UPDATE line_items
+		SET reversed_cents = reversed_cents + ABS($3)
+		WHERE uuid = $1 AND bill_uuid = $2 AND fee_type <> 'REVERSAL'
+			AND reversed_cents + ABS($3) <= ABS(amount_cents)
+		RETURNING reversed_cents - ABS($3)
+	`, *reversal.ReferenceUUID, reversal.BillUUID, reversal.AmountCents).Scan(&reversedFromCents)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return entity.ErrReversalExceedsLineItem
+		}
+		slog.ErrorContext(ctx, "error updating reversed amount",
+			"uuid", *reversal.ReferenceUUID,
+			"err", err.Error())
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE line_items SET reversed_from_cents = $2 WHERE uuid = $1
+	`, reversal.UUID, reversedFromCents)
+	if err != nil {
+		slog.ErrorContext(ctx, "error recording reversal start",
+			"uuid", reversal.UUID,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// lineItemEventType reports REVERSAL line items as reversals. Discounts and
+// credits reference their promotion or credit, not a line item.
+func lineItemEventType(lineItem *entity.LineItemEntity) entity.WebhookEventType {
+	if lineItem.FeeType == entity.FeeTypeReversal.String() {
+		return entity.WebhookEventLineItemReversed
+	}
+	return entity.WebhookEventLineItemPersisted
+}
+
 // FetchLineItemsByBillUUID fetches line items for a bill with cursor-based pagination.
 // Uses (created_at, id) tuple for stable cursor-based pagination, matching the bills API convention.
 func FetchLineItemsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID string, cursorTime time.Time, cursorID int64, limit int) ([]*entity.LineItemEntity, error) {
@@ -139,7 +225,8 @@ func FetchLineItemsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID
 	if subsequentPage {
 		query = `
 			SELECT
-				id, uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at
+				id, uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at,
+				original_amount_cents, original_currency, fx_rate::TEXT, reversed_cents
 			FROM line_items
 			WHERE bill_uuid = $1
 				AND (created_at, id) > ($2, $3)
@@ -151,7 +238,8 @@ func FetchLineItemsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID
 		// first page
 		query = `
 			SELECT
-				id, uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at
+				id, uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at,
+				original_amount_cents, original_currency, fx_rate::TEXT, reversed_cents
 			FROM line_items
 			WHERE bill_uuid = $1
 			ORDER BY created_at ASC, id ASC
@@ -170,7 +258,8 @@ func FetchLineItemsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID
 	var lineItems []*entity.LineItemEntity
 	for rows.Next() {
 		li := &entity.LineItemEntity{}
-		err := rows.Scan(&li.ID, &li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt)
+		err := rows.Scan(&li.ID, &li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt,
+			&li.OriginalAmountCents, &li.OriginalCurrency, &li.FXRate, &li.ReversedCents)
 		if err != nil {
 			slog.ErrorContext(ctx, "error scanning line item row", "err", err.Error())
 			return nil, err
@@ -189,14 +278,16 @@ func FetchLineItemsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID
 func FetchLineItemByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.LineItemEntity, error) {
 	query := `
 		SELECT
-			uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at
+			uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at,
+			original_amount_cents, original_currency, fx_rate::TEXT, reversed_cents
 		FROM line_items
 		WHERE uuid = $1
 	`
 	li := &entity.LineItemEntity{}
 
 	err := db.QueryRow(ctx, query, uuid).
-		Scan(&li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt)
+		Scan(&li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt,
+			&li.OriginalAmountCents, &li.OriginalCurrency, &li.FXRate, &li.ReversedCents)
 	if err != nil {
 		return nil, err
 	}
@@ -204,21 +295,96 @@ func FetchLineItemByUUID(ctx context.Context, db *sqldb.Database, uuid string) (
 }
 
 // FetchReversalByOriginalUUID checks if a line item has been reversed.
-// Returns the reversal line item if found, or sqldb.ErrNoRows if not reversed.
+// Returns its earliest reversal if found, or sqldb.ErrNoRows if not reversed.
+// A partially reversed line item can have several, see LineItemEntity.ReversedCents.
 func FetchReversalByOriginalUUID(ctx context.Context, db *sqldb.Database, originalUUID string) (*entity.LineItemEntity, error) {
 	query := `
 		SELECT
-			uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at
+			uuid, bill_uuid, idempotency_key, fee_type, description, amount_cents, reference_uuid, created_at,
+			original_amount_cents, original_currency, fx_rate::TEXT, reversed_cents
 		FROM line_items
-		WHERE reference_uuid = $1
+		WHERE reference_uuid = $1 AND fee_type = 'REVERSAL'
+		ORDER BY created_at ASC, id ASC
 		LIMIT 1
 	`
 	li := &entity.LineItemEntity{}
 
 	err := db.QueryRow(ctx, query, originalUUID).
-		Scan(&li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt)
+		Scan(&li.UUID, &li.BillUUID, &li.IdempotencyKey, &li.FeeType, &li.Description, &li.AmountCents, &li.ReferenceUUID, &li.CreatedAt,
+			&li.OriginalAmountCents, &li.OriginalCurrency, &li.FXRate, &li.ReversedCents)
 	if err != nil {
 		return nil, err
 	}
 	return li, nil
 }
+
+// SumLineItemsByFeeType returns the bill's line item subtotals keyed by fee type.
+func SumLineItemsByFeeType(ctx context.Context, db *sqldb.Database, billUUID string) (map[string]int64, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			fee_type, COALESCE(SUM(amount_cents), 0)
+		FROM line_items
+			WHERE bill_uuid = $1
+		GROUP BY fee_type
+	`, billUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing line items by fee type",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	subtotals := make(map[string]int64)
+	for rows.Next() {
+		var feeType string
+		var amountCents int64
+		if err := rows.Scan(&feeType, &amountCents); err != nil {
+			return nil, err
+		}
+		subtotals[feeType] = amountCents
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subtotals, nil
+}
+
+// SumLineItemsByReference sums a bill's line items of one fee type per the
+// reference they carry, e.g. what each subscription has been charged so far.
+// Line items without a reference are left out.
+func SumLineItemsByReference(ctx context.Context, db *sqldb.Database, billUUID, feeType string) (map[string]int64, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			reference_uuid, COALESCE(SUM(amount_cents), 0)
+		FROM line_items
+			WHERE bill_uuid = $1 AND fee_type = $2 AND reference_uuid IS NOT NULL
+		GROUP BY reference_uuid
+	`, billUUID, feeType)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing line items by reference",
+			"bill_uuid", billUUID,
+			"fee_type", feeType,
+			"err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := make(map[string]int64)
+	for rows.Next() {
+		var referenceUUID string
+		var amountCents int64
+		if err := rows.Scan(&referenceUUID, &amountCents); err != nil {
+			return nil, err
+		}
+		sums[referenceUUID] = amountCents
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sums, nil
+}
@@ -0,0 +1,13 @@
+-- Billing events written in the same transaction as the change they report,
+-- the relay publishes unpublished rows in id order
+CREATE TABLE billing_outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    uuid            UUID NOT NULL UNIQUE,
+    aggregate_uuid  UUID NOT NULL,
+    event_type      VARCHAR(50) NOT NULL,
+    payload         JSONB NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_billing_outbox_unpublished ON billing_outbox(id) WHERE published_at IS NULL;
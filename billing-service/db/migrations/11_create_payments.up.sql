@@ -0,0 +1,21 @@
+-- Payment terms: the date a closed bill must be paid by
+ALTER TABLE bills ADD COLUMN due_date TIMESTAMPTZ;
+
+CREATE INDEX idx_bills_due_date ON bills(due_date) WHERE due_date IS NOT NULL;
+
+-- Money received against closed bills, the balance is the total minus their sum
+CREATE TABLE payments (
+    id              BIGSERIAL PRIMARY KEY,
+    uuid            UUID NOT NULL UNIQUE,
+    bill_uuid       UUID NOT NULL REFERENCES bills(uuid),
+    idempotency_key VARCHAR(255) NOT NULL,
+    amount_cents    BIGINT NOT NULL CHECK (amount_cents > 0),
+    currency        VARCHAR(3) NOT NULL,
+    reference       TEXT,
+    received_at     TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (bill_uuid, idempotency_key)
+);
+
+CREATE INDEX idx_payments_bill_uuid ON payments(bill_uuid, received_at);
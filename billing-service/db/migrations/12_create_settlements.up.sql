@@ -0,0 +1,14 @@
+-- Bank transfers collecting closed bills, the payment is recorded once the money arrives
+CREATE TABLE settlements (
+    id             BIGSERIAL PRIMARY KEY,
+    uuid           UUID NOT NULL UNIQUE,
+    bill_uuid      UUID NOT NULL UNIQUE REFERENCES bills(uuid),
+    source_account VARCHAR(64) NOT NULL,
+    target_account VARCHAR(64) NOT NULL,
+    amount_cents   BIGINT NOT NULL CHECK (amount_cents > 0),
+    status         VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    payment_uuid   UUID REFERENCES payments(uuid),
+    failure_reason TEXT,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+-- Line items added in another currency keep what was submitted next to the
+-- converted amount_cents
+ALTER TABLE line_items
+    ADD COLUMN original_amount_cents BIGINT,
+    ADD COLUMN original_currency     VARCHAR(3),
+    ADD COLUMN fx_rate               NUMERIC(24, 10);
+
+-- Default currency of a customer's bills, NULL falls back to the billing currency
+ALTER TABLE customers ADD COLUMN currency VARCHAR(3);
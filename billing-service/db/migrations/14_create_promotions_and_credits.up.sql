@@ -0,0 +1,64 @@
+-- Promo codes: a percent or fixed discount taken off the bills they are redeemed on
+CREATE TABLE promotions (
+    id               BIGSERIAL PRIMARY KEY,
+    uuid             UUID NOT NULL UNIQUE,
+    code             VARCHAR(64) NOT NULL UNIQUE,
+    kind             VARCHAR(16) NOT NULL CHECK (kind IN ('PERCENT', 'FIXED')),
+    percent_bps      BIGINT CHECK (percent_bps > 0 AND percent_bps <= 10000),
+    amount_cents     BIGINT CHECK (amount_cents > 0),
+    currency         VARCHAR(3),
+    max_redemptions  INT CHECK (max_redemptions > 0),
+    redemption_count INT NOT NULL DEFAULT 0,
+    expires_at       TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (
+        (kind = 'PERCENT' AND percent_bps IS NOT NULL) OR
+        (kind = 'FIXED' AND amount_cents IS NOT NULL AND currency IS NOT NULL)
+    ),
+    CHECK (max_redemptions IS NULL OR redemption_count <= max_redemptions)
+);
+
+-- A promo code counts against its usage limit once per bill
+CREATE TABLE promotion_redemptions (
+    id             BIGSERIAL PRIMARY KEY,
+    promotion_uuid UUID NOT NULL REFERENCES promotions(uuid),
+    bill_uuid      UUID NOT NULL REFERENCES bills(uuid),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (promotion_uuid, bill_uuid)
+);
+
+CREATE INDEX idx_promotion_redemptions_bill_uuid ON promotion_redemptions(bill_uuid, created_at);
+
+-- Credit granted to a customer, consumed by their closing bills oldest first
+CREATE TABLE customer_credits (
+    id              BIGSERIAL PRIMARY KEY,
+    uuid            UUID NOT NULL UNIQUE,
+    customer_uuid   VARCHAR(36) NOT NULL REFERENCES customers(uuid),
+    idempotency_key VARCHAR(255) NOT NULL,
+    currency        VARCHAR(3) NOT NULL,
+    amount_cents    BIGINT NOT NULL CHECK (amount_cents > 0),
+    remaining_cents BIGINT NOT NULL CHECK (remaining_cents >= 0),
+    description     TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (customer_uuid, idempotency_key),
+    CHECK (remaining_cents <= amount_cents)
+);
+
+CREATE INDEX idx_customer_credits_available ON customer_credits(customer_uuid, currency, created_at)
+    WHERE remaining_cents > 0;
+
+-- Credit a bill consumed at close, each credit is consumed at most once per bill
+CREATE TABLE credit_applications (
+    id           BIGSERIAL PRIMARY KEY,
+    credit_uuid  UUID NOT NULL REFERENCES customer_credits(uuid),
+    bill_uuid    UUID NOT NULL REFERENCES bills(uuid),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (credit_uuid, bill_uuid)
+);
+
+CREATE INDEX idx_credit_applications_bill_uuid ON credit_applications(bill_uuid);
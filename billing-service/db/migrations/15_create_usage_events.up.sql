@@ -0,0 +1,16 @@
+-- Metered usage recorded against open bills, aggregated into one USAGE line
+-- item per meter when the bill closes
+CREATE TABLE usage_events (
+    id          BIGSERIAL PRIMARY KEY,
+    uuid        UUID NOT NULL UNIQUE,
+    bill_uuid   UUID NOT NULL REFERENCES bills(uuid),
+    meter       VARCHAR(100) NOT NULL,
+    quantity    BIGINT NOT NULL CHECK (quantity > 0),
+    dedup_key   VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (bill_uuid, dedup_key)
+);
+
+CREATE INDEX idx_usage_events_bill_meter ON usage_events(bill_uuid, meter);
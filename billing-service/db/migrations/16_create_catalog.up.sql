@@ -0,0 +1,40 @@
+-- Products customers subscribe to, priced per bill period in prices
+CREATE TABLE products (
+    id         BIGSERIAL PRIMARY KEY,
+    uuid       UUID NOT NULL UNIQUE,
+    code       VARCHAR(64) NOT NULL UNIQUE,
+    name       VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Price of one unit of a product per bill period. Prices are never updated,
+-- a change is a new row whose effective_from is the first period it applies to.
+CREATE TABLE prices (
+    id             BIGSERIAL PRIMARY KEY,
+    uuid           UUID NOT NULL UNIQUE,
+    product_uuid   UUID NOT NULL REFERENCES products(uuid),
+    currency       VARCHAR(3) NOT NULL,
+    amount_cents   BIGINT NOT NULL CHECK (amount_cents >= 0),
+    effective_from TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (product_uuid, currency, effective_from)
+);
+
+-- A customer's subscription to a product, billed as a MONTHLY_FEE line item on
+-- every bill whose period it overlaps. ended_at is NULL until it is cancelled.
+CREATE TABLE subscriptions (
+    id            BIGSERIAL PRIMARY KEY,
+    uuid          UUID NOT NULL UNIQUE,
+    customer_uuid VARCHAR(36) NOT NULL REFERENCES customers(uuid),
+    product_uuid  UUID NOT NULL REFERENCES products(uuid),
+    quantity      BIGINT NOT NULL CHECK (quantity > 0),
+    started_at    TIMESTAMPTZ NOT NULL,
+    ended_at      TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (ended_at IS NULL OR ended_at > started_at)
+);
+
+CREATE INDEX idx_subscriptions_customer ON subscriptions(customer_uuid, started_at);
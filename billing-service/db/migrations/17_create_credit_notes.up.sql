@@ -0,0 +1,43 @@
+-- Last credit note sequence issued per customer, numbered apart from invoices
+CREATE TABLE customer_credit_note_sequences (
+    customer_uuid   VARCHAR(36) PRIMARY KEY,
+    last_sequence   BIGINT NOT NULL
+);
+
+-- Credit issued against a closed bill. The bill itself is never changed, the
+-- credit reaches the customer as customer credit once the note is applied.
+CREATE TABLE credit_notes (
+    id                 BIGSERIAL PRIMARY KEY,
+    uuid               UUID NOT NULL UNIQUE,
+    bill_uuid          UUID NOT NULL REFERENCES bills(uuid),
+    customer_uuid      VARCHAR(36) NOT NULL,
+    idempotency_key    VARCHAR(255) NOT NULL,
+    sequence           BIGINT NOT NULL,
+    credit_note_number VARCHAR(64) NOT NULL UNIQUE,
+    currency           VARCHAR(3) NOT NULL,
+    amount_cents       BIGINT NOT NULL CHECK (amount_cents > 0),
+    reason             TEXT,
+    status             VARCHAR(16) NOT NULL DEFAULT 'ISSUED' CHECK (status IN ('ISSUED', 'APPLIED')),
+    credit_uuid        UUID REFERENCES customer_credits(uuid),
+    issued_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    applied_at         TIMESTAMPTZ,
+
+    UNIQUE (bill_uuid, idempotency_key),
+    CONSTRAINT unique_customer_credit_note_sequence UNIQUE (customer_uuid, sequence),
+    CHECK ((status = 'APPLIED') = (credit_uuid IS NOT NULL))
+);
+
+CREATE INDEX idx_credit_notes_bill_uuid ON credit_notes(bill_uuid, issued_at);
+
+-- The line items a credit note credits and by how much, a line item is never
+-- credited beyond its amount across all notes
+CREATE TABLE credit_note_lines (
+    id               BIGSERIAL PRIMARY KEY,
+    credit_note_uuid UUID NOT NULL REFERENCES credit_notes(uuid),
+    line_item_uuid   UUID NOT NULL REFERENCES line_items(uuid),
+    amount_cents     BIGINT NOT NULL CHECK (amount_cents > 0),
+
+    UNIQUE (credit_note_uuid, line_item_uuid)
+);
+
+CREATE INDEX idx_credit_note_lines_line_item_uuid ON credit_note_lines(line_item_uuid);
@@ -0,0 +1,17 @@
+-- Running total of what REVERSAL line items took back of each line item,
+-- counted without sign. A line item can be reversed in parts, never beyond
+-- its amount.
+ALTER TABLE line_items ADD COLUMN reversed_cents BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE line_items ADD CONSTRAINT reversed_within_amount
+    CHECK (reversed_cents >= 0 AND reversed_cents <= ABS(amount_cents));
+
+-- Where a REVERSAL starts in the line item it references: the running total
+-- before it. Each reversal takes back the part after the ones before it.
+ALTER TABLE line_items ADD COLUMN reversed_from_cents BIGINT;
+
+-- At most one reversal per part of a line item, so a reversal cannot be
+-- counted against a running total another reversal already moved past.
+CREATE UNIQUE INDEX idx_line_items_reversal_reference
+    ON line_items(reference_uuid, reversed_from_cents)
+    WHERE fee_type = 'REVERSAL';
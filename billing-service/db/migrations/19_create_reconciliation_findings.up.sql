@@ -0,0 +1,26 @@
+-- Disagreements the reconciliation job found between a bill's total_cents, the
+-- sum of its line items and the total its workflow holds. A finding stays OPEN
+-- while later runs keep seeing it and is RESOLVED once they no longer do;
+-- REPAIRED findings record a total_cents the job set to its line items' sum.
+CREATE TABLE reconciliation_findings (
+    id                   BIGSERIAL PRIMARY KEY,
+    uuid                 UUID NOT NULL UNIQUE,
+    bill_uuid            UUID NOT NULL REFERENCES bills(uuid),
+    kind                 VARCHAR(32) NOT NULL CHECK (kind IN ('BILL_TOTAL_MISMATCH', 'WORKFLOW_TOTAL_MISMATCH', 'WORKFLOW_NOT_FOUND')),
+    status               VARCHAR(16) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'RESOLVED', 'REPAIRED')),
+    bill_status          VARCHAR(20) NOT NULL,
+    bill_total_cents     BIGINT NOT NULL,
+    line_items_cents     BIGINT NOT NULL,
+    workflow_total_cents BIGINT,
+    detected_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at          TIMESTAMPTZ,
+
+    CHECK ((status = 'OPEN') = (resolved_at IS NULL))
+);
+
+-- one open finding per bill and kind, later runs update it
+CREATE UNIQUE INDEX idx_reconciliation_findings_open
+    ON reconciliation_findings(bill_uuid, kind) WHERE status = 'OPEN';
+
+CREATE INDEX idx_reconciliation_findings_detected_at ON reconciliation_findings(detected_at, id);
@@ -0,0 +1,114 @@
+-- Ledger accounts. RECEIVABLE and CUSTOMER_CREDIT are kept per customer, the
+-- other types are system accounts with an empty customer_uuid. Every account
+-- holds a single currency.
+CREATE TABLE accounts (
+    id            BIGSERIAL PRIMARY KEY,
+    type          VARCHAR(20) NOT NULL CHECK (type IN ('RECEIVABLE', 'CUSTOMER_CREDIT', 'REVENUE', 'TAX_PAYABLE', 'CASH', 'CREDITS_GRANTED')),
+    customer_uuid VARCHAR(36) NOT NULL DEFAULT '',
+    currency      VARCHAR(3) NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (type, customer_uuid, currency)
+);
+
+CREATE INDEX idx_accounts_customer_uuid ON accounts(customer_uuid) WHERE customer_uuid <> '';
+
+-- Append-only double-entry ledger. The entries a source (a line item, payment,
+-- credit grant or bill void) posts form one transaction, whose debits and
+-- credits are checked to balance when the database transaction commits.
+CREATE TABLE ledger_entries (
+    id           BIGSERIAL PRIMARY KEY,
+    source_type  VARCHAR(16) NOT NULL CHECK (source_type IN ('LINE_ITEM', 'PAYMENT', 'CREDIT_GRANT', 'BILL_VOID')),
+    source_uuid  UUID NOT NULL,
+    bill_uuid    UUID REFERENCES bills(uuid),
+    account_id   BIGINT NOT NULL REFERENCES accounts(id),
+    direction    VARCHAR(6) NOT NULL CHECK (direction IN ('DEBIT', 'CREDIT')),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    posted_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (source_type, source_uuid, account_id, direction)
+);
+
+CREATE INDEX idx_ledger_entries_bill_uuid ON ledger_entries(bill_uuid) WHERE bill_uuid IS NOT NULL;
+CREATE INDEX idx_ledger_entries_account_id ON ledger_entries(account_id);
+
+CREATE FUNCTION ledger_entries_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger entries are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_append_only();
+
+CREATE FUNCTION ledger_transaction_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(CASE direction WHEN 'DEBIT' THEN amount_cents ELSE -amount_cents END)
+        FROM ledger_entries
+        WHERE source_type = NEW.source_type AND source_uuid = NEW.source_uuid) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % % does not balance', NEW.source_type, NEW.source_uuid;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_transaction_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_transaction_balanced();
+
+-- Post what was recorded before the ledger existed, the way the service posts it
+CREATE TEMP TABLE ledger_backfill (
+    source_type   VARCHAR(16),
+    source_uuid   UUID,
+    bill_uuid     UUID,
+    account_type  VARCHAR(20),
+    customer_uuid VARCHAR(36),
+    currency      VARCHAR(3),
+    signed_cents  BIGINT, -- debits positive, credits negative
+    posted_at     TIMESTAMPTZ
+) ON COMMIT DROP;
+
+INSERT INTO ledger_backfill
+SELECT 'LINE_ITEM', li.uuid, li.bill_uuid, 'RECEIVABLE', b.customer_uuid, b.currency, li.amount_cents, li.created_at
+FROM line_items li JOIN bills b ON b.uuid = li.bill_uuid
+WHERE li.amount_cents <> 0
+UNION ALL
+SELECT 'LINE_ITEM', li.uuid, li.bill_uuid,
+       CASE li.fee_type WHEN 'TAX' THEN 'TAX_PAYABLE' WHEN 'CREDIT' THEN 'CUSTOMER_CREDIT' ELSE 'REVENUE' END,
+       CASE li.fee_type WHEN 'CREDIT' THEN b.customer_uuid ELSE '' END,
+       b.currency, -li.amount_cents, li.created_at
+FROM line_items li JOIN bills b ON b.uuid = li.bill_uuid
+WHERE li.amount_cents <> 0;
+
+INSERT INTO ledger_backfill
+SELECT 'BILL_VOID', b.uuid, b.uuid, l.account_type, l.customer_uuid, l.currency, -SUM(l.signed_cents), b.updated_at
+FROM ledger_backfill l JOIN bills b ON b.uuid = l.bill_uuid
+WHERE l.source_type = 'LINE_ITEM' AND b.status = 'VOIDED'
+GROUP BY b.uuid, b.updated_at, l.account_type, l.customer_uuid, l.currency
+HAVING SUM(l.signed_cents) <> 0;
+
+INSERT INTO ledger_backfill
+SELECT 'PAYMENT', p.uuid, p.bill_uuid, 'CASH', '', p.currency, p.amount_cents, p.created_at
+FROM payments p
+UNION ALL
+SELECT 'PAYMENT', p.uuid, p.bill_uuid, 'RECEIVABLE', b.customer_uuid, p.currency, -p.amount_cents, p.created_at
+FROM payments p JOIN bills b ON b.uuid = p.bill_uuid
+UNION ALL
+SELECT 'CREDIT_GRANT', c.uuid, NULL, 'CREDITS_GRANTED', '', c.currency, c.amount_cents, c.created_at
+FROM customer_credits c
+UNION ALL
+SELECT 'CREDIT_GRANT', c.uuid, NULL, 'CUSTOMER_CREDIT', c.customer_uuid, c.currency, -c.amount_cents, c.created_at
+FROM customer_credits c;
+
+INSERT INTO accounts (type, customer_uuid, currency)
+SELECT DISTINCT account_type, customer_uuid, currency FROM ledger_backfill
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ledger_entries (source_type, source_uuid, bill_uuid, account_id, direction, amount_cents, posted_at)
+SELECT l.source_type, l.source_uuid, l.bill_uuid, a.id,
+       CASE WHEN l.signed_cents > 0 THEN 'DEBIT' ELSE 'CREDIT' END, ABS(l.signed_cents), l.posted_at
+FROM ledger_backfill l
+JOIN accounts a ON a.type = l.account_type AND a.customer_uuid = l.customer_uuid AND a.currency = l.currency
+ORDER BY l.posted_at;
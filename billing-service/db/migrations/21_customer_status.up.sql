@@ -0,0 +1,33 @@
+-- Customers are archived instead of deleted, and a duplicate is merged into the
+-- customer that is kept. Neither takes new bills.
+ALTER TABLE customers
+    ADD COLUMN status           VARCHAR(16) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'ARCHIVED', 'MERGED')),
+    ADD COLUMN archived_at      TIMESTAMPTZ,
+    ADD COLUMN merged_into_uuid VARCHAR(36) REFERENCES customers(uuid),
+    ADD CONSTRAINT customers_merged_into CHECK ((status = 'MERGED') = (merged_into_uuid IS NOT NULL));
+
+-- Duplicates created before emails were unique are archived, all but the
+-- oldest, so they can be merged into it
+UPDATE customers c
+SET status = 'ARCHIVED', archived_at = NOW(), updated_at = NOW()
+WHERE EXISTS (
+    SELECT 1 FROM customers o
+    WHERE o.email = c.email AND (o.created_at, o.id) < (c.created_at, c.id)
+);
+
+-- An email belongs to one active customer; archived and merged customers
+-- release theirs
+DROP INDEX idx_customers_email;
+CREATE UNIQUE INDEX idx_customers_email_active ON customers(email) WHERE status = 'ACTIVE';
+
+-- Customer list and prefix search
+CREATE INDEX idx_customers_created_at ON customers(created_at DESC, id DESC);
+CREATE INDEX idx_customers_name_prefix ON customers(LOWER(name) text_pattern_ops);
+CREATE INDEX idx_customers_email_prefix ON customers(LOWER(email) text_pattern_ops);
+
+-- A merge moves the duplicate's receivable and credit balances to the customer
+-- it is merged into
+ALTER TABLE ledger_entries
+    DROP CONSTRAINT ledger_entries_source_type_check,
+    ADD CONSTRAINT ledger_entries_source_type_check
+        CHECK (source_type IN ('LINE_ITEM', 'PAYMENT', 'CREDIT_GRANT', 'BILL_VOID', 'CUSTOMER_MERGE'));
@@ -0,0 +1,17 @@
+-- A customer's billing profile: the postal address and tax ID printed on their
+-- invoices, their payment terms and how they receive invoices. The default
+-- currency of the profile is customers.currency.
+CREATE TABLE customer_billing_profiles (
+    customer_uuid    VARCHAR(36) PRIMARY KEY REFERENCES customers(uuid),
+    address_line1    VARCHAR(255) NOT NULL,
+    address_line2    VARCHAR(255),
+    city             VARCHAR(128) NOT NULL,
+    postal_code      VARCHAR(32),
+    region           VARCHAR(128),
+    country          VARCHAR(2) NOT NULL CHECK (country ~ '^[A-Z]{2}$'),
+    tax_id           VARCHAR(32),
+    payment_terms    VARCHAR(8) CHECK (payment_terms IN ('NET15', 'NET30')), -- NULL keeps the service's terms
+    invoice_delivery VARCHAR(8) NOT NULL DEFAULT 'EMAIL' CHECK (invoice_delivery IN ('EMAIL', 'PORTAL')),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+-- The recurrence a bill was created with, so an idempotent create returns what
+-- was stored and each bill of a series records the schedule it belongs to.
+-- NULL cadence for one-off bills.
+ALTER TABLE bills
+    ADD COLUMN recurrence_cadence       VARCHAR(16) CHECK (recurrence_cadence IN ('MONTHLY', 'WEEKLY', 'CUSTOM')),
+    ADD COLUMN recurrence_interval_days INT,
+    ADD COLUMN recurrence_anchor_day    SMALLINT CHECK (recurrence_anchor_day BETWEEN 1 AND 31);
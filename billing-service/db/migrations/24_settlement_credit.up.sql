@@ -0,0 +1,3 @@
+-- A transfer whose payment the bill no longer accepts is granted to the
+-- customer as credit, the settlement points at that credit
+ALTER TABLE settlements ADD COLUMN credit_uuid UUID REFERENCES customer_credits(uuid);
@@ -0,0 +1,9 @@
+-- The ledger total is what a bill's total_cents is checked against and
+-- repaired to; LEDGER_MISMATCH findings record line items whose sum differs
+-- from what the ledger posted for them. Findings recorded before have no
+-- ledger total.
+ALTER TABLE reconciliation_findings ADD COLUMN ledger_cents BIGINT;
+
+ALTER TABLE reconciliation_findings DROP CONSTRAINT reconciliation_findings_kind_check;
+ALTER TABLE reconciliation_findings ADD CONSTRAINT reconciliation_findings_kind_check
+    CHECK (kind IN ('BILL_TOTAL_MISMATCH', 'WORKFLOW_TOTAL_MISMATCH', 'WORKFLOW_NOT_FOUND', 'LEDGER_MISMATCH'));
@@ -0,0 +1,14 @@
+-- Record every bill status transition
+CREATE TABLE bill_status_history (
+    id              BIGSERIAL PRIMARY KEY,
+    bill_uuid       UUID NOT NULL,
+    from_status     VARCHAR(20),
+    to_status       VARCHAR(20) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_bill_status_history_bill_uuid ON bill_status_history(bill_uuid, created_at);
+
+-- Backfill the current status of existing bills
+INSERT INTO bill_status_history (bill_uuid, from_status, to_status, created_at)
+SELECT uuid, NULL, status, updated_at FROM bills;
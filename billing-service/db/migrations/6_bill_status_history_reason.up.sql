@@ -0,0 +1,2 @@
+-- Operator supplied reason for a transition, e.g. why a bill was voided
+ALTER TABLE bill_status_history ADD COLUMN reason TEXT;
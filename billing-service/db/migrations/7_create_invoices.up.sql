@@ -0,0 +1,20 @@
+-- Last invoice sequence issued per customer, incremented in the same
+-- transaction as the invoice insert so numbers never skip
+CREATE TABLE customer_invoice_sequences (
+    customer_uuid   VARCHAR(36) PRIMARY KEY,
+    last_sequence   BIGINT NOT NULL
+);
+
+-- One invoice per closed bill
+CREATE TABLE invoices (
+    id              BIGSERIAL PRIMARY KEY,
+    bill_uuid       UUID NOT NULL UNIQUE,
+    customer_uuid   VARCHAR(36) NOT NULL,
+    sequence        BIGINT NOT NULL,
+    invoice_number  VARCHAR(64) NOT NULL UNIQUE,
+    issued_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_customer_sequence UNIQUE (customer_uuid, sequence)
+);
+
+CREATE INDEX idx_invoices_customer_uuid ON invoices(customer_uuid);
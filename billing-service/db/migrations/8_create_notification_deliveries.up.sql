@@ -0,0 +1,19 @@
+-- One row per customer notification, claimed before sending and marked SENT
+-- afterwards so a retried activity does not deliver it twice
+CREATE TABLE notification_deliveries (
+    id                  BIGSERIAL PRIMARY KEY,
+    idempotency_key     VARCHAR(255) NOT NULL UNIQUE,
+    bill_uuid           UUID NOT NULL,
+    customer_uuid       VARCHAR(36) NOT NULL,
+    event               VARCHAR(50) NOT NULL,
+    channel             VARCHAR(20) NOT NULL,
+    recipient           VARCHAR(255) NOT NULL,
+    status              VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts            INT NOT NULL DEFAULT 0,
+    sent_at             TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_delivery_status CHECK (status IN ('PENDING', 'SENT'))
+);
+
+CREATE INDEX idx_notification_deliveries_bill_uuid ON notification_deliveries(bill_uuid);
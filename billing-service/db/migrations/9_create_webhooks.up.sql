@@ -0,0 +1,46 @@
+-- Endpoints integrators registered for billing events; deleting a subscription
+-- keeps its delivery log
+CREATE TABLE webhook_subscriptions (
+    id              BIGSERIAL PRIMARY KEY,
+    uuid            UUID NOT NULL UNIQUE,
+    url             TEXT NOT NULL,
+    secret          VARCHAR(255) NOT NULL,
+    events          TEXT[] NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at      TIMESTAMPTZ
+);
+
+CREATE INDEX idx_webhook_subscriptions_active ON webhook_subscriptions(created_at) WHERE deleted_at IS NULL;
+
+-- One row per event and subscription, the payload is kept for replays
+CREATE TABLE webhook_deliveries (
+    id                  BIGSERIAL PRIMARY KEY,
+    uuid                UUID NOT NULL UNIQUE,
+    subscription_uuid   UUID NOT NULL REFERENCES webhook_subscriptions(uuid),
+    event_uuid          UUID NOT NULL,
+    event_type          VARCHAR(50) NOT NULL,
+    payload             JSONB NOT NULL,
+    status              VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts            INT NOT NULL DEFAULT 0,
+    last_status_code    INT,
+    last_error          TEXT,
+    delivered_at        TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_subscription_event UNIQUE (subscription_uuid, event_uuid),
+    CONSTRAINT valid_webhook_delivery_status CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED'))
+);
+
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_uuid, created_at, id);
+
+-- Delivery log, one row per HTTP attempt
+CREATE TABLE webhook_delivery_attempts (
+    id              BIGSERIAL PRIMARY KEY,
+    delivery_uuid   UUID NOT NULL REFERENCES webhook_deliveries(uuid),
+    status_code     INT,
+    error           TEXT,
+    attempted_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts(delivery_uuid);
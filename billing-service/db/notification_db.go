@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// ClaimNotificationDelivery records a delivery attempt. The first claim inserts
+// the row, later claims of the same idempotency key count another attempt.
+// The stored row is loaded back into delivery, so callers see whether it was
+// already sent.
+func ClaimNotificationDelivery(ctx context.Context, db *sqldb.Database, delivery *entity.NotificationDeliveryEntity) error {
+	err := db.QueryRow(ctx, `
+		INSERT INTO notification_deliveries
+			(idempotency_key, bill_uuid, customer_uuid, event, channel, recipient, attempts)
+		VALUES
+			($1, $2, $3, $4, $5, $6, 1)
+		ON CONFLICT (idempotency_key)
+			DO UPDATE SET attempts = notification_deliveries.attempts + 1
+		RETURNING id, bill_uuid, customer_uuid, event, channel, recipient, status, attempts, sent_at, created_at
+	`, delivery.IdempotencyKey, delivery.BillUUID, delivery.CustomerUUID, delivery.Event, delivery.Channel, delivery.Recipient).
+		Scan(&delivery.ID, &delivery.BillUUID, &delivery.CustomerUUID, &delivery.Event, &delivery.Channel,
+			&delivery.Recipient, &delivery.Status, &delivery.Attempts, &delivery.SentAt, &delivery.CreatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error claiming notification delivery",
+			"idempotency_key", delivery.IdempotencyKey,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+func MarkNotificationDeliverySent(ctx context.Context, db *sqldb.Database, idempotencyKey string, sentAt time.Time) error {
+	_, err := db.Exec(ctx, `
+		UPDATE notification_deliveries
+			SET status = 'SENT', sent_at = $2
+		WHERE idempotency_key = $1
+			AND status = 'PENDING'
+	`, idempotencyKey, sentAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error marking notification delivery sent",
+			"idempotency_key", idempotencyKey,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
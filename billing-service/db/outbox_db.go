@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+)
+
+// insertOutboxEvent records an event inside the transaction of the change it
+// reports, so the event exists exactly when the change was committed.
+//
+// The id is drawn at insert but becomes visible at commit, so two transactions
+// can commit in the opposite order of their ids. The advisory lock, held until
+// commit, keeps the events of one aggregate in commit order; the relay reads
+// in id order and so publishes each aggregate's events as they happened.
+func insertOutboxEvent(ctx context.Context, tx *sqldb.Tx, aggregateUUID string, eventType entity.WebhookEventType, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "billing_outbox:"+aggregateUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error locking outbox aggregate",
+			"aggregate_uuid", aggregateUUID,
+			"err", err.Error())
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO billing_outbox
+			(uuid, aggregate_uuid, event_type, payload)
+		VALUES
+			($1, $2, $3, $4)
+	`, uuid.NewString(), aggregateUUID, eventType.String(), payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting outbox event",
+			"aggregate_uuid", aggregateUUID,
+			"event_type", eventType,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FetchUnpublishedOutboxEvents returns the oldest unpublished events in id order.
+// Ids follow commit order within an aggregate only, events of different
+// aggregates may come out in either order.
+func FetchUnpublishedOutboxEvents(ctx context.Context, db *sqldb.Database, limit int) ([]*entity.OutboxEventEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			id, uuid, aggregate_uuid, event_type, payload, created_at, published_at
+		FROM billing_outbox
+		WHERE published_at IS NULL
+		ORDER BY id ASC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching outbox events", "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.OutboxEventEntity
+	for rows.Next() {
+		e := &entity.OutboxEventEntity{}
+		err := rows.Scan(&e.ID, &e.UUID, &e.AggregateUUID, &e.EventType, &e.Payload, &e.CreatedAt, &e.PublishedAt)
+		if err != nil {
+			slog.ErrorContext(ctx, "error scanning outbox event row", "err", err.Error())
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// MarkOutboxEventPublished records that an event reached every sink.
+func MarkOutboxEventPublished(ctx context.Context, db *sqldb.Database, id int64, publishedAt time.Time) error {
+	_, err := db.Exec(ctx, `
+		UPDATE billing_outbox
+		SET published_at = $2
+		WHERE id = $1 AND published_at IS NULL
+	`, id, publishedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error marking outbox event published",
+			"id", id,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// InsertPayment records a payment against a bill and posts it to the ledger.
+// The bill row is locked while the balance is checked, so concurrent payments
+// cannot overpay it. A payment whose idempotency key was already used on the
+// bill is not inserted again; payment is loaded with the stored row instead,
+// callers compare the UUID to tell the two apart. Returns
+// entity.ErrBillNotPayable for bills that do not accept payments and
+// entity.ErrPaymentExceedsBalance for overpayments.
+func InsertPayment(ctx context.Context, db *sqldb.Database, payment *entity.PaymentEntity) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", payment.BillUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		status       string
+		customerUUID string
+		currency     string
+		totalCents   int64
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT status, customer_uuid, currency, COALESCE(total_cents, 0)
+		FROM bills
+		WHERE uuid = $1
+		FOR UPDATE
+	`, payment.BillUUID).Scan(&status, &customerUUID, &currency, &totalCents)
+	if err != nil {
+		return err
+	}
+
+	existing, err := scanPayment(tx.QueryRow(ctx, `
+		SELECT
+			id, uuid, bill_uuid, idempotency_key, amount_cents, currency, reference, received_at, created_at
+		FROM payments
+		WHERE bill_uuid = $1 AND idempotency_key = $2
+	`, payment.BillUUID, payment.IdempotencyKey))
+	if err == nil {
+		*payment = *existing
+		return nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		slog.ErrorContext(ctx, "error fetching payment by idempotency key",
+			"bill_uuid", payment.BillUUID,
+			"err", err.Error())
+		return err
+	}
+
+	if !entity.BillStatus(status).AcceptsPayments() {
+		return entity.ErrBillNotPayable
+	}
+
+	var paidCents int64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount_cents), 0) FROM payments WHERE bill_uuid = $1
+	`, payment.BillUUID).Scan(&paidCents)
+	if err != nil {
+		return err
+	}
+	if paidCents+payment.AmountCents > totalCents {
+		return entity.ErrPaymentExceedsBalance
+	}
+
+	payment.Currency = currency
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payments
+			(uuid, bill_uuid, idempotency_key, amount_cents, currency, reference, received_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, payment.UUID, payment.BillUUID, payment.IdempotencyKey, payment.AmountCents,
+		payment.Currency, payment.Reference, payment.ReceivedAt).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting payment",
+			"uuid", payment.UUID,
+			"bill_uuid", payment.BillUUID,
+			"err", err.Error())
+		return err
+	}
+
+	if err = postPayment(ctx, tx, payment, customerUUID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FetchPaymentsByBillUUID returns a bill's payments in the order they were received.
+func FetchPaymentsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID string) ([]*entity.PaymentEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			id, uuid, bill_uuid, idempotency_key, amount_cents, currency, reference, received_at, created_at
+		FROM payments
+		WHERE bill_uuid = $1
+		ORDER BY received_at ASC, id ASC
+	`, billUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching payments", "bill_uuid", billUUID, "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*entity.PaymentEntity
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "error scanning payment row", "err", err.Error())
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func scanPayment(row rowScanner) (*entity.PaymentEntity, error) {
+	p := &entity.PaymentEntity{}
+	err := row.Scan(&p.ID, &p.UUID, &p.BillUUID, &p.IdempotencyKey, &p.AmountCents,
+		&p.Currency, &p.Reference, &p.ReceivedAt, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const promotionColumns = `
+	id, uuid, code, kind, percent_bps, amount_cents, currency, max_redemptions, redemption_count, expires_at, created_at`
+
+// InsertPromotion stores a new promo code. Returns entity.ErrPromotionCodeTaken
+// when the code is already in use.
+func InsertPromotion(ctx context.Context, db *sqldb.Database, promotion *entity.PromotionEntity) error {
+	err := db.QueryRow(ctx, `
+		INSERT INTO promotions
+			(uuid, code, kind, percent_bps, amount_cents, currency, max_redemptions, expires_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id, created_at
+	`, promotion.UUID, promotion.Code, promotion.Kind, promotion.PercentBps, promotion.AmountCents,
+		promotion.Currency, promotion.MaxRedemptions, promotion.ExpiresAt).Scan(&promotion.ID, &promotion.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return entity.ErrPromotionCodeTaken
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting promotion",
+			"code", promotion.Code,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FetchPromotionByCode fetches a promo code, sqldb.ErrNoRows when it does not exist.
+func FetchPromotionByCode(ctx context.Context, db *sqldb.Database, code string) (*entity.PromotionEntity, error) {
+	return scanPromotion(db.QueryRow(ctx, `
+		SELECT`+promotionColumns+`
+		FROM promotions
+		WHERE code = $1
+	`, code))
+}
+
+// RedeemPromotion redeems a promo code on an open bill. The promotion row is
+// locked while its usage limit is checked, so concurrent redemptions cannot
+// exceed it. Redeeming a code twice on the same bill is a no-op. Returns
+// entity.ErrBillNotOpen, entity.ErrPromotionExpired or entity.ErrPromotionExhausted
+// when the code cannot be redeemed.
+func RedeemPromotion(ctx context.Context, db *sqldb.Database, billUUID, promotionUUID string, now time.Time) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM bills WHERE uuid = $1 FOR SHARE
+	`, billUUID).Scan(&status)
+	if err != nil {
+		return err
+	}
+
+	var redeemed bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM promotion_redemptions WHERE promotion_uuid = $1 AND bill_uuid = $2
+		)
+	`, promotionUUID, billUUID).Scan(&redeemed)
+	if err != nil {
+		return err
+	}
+	if redeemed {
+		return nil
+	}
+
+	if entity.BillStatus(status) != entity.BillStatusOpen {
+		return entity.ErrBillNotOpen
+	}
+
+	promotion, err := scanPromotion(tx.QueryRow(ctx, `
+		SELECT`+promotionColumns+`
+		FROM promotions
+		WHERE uuid = $1
+		FOR UPDATE
+	`, promotionUUID))
+	if err != nil {
+		return err
+	}
+	if promotion.IsExpired(now) {
+		return entity.ErrPromotionExpired
+	}
+	if promotion.MaxRedemptions != nil && promotion.RedemptionCount >= *promotion.MaxRedemptions {
+		return entity.ErrPromotionExhausted
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO promotion_redemptions (promotion_uuid, bill_uuid, created_at)
+		VALUES ($1, $2, $3)
+	`, promotionUUID, billUUID, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting promotion redemption",
+			"promotion_uuid", promotionUUID,
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE promotions SET redemption_count = redemption_count + 1 WHERE uuid = $1
+	`, promotionUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error counting promotion redemption",
+			"promotion_uuid", promotionUUID,
+			"err", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FetchPromotionsByBillUUID returns the promo codes redeemed on a bill in the
+// order they were redeemed, which is the order their discounts apply in.
+func FetchPromotionsByBillUUID(ctx context.Context, db *sqldb.Database, billUUID string) ([]*entity.PromotionEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			p.id, p.uuid, p.code, p.kind, p.percent_bps, p.amount_cents, p.currency,
+			p.max_redemptions, p.redemption_count, p.expires_at, p.created_at
+		FROM promotion_redemptions r
+		JOIN promotions p ON p.uuid = r.promotion_uuid
+		WHERE r.bill_uuid = $1
+		ORDER BY r.created_at ASC, r.id ASC
+	`, billUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching redeemed promotions", "bill_uuid", billUUID, "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []*entity.PromotionEntity
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "error scanning promotion row", "err", err.Error())
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func scanPromotion(row rowScanner) (*entity.PromotionEntity, error) {
+	p := &entity.PromotionEntity{}
+	err := row.Scan(&p.ID, &p.UUID, &p.Code, &p.Kind, &p.PercentBps, &p.AmountCents, &p.Currency,
+		&p.MaxRedemptions, &p.RedemptionCount, &p.ExpiresAt, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
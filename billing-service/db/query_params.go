@@ -30,3 +30,17 @@ type LineItemQueryParams struct {
 	Limit    int
 	SortDesc bool // true = newest first (default), false = oldest first
 }
+
+// ReconciliationFindingQueryParams contains filters and pagination options for fetching reconciliation findings
+type ReconciliationFindingQueryParams struct {
+	// Filters
+	BillUUID string
+	Status   string
+
+	// Cursor (decoded values)
+	CursorTime time.Time
+	CursorID   int64
+
+	// Pagination
+	Limit int
+}
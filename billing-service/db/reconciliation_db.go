@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const reconciliationFindingColumns = `
+	id, uuid, bill_uuid, kind, status, bill_status, bill_total_cents, line_items_cents,
+	ledger_cents, workflow_total_cents, detected_at, last_seen_at, resolved_at`
+
+// FetchBillTotals returns the next bills after afterID the reconciliation job
+// checks, oldest first: every bill that is not voided and was not closed before
+// closedSince. Each bill's total_cents, line item sum and ledger total are read
+// in one statement, so they are consistent with each other.
+func FetchBillTotals(ctx context.Context, db *sqldb.Database, afterID int64, closedSince time.Time, limit int) ([]*entity.BillTotalsEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT b.id, b.uuid, b.status, COALESCE(b.total_cents, 0),
+		       COALESCE((SELECT SUM(amount_cents) FROM line_items WHERE bill_uuid = b.uuid), 0),
+		       (`+ledgerTotalOf("b.uuid")+`)
+		FROM bills b
+		WHERE b.id > $1
+		  AND b.status <> $2
+		  AND (b.closed_at IS NULL OR b.closed_at >= $3)
+		ORDER BY b.id ASC
+		LIMIT $4
+	`, afterID, entity.BillStatusVoided, closedSince, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching bill totals",
+			"after_id", afterID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*entity.BillTotalsEntity
+	for rows.Next() {
+		t := &entity.BillTotalsEntity{}
+		if err := rows.Scan(&t.ID, &t.BillUUID, &t.Status, &t.TotalCents, &t.LineItemsCents, &t.LedgerCents); err != nil {
+			slog.ErrorContext(ctx, "error scanning bill totals row", "err", err.Error())
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// RecordReconciliationFinding stores an open finding, or updates the amounts
+// and last_seen_at of the one already open for the bill and kind. The stored
+// row is loaded back into finding.
+func RecordReconciliationFinding(ctx context.Context, db *sqldb.Database, finding *entity.ReconciliationFindingEntity) error {
+	stored, err := scanReconciliationFinding(db.QueryRow(ctx, `
+		INSERT INTO reconciliation_findings
+			(uuid, bill_uuid, kind, bill_status, bill_total_cents, line_items_cents,
+			 ledger_cents, workflow_total_cents, detected_at, last_seen_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (bill_uuid, kind) WHERE status = 'OPEN'
+			DO UPDATE SET bill_status = EXCLUDED.bill_status,
+			              bill_total_cents = EXCLUDED.bill_total_cents,
+			              line_items_cents = EXCLUDED.line_items_cents,
+			              ledger_cents = EXCLUDED.ledger_cents,
+			              workflow_total_cents = EXCLUDED.workflow_total_cents,
+			              last_seen_at = EXCLUDED.last_seen_at
+		RETURNING`+reconciliationFindingColumns,
+		finding.UUID, finding.BillUUID, finding.Kind, finding.BillStatus, finding.BillTotalCents,
+		finding.LineItemsCents, finding.LedgerCents, finding.WorkflowTotalCents, finding.LastSeenAt))
+	if err != nil {
+		slog.ErrorContext(ctx, "error recording reconciliation finding",
+			"bill_uuid", finding.BillUUID,
+			"kind", finding.Kind,
+			"err", err.Error())
+		return err
+	}
+	*finding = *stored
+	return nil
+}
+
+// ResolveReconciliationFindings resolves the bill's open findings of the given
+// kinds, once a run no longer sees them.
+func ResolveReconciliationFindings(ctx context.Context, db *sqldb.Database, billUUID string, kinds []entity.ReconciliationFindingKind, resolvedAt time.Time) error {
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = kind.String()
+	}
+	_, err := db.Exec(ctx, `
+		UPDATE reconciliation_findings
+		SET status = $3, resolved_at = $4, last_seen_at = $4
+		WHERE bill_uuid = $1 AND kind = ANY($2::TEXT[]) AND status = $5
+	`, billUUID, names, entity.FindingStatusResolved, resolvedAt, entity.FindingStatusOpen)
+	if err != nil {
+		slog.ErrorContext(ctx, "error resolving reconciliation findings",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// RepairBillTotal sets a bill's total_cents to its ledger total, the way every
+// line item insert and void sets it, and records the repair as a REPAIRED
+// finding, taking over the bill's open BILL_TOTAL_MISMATCH finding if there is
+// one. The bill row is locked while the totals are taken, so line items
+// inserted concurrently are not lost. Returns false, and records nothing, when
+// the totals already agree.
+func RepairBillTotal(ctx context.Context, db *sqldb.Database, finding *entity.ReconciliationFindingEntity) (bool, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", finding.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(ctx, `
+		SELECT status, COALESCE(total_cents, 0) FROM bills WHERE uuid = $1 FOR UPDATE
+	`, finding.BillUUID).Scan(&finding.BillStatus, &finding.BillTotalCents)
+	if err != nil {
+		return false, err
+	}
+	var ledgerCents int64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE((SELECT SUM(amount_cents) FROM line_items WHERE bill_uuid = $1), 0),
+		       (`+billLedgerTotal+`)
+	`, finding.BillUUID).Scan(&finding.LineItemsCents, &ledgerCents)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing bill totals",
+			"bill_uuid", finding.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+	finding.LedgerCents = &ledgerCents
+	if finding.BillTotalCents == ledgerCents {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bills SET total_cents = $2, updated_at = $3 WHERE uuid = $1
+	`, finding.BillUUID, ledgerCents, finding.LastSeenAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error repairing bill total_cents",
+			"bill_uuid", finding.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+
+	finding.Kind = entity.FindingBillTotalMismatch
+	finding.Status = entity.FindingStatusRepaired
+	stored, err := scanReconciliationFinding(tx.QueryRow(ctx, `
+		UPDATE reconciliation_findings
+		SET status = $3, bill_status = $4, bill_total_cents = $5, line_items_cents = $6,
+		    ledger_cents = $7, last_seen_at = $8, resolved_at = $8
+		WHERE bill_uuid = $1 AND kind = $2 AND status = $9
+		RETURNING`+reconciliationFindingColumns,
+		finding.BillUUID, finding.Kind, finding.Status, finding.BillStatus, finding.BillTotalCents,
+		finding.LineItemsCents, finding.LedgerCents, finding.LastSeenAt, entity.FindingStatusOpen))
+	if errors.Is(err, sqldb.ErrNoRows) {
+		stored, err = scanReconciliationFinding(tx.QueryRow(ctx, `
+			INSERT INTO reconciliation_findings
+				(uuid, bill_uuid, kind, status, bill_status, bill_total_cents, line_items_cents,
+				 ledger_cents, detected_at, last_seen_at, resolved_at)
+			VALUES
+				($1, $2, $3, $4, $5, $6, $7, $8, $9, $9, $9)
+			RETURNING`+reconciliationFindingColumns,
+			finding.UUID, finding.BillUUID, finding.Kind, finding.Status, finding.BillStatus,
+			finding.BillTotalCents, finding.LineItemsCents, finding.LedgerCents, finding.LastSeenAt))
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error recording bill total repair",
+			"bill_uuid", finding.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "error committing transaction",
+			"bill_uuid", finding.BillUUID,
+			"err", err.Error())
+		return false, err
+	}
+	*finding = *stored
+	return true, nil
+}
+
+// FetchReconciliationFindings pages through findings, newest first, filtered
+// by bill and status when those are set.
+func FetchReconciliationFindings(ctx context.Context, db *sqldb.Database, params ReconciliationFindingQueryParams) ([]*entity.ReconciliationFindingEntity, error) {
+	var query string
+	var args []any
+
+	if params.CursorID > 0 {
+		query = `
+			SELECT ` + reconciliationFindingColumns + `
+			FROM reconciliation_findings
+			WHERE (detected_at, id) < ($1, $2)
+			  AND ($3 = '' OR bill_uuid::TEXT = $3)
+			  AND ($4 = '' OR status = $4)
+			ORDER BY detected_at DESC, id DESC
+			LIMIT $5
+		`
+		args = []any{params.CursorTime, params.CursorID, params.BillUUID, params.Status, params.Limit}
+	} else {
+		// first page
+		query = `
+			SELECT ` + reconciliationFindingColumns + `
+			FROM reconciliation_findings
+			WHERE ($1 = '' OR bill_uuid::TEXT = $1)
+			  AND ($2 = '' OR status = $2)
+			ORDER BY detected_at DESC, id DESC
+			LIMIT $3
+		`
+		args = []any{params.BillUUID, params.Status, params.Limit}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching reconciliation findings", "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []*entity.ReconciliationFindingEntity
+	for rows.Next() {
+		finding, err := scanReconciliationFinding(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "error scanning reconciliation finding row", "err", err.Error())
+			return nil, err
+		}
+		findings = append(findings, finding)
+	}
+	return findings, rows.Err()
+}
+
+func scanReconciliationFinding(row rowScanner) (*entity.ReconciliationFindingEntity, error) {
+	f := &entity.ReconciliationFindingEntity{}
+	err := row.Scan(&f.ID, &f.UUID, &f.BillUUID, &f.Kind, &f.Status, &f.BillStatus, &f.BillTotalCents,
+		&f.LineItemsCents, &f.LedgerCents, &f.WorkflowTotalCents, &f.DetectedAt, &f.LastSeenAt, &f.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
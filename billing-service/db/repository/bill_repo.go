@@ -25,8 +25,16 @@ func (r *BillRepo) Insert(ctx context.Context, bill *entity.BillEntity) error {
 	return db.InsertBill(ctx, r.DB, bill)
 }
 
-func (r *BillRepo) Close(ctx context.Context, billUUID string, closedAt time.Time) error {
-	return db.CloseBill(ctx, r.DB, billUUID, closedAt)
+func (r *BillRepo) Close(ctx context.Context, billUUID string, closedAt time.Time, dueDate *time.Time) error {
+	return db.CloseBill(ctx, r.DB, billUUID, closedAt, dueDate)
+}
+
+func (r *BillRepo) UpdateStatus(ctx context.Context, billUUID string, from, to entity.BillStatus) error {
+	return db.UpdateBillStatus(ctx, r.DB, billUUID, from, to)
+}
+
+func (r *BillRepo) Void(ctx context.Context, billUUID string, reason string, voidedAt time.Time) error {
+	return db.VoidBill(ctx, r.DB, billUUID, reason, voidedAt)
 }
 
 func (r *BillRepo) FetchClosed(ctx context.Context, billUUID string, fallbackClosedAt time.Time) (int64, time.Time, error) {
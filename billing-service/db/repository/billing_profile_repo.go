@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// BillingProfileRepo is the PostgreSQL implementation of BillingProfileRepository.
+type BillingProfileRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure BillingProfileRepo implements BillingProfileRepository.
+var _ BillingProfileRepository = (*BillingProfileRepo)(nil)
+
+func (r *BillingProfileRepo) FetchByCustomerUUID(ctx context.Context, customerUUID string) (*entity.BillingProfileEntity, error) {
+	return db.FetchBillingProfile(ctx, r.DB, customerUUID)
+}
+
+func (r *BillingProfileRepo) Upsert(ctx context.Context, profile *entity.BillingProfileEntity) error {
+	return db.UpsertBillingProfile(ctx, r.DB, profile)
+}
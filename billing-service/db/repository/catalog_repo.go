@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// PriceRepo is the PostgreSQL implementation of PriceRepository.
+type PriceRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure PriceRepo implements PriceRepository.
+var _ PriceRepository = (*PriceRepo)(nil)
+
+func (r *PriceRepo) InsertProduct(ctx context.Context, product *entity.ProductEntity) error {
+	return db.InsertProduct(ctx, r.DB, product)
+}
+
+func (r *PriceRepo) FetchProductByCode(ctx context.Context, code string) (*entity.ProductEntity, error) {
+	return db.FetchProductByCode(ctx, r.DB, code)
+}
+
+func (r *PriceRepo) FetchProductByUUID(ctx context.Context, uuid string) (*entity.ProductEntity, error) {
+	return db.FetchProductByUUID(ctx, r.DB, uuid)
+}
+
+func (r *PriceRepo) InsertPrice(ctx context.Context, price *entity.PriceEntity) error {
+	return db.InsertPrice(ctx, r.DB, price)
+}
+
+func (r *PriceRepo) FetchEffective(ctx context.Context, productUUID, currency string, at time.Time) (*entity.PriceEntity, error) {
+	return db.FetchEffectivePrice(ctx, r.DB, productUUID, currency, at)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// CreditNoteRepo is the PostgreSQL implementation of CreditNoteRepository.
+type CreditNoteRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure CreditNoteRepo implements CreditNoteRepository.
+var _ CreditNoteRepository = (*CreditNoteRepo)(nil)
+
+func (r *CreditNoteRepo) Insert(ctx context.Context, note *entity.CreditNoteEntity) error {
+	return db.InsertCreditNote(ctx, r.DB, note)
+}
+
+func (r *CreditNoteRepo) FetchByUUID(ctx context.Context, uuid string) (*entity.CreditNoteEntity, error) {
+	return db.FetchCreditNoteByUUID(ctx, r.DB, uuid)
+}
+
+func (r *CreditNoteRepo) MarkApplied(ctx context.Context, uuid, creditUUID string, appliedAt time.Time) error {
+	return db.MarkCreditNoteApplied(ctx, r.DB, uuid, creditUUID, appliedAt)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// CreditRepo is the PostgreSQL implementation of CreditRepository.
+type CreditRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure CreditRepo implements CreditRepository.
+var _ CreditRepository = (*CreditRepo)(nil)
+
+func (r *CreditRepo) Insert(ctx context.Context, credit *entity.CreditEntity) error {
+	return db.InsertCredit(ctx, r.DB, credit)
+}
+
+func (r *CreditRepo) FetchAvailable(ctx context.Context, customerUUID, currency string) (int64, error) {
+	return db.FetchAvailableCredit(ctx, r.DB, customerUUID, currency)
+}
+
+func (r *CreditRepo) Apply(ctx context.Context, billUUID, customerUUID, currency string, dueCents int64) ([]*entity.CreditApplicationEntity, error) {
+	return db.ApplyCredits(ctx, r.DB, billUUID, customerUUID, currency, dueCents)
+}
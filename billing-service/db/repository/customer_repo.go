@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"encore.app/db"
 	"encore.app/entity"
@@ -27,3 +28,19 @@ func (r *CustomerRepo) FetchByEmail(ctx context.Context, email string) (*entity.
 func (r *CustomerRepo) Insert(ctx context.Context, customer *entity.CustomerEntity) error {
 	return db.InsertCustomer(ctx, r.DB, customer)
 }
+
+func (r *CustomerRepo) FetchAll(ctx context.Context, params db.CustomerQueryParams) ([]*entity.CustomerEntity, error) {
+	return db.FetchCustomers(ctx, r.DB, params)
+}
+
+func (r *CustomerRepo) Update(ctx context.Context, customer *entity.CustomerEntity) error {
+	return db.UpdateCustomer(ctx, r.DB, customer)
+}
+
+func (r *CustomerRepo) Archive(ctx context.Context, uuid string, archivedAt time.Time) (*entity.CustomerEntity, error) {
+	return db.ArchiveCustomer(ctx, r.DB, uuid, archivedAt)
+}
+
+func (r *CustomerRepo) Merge(ctx context.Context, sourceUUID, targetUUID string) (*entity.CustomerMergeEntity, error) {
+	return db.MergeCustomers(ctx, r.DB, sourceUUID, targetUUID)
+}
@@ -16,7 +16,10 @@ import (
 type BillRepository interface {
 	FetchByUUID(ctx context.Context, uuid string) (*entity.BillEntity, error)
 	Insert(ctx context.Context, bill *entity.BillEntity) error
-	Close(ctx context.Context, billUUID string, closedAt time.Time) error
+	// Close locks the bill totals and sets its due date, nil without payment terms
+	Close(ctx context.Context, billUUID string, closedAt time.Time, dueDate *time.Time) error
+	UpdateStatus(ctx context.Context, billUUID string, from, to entity.BillStatus) error
+	Void(ctx context.Context, billUUID string, reason string, voidedAt time.Time) error
 	FetchClosed(ctx context.Context, billUUID string, fallbackClosedAt time.Time) (int64, time.Time, error)
 	FetchAll(ctx context.Context, params db.BillQueryParams) ([]*entity.BillEntity, error)
 }
@@ -30,6 +33,9 @@ type LineItemRepository interface {
 	FetchByBillUUID(ctx context.Context, billUUID string, cursorTime time.Time, cursorID int64, limit int) ([]*entity.LineItemEntity, error)
 	FetchReversalByOriginalUUID(ctx context.Context, originalUUID string) (*entity.LineItemEntity, error)
 	InsertWithBillUpdate(ctx context.Context, lineItem *entity.LineItemEntity) error
+	SumByFeeType(ctx context.Context, billUUID string) (map[string]int64, error)
+	// SumByReference sums the bill's line items of feeType per reference UUID
+	SumByReference(ctx context.Context, billUUID, feeType string) (map[string]int64, error)
 }
 
 // CustomerRepository defines operations for customer persistence.
@@ -38,5 +44,193 @@ type LineItemRepository interface {
 type CustomerRepository interface {
 	FetchByUUID(ctx context.Context, uuid string) (*entity.CustomerEntity, error)
 	FetchByEmail(ctx context.Context, email string) (*entity.CustomerEntity, error)
+	FetchAll(ctx context.Context, params db.CustomerQueryParams) ([]*entity.CustomerEntity, error)
+	// Insert returns entity.ErrEmailTaken when an active customer uses the email
 	Insert(ctx context.Context, customer *entity.CustomerEntity) error
+	// Update changes the name and email, loading the updated customer
+	Update(ctx context.Context, customer *entity.CustomerEntity) error
+	Archive(ctx context.Context, uuid string, archivedAt time.Time) (*entity.CustomerEntity, error)
+	// Merge moves the source customer's bills, credits and subscriptions to the target
+	Merge(ctx context.Context, sourceUUID, targetUUID string) (*entity.CustomerMergeEntity, error)
+}
+
+// BillingProfileRepository defines operations for customer billing profile persistence.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type BillingProfileRepository interface {
+	FetchByCustomerUUID(ctx context.Context, customerUUID string) (*entity.BillingProfileEntity, error)
+	// Upsert replaces the customer's profile and default currency, loading the stored profile
+	Upsert(ctx context.Context, profile *entity.BillingProfileEntity) error
+}
+
+// InvoiceRepository defines operations for invoice persistence.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type InvoiceRepository interface {
+	FetchByBillUUID(ctx context.Context, billUUID string) (*entity.InvoiceEntity, error)
+	// Insert allocates the customer's next invoice number, or loads the
+	// invoice already issued for the bill.
+	Insert(ctx context.Context, invoice *entity.InvoiceEntity) error
+}
+
+// NotificationDeliveryRepository records customer notification deliveries.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type NotificationDeliveryRepository interface {
+	// Claim records an attempt and loads the stored delivery, which is already
+	// SENT when an earlier attempt delivered it.
+	Claim(ctx context.Context, delivery *entity.NotificationDeliveryEntity) error
+	MarkSent(ctx context.Context, idempotencyKey string, sentAt time.Time) error
+}
+
+// WebhookRepository defines operations for webhook subscriptions and their
+// delivery log. All methods return raw database errors; callers are responsible
+// for translating them to domain-specific errors.
+type WebhookRepository interface {
+	InsertSubscription(ctx context.Context, sub *entity.WebhookSubscriptionEntity) error
+	FetchSubscription(ctx context.Context, uuid string) (*entity.WebhookSubscriptionEntity, error)
+	// FetchActiveSubscriptions lists undeleted subscriptions, those selecting
+	// eventType when it is not empty.
+	FetchActiveSubscriptions(ctx context.Context, eventType string) ([]*entity.WebhookSubscriptionEntity, error)
+	DeleteSubscription(ctx context.Context, uuid string, deletedAt time.Time) error
+
+	// InsertDelivery records a delivery, or loads the stored one with the same UUID.
+	InsertDelivery(ctx context.Context, delivery *entity.WebhookDeliveryEntity) error
+	FetchDelivery(ctx context.Context, uuid string) (*entity.WebhookDeliveryEntity, error)
+	FetchDeliveries(ctx context.Context, subscriptionUUID string, cursorTime time.Time, cursorID int64, limit int) ([]*entity.WebhookDeliveryEntity, error)
+	RecordAttempt(ctx context.Context, attempt *entity.WebhookDeliveryAttemptEntity, delivered bool) error
+	UpdateDeliveryStatus(ctx context.Context, uuid string, from, to entity.WebhookDeliveryStatus) error
+}
+
+// OutboxRepository reads the billing outbox for the relay. Events are written by
+// the bill and line item transactions themselves, not through this interface.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type OutboxRepository interface {
+	FetchUnpublished(ctx context.Context, limit int) ([]*entity.OutboxEventEntity, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+}
+
+// PaymentRepository defines operations for payments received against bills.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type PaymentRepository interface {
+	// Insert records the payment, or loads the stored one when its idempotency
+	// key was already used on the bill
+	Insert(ctx context.Context, payment *entity.PaymentEntity) error
+	FetchByBillUUID(ctx context.Context, billUUID string) ([]*entity.PaymentEntity, error)
+}
+
+// SettlementRepository defines operations for the bank transfers settling bills.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type SettlementRepository interface {
+	// Insert records a pending settlement, or loads the bill's existing one
+	Insert(ctx context.Context, settlement *entity.SettlementEntity) error
+	FetchByBillUUID(ctx context.Context, billUUID string) (*entity.SettlementEntity, error)
+	MarkSettled(ctx context.Context, billUUID, paymentUUID string) error
+	// MarkCredited records the customer credit a transfer the bill no longer
+	// accepted was granted as
+	MarkCredited(ctx context.Context, billUUID, creditUUID, reason string) error
+	MarkFailed(ctx context.Context, billUUID, reason string) error
+}
+
+// PromotionRepository defines operations for promo codes and their redemptions.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type PromotionRepository interface {
+	Insert(ctx context.Context, promotion *entity.PromotionEntity) error
+	FetchByCode(ctx context.Context, code string) (*entity.PromotionEntity, error)
+	// Redeem counts the promotion against its usage limit on an open bill
+	Redeem(ctx context.Context, billUUID, promotionUUID string, now time.Time) error
+	// FetchByBillUUID returns the promotions redeemed on the bill, in redemption order
+	FetchByBillUUID(ctx context.Context, billUUID string) ([]*entity.PromotionEntity, error)
+}
+
+// CreditRepository defines operations for customer credit balances.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type CreditRepository interface {
+	// Insert grants the credit, or loads the stored one when its idempotency
+	// key was already used for the customer
+	Insert(ctx context.Context, credit *entity.CreditEntity) error
+	FetchAvailable(ctx context.Context, customerUUID, currency string) (int64, error)
+	// Apply consumes credit towards a closing bill once, later calls return
+	// the first call's applications
+	Apply(ctx context.Context, billUUID, customerUUID, currency string, dueCents int64) ([]*entity.CreditApplicationEntity, error)
+}
+
+// CreditNoteRepository defines operations for credit notes issued against closed bills.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type CreditNoteRepository interface {
+	// Insert numbers and stores the note with its lines, or loads the stored
+	// one when its idempotency key was already used on the bill
+	Insert(ctx context.Context, note *entity.CreditNoteEntity) error
+	FetchByUUID(ctx context.Context, uuid string) (*entity.CreditNoteEntity, error)
+	MarkApplied(ctx context.Context, uuid, creditUUID string, appliedAt time.Time) error
+}
+
+// UsageRepository defines operations for metered usage events.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type UsageRepository interface {
+	// Insert records the events on an open bill, skipping already used dedup
+	// keys, and returns how many were inserted
+	Insert(ctx context.Context, billUUID string, events []*entity.UsageEventEntity) (int, error)
+	SumByMeter(ctx context.Context, billUUID string) (map[string]int64, error)
+}
+
+// PriceRepository defines operations for the product catalog and its prices.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type PriceRepository interface {
+	InsertProduct(ctx context.Context, product *entity.ProductEntity) error
+	FetchProductByCode(ctx context.Context, code string) (*entity.ProductEntity, error)
+	FetchProductByUUID(ctx context.Context, uuid string) (*entity.ProductEntity, error)
+	InsertPrice(ctx context.Context, price *entity.PriceEntity) error
+	// FetchEffective returns the product's price in the currency in effect at at
+	FetchEffective(ctx context.Context, productUUID, currency string, at time.Time) (*entity.PriceEntity, error)
+}
+
+// SubscriptionRepository defines operations for customer subscriptions.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type SubscriptionRepository interface {
+	// Insert stores the subscription, or loads the stored one when its UUID exists
+	Insert(ctx context.Context, subscription *entity.SubscriptionEntity) error
+	FetchByUUID(ctx context.Context, uuid string) (*entity.SubscriptionEntity, error)
+	End(ctx context.Context, uuid string, endedAt time.Time) (*entity.SubscriptionEntity, error)
+	// FetchActive returns the customer's subscriptions overlapping [start, end)
+	FetchActive(ctx context.Context, customerUUID string, start, end time.Time) ([]*entity.SubscriptionEntity, error)
+}
+
+// ReconciliationRepository defines operations for the reconciliation of bill
+// totals and the findings it records.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type ReconciliationRepository interface {
+	// FetchBillTotals returns the next bills after afterID to check, oldest
+	// first: those not voided and not closed before closedSince
+	FetchBillTotals(ctx context.Context, afterID int64, closedSince time.Time, limit int) ([]*entity.BillTotalsEntity, error)
+	// RecordFinding stores an open finding, or updates the one already open
+	// for the bill and kind
+	RecordFinding(ctx context.Context, finding *entity.ReconciliationFindingEntity) error
+	ResolveFindings(ctx context.Context, billUUID string, kinds []entity.ReconciliationFindingKind, resolvedAt time.Time) error
+	// RepairBillTotal sets total_cents to the ledger total and records the
+	// repair, false when the totals already agree
+	RepairBillTotal(ctx context.Context, finding *entity.ReconciliationFindingEntity) (bool, error)
+	FetchFindings(ctx context.Context, params db.ReconciliationFindingQueryParams) ([]*entity.ReconciliationFindingEntity, error)
+}
+
+// LedgerRepository defines read operations on the double-entry ledger. Entries
+// are posted by the repositories recording what they book, in the same
+// transaction.
+// All methods return raw database errors; callers are responsible for
+// translating them to domain-specific errors.
+type LedgerRepository interface {
+	// FetchTrialBalance totals debits and credits per currency and account
+	// type, for one currency when it is set
+	FetchTrialBalance(ctx context.Context, currency string) ([]*entity.TrialBalanceLineEntity, error)
+	FetchCustomerBalances(ctx context.Context, customerUUID string) ([]*entity.CustomerBalanceEntity, error)
 }
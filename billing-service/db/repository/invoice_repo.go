@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// InvoiceRepo is the PostgreSQL implementation of InvoiceRepository.
+type InvoiceRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure InvoiceRepo implements InvoiceRepository.
+var _ InvoiceRepository = (*InvoiceRepo)(nil)
+
+func (r *InvoiceRepo) FetchByBillUUID(ctx context.Context, billUUID string) (*entity.InvoiceEntity, error) {
+	return db.FetchInvoiceByBillUUID(ctx, r.DB, billUUID)
+}
+
+func (r *InvoiceRepo) Insert(ctx context.Context, invoice *entity.InvoiceEntity) error {
+	return db.InsertInvoice(ctx, r.DB, invoice)
+}
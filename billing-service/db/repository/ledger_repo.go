@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// LedgerRepo is the PostgreSQL implementation of LedgerRepository.
+type LedgerRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure LedgerRepo implements LedgerRepository.
+var _ LedgerRepository = (*LedgerRepo)(nil)
+
+func (r *LedgerRepo) FetchTrialBalance(ctx context.Context, currency string) ([]*entity.TrialBalanceLineEntity, error) {
+	return db.FetchTrialBalance(ctx, r.DB, currency)
+}
+
+func (r *LedgerRepo) FetchCustomerBalances(ctx context.Context, customerUUID string) ([]*entity.CustomerBalanceEntity, error) {
+	return db.FetchCustomerBalances(ctx, r.DB, customerUUID)
+}
@@ -36,3 +36,11 @@ func (r *LineItemRepo) FetchReversalByOriginalUUID(ctx context.Context, original
 func (r *LineItemRepo) InsertWithBillUpdate(ctx context.Context, lineItem *entity.LineItemEntity) error {
 	return db.InsertLineItemWithBillUpdate(ctx, r.DB, lineItem)
 }
+
+func (r *LineItemRepo) SumByFeeType(ctx context.Context, billUUID string) (map[string]int64, error) {
+	return db.SumLineItemsByFeeType(ctx, r.DB, billUUID)
+}
+
+func (r *LineItemRepo) SumByReference(ctx context.Context, billUUID, feeType string) (map[string]int64, error) {
+	return db.SumLineItemsByReference(ctx, r.DB, billUUID, feeType)
+}
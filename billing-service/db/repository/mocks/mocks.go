@@ -44,17 +44,17 @@ func (m *MockBillRepository) EXPECT() *MockBillRepositoryMockRecorder {
 }
 
 // Close mocks base method.
-func (m *MockBillRepository) Close(ctx context.Context, billUUID string, closedAt time.Time) error {
+func (m *MockBillRepository) Close(ctx context.Context, billUUID string, closedAt time.Time, dueDate *time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Close", ctx, billUUID, closedAt)
+	ret := m.ctrl.Call(m, "Close", ctx, billUUID, closedAt, dueDate)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Close indicates an expected call of Close.
-func (mr *MockBillRepositoryMockRecorder) Close(ctx, billUUID, closedAt any) *gomock.Call {
+func (mr *MockBillRepositoryMockRecorder) Close(ctx, billUUID, closedAt, dueDate any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBillRepository)(nil).Close), ctx, billUUID, closedAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBillRepository)(nil).Close), ctx, billUUID, closedAt, dueDate)
 }
 
 // FetchAll mocks base method.
@@ -117,6 +117,34 @@ func (mr *MockBillRepositoryMockRecorder) Insert(ctx, bill any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockBillRepository)(nil).Insert), ctx, bill)
 }
 
+// UpdateStatus mocks base method.
+func (m *MockBillRepository) UpdateStatus(ctx context.Context, billUUID string, from, to entity.BillStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, billUUID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockBillRepositoryMockRecorder) UpdateStatus(ctx, billUUID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockBillRepository)(nil).UpdateStatus), ctx, billUUID, from, to)
+}
+
+// Void mocks base method.
+func (m *MockBillRepository) Void(ctx context.Context, billUUID, reason string, voidedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, billUUID, reason, voidedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockBillRepositoryMockRecorder) Void(ctx, billUUID, reason, voidedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockBillRepository)(nil).Void), ctx, billUUID, reason, voidedAt)
+}
+
 // MockLineItemRepository is a mock of LineItemRepository interface.
 type MockLineItemRepository struct {
 	ctrl     *gomock.Controller
@@ -215,6 +243,36 @@ func (mr *MockLineItemRepositoryMockRecorder) InsertWithBillUpdate(ctx, lineItem
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWithBillUpdate", reflect.TypeOf((*MockLineItemRepository)(nil).InsertWithBillUpdate), ctx, lineItem)
 }
 
+// SumByFeeType mocks base method.
+func (m *MockLineItemRepository) SumByFeeType(ctx context.Context, billUUID string) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByFeeType", ctx, billUUID)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByFeeType indicates an expected call of SumByFeeType.
+func (mr *MockLineItemRepositoryMockRecorder) SumByFeeType(ctx, billUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByFeeType", reflect.TypeOf((*MockLineItemRepository)(nil).SumByFeeType), ctx, billUUID)
+}
+
+// SumByReference mocks base method.
+func (m *MockLineItemRepository) SumByReference(ctx context.Context, billUUID, feeType string) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByReference", ctx, billUUID, feeType)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByReference indicates an expected call of SumByReference.
+func (mr *MockLineItemRepositoryMockRecorder) SumByReference(ctx, billUUID, feeType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByReference", reflect.TypeOf((*MockLineItemRepository)(nil).SumByReference), ctx, billUUID, feeType)
+}
+
 // MockCustomerRepository is a mock of CustomerRepository interface.
 type MockCustomerRepository struct {
 	ctrl     *gomock.Controller
@@ -239,6 +297,36 @@ func (m *MockCustomerRepository) EXPECT() *MockCustomerRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Archive mocks base method.
+func (m *MockCustomerRepository) Archive(ctx context.Context, uuid string, archivedAt time.Time) (*entity.CustomerEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, uuid, archivedAt)
+	ret0, _ := ret[0].(*entity.CustomerEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockCustomerRepositoryMockRecorder) Archive(ctx, uuid, archivedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockCustomerRepository)(nil).Archive), ctx, uuid, archivedAt)
+}
+
+// FetchAll mocks base method.
+func (m *MockCustomerRepository) FetchAll(ctx context.Context, params db.CustomerQueryParams) ([]*entity.CustomerEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAll", ctx, params)
+	ret0, _ := ret[0].([]*entity.CustomerEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAll indicates an expected call of FetchAll.
+func (mr *MockCustomerRepositoryMockRecorder) FetchAll(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAll", reflect.TypeOf((*MockCustomerRepository)(nil).FetchAll), ctx, params)
+}
+
 // FetchByEmail mocks base method.
 func (m *MockCustomerRepository) FetchByEmail(ctx context.Context, email string) (*entity.CustomerEntity, error) {
 	m.ctrl.T.Helper()
@@ -282,3 +370,1147 @@ func (mr *MockCustomerRepositoryMockRecorder) Insert(ctx, customer any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCustomerRepository)(nil).Insert), ctx, customer)
 }
+
+// Merge mocks base method.
+func (m *MockCustomerRepository) Merge(ctx context.Context, sourceUUID, targetUUID string) (*entity.CustomerMergeEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, sourceUUID, targetUUID)
+	ret0, _ := ret[0].(*entity.CustomerMergeEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockCustomerRepositoryMockRecorder) Merge(ctx, sourceUUID, targetUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockCustomerRepository)(nil).Merge), ctx, sourceUUID, targetUUID)
+}
+
+// Update mocks base method.
+func (m *MockCustomerRepository) Update(ctx context.Context, customer *entity.CustomerEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCustomerRepositoryMockRecorder) Update(ctx, customer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomerRepository)(nil).Update), ctx, customer)
+}
+
+// MockBillingProfileRepository is a mock of BillingProfileRepository interface.
+type MockBillingProfileRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBillingProfileRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockBillingProfileRepositoryMockRecorder is the mock recorder for MockBillingProfileRepository.
+type MockBillingProfileRepositoryMockRecorder struct {
+	mock *MockBillingProfileRepository
+}
+
+// NewMockBillingProfileRepository creates a new mock instance.
+func NewMockBillingProfileRepository(ctrl *gomock.Controller) *MockBillingProfileRepository {
+	mock := &MockBillingProfileRepository{ctrl: ctrl}
+	mock.recorder = &MockBillingProfileRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBillingProfileRepository) EXPECT() *MockBillingProfileRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchByCustomerUUID mocks base method.
+func (m *MockBillingProfileRepository) FetchByCustomerUUID(ctx context.Context, customerUUID string) (*entity.BillingProfileEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByCustomerUUID", ctx, customerUUID)
+	ret0, _ := ret[0].(*entity.BillingProfileEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByCustomerUUID indicates an expected call of FetchByCustomerUUID.
+func (mr *MockBillingProfileRepositoryMockRecorder) FetchByCustomerUUID(ctx, customerUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByCustomerUUID", reflect.TypeOf((*MockBillingProfileRepository)(nil).FetchByCustomerUUID), ctx, customerUUID)
+}
+
+// Upsert mocks base method.
+func (m *MockBillingProfileRepository) Upsert(ctx context.Context, profile *entity.BillingProfileEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockBillingProfileRepositoryMockRecorder) Upsert(ctx, profile any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockBillingProfileRepository)(nil).Upsert), ctx, profile)
+}
+
+// MockInvoiceRepository is a mock of InvoiceRepository interface.
+type MockInvoiceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvoiceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockInvoiceRepositoryMockRecorder is the mock recorder for MockInvoiceRepository.
+type MockInvoiceRepositoryMockRecorder struct {
+	mock *MockInvoiceRepository
+}
+
+// NewMockInvoiceRepository creates a new mock instance.
+func NewMockInvoiceRepository(ctrl *gomock.Controller) *MockInvoiceRepository {
+	mock := &MockInvoiceRepository{ctrl: ctrl}
+	mock.recorder = &MockInvoiceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvoiceRepository) EXPECT() *MockInvoiceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchByBillUUID mocks base method.
+func (m *MockInvoiceRepository) FetchByBillUUID(ctx context.Context, billUUID string) (*entity.InvoiceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByBillUUID", ctx, billUUID)
+	ret0, _ := ret[0].(*entity.InvoiceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByBillUUID indicates an expected call of FetchByBillUUID.
+func (mr *MockInvoiceRepositoryMockRecorder) FetchByBillUUID(ctx, billUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByBillUUID", reflect.TypeOf((*MockInvoiceRepository)(nil).FetchByBillUUID), ctx, billUUID)
+}
+
+// Insert mocks base method.
+func (m *MockInvoiceRepository) Insert(ctx context.Context, invoice *entity.InvoiceEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, invoice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockInvoiceRepositoryMockRecorder) Insert(ctx, invoice any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockInvoiceRepository)(nil).Insert), ctx, invoice)
+}
+
+// MockNotificationDeliveryRepository is a mock of NotificationDeliveryRepository interface.
+type MockNotificationDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationDeliveryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationDeliveryRepositoryMockRecorder is the mock recorder for MockNotificationDeliveryRepository.
+type MockNotificationDeliveryRepositoryMockRecorder struct {
+	mock *MockNotificationDeliveryRepository
+}
+
+// NewMockNotificationDeliveryRepository creates a new mock instance.
+func NewMockNotificationDeliveryRepository(ctrl *gomock.Controller) *MockNotificationDeliveryRepository {
+	mock := &MockNotificationDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationDeliveryRepository) EXPECT() *MockNotificationDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockNotificationDeliveryRepository) Claim(ctx context.Context, delivery *entity.NotificationDeliveryEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockNotificationDeliveryRepositoryMockRecorder) Claim(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockNotificationDeliveryRepository)(nil).Claim), ctx, delivery)
+}
+
+// MarkSent mocks base method.
+func (m *MockNotificationDeliveryRepository) MarkSent(ctx context.Context, idempotencyKey string, sentAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, idempotencyKey, sentAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockNotificationDeliveryRepositoryMockRecorder) MarkSent(ctx, idempotencyKey, sentAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockNotificationDeliveryRepository)(nil).MarkSent), ctx, idempotencyKey, sentAt)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, uuid string, deletedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, uuid, deletedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteSubscription(ctx, uuid, deletedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteSubscription), ctx, uuid, deletedAt)
+}
+
+// FetchActiveSubscriptions mocks base method.
+func (m *MockWebhookRepository) FetchActiveSubscriptions(ctx context.Context, eventType string) ([]*entity.WebhookSubscriptionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchActiveSubscriptions", ctx, eventType)
+	ret0, _ := ret[0].([]*entity.WebhookSubscriptionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchActiveSubscriptions indicates an expected call of FetchActiveSubscriptions.
+func (mr *MockWebhookRepositoryMockRecorder) FetchActiveSubscriptions(ctx, eventType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchActiveSubscriptions", reflect.TypeOf((*MockWebhookRepository)(nil).FetchActiveSubscriptions), ctx, eventType)
+}
+
+// FetchDeliveries mocks base method.
+func (m *MockWebhookRepository) FetchDeliveries(ctx context.Context, subscriptionUUID string, cursorTime time.Time, cursorID int64, limit int) ([]*entity.WebhookDeliveryEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDeliveries", ctx, subscriptionUUID, cursorTime, cursorID, limit)
+	ret0, _ := ret[0].([]*entity.WebhookDeliveryEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDeliveries indicates an expected call of FetchDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) FetchDeliveries(ctx, subscriptionUUID, cursorTime, cursorID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).FetchDeliveries), ctx, subscriptionUUID, cursorTime, cursorID, limit)
+}
+
+// FetchDelivery mocks base method.
+func (m *MockWebhookRepository) FetchDelivery(ctx context.Context, uuid string) (*entity.WebhookDeliveryEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDelivery", ctx, uuid)
+	ret0, _ := ret[0].(*entity.WebhookDeliveryEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDelivery indicates an expected call of FetchDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) FetchDelivery(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).FetchDelivery), ctx, uuid)
+}
+
+// FetchSubscription mocks base method.
+func (m *MockWebhookRepository) FetchSubscription(ctx context.Context, uuid string) (*entity.WebhookSubscriptionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchSubscription", ctx, uuid)
+	ret0, _ := ret[0].(*entity.WebhookSubscriptionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchSubscription indicates an expected call of FetchSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) FetchSubscription(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).FetchSubscription), ctx, uuid)
+}
+
+// InsertDelivery mocks base method.
+func (m *MockWebhookRepository) InsertDelivery(ctx context.Context, delivery *entity.WebhookDeliveryEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertDelivery indicates an expected call of InsertDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) InsertDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).InsertDelivery), ctx, delivery)
+}
+
+// InsertSubscription mocks base method.
+func (m *MockWebhookRepository) InsertSubscription(ctx context.Context, sub *entity.WebhookSubscriptionEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertSubscription indicates an expected call of InsertSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) InsertSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).InsertSubscription), ctx, sub)
+}
+
+// RecordAttempt mocks base method.
+func (m *MockWebhookRepository) RecordAttempt(ctx context.Context, attempt *entity.WebhookDeliveryAttemptEntity, delivered bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", ctx, attempt, delivered)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt.
+func (mr *MockWebhookRepositoryMockRecorder) RecordAttempt(ctx, attempt, delivered any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*MockWebhookRepository)(nil).RecordAttempt), ctx, attempt, delivered)
+}
+
+// UpdateDeliveryStatus mocks base method.
+func (m *MockWebhookRepository) UpdateDeliveryStatus(ctx context.Context, uuid string, from, to entity.WebhookDeliveryStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeliveryStatus", ctx, uuid, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDeliveryStatus indicates an expected call of UpdateDeliveryStatus.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateDeliveryStatus(ctx, uuid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeliveryStatus", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateDeliveryStatus), ctx, uuid, from, to)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchUnpublished mocks base method.
+func (m *MockOutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]*entity.OutboxEventEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUnpublished", ctx, limit)
+	ret0, _ := ret[0].([]*entity.OutboxEventEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUnpublished indicates an expected call of FetchUnpublished.
+func (mr *MockOutboxRepositoryMockRecorder) FetchUnpublished(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUnpublished", reflect.TypeOf((*MockOutboxRepository)(nil).FetchUnpublished), ctx, limit)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ctx, id, publishedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxRepositoryMockRecorder) MarkPublished(ctx, id, publishedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxRepository)(nil).MarkPublished), ctx, id, publishedAt)
+}
+
+// MockPaymentRepository is a mock of PaymentRepository interface.
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository.
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance.
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchByBillUUID mocks base method.
+func (m *MockPaymentRepository) FetchByBillUUID(ctx context.Context, billUUID string) ([]*entity.PaymentEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByBillUUID", ctx, billUUID)
+	ret0, _ := ret[0].([]*entity.PaymentEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByBillUUID indicates an expected call of FetchByBillUUID.
+func (mr *MockPaymentRepositoryMockRecorder) FetchByBillUUID(ctx, billUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByBillUUID", reflect.TypeOf((*MockPaymentRepository)(nil).FetchByBillUUID), ctx, billUUID)
+}
+
+// Insert mocks base method.
+func (m *MockPaymentRepository) Insert(ctx context.Context, payment *entity.PaymentEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockPaymentRepositoryMockRecorder) Insert(ctx, payment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPaymentRepository)(nil).Insert), ctx, payment)
+}
+
+// MockSettlementRepository is a mock of SettlementRepository interface.
+type MockSettlementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettlementRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSettlementRepositoryMockRecorder is the mock recorder for MockSettlementRepository.
+type MockSettlementRepositoryMockRecorder struct {
+	mock *MockSettlementRepository
+}
+
+// NewMockSettlementRepository creates a new mock instance.
+func NewMockSettlementRepository(ctrl *gomock.Controller) *MockSettlementRepository {
+	mock := &MockSettlementRepository{ctrl: ctrl}
+	mock.recorder = &MockSettlementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettlementRepository) EXPECT() *MockSettlementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchByBillUUID mocks base method.
+func (m *MockSettlementRepository) FetchByBillUUID(ctx context.Context, billUUID string) (*entity.SettlementEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByBillUUID", ctx, billUUID)
+	ret0, _ := ret[0].(*entity.SettlementEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByBillUUID indicates an expected call of FetchByBillUUID.
+func (mr *MockSettlementRepositoryMockRecorder) FetchByBillUUID(ctx, billUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByBillUUID", reflect.TypeOf((*MockSettlementRepository)(nil).FetchByBillUUID), ctx, billUUID)
+}
+
+// Insert mocks base method.
+func (m *MockSettlementRepository) Insert(ctx context.Context, settlement *entity.SettlementEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, settlement)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockSettlementRepositoryMockRecorder) Insert(ctx, settlement any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockSettlementRepository)(nil).Insert), ctx, settlement)
+}
+
+// MarkCredited mocks base method.
+func (m *MockSettlementRepository) MarkCredited(ctx context.Context, billUUID, creditUUID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkCredited", ctx, billUUID, creditUUID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkCredited indicates an expected call of MarkCredited.
+func (mr *MockSettlementRepositoryMockRecorder) MarkCredited(ctx, billUUID, creditUUID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkCredited", reflect.TypeOf((*MockSettlementRepository)(nil).MarkCredited), ctx, billUUID, creditUUID, reason)
+}
+
+// MarkFailed mocks base method.
+func (m *MockSettlementRepository) MarkFailed(ctx context.Context, billUUID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, billUUID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockSettlementRepositoryMockRecorder) MarkFailed(ctx, billUUID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockSettlementRepository)(nil).MarkFailed), ctx, billUUID, reason)
+}
+
+// MarkSettled mocks base method.
+func (m *MockSettlementRepository) MarkSettled(ctx context.Context, billUUID, paymentUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSettled", ctx, billUUID, paymentUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSettled indicates an expected call of MarkSettled.
+func (mr *MockSettlementRepositoryMockRecorder) MarkSettled(ctx, billUUID, paymentUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSettled", reflect.TypeOf((*MockSettlementRepository)(nil).MarkSettled), ctx, billUUID, paymentUUID)
+}
+
+// MockPromotionRepository is a mock of PromotionRepository interface.
+type MockPromotionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPromotionRepositoryMockRecorder is the mock recorder for MockPromotionRepository.
+type MockPromotionRepositoryMockRecorder struct {
+	mock *MockPromotionRepository
+}
+
+// NewMockPromotionRepository creates a new mock instance.
+func NewMockPromotionRepository(ctrl *gomock.Controller) *MockPromotionRepository {
+	mock := &MockPromotionRepository{ctrl: ctrl}
+	mock.recorder = &MockPromotionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotionRepository) EXPECT() *MockPromotionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchByBillUUID mocks base method.
+func (m *MockPromotionRepository) FetchByBillUUID(ctx context.Context, billUUID string) ([]*entity.PromotionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByBillUUID", ctx, billUUID)
+	ret0, _ := ret[0].([]*entity.PromotionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByBillUUID indicates an expected call of FetchByBillUUID.
+func (mr *MockPromotionRepositoryMockRecorder) FetchByBillUUID(ctx, billUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByBillUUID", reflect.TypeOf((*MockPromotionRepository)(nil).FetchByBillUUID), ctx, billUUID)
+}
+
+// FetchByCode mocks base method.
+func (m *MockPromotionRepository) FetchByCode(ctx context.Context, code string) (*entity.PromotionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByCode", ctx, code)
+	ret0, _ := ret[0].(*entity.PromotionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByCode indicates an expected call of FetchByCode.
+func (mr *MockPromotionRepositoryMockRecorder) FetchByCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByCode", reflect.TypeOf((*MockPromotionRepository)(nil).FetchByCode), ctx, code)
+}
+
+// Insert mocks base method.
+func (m *MockPromotionRepository) Insert(ctx context.Context, promotion *entity.PromotionEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, promotion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockPromotionRepositoryMockRecorder) Insert(ctx, promotion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPromotionRepository)(nil).Insert), ctx, promotion)
+}
+
+// Redeem mocks base method.
+func (m *MockPromotionRepository) Redeem(ctx context.Context, billUUID, promotionUUID string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeem", ctx, billUUID, promotionUUID, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeem indicates an expected call of Redeem.
+func (mr *MockPromotionRepositoryMockRecorder) Redeem(ctx, billUUID, promotionUUID, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeem", reflect.TypeOf((*MockPromotionRepository)(nil).Redeem), ctx, billUUID, promotionUUID, now)
+}
+
+// MockCreditRepository is a mock of CreditRepository interface.
+type MockCreditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCreditRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCreditRepositoryMockRecorder is the mock recorder for MockCreditRepository.
+type MockCreditRepositoryMockRecorder struct {
+	mock *MockCreditRepository
+}
+
+// NewMockCreditRepository creates a new mock instance.
+func NewMockCreditRepository(ctrl *gomock.Controller) *MockCreditRepository {
+	mock := &MockCreditRepository{ctrl: ctrl}
+	mock.recorder = &MockCreditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCreditRepository) EXPECT() *MockCreditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockCreditRepository) Apply(ctx context.Context, billUUID, customerUUID, currency string, dueCents int64) ([]*entity.CreditApplicationEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, billUUID, customerUUID, currency, dueCents)
+	ret0, _ := ret[0].([]*entity.CreditApplicationEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockCreditRepositoryMockRecorder) Apply(ctx, billUUID, customerUUID, currency, dueCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockCreditRepository)(nil).Apply), ctx, billUUID, customerUUID, currency, dueCents)
+}
+
+// FetchAvailable mocks base method.
+func (m *MockCreditRepository) FetchAvailable(ctx context.Context, customerUUID, currency string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAvailable", ctx, customerUUID, currency)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAvailable indicates an expected call of FetchAvailable.
+func (mr *MockCreditRepositoryMockRecorder) FetchAvailable(ctx, customerUUID, currency any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAvailable", reflect.TypeOf((*MockCreditRepository)(nil).FetchAvailable), ctx, customerUUID, currency)
+}
+
+// Insert mocks base method.
+func (m *MockCreditRepository) Insert(ctx context.Context, credit *entity.CreditEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, credit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCreditRepositoryMockRecorder) Insert(ctx, credit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCreditRepository)(nil).Insert), ctx, credit)
+}
+
+// MockCreditNoteRepository is a mock of CreditNoteRepository interface.
+type MockCreditNoteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCreditNoteRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCreditNoteRepositoryMockRecorder is the mock recorder for MockCreditNoteRepository.
+type MockCreditNoteRepositoryMockRecorder struct {
+	mock *MockCreditNoteRepository
+}
+
+// NewMockCreditNoteRepository creates a new mock instance.
+func NewMockCreditNoteRepository(ctrl *gomock.Controller) *MockCreditNoteRepository {
+	mock := &MockCreditNoteRepository{ctrl: ctrl}
+	mock.recorder = &MockCreditNoteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCreditNoteRepository) EXPECT() *MockCreditNoteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchByUUID mocks base method.
+func (m *MockCreditNoteRepository) FetchByUUID(ctx context.Context, uuid string) (*entity.CreditNoteEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByUUID", ctx, uuid)
+	ret0, _ := ret[0].(*entity.CreditNoteEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByUUID indicates an expected call of FetchByUUID.
+func (mr *MockCreditNoteRepositoryMockRecorder) FetchByUUID(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByUUID", reflect.TypeOf((*MockCreditNoteRepository)(nil).FetchByUUID), ctx, uuid)
+}
+
+// Insert mocks base method.
+func (m *MockCreditNoteRepository) Insert(ctx context.Context, note *entity.CreditNoteEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCreditNoteRepositoryMockRecorder) Insert(ctx, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCreditNoteRepository)(nil).Insert), ctx, note)
+}
+
+// MarkApplied mocks base method.
+func (m *MockCreditNoteRepository) MarkApplied(ctx context.Context, uuid, creditUUID string, appliedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkApplied", ctx, uuid, creditUUID, appliedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkApplied indicates an expected call of MarkApplied.
+func (mr *MockCreditNoteRepositoryMockRecorder) MarkApplied(ctx, uuid, creditUUID, appliedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkApplied", reflect.TypeOf((*MockCreditNoteRepository)(nil).MarkApplied), ctx, uuid, creditUUID, appliedAt)
+}
+
+// MockUsageRepository is a mock of UsageRepository interface.
+type MockUsageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsageRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUsageRepositoryMockRecorder is the mock recorder for MockUsageRepository.
+type MockUsageRepositoryMockRecorder struct {
+	mock *MockUsageRepository
+}
+
+// NewMockUsageRepository creates a new mock instance.
+func NewMockUsageRepository(ctrl *gomock.Controller) *MockUsageRepository {
+	mock := &MockUsageRepository{ctrl: ctrl}
+	mock.recorder = &MockUsageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsageRepository) EXPECT() *MockUsageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockUsageRepository) Insert(ctx context.Context, billUUID string, events []*entity.UsageEventEntity) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, billUUID, events)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockUsageRepositoryMockRecorder) Insert(ctx, billUUID, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockUsageRepository)(nil).Insert), ctx, billUUID, events)
+}
+
+// SumByMeter mocks base method.
+func (m *MockUsageRepository) SumByMeter(ctx context.Context, billUUID string) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByMeter", ctx, billUUID)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByMeter indicates an expected call of SumByMeter.
+func (mr *MockUsageRepositoryMockRecorder) SumByMeter(ctx, billUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByMeter", reflect.TypeOf((*MockUsageRepository)(nil).SumByMeter), ctx, billUUID)
+}
+
+// MockPriceRepository is a mock of PriceRepository interface.
+type MockPriceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPriceRepositoryMockRecorder is the mock recorder for MockPriceRepository.
+type MockPriceRepositoryMockRecorder struct {
+	mock *MockPriceRepository
+}
+
+// NewMockPriceRepository creates a new mock instance.
+func NewMockPriceRepository(ctrl *gomock.Controller) *MockPriceRepository {
+	mock := &MockPriceRepository{ctrl: ctrl}
+	mock.recorder = &MockPriceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceRepository) EXPECT() *MockPriceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchEffective mocks base method.
+func (m *MockPriceRepository) FetchEffective(ctx context.Context, productUUID, currency string, at time.Time) (*entity.PriceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchEffective", ctx, productUUID, currency, at)
+	ret0, _ := ret[0].(*entity.PriceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchEffective indicates an expected call of FetchEffective.
+func (mr *MockPriceRepositoryMockRecorder) FetchEffective(ctx, productUUID, currency, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchEffective", reflect.TypeOf((*MockPriceRepository)(nil).FetchEffective), ctx, productUUID, currency, at)
+}
+
+// FetchProductByCode mocks base method.
+func (m *MockPriceRepository) FetchProductByCode(ctx context.Context, code string) (*entity.ProductEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchProductByCode", ctx, code)
+	ret0, _ := ret[0].(*entity.ProductEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchProductByCode indicates an expected call of FetchProductByCode.
+func (mr *MockPriceRepositoryMockRecorder) FetchProductByCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchProductByCode", reflect.TypeOf((*MockPriceRepository)(nil).FetchProductByCode), ctx, code)
+}
+
+// FetchProductByUUID mocks base method.
+func (m *MockPriceRepository) FetchProductByUUID(ctx context.Context, uuid string) (*entity.ProductEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchProductByUUID", ctx, uuid)
+	ret0, _ := ret[0].(*entity.ProductEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchProductByUUID indicates an expected call of FetchProductByUUID.
+func (mr *MockPriceRepositoryMockRecorder) FetchProductByUUID(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchProductByUUID", reflect.TypeOf((*MockPriceRepository)(nil).FetchProductByUUID), ctx, uuid)
+}
+
+// InsertPrice mocks base method.
+func (m *MockPriceRepository) InsertPrice(ctx context.Context, price *entity.PriceEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPrice", ctx, price)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertPrice indicates an expected call of InsertPrice.
+func (mr *MockPriceRepositoryMockRecorder) InsertPrice(ctx, price any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPrice", reflect.TypeOf((*MockPriceRepository)(nil).InsertPrice), ctx, price)
+}
+
+// InsertProduct mocks base method.
+func (m *MockPriceRepository) InsertProduct(ctx context.Context, product *entity.ProductEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertProduct", ctx, product)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertProduct indicates an expected call of InsertProduct.
+func (mr *MockPriceRepositoryMockRecorder) InsertProduct(ctx, product any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProduct", reflect.TypeOf((*MockPriceRepository)(nil).InsertProduct), ctx, product)
+}
+
+// MockSubscriptionRepository is a mock of SubscriptionRepository interface.
+type MockSubscriptionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSubscriptionRepositoryMockRecorder is the mock recorder for MockSubscriptionRepository.
+type MockSubscriptionRepositoryMockRecorder struct {
+	mock *MockSubscriptionRepository
+}
+
+// NewMockSubscriptionRepository creates a new mock instance.
+func NewMockSubscriptionRepository(ctrl *gomock.Controller) *MockSubscriptionRepository {
+	mock := &MockSubscriptionRepository{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionRepository) EXPECT() *MockSubscriptionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// End mocks base method.
+func (m *MockSubscriptionRepository) End(ctx context.Context, uuid string, endedAt time.Time) (*entity.SubscriptionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "End", ctx, uuid, endedAt)
+	ret0, _ := ret[0].(*entity.SubscriptionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// End indicates an expected call of End.
+func (mr *MockSubscriptionRepositoryMockRecorder) End(ctx, uuid, endedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "End", reflect.TypeOf((*MockSubscriptionRepository)(nil).End), ctx, uuid, endedAt)
+}
+
+// FetchActive mocks base method.
+func (m *MockSubscriptionRepository) FetchActive(ctx context.Context, customerUUID string, start, end time.Time) ([]*entity.SubscriptionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchActive", ctx, customerUUID, start, end)
+	ret0, _ := ret[0].([]*entity.SubscriptionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchActive indicates an expected call of FetchActive.
+func (mr *MockSubscriptionRepositoryMockRecorder) FetchActive(ctx, customerUUID, start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchActive", reflect.TypeOf((*MockSubscriptionRepository)(nil).FetchActive), ctx, customerUUID, start, end)
+}
+
+// FetchByUUID mocks base method.
+func (m *MockSubscriptionRepository) FetchByUUID(ctx context.Context, uuid string) (*entity.SubscriptionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchByUUID", ctx, uuid)
+	ret0, _ := ret[0].(*entity.SubscriptionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchByUUID indicates an expected call of FetchByUUID.
+func (mr *MockSubscriptionRepositoryMockRecorder) FetchByUUID(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchByUUID", reflect.TypeOf((*MockSubscriptionRepository)(nil).FetchByUUID), ctx, uuid)
+}
+
+// Insert mocks base method.
+func (m *MockSubscriptionRepository) Insert(ctx context.Context, subscription *entity.SubscriptionEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockSubscriptionRepositoryMockRecorder) Insert(ctx, subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockSubscriptionRepository)(nil).Insert), ctx, subscription)
+}
+
+// MockReconciliationRepository is a mock of ReconciliationRepository interface.
+type MockReconciliationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReconciliationRepositoryMockRecorder is the mock recorder for MockReconciliationRepository.
+type MockReconciliationRepositoryMockRecorder struct {
+	mock *MockReconciliationRepository
+}
+
+// NewMockReconciliationRepository creates a new mock instance.
+func NewMockReconciliationRepository(ctrl *gomock.Controller) *MockReconciliationRepository {
+	mock := &MockReconciliationRepository{ctrl: ctrl}
+	mock.recorder = &MockReconciliationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationRepository) EXPECT() *MockReconciliationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchBillTotals mocks base method.
+func (m *MockReconciliationRepository) FetchBillTotals(ctx context.Context, afterID int64, closedSince time.Time, limit int) ([]*entity.BillTotalsEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchBillTotals", ctx, afterID, closedSince, limit)
+	ret0, _ := ret[0].([]*entity.BillTotalsEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchBillTotals indicates an expected call of FetchBillTotals.
+func (mr *MockReconciliationRepositoryMockRecorder) FetchBillTotals(ctx, afterID, closedSince, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchBillTotals", reflect.TypeOf((*MockReconciliationRepository)(nil).FetchBillTotals), ctx, afterID, closedSince, limit)
+}
+
+// FetchFindings mocks base method.
+func (m *MockReconciliationRepository) FetchFindings(ctx context.Context, params db.ReconciliationFindingQueryParams) ([]*entity.ReconciliationFindingEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchFindings", ctx, params)
+	ret0, _ := ret[0].([]*entity.ReconciliationFindingEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchFindings indicates an expected call of FetchFindings.
+func (mr *MockReconciliationRepositoryMockRecorder) FetchFindings(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchFindings", reflect.TypeOf((*MockReconciliationRepository)(nil).FetchFindings), ctx, params)
+}
+
+// RecordFinding mocks base method.
+func (m *MockReconciliationRepository) RecordFinding(ctx context.Context, finding *entity.ReconciliationFindingEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFinding", ctx, finding)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFinding indicates an expected call of RecordFinding.
+func (mr *MockReconciliationRepositoryMockRecorder) RecordFinding(ctx, finding any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFinding", reflect.TypeOf((*MockReconciliationRepository)(nil).RecordFinding), ctx, finding)
+}
+
+// RepairBillTotal mocks base method.
+func (m *MockReconciliationRepository) RepairBillTotal(ctx context.Context, finding *entity.ReconciliationFindingEntity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairBillTotal", ctx, finding)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepairBillTotal indicates an expected call of RepairBillTotal.
+func (mr *MockReconciliationRepositoryMockRecorder) RepairBillTotal(ctx, finding any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairBillTotal", reflect.TypeOf((*MockReconciliationRepository)(nil).RepairBillTotal), ctx, finding)
+}
+
+// ResolveFindings mocks base method.
+func (m *MockReconciliationRepository) ResolveFindings(ctx context.Context, billUUID string, kinds []entity.ReconciliationFindingKind, resolvedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveFindings", ctx, billUUID, kinds, resolvedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveFindings indicates an expected call of ResolveFindings.
+func (mr *MockReconciliationRepositoryMockRecorder) ResolveFindings(ctx, billUUID, kinds, resolvedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveFindings", reflect.TypeOf((*MockReconciliationRepository)(nil).ResolveFindings), ctx, billUUID, kinds, resolvedAt)
+}
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchCustomerBalances mocks base method.
+func (m *MockLedgerRepository) FetchCustomerBalances(ctx context.Context, customerUUID string) ([]*entity.CustomerBalanceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCustomerBalances", ctx, customerUUID)
+	ret0, _ := ret[0].([]*entity.CustomerBalanceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCustomerBalances indicates an expected call of FetchCustomerBalances.
+func (mr *MockLedgerRepositoryMockRecorder) FetchCustomerBalances(ctx, customerUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCustomerBalances", reflect.TypeOf((*MockLedgerRepository)(nil).FetchCustomerBalances), ctx, customerUUID)
+}
+
+// FetchTrialBalance mocks base method.
+func (m *MockLedgerRepository) FetchTrialBalance(ctx context.Context, currency string) ([]*entity.TrialBalanceLineEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchTrialBalance", ctx, currency)
+	ret0, _ := ret[0].([]*entity.TrialBalanceLineEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchTrialBalance indicates an expected call of FetchTrialBalance.
+func (mr *MockLedgerRepositoryMockRecorder) FetchTrialBalance(ctx, currency any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchTrialBalance", reflect.TypeOf((*MockLedgerRepository)(nil).FetchTrialBalance), ctx, currency)
+}
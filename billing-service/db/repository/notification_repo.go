@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// NotificationDeliveryRepo is the PostgreSQL implementation of NotificationDeliveryRepository.
+type NotificationDeliveryRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure NotificationDeliveryRepo implements NotificationDeliveryRepository.
+var _ NotificationDeliveryRepository = (*NotificationDeliveryRepo)(nil)
+
+func (r *NotificationDeliveryRepo) Claim(ctx context.Context, delivery *entity.NotificationDeliveryEntity) error {
+	return db.ClaimNotificationDelivery(ctx, r.DB, delivery)
+}
+
+func (r *NotificationDeliveryRepo) MarkSent(ctx context.Context, idempotencyKey string, sentAt time.Time) error {
+	return db.MarkNotificationDeliverySent(ctx, r.DB, idempotencyKey, sentAt)
+}
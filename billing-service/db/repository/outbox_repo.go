@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// OutboxRepo is the PostgreSQL implementation of OutboxRepository.
+type OutboxRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure OutboxRepo implements OutboxRepository.
+var _ OutboxRepository = (*OutboxRepo)(nil)
+
+func (r *OutboxRepo) FetchUnpublished(ctx context.Context, limit int) ([]*entity.OutboxEventEntity, error) {
+	return db.FetchUnpublishedOutboxEvents(ctx, r.DB, limit)
+}
+
+func (r *OutboxRepo) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	return db.MarkOutboxEventPublished(ctx, r.DB, id, publishedAt)
+}
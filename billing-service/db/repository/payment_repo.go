@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// PaymentRepo is the PostgreSQL implementation of PaymentRepository.
+type PaymentRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure PaymentRepo implements PaymentRepository.
+var _ PaymentRepository = (*PaymentRepo)(nil)
+
+func (r *PaymentRepo) Insert(ctx context.Context, payment *entity.PaymentEntity) error {
+	return db.InsertPayment(ctx, r.DB, payment)
+}
+
+func (r *PaymentRepo) FetchByBillUUID(ctx context.Context, billUUID string) ([]*entity.PaymentEntity, error) {
+	return db.FetchPaymentsByBillUUID(ctx, r.DB, billUUID)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// PromotionRepo is the PostgreSQL implementation of PromotionRepository.
+type PromotionRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure PromotionRepo implements PromotionRepository.
+var _ PromotionRepository = (*PromotionRepo)(nil)
+
+func (r *PromotionRepo) Insert(ctx context.Context, promotion *entity.PromotionEntity) error {
+	return db.InsertPromotion(ctx, r.DB, promotion)
+}
+
+func (r *PromotionRepo) FetchByCode(ctx context.Context, code string) (*entity.PromotionEntity, error) {
+	return db.FetchPromotionByCode(ctx, r.DB, code)
+}
+
+func (r *PromotionRepo) Redeem(ctx context.Context, billUUID, promotionUUID string, now time.Time) error {
+	return db.RedeemPromotion(ctx, r.DB, billUUID, promotionUUID, now)
+}
+
+func (r *PromotionRepo) FetchByBillUUID(ctx context.Context, billUUID string) ([]*entity.PromotionEntity, error) {
+	return db.FetchPromotionsByBillUUID(ctx, r.DB, billUUID)
+}
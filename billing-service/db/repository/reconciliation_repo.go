@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// ReconciliationRepo is the PostgreSQL implementation of ReconciliationRepository.
+type ReconciliationRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure ReconciliationRepo implements ReconciliationRepository.
+var _ ReconciliationRepository = (*ReconciliationRepo)(nil)
+
+func (r *ReconciliationRepo) FetchBillTotals(ctx context.Context, afterID int64, closedSince time.Time, limit int) ([]*entity.BillTotalsEntity, error) {
+	return db.FetchBillTotals(ctx, r.DB, afterID, closedSince, limit)
+}
+
+func (r *ReconciliationRepo) RecordFinding(ctx context.Context, finding *entity.ReconciliationFindingEntity) error {
+	return db.RecordReconciliationFinding(ctx, r.DB, finding)
+}
+
+func (r *ReconciliationRepo) ResolveFindings(ctx context.Context, billUUID string, kinds []entity.ReconciliationFindingKind, resolvedAt time.Time) error {
+	return db.ResolveReconciliationFindings(ctx, r.DB, billUUID, kinds, resolvedAt)
+}
+
+func (r *ReconciliationRepo) RepairBillTotal(ctx context.Context, finding *entity.ReconciliationFindingEntity) (bool, error) {
+	return db.RepairBillTotal(ctx, r.DB, finding)
+}
+
+func (r *ReconciliationRepo) FetchFindings(ctx context.Context, params db.ReconciliationFindingQueryParams) ([]*entity.ReconciliationFindingEntity, error) {
+	return db.FetchReconciliationFindings(ctx, r.DB, params)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// SettlementRepo is the PostgreSQL implementation of SettlementRepository.
+type SettlementRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure SettlementRepo implements SettlementRepository.
+var _ SettlementRepository = (*SettlementRepo)(nil)
+
+func (r *SettlementRepo) Insert(ctx context.Context, settlement *entity.SettlementEntity) error {
+	return db.InsertSettlement(ctx, r.DB, settlement)
+}
+
+func (r *SettlementRepo) FetchByBillUUID(ctx context.Context, billUUID string) (*entity.SettlementEntity, error) {
+	return db.FetchSettlementByBillUUID(ctx, r.DB, billUUID)
+}
+
+func (r *SettlementRepo) MarkSettled(ctx context.Context, billUUID, paymentUUID string) error {
+	return db.UpdateSettlement(ctx, r.DB, billUUID, entity.SettlementStatusSettled, &paymentUUID, nil, nil)
+}
+
+func (r *SettlementRepo) MarkCredited(ctx context.Context, billUUID, creditUUID, reason string) error {
+	return db.UpdateSettlement(ctx, r.DB, billUUID, entity.SettlementStatusCredited, nil, &creditUUID, &reason)
+}
+
+func (r *SettlementRepo) MarkFailed(ctx context.Context, billUUID, reason string) error {
+	return db.UpdateSettlement(ctx, r.DB, billUUID, entity.SettlementStatusFailed, nil, nil, &reason)
+}
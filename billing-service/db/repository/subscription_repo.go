@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// SubscriptionRepo is the PostgreSQL implementation of SubscriptionRepository.
+type SubscriptionRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure SubscriptionRepo implements SubscriptionRepository.
+var _ SubscriptionRepository = (*SubscriptionRepo)(nil)
+
+func (r *SubscriptionRepo) Insert(ctx context.Context, subscription *entity.SubscriptionEntity) error {
+	return db.InsertSubscription(ctx, r.DB, subscription)
+}
+
+func (r *SubscriptionRepo) FetchByUUID(ctx context.Context, uuid string) (*entity.SubscriptionEntity, error) {
+	return db.FetchSubscriptionByUUID(ctx, r.DB, uuid)
+}
+
+func (r *SubscriptionRepo) End(ctx context.Context, uuid string, endedAt time.Time) (*entity.SubscriptionEntity, error) {
+	return db.EndSubscription(ctx, r.DB, uuid, endedAt)
+}
+
+func (r *SubscriptionRepo) FetchActive(ctx context.Context, customerUUID string, start, end time.Time) ([]*entity.SubscriptionEntity, error) {
+	return db.FetchActiveSubscriptions(ctx, r.DB, customerUUID, start, end)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// UsageRepo is the PostgreSQL implementation of UsageRepository.
+type UsageRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure UsageRepo implements UsageRepository.
+var _ UsageRepository = (*UsageRepo)(nil)
+
+func (r *UsageRepo) Insert(ctx context.Context, billUUID string, events []*entity.UsageEventEntity) (int, error) {
+	return db.InsertUsageEvents(ctx, r.DB, billUUID, events)
+}
+
+func (r *UsageRepo) SumByMeter(ctx context.Context, billUUID string) (map[string]int64, error) {
+	return db.SumUsageByMeter(ctx, r.DB, billUUID)
+}
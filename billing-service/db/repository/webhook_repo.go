@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"encore.app/db"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// WebhookRepo is the PostgreSQL implementation of WebhookRepository.
+type WebhookRepo struct {
+	DB *sqldb.Database
+}
+
+// Ensure WebhookRepo implements WebhookRepository.
+var _ WebhookRepository = (*WebhookRepo)(nil)
+
+func (r *WebhookRepo) InsertSubscription(ctx context.Context, sub *entity.WebhookSubscriptionEntity) error {
+	return db.InsertWebhookSubscription(ctx, r.DB, sub)
+}
+
+func (r *WebhookRepo) FetchSubscription(ctx context.Context, uuid string) (*entity.WebhookSubscriptionEntity, error) {
+	return db.FetchWebhookSubscription(ctx, r.DB, uuid)
+}
+
+func (r *WebhookRepo) FetchActiveSubscriptions(ctx context.Context, eventType string) ([]*entity.WebhookSubscriptionEntity, error) {
+	return db.FetchActiveWebhookSubscriptions(ctx, r.DB, eventType)
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, uuid string, deletedAt time.Time) error {
+	return db.DeleteWebhookSubscription(ctx, r.DB, uuid, deletedAt)
+}
+
+func (r *WebhookRepo) InsertDelivery(ctx context.Context, delivery *entity.WebhookDeliveryEntity) error {
+	return db.InsertWebhookDelivery(ctx, r.DB, delivery)
+}
+
+func (r *WebhookRepo) FetchDelivery(ctx context.Context, uuid string) (*entity.WebhookDeliveryEntity, error) {
+	return db.FetchWebhookDelivery(ctx, r.DB, uuid)
+}
+
+func (r *WebhookRepo) FetchDeliveries(ctx context.Context, subscriptionUUID string, cursorTime time.Time, cursorID int64, limit int) ([]*entity.WebhookDeliveryEntity, error) {
+	return db.FetchWebhookDeliveries(ctx, r.DB, subscriptionUUID, cursorTime, cursorID, limit)
+}
+
+func (r *WebhookRepo) RecordAttempt(ctx context.Context, attempt *entity.WebhookDeliveryAttemptEntity, delivered bool) error {
+	return db.RecordWebhookDeliveryAttempt(ctx, r.DB, attempt, delivered)
+}
+
+func (r *WebhookRepo) UpdateDeliveryStatus(ctx context.Context, uuid string, from, to entity.WebhookDeliveryStatus) error {
+	return db.UpdateWebhookDeliveryStatus(ctx, r.DB, uuid, from, to)
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// InsertSettlement records the start of a bill's settlement. A bill has one
+// settlement, when it already exists settlement is loaded with the stored row.
+func InsertSettlement(ctx context.Context, db *sqldb.Database, settlement *entity.SettlementEntity) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO settlements
+			(uuid, bill_uuid, source_account, target_account, amount_cents, status)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (bill_uuid) DO NOTHING
+	`, settlement.UUID, settlement.BillUUID, settlement.SourceAccount, settlement.TargetAccount,
+		settlement.AmountCents, entity.SettlementStatusPending)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting settlement",
+			"bill_uuid", settlement.BillUUID,
+			"err", err.Error())
+		return err
+	}
+
+	stored, err := FetchSettlementByBillUUID(ctx, db, settlement.BillUUID)
+	if err != nil {
+		return err
+	}
+	*settlement = *stored
+	return nil
+}
+
+// FetchSettlementByBillUUID returns the settlement of a bill.
+func FetchSettlementByBillUUID(ctx context.Context, db *sqldb.Database, billUUID string) (*entity.SettlementEntity, error) {
+	s := &entity.SettlementEntity{}
+	err := db.QueryRow(ctx, `
+		SELECT
+			id, uuid, bill_uuid, source_account, target_account, amount_cents,
+			status, payment_uuid, credit_uuid, failure_reason, created_at, updated_at
+		FROM settlements
+		WHERE bill_uuid = $1
+	`, billUUID).Scan(&s.ID, &s.UUID, &s.BillUUID, &s.SourceAccount, &s.TargetAccount, &s.AmountCents,
+		&s.Status, &s.PaymentUUID, &s.CreditUUID, &s.FailureReason, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UpdateSettlement moves a pending settlement to SETTLED with its payment, to
+// CREDITED with the customer credit and the reason, or to FAILED with the
+// reason. Settlements that already finished are left alone, so retries are
+// harmless.
+func UpdateSettlement(ctx context.Context, db *sqldb.Database, billUUID string, status entity.SettlementStatus, paymentUUID, creditUUID, failureReason *string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE settlements
+		SET status = $2, payment_uuid = $3, credit_uuid = $4, failure_reason = $5, updated_at = NOW()
+		WHERE bill_uuid = $1 AND status = $6
+	`, billUUID, status, paymentUUID, creditUUID, failureReason, entity.SettlementStatusPending)
+	if err != nil {
+		slog.ErrorContext(ctx, "error updating settlement",
+			"bill_uuid", billUUID,
+			"status", status,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
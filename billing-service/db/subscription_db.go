@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const subscriptionColumns = `
+	id, uuid, customer_uuid, product_uuid, quantity, started_at, ended_at, created_at, updated_at`
+
+// InsertSubscription stores a subscription. A subscription whose UUID already
+// exists is not inserted again; subscription is loaded with the stored row
+// instead, so retried creates return the original.
+func InsertSubscription(ctx context.Context, db *sqldb.Database, subscription *entity.SubscriptionEntity) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO subscriptions (uuid, customer_uuid, product_uuid, quantity, started_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (uuid) DO NOTHING
+	`, subscription.UUID, subscription.CustomerUUID, subscription.ProductUUID, subscription.Quantity, subscription.StartedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting subscription",
+			"uuid", subscription.UUID,
+			"customer_uuid", subscription.CustomerUUID,
+			"err", err.Error())
+		return err
+	}
+
+	stored, err := FetchSubscriptionByUUID(ctx, db, subscription.UUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching subscription",
+			"uuid", subscription.UUID,
+			"err", err.Error())
+		return err
+	}
+
+	*subscription = *stored
+	return nil
+}
+
+// FetchSubscriptionByUUID fetches a subscription, sqldb.ErrNoRows when it does not exist.
+func FetchSubscriptionByUUID(ctx context.Context, db *sqldb.Database, uuid string) (*entity.SubscriptionEntity, error) {
+	return scanSubscription(db.QueryRow(ctx, `
+		SELECT`+subscriptionColumns+`
+		FROM subscriptions
+		WHERE uuid = $1
+	`, uuid))
+}
+
+// EndSubscription sets the end of a subscription that has none yet and returns
+// the updated row. Returns entity.ErrSubscriptionEnded when it already has one.
+func EndSubscription(ctx context.Context, db *sqldb.Database, uuid string, endedAt time.Time) (*entity.SubscriptionEntity, error) {
+	subscription, err := scanSubscription(db.QueryRow(ctx, `
+		UPDATE subscriptions
+		SET ended_at = $2, updated_at = NOW()
+		WHERE uuid = $1 AND ended_at IS NULL
+		RETURNING`+subscriptionColumns+`
+	`, uuid, endedAt))
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, entity.ErrSubscriptionEnded
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error ending subscription",
+			"uuid", uuid,
+			"err", err.Error())
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// FetchActiveSubscriptions returns the customer's subscriptions that overlap
+// the period [start, end), oldest first.
+func FetchActiveSubscriptions(ctx context.Context, db *sqldb.Database, customerUUID string, start, end time.Time) ([]*entity.SubscriptionEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT`+subscriptionColumns+`
+		FROM subscriptions
+		WHERE customer_uuid = $1
+			AND started_at < $3
+			AND (ended_at IS NULL OR ended_at > $2)
+		ORDER BY started_at ASC, id ASC
+	`, customerUUID, start, end)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching active subscriptions", "customer_uuid", customerUUID, "err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*entity.SubscriptionEntity
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "error scanning subscription row", "err", err.Error())
+			return nil, err
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func scanSubscription(row rowScanner) (*entity.SubscriptionEntity, error) {
+	s := &entity.SubscriptionEntity{}
+	err := row.Scan(&s.ID, &s.UUID, &s.CustomerUUID, &s.ProductUUID, &s.Quantity,
+		&s.StartedAt, &s.EndedAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
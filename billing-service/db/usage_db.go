@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+// InsertUsageEvents records a batch of usage events against an open bill in one
+// statement. Events whose dedup key was already used on the bill are skipped;
+// the number of events actually inserted is returned. The bill row is shared
+// locked, so no event lands once the bill started closing and its usage was
+// aggregated. Returns entity.ErrBillNotOpen for bills that are not open.
+func InsertUsageEvents(ctx context.Context, db *sqldb.Database, billUUID string, events []*entity.UsageEventEntity) (int, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM bills WHERE uuid = $1 FOR SHARE
+	`, billUUID).Scan(&status)
+	if err != nil {
+		return 0, err
+	}
+	if entity.BillStatus(status) != entity.BillStatusOpen {
+		return 0, entity.ErrBillNotOpen
+	}
+
+	uuids := make([]string, len(events))
+	meters := make([]string, len(events))
+	quantities := make([]int64, len(events))
+	dedupKeys := make([]string, len(events))
+	occurredAts := make([]time.Time, len(events))
+	for i, event := range events {
+		uuids[i] = event.UUID
+		meters[i] = event.Meter
+		quantities[i] = event.Quantity
+		dedupKeys[i] = event.DedupKey
+		occurredAts[i] = event.OccurredAt
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO usage_events
+			(uuid, bill_uuid, meter, quantity, dedup_key, occurred_at)
+		SELECT e.uuid, $1, e.meter, e.quantity, e.dedup_key, e.occurred_at
+		FROM unnest($2::UUID[], $3::TEXT[], $4::BIGINT[], $5::TEXT[], $6::TIMESTAMPTZ[])
+			AS e(uuid, meter, quantity, dedup_key, occurred_at)
+		ON CONFLICT (bill_uuid, dedup_key) DO NOTHING
+	`, billUUID, uuids, meters, quantities, dedupKeys, occurredAts)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting usage events",
+			"bill_uuid", billUUID,
+			"count", len(events),
+			"err", err.Error())
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "error committing transaction",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// SumUsageByMeter returns the bill's recorded usage quantities keyed by meter.
+func SumUsageByMeter(ctx context.Context, db *sqldb.Database, billUUID string) (map[string]int64, error) {
+	rows, err := db.Query(ctx, `
+		SELECT meter, SUM(quantity)
+		FROM usage_events
+		WHERE bill_uuid = $1
+		GROUP BY meter
+	`, billUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error summing usage by meter",
+			"bill_uuid", billUUID,
+			"err", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	quantities := make(map[string]int64)
+	for rows.Next() {
+		var meter string
+		var quantity int64
+		if err := rows.Scan(&meter, &quantity); err != nil {
+			return nil, err
+		}
+		quantities[meter] = quantity
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quantities, nil
+}
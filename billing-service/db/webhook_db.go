@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+)
+
+const webhookSubscriptionColumns = `id, uuid, url, secret, events, created_at, deleted_at`
+
+const webhookDeliveryColumns = `id, uuid, subscription_uuid, event_uuid, event_type, payload, status,
+	attempts, last_status_code, last_error, delivered_at, created_at, updated_at`
+
+func InsertWebhookSubscription(ctx context.Context, db *sqldb.Database, sub *entity.WebhookSubscriptionEntity) error {
+	err := db.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions
+			(uuid, url, secret, events)
+		VALUES
+			($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, sub.UUID, sub.URL, sub.Secret, sub.Events).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting webhook subscription",
+			"uuid", sub.UUID,
+			"err", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FetchWebhookSubscription fetches a subscription, including deleted ones so
+// their deliveries can still be inspected.
+func FetchWebhookSubscription(ctx context.Context, db *sqldb.Database, uuid string) (*entity.WebhookSubscriptionEntity, error) {
+	sub := &entity.WebhookSubscriptionEntity{}
+	err := db.QueryRow(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+			WHERE uuid = $1
+	`, uuid).Scan(&sub.ID, &sub.UUID, &sub.URL, &sub.Secret, &sub.Events, &sub.CreatedAt, &sub.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// FetchActiveWebhookSubscriptions lists subscriptions that are not deleted,
+// narrowed to those selecting eventType unless it is empty.
+func FetchActiveWebhookSubscriptions(ctx context.Context, db *sqldb.Database, eventType string) ([]*entity.WebhookSubscriptionEntity, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE deleted_at IS NULL
+			AND ($1 = '' OR $1 = ANY(events))
+		ORDER BY created_at ASC, id ASC
+	`, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*entity.WebhookSubscriptionEntity
+	for rows.Next() {
+		sub := &entity.WebhookSubscriptionEntity{}
+		if err := rows.Scan(&sub.ID, &sub.UUID, &sub.URL, &sub.Secret, &sub.Events, &sub.CreatedAt, &sub.DeletedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteWebhookSubscription soft-deletes a subscription. Deleting it again
+// returns sqldb.ErrNoRows.
+func DeleteWebhookSubscription(ctx context.Context, db *sqldb.Database, uuid string, deletedAt time.Time) error {
+	result, err := db.Exec(ctx, `
+		UPDATE webhook_subscriptions
+			SET deleted_at = $2
+		WHERE uuid = $1
+			AND deleted_at IS NULL
+	`, uuid, deletedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error deleting webhook subscription",
+			"uuid", uuid,
+			"err", err.Error())
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sqldb.ErrNoRows
+	}
+	return nil
+}
+
+// InsertWebhookDelivery records the delivery of an event to a subscription.
+// The delivery UUID is derived from both, so inserting it again loads the
+// stored row into delivery instead.
+func InsertWebhookDelivery(ctx context.Context, db *sqldb.Database, delivery *entity.WebhookDeliveryEntity) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO webhook_deliveries
+			(uuid, subscription_uuid, event_uuid, event_type, payload)
+		VALUES
+			($1, $2, $3, $4, $5)
+		ON CONFLICT (uuid) DO NOTHING
+	`, delivery.UUID, delivery.SubscriptionUUID, delivery.EventUUID, delivery.EventType, delivery.Payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting webhook delivery",
+			"uuid", delivery.UUID,
+			"err", err.Error())
+		return err
+	}
+
+	stored, err := FetchWebhookDelivery(ctx, db, delivery.UUID)
+	if err != nil {
+		return err
+	}
+	*delivery = *stored
+	return nil
+}
+
+func FetchWebhookDelivery(ctx context.Context, db *sqldb.Database, uuid string) (*entity.WebhookDeliveryEntity, error) {
+	return scanWebhookDelivery(db.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+			WHERE uuid = $1
+	`, uuid))
+}
+
+// FetchWebhookDeliveries lists a subscription's deliveries, newest first, with
+// the same (created_at, id) cursor as the other list endpoints.
+func FetchWebhookDeliveries(ctx context.Context, db *sqldb.Database, subscriptionUUID string, cursorTime time.Time, cursorID int64, limit int) ([]*entity.WebhookDeliveryEntity, error) {
+	var query string
+	var args []any
+
+	if cursorID > 0 {
+		query = `
+			SELECT ` + webhookDeliveryColumns + `
+			FROM webhook_deliveries
+			WHERE subscription_uuid = $1
+				AND (created_at, id) < ($2, $3)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $4
+		`
+		args = []any{subscriptionUUID, cursorTime, cursorID, limit}
+	} else {
+		// first page
+		query = `
+			SELECT ` + webhookDeliveryColumns + `
+			FROM webhook_deliveries
+			WHERE subscription_uuid = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		`
+		args = []any{subscriptionUUID, limit}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.WebhookDeliveryEntity
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordWebhookDeliveryAttempt appends an attempt to the delivery log and
+// updates the delivery with its outcome. A successful attempt marks the
+// delivery DELIVERED.
+func RecordWebhookDeliveryAttempt(ctx context.Context, db *sqldb.Database, attempt *entity.WebhookDeliveryAttemptEntity, delivered bool) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error beginning transaction",
+			"delivery_uuid", attempt.DeliveryUUID,
+			"err", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts
+			(delivery_uuid, status_code, error, attempted_at)
+		VALUES
+			($1, $2, $3, $4)
+	`, attempt.DeliveryUUID, attempt.StatusCode, attempt.Error, attempt.AttemptedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error inserting webhook delivery attempt",
+			"delivery_uuid", attempt.DeliveryUUID,
+			"err", err.Error())
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+				last_status_code = $2,
+				last_error = $3,
+				status = CASE WHEN $4 THEN 'DELIVERED' ELSE status END,
+				delivered_at = CASE WHEN $4 THEN $5 ELSE delivered_at END,
+				updated_at = NOW()
+		WHERE uuid = $1
+	`, attempt.DeliveryUUID, attempt.StatusCode, attempt.Error, delivered, attempt.AttemptedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "error updating webhook delivery",
+			"delivery_uuid", attempt.DeliveryUUID,
+			"err", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateWebhookDeliveryStatus moves a delivery between statuses. It returns
+// sqldb.ErrNoRows when the delivery is not in the from status.
+func UpdateWebhookDeliveryStatus(ctx context.Context, db *sqldb.Database, uuid string, from, to entity.WebhookDeliveryStatus) error {
+	result, err := db.Exec(ctx, `
+		UPDATE webhook_deliveries
+			SET status = $3, updated_at = NOW()
+		WHERE uuid = $1
+			AND status = $2
+	`, uuid, from, to)
+	if err != nil {
+		slog.ErrorContext(ctx, "error updating webhook delivery status",
+			"uuid", uuid,
+			"err", err.Error())
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sqldb.ErrNoRows
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhookDelivery(row rowScanner) (*entity.WebhookDeliveryEntity, error) {
+	d := &entity.WebhookDeliveryEntity{}
+	err := row.Scan(&d.ID, &d.UUID, &d.SubscriptionUUID, &d.EventUUID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -1,19 +1,32 @@
 package dto
 
 type CreateBillRequest struct {
-	UUID         string `json:"uuid"`
-	CustomerUUID string `json:"customerUuid"`
-	Currency     string `json:"currency"`
-	PeriodStart  string `json:"periodStart"`
-	PeriodEnd    string `json:"periodEnd"`
+	UUID         string          `json:"uuid"`
+	CustomerUUID string          `json:"customerUuid"`
+	Currency     string          `json:"currency,omitempty"` // defaults to the customer's currency
+	PeriodStart  string          `json:"periodStart"`
+	PeriodEnd    string          `json:"periodEnd"`
+	Recurrence   *BillRecurrence `json:"recurrence,omitempty"` // opt-in, nil for one-off bills
+
+	// SettlementAccount is the customer's bank account the closed bill is
+	// collected from by transfer, empty leaves paying to the customer
+	SettlementAccount string `json:"settlementAccount,omitempty"`
+}
+
+// BillRecurrence opts a bill into recurring periods: when the period ends the
+// bill is closed and the next one is opened automatically.
+type BillRecurrence struct {
+	Cadence      string `json:"cadence"`                // "MONTHLY", "WEEKLY" or "CUSTOM"
+	IntervalDays int    `json:"intervalDays,omitempty"` // required for "CUSTOM"
 }
 
 type CreateBillResponse struct {
-	UUID        string `json:"uuid"`
-	Status      string `json:"status"`
-	Currency    string `json:"currency"`
-	PeriodStart string `json:"periodStart"`
-	PeriodEnd   string `json:"periodEnd"`
+	UUID        string          `json:"uuid"`
+	Status      string          `json:"status"`
+	Currency    string          `json:"currency"`
+	PeriodStart string          `json:"periodStart"`
+	PeriodEnd   string          `json:"periodEnd"`
+	Recurrence  *BillRecurrence `json:"recurrence,omitempty"`
 }
 
 type GetBillRequest struct {
@@ -31,6 +44,39 @@ type GetBillResponse struct {
 	ClosedAt     string `json:"closedAt,omitempty"`
 	CreatedAt    string `json:"createdAt"`
 	UpdatedAt    string `json:"updatedAt"`
+
+	// DueDate is set once the bill closes with payment terms; the balance is
+	// the total minus the payments recorded against it
+	DueDate      string `json:"dueDate,omitempty"`
+	PaidCents    int64  `json:"paidCents"`
+	BalanceCents int64  `json:"balanceCents"`
+
+	// InFlight is the live workflow state of an open bill, omitted for closed
+	// bills or when the workflow could not be queried
+	InFlight *BillInFlight `json:"inFlight,omitempty"`
+}
+
+// BillInFlight is the bill workflow state, which runs ahead of the database row
+type BillInFlight struct {
+	Status           string             `json:"status"`
+	Total            Money              `json:"total"`
+	ItemCount        int                `json:"itemCount"`
+	FailedItemCount  int                `json:"failedItemCount"`
+	PendingLineItems []InFlightLineItem `json:"pendingLineItems"` // received, not yet persisted
+	RecentLineItems  []InFlightLineItem `json:"recentLineItems"`  // last persisted, newest last
+	ScheduledCloseAt string             `json:"scheduledCloseAt"`
+	WorkflowID       string             `json:"workflowId"`
+	RunID            string             `json:"runId"`
+}
+
+type InFlightLineItem struct {
+	UUID           string `json:"uuid"`
+	IdempotencyKey string `json:"idempotencyKey"`
+	FeeType        string `json:"feeType"`
+	Description    string `json:"description,omitempty"`
+	Amount         Money  `json:"amount"`
+	ReferenceUUID  string `json:"referenceUuid,omitempty"`
+	ProcessedAt    string `json:"processedAt,omitempty"`
 }
 
 // CloseBillRequest for POST /v1/bill/close
@@ -45,10 +91,35 @@ type CloseBillResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// VoidBillRequest for POST /v1/bill/void
+type VoidBillRequest struct {
+	UUID   string `json:"uuid"`
+	Reason string `json:"reason"`
+}
+
+// VoidBillResponse is returned once the bill is stored as VOIDED
+type VoidBillResponse struct {
+	UUID    string `json:"uuid"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// GetInvoiceRequest for GET /v1/bill/invoice, Format defaults to json
+type GetInvoiceRequest struct {
+	UUID   string `query:"uuid"`
+	Format string `query:"format"`
+}
+
+type GetInvoiceResponse struct {
+	InvoiceNumber string
+	ContentType   string
+	Content       []byte
+}
+
 // ListBillsRequest for POST /v1/bill/list
 type ListBillsRequest struct {
 	CustomerUUID string    `json:"customerUuid,omitempty"`
-	Status       string    `json:"status,omitempty"` // "OPEN" or "CLOSED"
+	Status       string    `json:"status,omitempty"` // "OPEN", "CLOSING", "CLOSED", "FINALIZED", "PARTIALLY_PAID", "OVERDUE", "PAID" or "VOIDED"
 	Cursor       string    `json:"cursor,omitempty"`
 	Limit        int       `json:"limit,omitempty"`     // default 20, max 20
 	SortOrder    SortOrder `json:"sortOrder,omitempty"` // "asc" or "desc", default "desc"
@@ -0,0 +1,60 @@
+package dto
+
+// CreateProductRequest for POST /v1/catalog/product/create
+type CreateProductRequest struct {
+	Code string `json:"code"`
+	Name string `json:"name"` // shown on the subscription fee line items
+}
+
+// CreateProductResponse for POST /v1/catalog/product/create
+type CreateProductResponse struct {
+	UUID      string `json:"uuid"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// CreatePriceRequest for POST /v1/catalog/price/create. Prices are per unit and
+// bill period; a new price replaces the previous one in the same currency for
+// the periods starting from EffectiveFrom.
+type CreatePriceRequest struct {
+	ProductCode   string `json:"productCode"`
+	Amount        Money  `json:"amount"`
+	EffectiveFrom string `json:"effectiveFrom,omitempty"` // RFC3339, defaults to now
+}
+
+// CreatePriceResponse for POST /v1/catalog/price/create
+type CreatePriceResponse struct {
+	UUID          string `json:"uuid"`
+	ProductCode   string `json:"productCode"`
+	Amount        Money  `json:"amount"`
+	EffectiveFrom string `json:"effectiveFrom"`
+}
+
+// CreateSubscriptionRequest for POST /v1/subscription/create. The UUID is
+// chosen by the caller, so a create can be retried safely.
+type CreateSubscriptionRequest struct {
+	UUID         string `json:"uuid"`
+	CustomerUUID string `json:"customerUuid"`
+	ProductCode  string `json:"productCode"`
+	Quantity     int64  `json:"quantity"`
+	StartsAt     string `json:"startsAt,omitempty"` // RFC3339, defaults to now
+}
+
+// CancelSubscriptionRequest for POST /v1/subscription/cancel
+type CancelSubscriptionRequest struct {
+	SubscriptionUUID string `json:"subscriptionUuid"`
+	EndsAt           string `json:"endsAt,omitempty"` // RFC3339, defaults to now
+}
+
+// SubscriptionResponse for the subscription endpoints. The subscription is
+// billed as a MONTHLY_FEE line item on every bill whose period it overlaps,
+// prorated when it starts or ends within the period.
+type SubscriptionResponse struct {
+	UUID         string `json:"uuid"`
+	CustomerUUID string `json:"customerUuid"`
+	ProductUUID  string `json:"productUuid"`
+	Quantity     int64  `json:"quantity"`
+	StartedAt    string `json:"startedAt"`
+	EndedAt      string `json:"endedAt,omitempty"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+// CreateCreditNoteRequest for POST /v1/bill/credit-note
+type CreateCreditNoteRequest struct {
+	BillUUID       string                  `json:"billUuid"`
+	IdempotencyKey string                  `json:"idempotencyKey"`
+	Lines          []CreditNoteLineRequest `json:"lines"`
+	Reason         string                  `json:"reason,omitempty"`
+}
+
+// CreditNoteLineRequest credits a line item of the bill. Without an amount the
+// line item is credited with all that earlier credit notes left of it.
+type CreditNoteLineRequest struct {
+	LineItemUUID string `json:"lineItemUuid"`
+	AmountCents  int64  `json:"amountCents,omitempty"`
+}
+
+// CreateCreditNoteResponse for POST /v1/bill/credit-note. The note is applied
+// as customer credit in the background; the customer's next bill in the
+// currency consumes it when it closes.
+type CreateCreditNoteResponse struct {
+	UUID         string                   `json:"uuid"`
+	Number       string                   `json:"number"`
+	BillUUID     string                   `json:"billUuid"`
+	CustomerUUID string                   `json:"customerUuid"`
+	Amount       Money                    `json:"amount"`
+	Lines        []CreditNoteLineResponse `json:"lines"`
+	Reason       string                   `json:"reason,omitempty"`
+	Status       string                   `json:"status"` // "ISSUED" or "APPLIED"
+	IssuedAt     string                   `json:"issuedAt"`
+}
+
+// CreditNoteLineResponse is the amount a credit note credits of a line item.
+type CreditNoteLineResponse struct {
+	LineItemUUID string `json:"lineItemUuid"`
+	Amount       Money  `json:"amount"`
+}
@@ -3,23 +3,127 @@ package dto
 import "time"
 
 type CreateCustomerRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Currency string `json:"currency,omitempty"` // default currency of the customer's bills
 }
 
 type CreateCustomerResponse struct {
+	UUID     string `json:"uuid"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Currency string `json:"currency,omitempty"`
+}
+
+type GetCustomerRequest struct {
+	UUID string `json:"uuid"`
+}
+
+type GetCustomerResponse struct {
+	UUID           string            `json:"uuid"`
+	Name           string            `json:"name"`
+	Email          string            `json:"email"`
+	Currency       string            `json:"currency,omitempty"`
+	Status         string            `json:"status"` // "ACTIVE", "ARCHIVED" or "MERGED"
+	MergedIntoUUID string            `json:"mergedIntoUuid,omitempty"`
+	Balances       []CustomerBalance `json:"balances"`
+	CreatedAt      time.Time         `json:"createdAt"`
+}
+
+// CustomerBalance is derived from the customer's ledger accounts in one currency
+type CustomerBalance struct {
+	Receivable Money `json:"receivable"` // still owed on their bills
+	Credit     Money `json:"credit"`     // credit left for their next bills
+}
+
+// UpdateCustomerRequest for POST /v1/customer/update
+type UpdateCustomerRequest struct {
 	UUID  string `json:"uuid"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
 
-type GetCustomerRequest struct {
+// ListCustomersRequest for POST /v1/customer/list. Name and Email search by
+// case-insensitive prefix.
+type ListCustomersRequest struct {
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Status string `json:"status,omitempty"` // "ACTIVE", "ARCHIVED" or "MERGED"
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"` // default 20, max 20
+}
+
+// CustomerSummary for customer responses
+type CustomerSummary struct {
+	UUID           string     `json:"uuid"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Currency       string     `json:"currency,omitempty"`
+	Status         string     `json:"status"` // "ACTIVE", "ARCHIVED" or "MERGED"
+	ArchivedAt     *time.Time `json:"archivedAt,omitempty"`
+	MergedIntoUUID string     `json:"mergedIntoUuid,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+type ListCustomersResponse struct {
+	Data       []CustomerSummary  `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// ArchiveCustomerRequest for POST /v1/customer/archive
+type ArchiveCustomerRequest struct {
 	UUID string `json:"uuid"`
 }
 
-type GetCustomerResponse struct {
-	UUID      string    `json:"uuid"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"createdAt"`
+// MergeCustomersRequest for POST /v1/customer/merge, merges the source
+// customer into the target
+type MergeCustomersRequest struct {
+	SourceUUID string `json:"sourceUuid"`
+	TargetUUID string `json:"targetUuid"`
+}
+
+// MergeCustomersResponse counts what moved from the source to the target
+type MergeCustomersResponse struct {
+	SourceUUID    string `json:"sourceUuid"`
+	TargetUUID    string `json:"targetUuid"`
+	Bills         int64  `json:"bills"`
+	Credits       int64  `json:"credits"`
+	Subscriptions int64  `json:"subscriptions"`
+}
+
+// BillingAddress is the postal address printed on a customer's invoices
+type BillingAddress struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Country    string `json:"country"` // ISO 3166-1 alpha-2
+}
+
+// UpdateBillingProfileRequest for POST /v1/customer/billing-profile/update,
+// replaces the customer's billing profile
+type UpdateBillingProfileRequest struct {
+	CustomerUUID    string         `json:"customerUuid"`
+	Address         BillingAddress `json:"address"`
+	TaxID           string         `json:"taxId,omitempty"`           // validated against the address country
+	Currency        string         `json:"currency,omitempty"`        // default currency of the customer's bills, kept when omitted
+	PaymentTerms    string         `json:"paymentTerms,omitempty"`    // "NET15" or "NET30", default the service's terms
+	InvoiceDelivery string         `json:"invoiceDelivery,omitempty"` // "EMAIL" or "PORTAL", default "EMAIL"
+}
+
+// GetBillingProfileRequest for POST /v1/customer/billing-profile/get
+type GetBillingProfileRequest struct {
+	CustomerUUID string `json:"customerUuid"`
+}
+
+type BillingProfileResponse struct {
+	CustomerUUID    string         `json:"customerUuid"`
+	Address         BillingAddress `json:"address"`
+	TaxID           string         `json:"taxId,omitempty"`
+	Currency        string         `json:"currency,omitempty"`
+	PaymentTerms    string         `json:"paymentTerms,omitempty"`
+	InvoiceDelivery string         `json:"invoiceDelivery"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
 }
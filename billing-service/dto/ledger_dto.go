@@ -0,0 +1,30 @@
+package dto
+
+// GetTrialBalanceRequest for POST /v1/admin/ledger/trial-balance
+type GetTrialBalanceRequest struct {
+	Currency string `json:"currency,omitempty"` // all currencies when empty
+}
+
+// TrialBalanceAccount totals one account type's entries, per-customer
+// receivable and credit accounts added up across customers
+type TrialBalanceAccount struct {
+	AccountType string `json:"accountType"`
+	DebitCents  int64  `json:"debitCents"`
+	CreditCents int64  `json:"creditCents"`
+	// BalanceCents is debits minus credits
+	BalanceCents int64 `json:"balanceCents"`
+}
+
+// TrialBalanceCurrency balances when its debits equal its credits
+type TrialBalanceCurrency struct {
+	Currency    string                `json:"currency"`
+	Accounts    []TrialBalanceAccount `json:"accounts"`
+	DebitCents  int64                 `json:"debitCents"`
+	CreditCents int64                 `json:"creditCents"`
+	Balanced    bool                  `json:"balanced"`
+}
+
+type GetTrialBalanceResponse struct {
+	Currencies []TrialBalanceCurrency `json:"currencies"`
+	Balanced   bool                   `json:"balanced"`
+}
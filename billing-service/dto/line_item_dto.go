@@ -6,14 +6,22 @@ type AddLineItemRequest struct {
 	FeeType        string `json:"feeType"`
 	Description    string `json:"description"`
 	Amount         Money  `json:"amount"`
+
+	// ConvertCurrency converts an amount in another currency into the bill
+	// currency at the current FX rate instead of rejecting it
+	ConvertCurrency bool `json:"convertCurrency,omitempty"`
 }
 
 type AddLineItemResponse struct {
 	UUID      string `json:"uuid"`
 	FeeType   string `json:"feeType"`
 	Amount    Money  `json:"amount"`
-	Status    string `json:"status"` // "pending" or "persisted"
+	Status    string `json:"status"` // "persisted"
 	CreatedAt string `json:"createdAt"`
+
+	// Original and FXRate are set when the amount was converted
+	Original *Money `json:"original,omitempty"`
+	FXRate   string `json:"fxRate,omitempty"`
 }
 
 // ListLineItemsRequest for POST /v1/bill/list-line-items
@@ -31,6 +39,14 @@ type LineItemSummary struct {
 	Amount        Money  `json:"amount"`
 	ReferenceUUID string `json:"referenceUuid,omitempty"`
 	CreatedAt     string `json:"createdAt"`
+
+	// Original and FXRate are set when the amount was converted
+	Original *Money `json:"original,omitempty"`
+	FXRate   string `json:"fxRate,omitempty"`
+
+	// RemainingReversible is how much of the amount reversals can still take
+	// back, without sign. Not set on reversals.
+	RemainingReversible *Money `json:"remainingReversible,omitempty"`
 }
 
 // ListLineItemsResponse for POST /v1/bill/list-line-items
@@ -45,6 +61,10 @@ type ReverseLineItemRequest struct {
 	LineItemUUID   string `json:"lineItemUuid"`
 	IdempotencyKey string `json:"idempotencyKey"`
 	Reason         string `json:"reason,omitempty"`
+
+	// AmountCents reverses part of the line item, without sign. Omitted, all
+	// that earlier reversals left of it is reversed.
+	AmountCents int64 `json:"amountCents,omitempty"`
 }
 
 // ReverseLineItemResponse for POST /v1/bill/reverse-line-item
@@ -53,5 +73,60 @@ type ReverseLineItemResponse struct {
 	FeeType       string `json:"feeType"` // "REVERSAL"
 	ReferenceUUID string `json:"referenceUuid"`
 	Amount        Money  `json:"amount"` // negative
+	Status        string `json:"status"` // "persisted"
 	CreatedAt     string `json:"createdAt"`
 }
+
+// ListFailedLineItemsRequest for POST /v1/bill/failed-line-items
+type ListFailedLineItemsRequest struct {
+	BillUUID string `json:"billUuid"`
+}
+
+// FailedLineItemSummary is a line item whose insert failed after all retries
+type FailedLineItemSummary struct {
+	UUID           string `json:"uuid"`
+	IdempotencyKey string `json:"idempotencyKey"`
+	FeeType        string `json:"feeType"`
+	Description    string `json:"description,omitempty"`
+	Amount         Money  `json:"amount"`
+	ReferenceUUID  string `json:"referenceUuid,omitempty"`
+	Error          string `json:"error"`
+	Attempts       int    `json:"attempts"`
+	FailedAt       string `json:"failedAt"`
+}
+
+// ListFailedLineItemsResponse for POST /v1/bill/failed-line-items
+type ListFailedLineItemsResponse struct {
+	Data []FailedLineItemSummary `json:"data"`
+}
+
+// RetryLineItemRequest for POST /v1/bill/retry-line-item
+type RetryLineItemRequest struct {
+	BillUUID     string `json:"billUuid"`
+	LineItemUUID string `json:"lineItemUuid"`
+}
+
+// RetryLineItemResponse for POST /v1/bill/retry-line-item
+type RetryLineItemResponse struct {
+	UUID      string `json:"uuid"`
+	FeeType   string `json:"feeType"`
+	Amount    Money  `json:"amount"`
+	Status    string `json:"status"` // "persisted"
+	CreatedAt string `json:"createdAt"`
+}
+
+// DiscardLineItemRequest for POST /v1/bill/discard-line-item
+type DiscardLineItemRequest struct {
+	BillUUID     string `json:"billUuid"`
+	LineItemUUID string `json:"lineItemUuid"`
+	Reason       string `json:"reason"`
+}
+
+// DiscardLineItemResponse for POST /v1/bill/discard-line-item
+type DiscardLineItemResponse struct {
+	UUID    string `json:"uuid"`
+	FeeType string `json:"feeType"`
+	Amount  Money  `json:"amount"`
+	Error   string `json:"error"`  // the last error of the insert
+	Status  string `json:"status"` // "discarded"
+}
@@ -0,0 +1,59 @@
+package dto
+
+// RecordPaymentRequest for POST /v1/bill/record-payment
+type RecordPaymentRequest struct {
+	BillUUID       string `json:"billUuid"`
+	IdempotencyKey string `json:"idempotencyKey"`
+	Amount         Money  `json:"amount"`
+	Reference      string `json:"reference,omitempty"`  // bank or processor reference
+	ReceivedAt     string `json:"receivedAt,omitempty"` // RFC3339, defaults to now
+}
+
+// RecordPaymentResponse for POST /v1/bill/record-payment. The bill status follows
+// asynchronously, poll GET /v1/bill/get for PARTIALLY_PAID or PAID.
+type RecordPaymentResponse struct {
+	Payment PaymentSummary `json:"payment"`
+	Paid    Money          `json:"paid"`
+	Balance Money          `json:"balance"`
+}
+
+// PaymentSummary for payment responses
+type PaymentSummary struct {
+	UUID           string `json:"uuid"`
+	BillUUID       string `json:"billUuid"`
+	IdempotencyKey string `json:"idempotencyKey"`
+	Amount         Money  `json:"amount"`
+	Reference      string `json:"reference,omitempty"`
+	ReceivedAt     string `json:"receivedAt"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ListPaymentsRequest for POST /v1/bill/list-payments
+type ListPaymentsRequest struct {
+	BillUUID string `json:"billUuid"`
+}
+
+// ListPaymentsResponse for POST /v1/bill/list-payments
+type ListPaymentsResponse struct {
+	Data    []PaymentSummary `json:"data"`
+	Status  string           `json:"status"`
+	DueDate string           `json:"dueDate,omitempty"`
+	Total   Money            `json:"total"`
+	Paid    Money            `json:"paid"`
+	Balance Money            `json:"balance"`
+
+	// Settlement is the bank transfer collecting the bill, nil when it is not settled by transfer
+	Settlement *SettlementSummary `json:"settlement,omitempty"`
+}
+
+// SettlementSummary for payment responses
+type SettlementSummary struct {
+	UUID          string `json:"uuid"`
+	Status        string `json:"status"` // PENDING, SETTLED, CREDITED or FAILED
+	SourceAccount string `json:"sourceAccount"`
+	TargetAccount string `json:"targetAccount"`
+	Amount        Money  `json:"amount"`
+	PaymentUUID   string `json:"paymentUuid,omitempty"`
+	CreditUUID    string `json:"creditUuid,omitempty"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+// CreatePromotionRequest for POST /v1/promotion/create. Exactly one of
+// PercentOffBps and AmountOff is set.
+type CreatePromotionRequest struct {
+	Code           string `json:"code"`
+	PercentOffBps  int64  `json:"percentOffBps,omitempty"` // 1000 is 10% off
+	AmountOff      *Money `json:"amountOff,omitempty"`
+	MaxRedemptions int    `json:"maxRedemptions,omitempty"` // unlimited when zero
+	ExpiresAt      string `json:"expiresAt,omitempty"`      // RFC3339, never expires when empty
+}
+
+// CreatePromotionResponse for POST /v1/promotion/create
+type CreatePromotionResponse struct {
+	Promotion PromotionSummary `json:"promotion"`
+}
+
+// PromotionSummary for promotion responses
+type PromotionSummary struct {
+	UUID           string `json:"uuid"`
+	Code           string `json:"code"`
+	Kind           string `json:"kind"` // PERCENT or FIXED
+	PercentOffBps  int64  `json:"percentOffBps,omitempty"`
+	AmountOff      *Money `json:"amountOff,omitempty"`
+	MaxRedemptions int    `json:"maxRedemptions,omitempty"`
+	Redemptions    int    `json:"redemptions"`
+	ExpiresAt      string `json:"expiresAt,omitempty"`
+}
+
+// ApplyPromoCodeRequest for POST /v1/bill/apply-promo
+type ApplyPromoCodeRequest struct {
+	BillUUID string `json:"billUuid"`
+	Code     string `json:"code"`
+}
+
+// ApplyPromoCodeResponse for POST /v1/bill/apply-promo. The discount is added
+// as a DISCOUNT line item when the bill closes.
+type ApplyPromoCodeResponse struct {
+	BillUUID  string           `json:"billUuid"`
+	Promotion PromotionSummary `json:"promotion"`
+}
+
+// GrantCreditRequest for POST /v1/customer/grant-credit
+type GrantCreditRequest struct {
+	CustomerUUID   string `json:"customerUuid"`
+	IdempotencyKey string `json:"idempotencyKey"`
+	Amount         Money  `json:"amount"`
+	Description    string `json:"description,omitempty"`
+}
+
+// GrantCreditResponse for POST /v1/customer/grant-credit. Bills in the credit's
+// currency consume the balance as CREDIT line items when they close.
+type GrantCreditResponse struct {
+	UUID         string `json:"uuid"`
+	CustomerUUID string `json:"customerUuid"`
+	Amount       Money  `json:"amount"`
+	Remaining    Money  `json:"remaining"`
+	Description  string `json:"description,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+	Balance      Money  `json:"balance"` // all credit the customer has left in the currency
+}
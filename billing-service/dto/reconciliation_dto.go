@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// ListReconciliationFindingsRequest for POST /v1/admin/reconciliation
+type ListReconciliationFindingsRequest struct {
+	BillUUID string `json:"billUuid,omitempty"`
+	Status   string `json:"status,omitempty"` // "OPEN", "RESOLVED" or "REPAIRED"
+	Cursor   string `json:"cursor,omitempty"`
+	Limit    int    `json:"limit,omitempty"` // default 20, max 20
+}
+
+// ReconciliationFindingSummary is a discrepancy between a bill's totals. The
+// ledger total is what the other totals are checked against.
+type ReconciliationFindingSummary struct {
+	UUID               string     `json:"uuid"`
+	BillUUID           string     `json:"billUuid"`
+	Kind               string     `json:"kind"`   // "BILL_TOTAL_MISMATCH", "LEDGER_MISMATCH", "WORKFLOW_TOTAL_MISMATCH" or "WORKFLOW_NOT_FOUND"
+	Status             string     `json:"status"` // "OPEN", "RESOLVED" or "REPAIRED"
+	BillStatus         string     `json:"billStatus"`
+	BillTotalCents     int64      `json:"billTotalCents"`
+	LineItemsCents     int64      `json:"lineItemsCents"`
+	LedgerCents        *int64     `json:"ledgerCents,omitempty"`
+	WorkflowTotalCents *int64     `json:"workflowTotalCents,omitempty"`
+	DetectedAt         time.Time  `json:"detectedAt"`
+	LastSeenAt         time.Time  `json:"lastSeenAt"`
+	ResolvedAt         *time.Time `json:"resolvedAt,omitempty"`
+}
+
+type ListReconciliationFindingsResponse struct {
+	Data       []ReconciliationFindingSummary `json:"data"`
+	Pagination PaginationResponse             `json:"pagination"`
+}
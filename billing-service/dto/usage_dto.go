@@ -0,0 +1,24 @@
+package dto
+
+// RecordUsageRequest for POST /v1/usage/record. Events are deduplicated per bill
+// by their dedup key, so a batch can be resent safely.
+type RecordUsageRequest struct {
+	BillUUID string       `json:"billUuid"`
+	Events   []UsageEvent `json:"events"`
+}
+
+// UsageEvent is a metered quantity, e.g. 1 API call or 20 GB stored.
+type UsageEvent struct {
+	DedupKey   string `json:"dedupKey"`
+	Meter      string `json:"meter"`
+	Quantity   int64  `json:"quantity"`
+	OccurredAt string `json:"occurredAt,omitempty"` // RFC3339, defaults to now
+}
+
+// RecordUsageResponse for POST /v1/usage/record. Usage is priced and added as
+// one USAGE line item per meter when the bill closes.
+type RecordUsageResponse struct {
+	BillUUID   string `json:"billUuid"`
+	Accepted   int    `json:"accepted"`   // events recorded by this request
+	Duplicates int    `json:"duplicates"` // events whose dedup key was already recorded
+}
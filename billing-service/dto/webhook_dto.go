@@ -0,0 +1,75 @@
+package dto
+
+import "time"
+
+// SubscribeWebhookRequest for POST /v1/webhook/subscribe
+type SubscribeWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // e.g. "bill.created", "line_item.persisted"
+}
+
+// SubscribeWebhookResponse returns the signing secret, it is not shown again
+type SubscribeWebhookResponse struct {
+	UUID      string    `json:"uuid"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WebhookSubscriptionSummary struct {
+	UUID      string    `json:"uuid"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListWebhooksResponse for POST /v1/webhook/list
+type ListWebhooksResponse struct {
+	Data []WebhookSubscriptionSummary `json:"data"`
+}
+
+// DeleteWebhookRequest for POST /v1/webhook/delete
+type DeleteWebhookRequest struct {
+	UUID string `json:"uuid"`
+}
+
+type DeleteWebhookResponse struct {
+	UUID      string    `json:"uuid"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// ListWebhookDeliveriesRequest for POST /v1/webhook/deliveries
+type ListWebhookDeliveriesRequest struct {
+	SubscriptionUUID string `json:"subscriptionUuid"`
+	Cursor           string `json:"cursor,omitempty"`
+	Limit            int    `json:"limit,omitempty"` // default 20, max 20
+}
+
+type WebhookDeliverySummary struct {
+	UUID           string     `json:"uuid"`
+	EventUUID      string     `json:"eventUuid"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"` // "PENDING", "DELIVERED" or "FAILED"
+	Attempts       int        `json:"attempts"`
+	LastStatusCode *int       `json:"lastStatusCode,omitempty"`
+	LastError      *string    `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Data       []WebhookDeliverySummary `json:"data"`
+	Pagination PaginationResponse       `json:"pagination"`
+}
+
+// ReplayWebhookDeliveryRequest for POST /v1/webhook/replay
+type ReplayWebhookDeliveryRequest struct {
+	DeliveryUUID string `json:"deliveryUuid"`
+}
+
+// ReplayWebhookDeliveryResponse - async response, poll /v1/webhook/deliveries for the outcome
+type ReplayWebhookDeliveryResponse struct {
+	DeliveryUUID string `json:"deliveryUuid"`
+	Status       string `json:"status"`
+}
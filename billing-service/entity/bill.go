@@ -13,10 +13,45 @@ type BillEntity struct {
 	ClosedAt     *time.Time
 	TotalCents   *int64
 
+	// DueDate is set when the bill closes, nil without payment terms
+	DueDate *time.Time
+
+	// PaidCents is the sum of the payments recorded against the bill
+	PaidCents int64
+
+	// Recurrence is the schedule of the series the bill belongs to, nil for
+	// one-off bills
+	Recurrence *BillRecurrence
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
 func (b *BillEntity) IsOpen() bool {
-	return b.Status == "OPEN"
+	return b.Status == BillStatusOpen.String()
+}
+
+// BalanceCents is the amount left to pay, derived from the total and the payments.
+func (b *BillEntity) BalanceCents() int64 {
+	if b.TotalCents == nil {
+		return 0
+	}
+	return *b.TotalCents - b.PaidCents
+}
+
+// HasRunningWorkflow reports whether the bill workflow is still running, which
+// is the case until the close completes.
+func (b *BillEntity) HasRunningWorkflow() bool {
+	return b.Status == BillStatusOpen.String() || b.Status == BillStatusClosing.String()
+}
+
+// BillStatusHistoryEntity is one recorded bill status transition.
+// FromStatus is empty for the row written when the bill is created.
+type BillStatusHistoryEntity struct {
+	ID         int64 `json:"-"`
+	BillUUID   string
+	FromStatus string
+	ToStatus   string
+	Reason     *string
+	CreatedAt  time.Time
 }
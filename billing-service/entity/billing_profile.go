@@ -0,0 +1,75 @@
+package entity
+
+import "time"
+
+// PaymentTerms is how long after close a customer's bills are due.
+type PaymentTerms string
+
+const (
+	PaymentTermsNet15 PaymentTerms = "NET15"
+	PaymentTermsNet30 PaymentTerms = "NET30"
+)
+
+func (t PaymentTerms) String() string {
+	return string(t)
+}
+
+func (t PaymentTerms) IsValid() bool {
+	return t.Days() > 0
+}
+
+// Days is the time between close and the due date, 0 for unknown terms
+func (t PaymentTerms) Days() int {
+	switch t {
+	case PaymentTermsNet15:
+		return 15
+	case PaymentTermsNet30:
+		return 30
+	}
+	return 0
+}
+
+// InvoiceDelivery is how a customer prefers to receive their invoices.
+type InvoiceDelivery string
+
+const (
+	// InvoiceDeliveryEmail - the invoice is announced with the bill closed notification
+	InvoiceDeliveryEmail InvoiceDelivery = "EMAIL"
+
+	// InvoiceDeliveryPortal - no bill closed notification, the customer fetches the invoice
+	InvoiceDeliveryPortal InvoiceDelivery = "PORTAL"
+)
+
+func (d InvoiceDelivery) String() string {
+	return string(d)
+}
+
+func (d InvoiceDelivery) IsValid() bool {
+	return d == InvoiceDeliveryEmail || d == InvoiceDeliveryPortal
+}
+
+// BillingProfileEntity is what a customer is invoiced and billed with.
+type BillingProfileEntity struct {
+	CustomerUUID string
+
+	AddressLine1 string
+	AddressLine2 *string
+	City         string
+	PostalCode   *string
+	Region       *string
+	Country      string // ISO 3166-1 alpha-2
+
+	// TaxID is normalized and valid for the country, see taxid.Validate
+	TaxID *string
+
+	// PaymentTerms set the due date of closed bills, empty keeps the service's
+	// payment terms
+	PaymentTerms    PaymentTerms
+	InvoiceDelivery InvoiceDelivery
+
+	// Currency is the customer's default currency, stored on the customer
+	Currency *string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
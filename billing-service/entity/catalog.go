@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrProductCodeTaken is returned when a product is created with a code
+	// that is already in use.
+	ErrProductCodeTaken = errors.New("product code already exists")
+
+	// ErrPriceExists is returned when a product already has a price in the
+	// currency from the same effective time.
+	ErrPriceExists = errors.New("price already exists")
+
+	// ErrSubscriptionEnded is returned when a subscription that already has an
+	// end is cancelled again.
+	ErrSubscriptionEnded = errors.New("subscription already ended")
+)
+
+// ProductEntity is something customers subscribe to.
+type ProductEntity struct {
+	ID        int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID      string
+	Code      string
+	Name      string
+	CreatedAt time.Time
+}
+
+// PriceEntity is what one unit of a product costs per bill period in Currency
+// from EffectiveFrom on. A bill uses the price in effect when its period starts,
+// so a change mid-period applies from the next period.
+type PriceEntity struct {
+	ID            int64 `json:"-"`
+	UUID          string
+	ProductUUID   string
+	Currency      string
+	AmountCents   int64
+	EffectiveFrom time.Time
+	CreatedAt     time.Time
+}
+
+// SubscriptionEntity is a customer's subscription to Quantity units of a
+// product. It is billed on every bill period it overlaps.
+type SubscriptionEntity struct {
+	ID           int64 `json:"-"`
+	UUID         string
+	CustomerUUID string
+	ProductUUID  string
+	Quantity     int64
+	StartedAt    time.Time
+	EndedAt      *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Coverage returns the part of the period [start, end) the subscription is
+// active in, and false when it does not overlap the period.
+func (s *SubscriptionEntity) Coverage(start, end time.Time) (time.Time, time.Time, bool) {
+	from, to := start, end
+	if s.StartedAt.After(from) {
+		from = s.StartedAt
+	}
+	if s.EndedAt != nil && s.EndedAt.Before(to) {
+		to = *s.EndedAt
+	}
+	return from, to, from.Before(to)
+}
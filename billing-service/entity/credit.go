@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// CreditEntity is credit granted to a customer. Bills in the same currency
+// consume it at close until RemainingCents reaches zero.
+type CreditEntity struct {
+	ID             int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID           string
+	CustomerUUID   string
+	IdempotencyKey string
+	Currency       string
+	AmountCents    int64
+	RemainingCents int64
+	Description    *string
+	CreatedAt      time.Time
+}
+
+// CreditApplicationEntity is the part of a credit a bill consumed.
+type CreditApplicationEntity struct {
+	CreditUUID  string
+	BillUUID    string
+	AmountCents int64
+}
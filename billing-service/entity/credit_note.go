@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCreditNoteBillNotClosed is returned when a credit note is issued for a
+	// bill whose total is not locked yet, or that was voided.
+	ErrCreditNoteBillNotClosed = errors.New("credit notes need a closed bill")
+
+	// ErrCreditExceedsLineItem is returned when a credit note line credits more
+	// than the line item has left after earlier credit notes.
+	ErrCreditExceedsLineItem = errors.New("credit exceeds the line item's creditable amount")
+)
+
+// CreditNoteStatus tracks a credit note from issue until its credit reached the customer.
+type CreditNoteStatus string
+
+const (
+	// CreditNoteStatusIssued means the note is numbered, its credit not granted yet
+	CreditNoteStatusIssued CreditNoteStatus = "ISSUED"
+	// CreditNoteStatusApplied means the credit was granted to the customer
+	CreditNoteStatusApplied CreditNoteStatus = "APPLIED"
+)
+
+func (s CreditNoteStatus) String() string {
+	return string(s)
+}
+
+// CreditNoteEntity is a credit document issued against a closed bill. It has
+// its own per-customer number and leaves the bill untouched; once applied,
+// CreditUUID is the customer credit it granted, which the customer's next bill
+// in the currency consumes at close.
+type CreditNoteEntity struct {
+	ID             int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID           string
+	BillUUID       string
+	CustomerUUID   string
+	IdempotencyKey string
+	Sequence       int64
+	Number         string
+	Currency       string
+	AmountCents    int64
+	Reason         *string
+	Status         CreditNoteStatus
+	CreditUUID     *string
+	IssuedAt       time.Time
+	AppliedAt      *time.Time
+
+	Lines []*CreditNoteLineEntity
+}
+
+// CreditNoteLineEntity is the part of a line item a credit note credits.
+type CreditNoteLineEntity struct {
+	LineItemUUID string
+	AmountCents  int64
+}
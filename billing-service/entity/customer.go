@@ -1,11 +1,89 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrEmailTaken is returned when a customer is created or updated with an
+	// email another active customer already uses.
+	ErrEmailTaken = errors.New("email already used by an active customer")
+
+	// ErrCustomerArchived is returned when an archived customer is changed or
+	// merged into.
+	ErrCustomerArchived = errors.New("customer is archived")
+
+	// ErrCustomerMerged is returned when a merged customer is changed,
+	// archived or merged again.
+	ErrCustomerMerged = errors.New("customer is merged")
+
+	// ErrCustomerHasOpenBills is returned when a customer is merged while one
+	// of their bills is still open or closing.
+	ErrCustomerHasOpenBills = errors.New("customer has open bills")
+)
+
+// CustomerStatus represents the lifecycle status of a customer
+type CustomerStatus string
+
+const (
+	// CustomerStatusActive - Customer can be billed
+	CustomerStatusActive CustomerStatus = "ACTIVE"
+
+	// CustomerStatusArchived - Soft deleted, kept with their bills but takes no new ones
+	CustomerStatusArchived CustomerStatus = "ARCHIVED"
+
+	// CustomerStatusMerged - Duplicate whose bills moved to the customer it was merged into
+	CustomerStatusMerged CustomerStatus = "MERGED"
+)
+
+func (s CustomerStatus) String() string {
+	return string(s)
+}
+
+func (s CustomerStatus) IsValid() bool {
+	switch s {
+	case CustomerStatusActive, CustomerStatusArchived, CustomerStatusMerged:
+		return true
+	}
+	return false
+}
 
 type CustomerEntity struct {
-	UUID      string
-	Name      string
-	Email     string
+	ID    int64
+	UUID  string
+	Name  string
+	Email string
+
+	// Currency is the default currency of the customer's bills, nil falls back
+	// to the billing currency
+	Currency *string
+
+	Status         CustomerStatus
+	ArchivedAt     *time.Time
+	MergedIntoUUID *string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+// NotActiveErr returns ErrCustomerArchived or ErrCustomerMerged for a customer
+// that is not active, nil for an active one
+func (s CustomerStatus) NotActiveErr() error {
+	switch s {
+	case CustomerStatusArchived:
+		return ErrCustomerArchived
+	case CustomerStatusMerged:
+		return ErrCustomerMerged
+	}
+	return nil
+}
+
+// CustomerMergeEntity is what merging a duplicate into another customer moved
+type CustomerMergeEntity struct {
+	SourceUUID    string
+	TargetUUID    string
+	Bills         int64
+	Credits       int64
+	Subscriptions int64
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// InvoiceEntity is the invoice issued for a closed bill. Sequence counts up per
+// customer without gaps; Number is its printed form.
+type InvoiceEntity struct {
+	ID           int64 `json:"-"` // Internal use only, excluded from JSON
+	BillUUID     string
+	CustomerUUID string
+	Sequence     int64
+	Number       string
+	IssuedAt     time.Time
+}
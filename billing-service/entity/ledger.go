@@ -0,0 +1,69 @@
+package entity
+
+// AccountType is the kind of ledger account. RECEIVABLE and CUSTOMER_CREDIT
+// accounts are held per customer, the others once per currency.
+type AccountType string
+
+const (
+	// AccountReceivable - what a customer owes on their bills (asset)
+	AccountReceivable AccountType = "RECEIVABLE"
+	// AccountCustomerCredit - credit granted to a customer and not consumed yet (liability)
+	AccountCustomerCredit AccountType = "CUSTOMER_CREDIT"
+	// AccountRevenue - charges billed, net of discounts and reversals (income)
+	AccountRevenue AccountType = "REVENUE"
+	// AccountTaxPayable - tax billed on behalf of the tax authority (liability)
+	AccountTaxPayable AccountType = "TAX_PAYABLE"
+	// AccountCash - payments received (asset)
+	AccountCash AccountType = "CASH"
+	// AccountCreditsGranted - credit given away to customers (expense)
+	AccountCreditsGranted AccountType = "CREDITS_GRANTED"
+)
+
+func (t AccountType) String() string {
+	return string(t)
+}
+
+// IsCustomerAccount reports whether the account type is held per customer
+func (t AccountType) IsCustomerAccount() bool {
+	return t == AccountReceivable || t == AccountCustomerCredit
+}
+
+// LedgerSource is what posted a ledger transaction; a source posts once.
+type LedgerSource string
+
+const (
+	LedgerSourceLineItem    LedgerSource = "LINE_ITEM"
+	LedgerSourcePayment     LedgerSource = "PAYMENT"
+	LedgerSourceCreditGrant LedgerSource = "CREDIT_GRANT"
+	LedgerSourceBillVoid    LedgerSource = "BILL_VOID"
+
+	// LedgerSourceCustomerMerge moves a merged customer's balances, keyed by
+	// the customer merged away
+	LedgerSourceCustomerMerge LedgerSource = "CUSTOMER_MERGE"
+)
+
+// EntryDirection is the side of the account a ledger entry is posted to.
+type EntryDirection string
+
+const (
+	EntryDebit  EntryDirection = "DEBIT"
+	EntryCredit EntryDirection = "CREDIT"
+)
+
+// TrialBalanceLineEntity totals the ledger entries of one account type in one
+// currency, across customers for the per-customer types.
+type TrialBalanceLineEntity struct {
+	Currency    string
+	AccountType AccountType
+	DebitCents  int64
+	CreditCents int64
+}
+
+// CustomerBalanceEntity is what the ledger holds for a customer in one
+// currency: the receivable still owed on their bills and the credit they have
+// left.
+type CustomerBalanceEntity struct {
+	Currency        string
+	ReceivableCents int64
+	CreditCents     int64
+}
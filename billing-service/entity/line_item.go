@@ -1,6 +1,13 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrReversalExceedsLineItem is returned when a reversal would reverse more of
+// a line item than is left of it.
+var ErrReversalExceedsLineItem = errors.New("reversal exceeds the line item's remaining amount")
 
 type LineItemEntity struct {
 	ID             int64 `json:"-"` // Internal use only, excluded from JSON
@@ -12,4 +19,27 @@ type LineItemEntity struct {
 	AmountCents    int64
 	ReferenceUUID  *string
 	CreatedAt      time.Time
+
+	// OriginalAmountCents, OriginalCurrency and FXRate are set when the item was
+	// submitted in another currency; AmountCents is then the converted amount
+	OriginalAmountCents *int64
+	OriginalCurrency    *string
+	FXRate              *string
+
+	// ReversedCents is how much of the amount REVERSAL line items took back,
+	// counted without sign
+	ReversedCents int64
+}
+
+// RemainingReversibleCents is how much of the line item can still be reversed,
+// without sign. Reversals themselves cannot be reversed.
+func (li *LineItemEntity) RemainingReversibleCents() int64 {
+	if li.FeeType == FeeTypeReversal.String() {
+		return 0
+	}
+	amountCents := li.AmountCents
+	if amountCents < 0 {
+		amountCents = -amountCents
+	}
+	return amountCents - li.ReversedCents
 }
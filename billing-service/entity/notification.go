@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending NotificationDeliveryStatus = "PENDING"
+	NotificationDeliverySent    NotificationDeliveryStatus = "SENT"
+)
+
+// NotificationDeliveryEntity records one customer notification. The idempotency
+// key is unique, so a retried send finds the delivery it already made.
+type NotificationDeliveryEntity struct {
+	ID             int64 `json:"-"` // Internal use only, excluded from JSON
+	IdempotencyKey string
+	BillUUID       string
+	CustomerUUID   string
+	Event          string
+	Channel        string
+	Recipient      string
+	Status         NotificationDeliveryStatus
+	Attempts       int
+	SentAt         *time.Time
+	CreatedAt      time.Time
+}
+
+func (d *NotificationDeliveryEntity) IsSent() bool {
+	return d.Status == NotificationDeliverySent
+}
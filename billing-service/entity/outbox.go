@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// OutboxEventEntity is a billing event recorded in the transaction of the change
+// it reports. PublishedAt is set once the relay handed it to every sink.
+type OutboxEventEntity struct {
+	ID            int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID          string
+	AggregateUUID string
+	EventType     string
+	Payload       []byte
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+}
+
+// BillEventData is the payload of bill.created and bill.closed events.
+type BillEventData struct {
+	UUID         string     `json:"uuid"`
+	CustomerUUID string     `json:"customerUuid,omitempty"`
+	Currency     string     `json:"currency,omitempty"`
+	PeriodStart  *time.Time `json:"periodStart,omitempty"`
+	PeriodEnd    *time.Time `json:"periodEnd,omitempty"`
+	TotalCents   *int64     `json:"totalCents,omitempty"`
+	ClosedAt     *time.Time `json:"closedAt,omitempty"`
+}
+
+// LineItemEventData is the payload of line_item.persisted and line_item.reversed events.
+type LineItemEventData struct {
+	UUID           string  `json:"uuid"`
+	BillUUID       string  `json:"billUuid"`
+	IdempotencyKey string  `json:"idempotencyKey"`
+	FeeType        string  `json:"feeType"`
+	Description    string  `json:"description,omitempty"`
+	AmountCents    int64   `json:"amountCents"`
+	ReferenceUUID  *string `json:"referenceUuid,omitempty"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrBillNotPayable is returned when a payment targets a bill that is still
+	// open, voided or already paid.
+	ErrBillNotPayable = errors.New("bill does not accept payments")
+
+	// ErrPaymentExceedsBalance is returned when a payment is larger than the
+	// bill's remaining balance.
+	ErrPaymentExceedsBalance = errors.New("payment exceeds the bill balance")
+)
+
+// PaymentEntity is money received against a closed bill. The idempotency key is
+// unique per bill, so a retried payment request records it once.
+type PaymentEntity struct {
+	ID             int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID           string
+	BillUUID       string
+	IdempotencyKey string
+	AmountCents    int64
+	Currency       string
+	Reference      *string
+	ReceivedAt     time.Time
+	CreatedAt      time.Time
+}
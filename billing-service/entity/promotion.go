@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPromotionCodeTaken is returned when a promotion is created with a code
+	// that is already in use.
+	ErrPromotionCodeTaken = errors.New("promotion code already exists")
+
+	// ErrPromotionExpired is returned when an expired promo code is redeemed.
+	ErrPromotionExpired = errors.New("promotion has expired")
+
+	// ErrPromotionExhausted is returned when a promo code reached its usage limit.
+	ErrPromotionExhausted = errors.New("promotion usage limit reached")
+)
+
+// PromotionKind tells how a promotion's discount is computed.
+type PromotionKind string
+
+const (
+	// PromotionKindPercent takes PercentBps of the bill total off
+	PromotionKindPercent PromotionKind = "PERCENT"
+	// PromotionKindFixed takes AmountCents off bills in Currency
+	PromotionKindFixed PromotionKind = "FIXED"
+)
+
+func (k PromotionKind) String() string {
+	return string(k)
+}
+
+// PromotionEntity is a promo code. Redeeming it on an open bill counts against
+// MaxRedemptions; the discount itself is applied when the bill closes.
+type PromotionEntity struct {
+	ID              int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID            string
+	Code            string
+	Kind            PromotionKind
+	PercentBps      *int64
+	AmountCents     *int64
+	Currency        *string
+	MaxRedemptions  *int
+	RedemptionCount int
+	ExpiresAt       *time.Time
+	CreatedAt       time.Time
+}
+
+// IsExpired reports whether the promotion can no longer be redeemed at now.
+func (p *PromotionEntity) IsExpired(now time.Time) bool {
+	return p.ExpiresAt != nil && !now.Before(*p.ExpiresAt)
+}
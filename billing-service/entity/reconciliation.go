@@ -0,0 +1,80 @@
+package entity
+
+import "time"
+
+// ReconciliationFindingKind names which of a bill's totals disagree.
+type ReconciliationFindingKind string
+
+const (
+	// FindingBillTotalMismatch - bills.total_cents differs from the bill's ledger total
+	FindingBillTotalMismatch ReconciliationFindingKind = "BILL_TOTAL_MISMATCH"
+
+	// FindingLedgerMismatch - the bill's line items sum to another amount than the ledger posted for them
+	FindingLedgerMismatch ReconciliationFindingKind = "LEDGER_MISMATCH"
+
+	// FindingWorkflowTotalMismatch - the open bill's workflow holds another total than its line items sum to
+	FindingWorkflowTotalMismatch ReconciliationFindingKind = "WORKFLOW_TOTAL_MISMATCH"
+
+	// FindingWorkflowNotFound - the bill is open but has no workflow to answer for it
+	FindingWorkflowNotFound ReconciliationFindingKind = "WORKFLOW_NOT_FOUND"
+)
+
+func (k ReconciliationFindingKind) String() string {
+	return string(k)
+}
+
+// ReconciliationFindingStatus tracks a finding across reconciliation runs.
+type ReconciliationFindingStatus string
+
+const (
+	// FindingStatusOpen means the last run still saw the discrepancy
+	FindingStatusOpen ReconciliationFindingStatus = "OPEN"
+	// FindingStatusResolved means a later run no longer saw it
+	FindingStatusResolved ReconciliationFindingStatus = "RESOLVED"
+	// FindingStatusRepaired means the run set total_cents to the ledger total
+	FindingStatusRepaired ReconciliationFindingStatus = "REPAIRED"
+)
+
+func (s ReconciliationFindingStatus) String() string {
+	return string(s)
+}
+
+// IsValid checks if the status is a valid finding status (for filtering)
+func (s ReconciliationFindingStatus) IsValid() bool {
+	switch s {
+	case FindingStatusOpen, FindingStatusResolved, FindingStatusRepaired:
+		return true
+	}
+	return false
+}
+
+// BillTotalsEntity is what the database holds about a bill's total: the
+// total_cents column, the sum of its line items and what the ledger posted to
+// the bill, read together.
+type BillTotalsEntity struct {
+	ID             int64
+	BillUUID       string
+	Status         BillStatus
+	TotalCents     int64
+	LineItemsCents int64
+	LedgerCents    int64
+}
+
+// ReconciliationFindingEntity is a discrepancy between a bill's totals.
+// WorkflowTotalCents is only set for findings about the bill workflow,
+// LedgerCents is nil on findings recorded before the ledger was checked.
+type ReconciliationFindingEntity struct {
+	ID                 int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID               string
+	BillUUID           string
+	Kind               ReconciliationFindingKind
+	Status             ReconciliationFindingStatus
+	BillStatus         BillStatus
+	BillTotalCents     int64
+	LineItemsCents     int64
+	LedgerCents        *int64
+	WorkflowTotalCents *int64
+	DetectedAt         time.Time
+	LastSeenAt         time.Time
+	ResolvedAt         *time.Time
+}
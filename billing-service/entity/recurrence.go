@@ -0,0 +1,79 @@
+package entity
+
+import "time"
+
+// =============================================================================
+// Bill Cadence (recurring bill periods)
+// =============================================================================
+
+// BillCadence represents how often a recurring bill rolls over into a new period
+type BillCadence string
+
+const (
+	BillCadenceMonthly BillCadence = "MONTHLY"
+	BillCadenceWeekly  BillCadence = "WEEKLY"
+	BillCadenceCustom  BillCadence = "CUSTOM" // every IntervalDays days
+)
+
+// IsValid checks if the cadence is a supported bill cadence
+func (c BillCadence) IsValid() bool {
+	return c == BillCadenceMonthly || c == BillCadenceWeekly || c == BillCadenceCustom
+}
+
+// String returns the string representation of the cadence
+func (c BillCadence) String() string {
+	return string(c)
+}
+
+// BillRecurrence describes an opt-in recurring schedule for a bill.
+// When set, closing a bill at the end of its period opens the next one.
+type BillRecurrence struct {
+	Cadence      BillCadence
+	IntervalDays int // only used by CUSTOM
+
+	// AnchorDay is the day of the month MONTHLY periods end on, clamped to the
+	// last day of shorter months. Zero anchors on the day of the period start.
+	AnchorDay int
+}
+
+// NewBillRecurrence builds the recurrence of a series whose first period ends
+// at firstPeriodEnd. Monthly periods keep ending on that day of the month, or
+// on the last day of the month when the first period ends on one.
+func NewBillRecurrence(cadence BillCadence, intervalDays int, firstPeriodEnd time.Time) *BillRecurrence {
+	r := &BillRecurrence{Cadence: cadence, IntervalDays: intervalDays}
+	if cadence == BillCadenceMonthly {
+		r.AnchorDay = firstPeriodEnd.Day()
+		if firstPeriodEnd.Day() == daysInMonth(firstPeriodEnd.Year(), firstPeriodEnd.Month()) {
+			r.AnchorDay = 31
+		}
+	}
+	return r
+}
+
+// NextPeriodEnd returns the end of the period starting at start.
+// Periods are contiguous: the next period starts where the previous one ended.
+func (r BillRecurrence) NextPeriodEnd(start time.Time) time.Time {
+	switch r.Cadence {
+	case BillCadenceWeekly:
+		return start.AddDate(0, 0, 7)
+	case BillCadenceCustom:
+		return start.AddDate(0, 0, r.IntervalDays)
+	default:
+		// AddDate normalizes Jan 31 + 1 month to Mar 2 or 3, and every later
+		// period would keep that shift
+		anchor := r.AnchorDay
+		if anchor <= 0 {
+			anchor = start.Day()
+		}
+		year, month := start.Year(), start.Month()+1
+		if month > time.December {
+			year, month = year+1, time.January
+		}
+		day := min(anchor, daysInMonth(year, month))
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBillRecurrence_NextPeriodEnd(t *testing.T) {
+	t.Run("monthly series anchored on the 31st ends on month ends", func(t *testing.T) {
+		r := NewBillRecurrence(BillCadenceMonthly, 0, date(2024, time.January, 31))
+
+		var ends []time.Time
+		end := date(2024, time.January, 31)
+		for range 4 {
+			end = r.NextPeriodEnd(end)
+			ends = append(ends, end)
+		}
+
+		assert.Equal(t, []time.Time{
+			date(2024, time.February, 29), // leap year
+			date(2024, time.March, 31),
+			date(2024, time.April, 30),
+			date(2024, time.May, 31),
+		}, ends)
+	})
+
+	t.Run("february of a common year", func(t *testing.T) {
+		r := NewBillRecurrence(BillCadenceMonthly, 0, date(2023, time.January, 30))
+
+		feb := r.NextPeriodEnd(date(2023, time.January, 30))
+		assert.Equal(t, date(2023, time.February, 28), feb)
+		assert.Equal(t, date(2023, time.March, 30), r.NextPeriodEnd(feb))
+	})
+
+	t.Run("first period ending on the last day of february keeps month ends", func(t *testing.T) {
+		r := NewBillRecurrence(BillCadenceMonthly, 0, date(2023, time.February, 28))
+
+		assert.Equal(t, 31, r.AnchorDay)
+		assert.Equal(t, date(2023, time.March, 31), r.NextPeriodEnd(date(2023, time.February, 28)))
+	})
+
+	t.Run("rolls over the year", func(t *testing.T) {
+		r := NewBillRecurrence(BillCadenceMonthly, 0, date(2024, time.December, 15))
+
+		assert.Equal(t, date(2025, time.January, 15), r.NextPeriodEnd(date(2024, time.December, 15)))
+	})
+
+	t.Run("recurrence without anchor uses the day of the start", func(t *testing.T) {
+		r := BillRecurrence{Cadence: BillCadenceMonthly}
+
+		assert.Equal(t, date(2024, time.February, 29), r.NextPeriodEnd(date(2024, time.January, 31)))
+	})
+
+	t.Run("weekly and custom add days", func(t *testing.T) {
+		start := date(2024, time.February, 26)
+
+		assert.Equal(t, date(2024, time.March, 4), NewBillRecurrence(BillCadenceWeekly, 0, start).NextPeriodEnd(start))
+		assert.Equal(t, date(2024, time.March, 7), NewBillRecurrence(BillCadenceCustom, 10, start).NextPeriodEnd(start))
+	})
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// SettlementStatus tracks the bank transfer collecting a closed bill.
+type SettlementStatus string
+
+const (
+	// SettlementStatusPending means the transfer is running
+	SettlementStatusPending SettlementStatus = "PENDING"
+	// SettlementStatusSettled means the money arrived and was recorded as a payment
+	SettlementStatusSettled SettlementStatus = "SETTLED"
+	// SettlementStatusFailed means the transfer failed, a withdrawn amount was refunded
+	SettlementStatusFailed SettlementStatus = "FAILED"
+	// SettlementStatusCredited means the money arrived but the bill no longer
+	// accepted it, it was granted to the customer as credit
+	SettlementStatusCredited SettlementStatus = "CREDITED"
+)
+
+func (s SettlementStatus) String() string {
+	return string(s)
+}
+
+// SettlementEntity is the bank transfer collecting a bill's balance from the
+// customer's account. A bill is settled at most once.
+type SettlementEntity struct {
+	ID            int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID          string
+	BillUUID      string
+	SourceAccount string
+	TargetAccount string
+	AmountCents   int64
+	Status        SettlementStatus
+	PaymentUUID   *string
+	CreditUUID    *string
+	FailureReason *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
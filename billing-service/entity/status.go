@@ -1,7 +1,17 @@
 package entity
 
+import "errors"
+
+// ErrInvalidBillTransition is returned when a status change is not allowed by
+// the bill lifecycle, or the bill is no longer in the expected status.
+var ErrInvalidBillTransition = errors.New("invalid bill status transition")
+
+// ErrBillNotOpen is returned when something only open bills accept, like a
+// promo code or usage, reaches a bill that already started closing.
+var ErrBillNotOpen = errors.New("bill is not open")
+
 // =============================================================================
-// Bill Status (persisted in DB: OPEN, CLOSED only)
+// Bill Status (persisted in DB, every change recorded in bill_status_history)
 // =============================================================================
 
 // BillStatus represents the lifecycle status of a bill
@@ -11,17 +21,82 @@ const (
 	// BillStatusOpen - Bill is active, accepting line items
 	BillStatusOpen BillStatus = "OPEN"
 
-	// BillStatusClosing - Close signal sent, workflow processing
-	// NOTE: API-only status, not persisted to database
+	// BillStatusClosing - Close accepted, workflow draining line items before totals are locked
 	BillStatusClosing BillStatus = "CLOSING"
 
-	// BillStatusClosed - Bill finalized, no modifications allowed
+	// BillStatusClosed - Totals locked, no modifications allowed
 	BillStatusClosed BillStatus = "CLOSED"
+
+	// BillStatusFinalized - Closed bill invoiced and handed off downstream, awaiting payment
+	BillStatusFinalized BillStatus = "FINALIZED"
+
+	// BillStatusPartiallyPaid - Payments received short of the total, not yet due
+	BillStatusPartiallyPaid BillStatus = "PARTIALLY_PAID"
+
+	// BillStatusOverdue - Past the due date with a balance left
+	BillStatusOverdue BillStatus = "OVERDUE"
+
+	// BillStatusPaid - Payments cover the total, terminal
+	BillStatusPaid BillStatus = "PAID"
+
+	// BillStatusVoided - Open bill cancelled with a zero total, terminal
+	BillStatusVoided BillStatus = "VOIDED"
 )
 
+// billStatusTransitions is the bill lifecycle: the statuses each status may move to.
+// Both the handlers and the bill workflow check transitions against it.
+var billStatusTransitions = map[BillStatus][]BillStatus{
+	BillStatusOpen:      {BillStatusClosing, BillStatusVoided},
+	BillStatusClosing:   {BillStatusClosed},
+	BillStatusClosed:    {BillStatusFinalized, BillStatusPartiallyPaid, BillStatusOverdue, BillStatusPaid},
+	BillStatusFinalized: {BillStatusPartiallyPaid, BillStatusOverdue, BillStatusPaid},
+
+	BillStatusPartiallyPaid: {BillStatusOverdue, BillStatusPaid},
+	BillStatusOverdue:       {BillStatusPaid},
+	BillStatusPaid:          {},
+	BillStatusVoided:        {},
+}
+
 // IsValid checks if the status is a valid bill status (for filtering)
 func (s BillStatus) IsValid() bool {
-	return s == BillStatusOpen || s == BillStatusClosed
+	_, ok := billStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to next
+func (s BillStatus) CanTransitionTo(next BillStatus) bool {
+	for _, allowed := range billStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are allowed
+func (s BillStatus) IsTerminal() bool {
+	return len(billStatusTransitions[s]) == 0
+}
+
+// AcceptsLineItems reports whether line items can still be added
+func (s BillStatus) AcceptsLineItems() bool {
+	return s == BillStatusOpen
+}
+
+// AcceptsPayments reports whether payments can be recorded against the bill:
+// once its total is locked and until it is paid in full
+func (s BillStatus) AcceptsPayments() bool {
+	switch s {
+	case BillStatusClosed, BillStatusFinalized, BillStatusPartiallyPaid, BillStatusOverdue:
+		return true
+	}
+	return false
+}
+
+// AcceptsCreditNotes reports whether credit notes can be issued against the
+// bill: once its total is locked, paid or not. Voided bills have nothing to credit.
+func (s BillStatus) AcceptsCreditNotes() bool {
+	return s.AcceptsPayments() || s == BillStatusPaid
 }
 
 // String returns the string representation of the status
@@ -62,6 +137,15 @@ const (
 	FeeTypeMonthlyFee   FeeType = "MONTHLY_FEE"
 	FeeTypeReversal     FeeType = "REVERSAL"
 	FeeTypeOther        FeeType = "OTHER"
+
+	// FeeTypeTax marks tax line items added by the bill workflow at close
+	FeeTypeTax FeeType = "TAX"
+	// FeeTypeDiscount marks the negative line items of promo codes, applied at close
+	FeeTypeDiscount FeeType = "DISCOUNT"
+	// FeeTypeCredit marks the negative line items of customer credit consumed at close
+	FeeTypeCredit FeeType = "CREDIT"
+	// FeeTypeUsage marks the line items metered usage is aggregated into at close
+	FeeTypeUsage FeeType = "USAGE"
 )
 
 // ValidFeeTypes is the list of allowed fee types
@@ -71,6 +155,10 @@ var ValidFeeTypes = []FeeType{
 	FeeTypeMonthlyFee,
 	FeeTypeReversal,
 	FeeTypeOther,
+	FeeTypeTax,
+	FeeTypeDiscount,
+	FeeTypeCredit,
+	FeeTypeUsage,
 }
 
 // IsValid checks if the fee type is valid
@@ -83,6 +171,16 @@ func (f FeeType) IsValid() bool {
 	return false
 }
 
+// AppliedAtClose reports whether line items of this fee type are only added by
+// the bill workflow while the bill closes.
+func (f FeeType) AppliedAtClose() bool {
+	switch f {
+	case FeeTypeUsage, FeeTypeTax, FeeTypeDiscount, FeeTypeCredit:
+		return true
+	}
+	return false
+}
+
 // String returns the string representation of the fee type
 func (f FeeType) String() string {
 	return string(f)
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// UsageEventEntity is a metered quantity recorded against an open bill. The
+// dedup key is unique per bill, so a resent event is counted once.
+type UsageEventEntity struct {
+	ID         int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID       string
+	BillUUID   string
+	Meter      string
+	Quantity   int64
+	DedupKey   string
+	OccurredAt time.Time
+	CreatedAt  time.Time
+}
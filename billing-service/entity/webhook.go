@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+// WebhookEventType names a billing event pushed to webhook subscribers.
+type WebhookEventType string
+
+const (
+	WebhookEventBillCreated       WebhookEventType = "bill.created"
+	WebhookEventLineItemPersisted WebhookEventType = "line_item.persisted"
+	WebhookEventLineItemReversed  WebhookEventType = "line_item.reversed"
+	WebhookEventBillClosed        WebhookEventType = "bill.closed"
+)
+
+// ValidWebhookEventTypes lists every event a subscription can select.
+var ValidWebhookEventTypes = []WebhookEventType{
+	WebhookEventBillCreated,
+	WebhookEventLineItemPersisted,
+	WebhookEventLineItemReversed,
+	WebhookEventBillClosed,
+}
+
+func (e WebhookEventType) String() string {
+	return string(e)
+}
+
+// IsValid checks if the event type is a published billing event
+func (e WebhookEventType) IsValid() bool {
+	for _, valid := range ValidWebhookEventTypes {
+		if e == valid {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookSubscriptionEntity struct {
+	ID        int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID      string
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+// Subscribes reports whether the subscription selected the event type.
+func (s *WebhookSubscriptionEntity) Subscribes(eventType WebhookEventType) bool {
+	for _, e := range s.Events {
+		if e == eventType.String() {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDeliveryEntity is one event sent to one subscription. The payload is
+// stored as sent so a replay signs and posts the same body.
+type WebhookDeliveryEntity struct {
+	ID               int64 `json:"-"` // Internal use only, excluded from JSON
+	UUID             string
+	SubscriptionUUID string
+	EventUUID        string
+	EventType        string
+	Payload          []byte
+	Status           WebhookDeliveryStatus
+	Attempts         int
+	LastStatusCode   *int
+	LastError        *string
+	DeliveredAt      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// WebhookDeliveryAttemptEntity is a line of the delivery log.
+type WebhookDeliveryAttemptEntity struct {
+	DeliveryUUID string
+	StatusCode   *int
+	Error        *string
+	AttemptedAt  time.Time
+}
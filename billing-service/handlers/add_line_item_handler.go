@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 
+	"encore.app/currency"
 	"encore.app/db/repository"
 	"encore.app/dto"
 	"encore.app/entity"
@@ -13,16 +15,18 @@ import (
 	t "encore.app/temporal"
 	tbill "encore.app/temporal/bill"
 
-	"encore.dev/rlog"
 	"encore.dev/storage/sqldb"
 	"github.com/google/uuid"
-	"go.temporal.io/api/serviceerror"
 )
 
 type AddLineItemHandler struct {
 	BillRepo       repository.BillRepository
 	LineItemRepo   repository.LineItemRepository
 	TemporalClient t.WorkflowClient
+
+	// FXRates converts amounts in other currencies when the request asks for
+	// it, nil rejects every currency mismatch
+	FXRates currency.FXRateProvider
 }
 
 func (h *AddLineItemHandler) Handle(ctx context.Context, req *dto.AddLineItemRequest) (*dto.AddLineItemResponse, error) {
@@ -39,28 +43,31 @@ func (h *AddLineItemHandler) Handle(ctx context.Context, req *dto.AddLineItemReq
 		return nil, utils.ErrBillClosed
 	}
 
-	if req.Amount.Currency != bill.Currency {
-		return nil, utils.ErrCurrencyMismatch
-	}
-
+	// a replay returns the stored line item as it was converted, it must not
+	// fail on, or fetch, today's rate
 	if existingResp, err := h.checkIdempotency(ctx, req, bill); existingResp != nil || err != nil {
 		return existingResp, err
 	}
 
-	lineItemUUID := uuid.New().String()
-	workflowID := t.BillWorkflowIDPrefix + req.BillUUID
-
-	if err := h.queryWorkflowState(ctx, workflowID, req.BillUUID); err != nil {
-		return nil, err
+	update := h.buildUpdate(uuid.New().String(), req)
+	if req.Amount.Currency != bill.Currency {
+		if err := h.convert(ctx, &update, bill.Currency, req.ConvertCurrency); err != nil {
+			return nil, err
+		}
 	}
 
-	signal := h.buildSignal(lineItemUUID, req)
-
-	if err := h.signalWorkflow(ctx, workflowID, req.BillUUID, signal); err != nil {
+	lineItem, err := updateLineItem(ctx, h.TemporalClient, req.BillUUID, update)
+	if err != nil {
+		// lost a race against a concurrent request with the same idempotency key
+		if err == utils.ErrDuplicateIdempotencyKey {
+			if existingResp, err := h.checkIdempotency(ctx, req, bill); existingResp != nil || err != nil {
+				return existingResp, err
+			}
+		}
 		return nil, err
 	}
 
-	return h.buildPendingResponse(lineItemUUID, req), nil
+	return mapLineItemToAddResponse(lineItem, bill.Currency), nil
 }
 
 func (h *AddLineItemHandler) fetchBill(ctx context.Context, billUUID string) (*entity.BillEntity, error) {
@@ -83,91 +90,88 @@ func (h *AddLineItemHandler) checkIdempotency(ctx context.Context, req *dto.AddL
 		return nil, utils.ErrInternal
 	}
 
-	return &dto.AddLineItemResponse{
-		UUID:    existing.UUID,
-		FeeType: existing.FeeType,
-		Amount: dto.Money{
-			Amount:   existing.AmountCents,
-			Currency: bill.Currency,
-		},
-		Status:    "persisted",
-		CreatedAt: existing.CreatedAt.Format(time.RFC3339),
-	}, nil
+	return mapLineItemToAddResponse(existing, bill.Currency), nil
 }
 
-func (h *AddLineItemHandler) queryWorkflowState(ctx context.Context, workflowID, billUUID string) error {
-	queryResp, err := h.TemporalClient.QueryWorkflow(ctx, workflowID, "", tbill.QueryGetBillState)
-	if err != nil {
-		var notFound *serviceerror.NotFound
-		if errors.As(err, &notFound) {
-			rlog.Error("data inconsistency: bill exists but workflow not found",
-				"bill_uuid", billUUID,
-				"workflow_id", workflowID)
-			return utils.ErrWorkflowNotFound
-		}
-
-		rlog.Error("failed to query workflow state",
-			"bill_uuid", billUUID,
-			"error", err)
-		return utils.ErrWorkflowQueryFailed
+func (h *AddLineItemHandler) buildUpdate(lineItemUUID string, req *dto.AddLineItemRequest) tbill.AddLineItemUpdate {
+	return tbill.AddLineItemUpdate{
+		AddLineItemSignal: tbill.AddLineItemSignal{
+			UUID:           lineItemUUID,
+			IdempotencyKey: req.IdempotencyKey,
+			FeeType:        req.FeeType,
+			Description:    req.Description,
+			AmountCents:    req.Amount.Amount,
+		},
+		Currency: req.Amount.Currency,
 	}
+}
 
-	var billState tbill.BillStateQuery
-	if err := queryResp.Get(&billState); err != nil {
-		rlog.Error("failed to decode workflow state",
-			"bill_uuid", billUUID,
-			"error", err)
-		return utils.ErrWorkflowQueryFailed
+// convert converts the update's amount into the bill currency, keeping what
+// was submitted next to it. Without the request opting in, or without an FX
+// rate provider, a different currency is a mismatch.
+func (h *AddLineItemHandler) convert(ctx context.Context, update *tbill.AddLineItemUpdate, billCurrency string, requested bool) error {
+	if !requested || h.FXRates == nil {
+		return utils.ErrCurrencyMismatch
 	}
 
-	if billState.Status == "CLOSED" {
-		rlog.Info("signaling workflow in closing state",
-			"bill_uuid", billUUID,
-			"workflow_status", billState.Status)
+	from, err := currency.Lookup(update.Currency)
+	if err != nil {
+		return utils.ErrCurrencyMismatch
 	}
-
-	return nil
-}
-
-func (h *AddLineItemHandler) buildSignal(lineItemUUID string, req *dto.AddLineItemRequest) tbill.AddLineItemSignal {
-	return tbill.AddLineItemSignal{
-		UUID:           lineItemUUID,
-		IdempotencyKey: req.IdempotencyKey,
-		FeeType:        req.FeeType,
-		Description:    req.Description,
-		AmountCents:    req.Amount.Amount,
+	to, err := currency.Lookup(billCurrency)
+	if err != nil {
+		return utils.ErrCurrencyMismatch
 	}
-}
 
-func (h *AddLineItemHandler) signalWorkflow(ctx context.Context, workflowID, billUUID string, signal tbill.AddLineItemSignal) error {
-	err := h.TemporalClient.SignalWorkflow(ctx, workflowID, "", tbill.SignalAddLineItem, signal)
+	rate, err := h.FXRates.Rate(ctx, from.Code, to.Code)
 	if err != nil {
-		var notFound *serviceerror.NotFound
-		if errors.As(err, &notFound) {
-			rlog.Warn("workflow completed between query and signal",
-				"bill_uuid", billUUID)
-			return utils.ErrBillClosed
+		if errors.Is(err, currency.ErrRateUnavailable) {
+			return utils.ErrFXRateUnavailable
 		}
+		slog.ErrorContext(ctx, "error fetching fx rate",
+			"from", from.Code,
+			"to", to.Code,
+			"err", err.Error())
+		return utils.ErrInternal
+	}
+
+	converted := currency.Convert(update.AmountCents, from, to, rate)
+	if converted <= 0 {
+		return utils.ErrValidationFailedWithDetails([]utils.ValidationError{utils.ErrInvalidAmount})
+	}
 
-		rlog.Error("failed to signal workflow",
-			"bill_uuid", billUUID,
-			"error", err)
-		return utils.ErrWorkflowSignalFailed
+	update.FX = &tbill.FXConversion{
+		OriginalAmountCents: update.AmountCents,
+		OriginalCurrency:    from.Code,
+		Rate:                currency.FormatRate(rate),
 	}
+	update.AmountCents = converted
+	update.Currency = to.Code
 	return nil
 }
 
-func (h *AddLineItemHandler) buildPendingResponse(lineItemUUID string, req *dto.AddLineItemRequest) *dto.AddLineItemResponse {
-	return &dto.AddLineItemResponse{
-		UUID:    lineItemUUID,
-		FeeType: req.FeeType,
+func mapLineItemToAddResponse(li *entity.LineItemEntity, currency string) *dto.AddLineItemResponse {
+	resp := &dto.AddLineItemResponse{
+		UUID:    li.UUID,
+		FeeType: li.FeeType,
 		Amount: dto.Money{
-			Amount:   req.Amount.Amount,
-			Currency: req.Amount.Currency,
+			Amount:   li.AmountCents,
+			Currency: currency,
 		},
-		Status:    "pending",
-		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Status:    entity.LineItemStatusPersisted.String(),
+		CreatedAt: li.CreatedAt.Format(time.RFC3339),
+	}
+	resp.Original, resp.FXRate = mapLineItemConversion(li)
+	return resp
+}
+
+// mapLineItemConversion returns what a converted line item was submitted as
+// and its rate, nil for items added in the bill currency.
+func mapLineItemConversion(li *entity.LineItemEntity) (*dto.Money, string) {
+	if li.OriginalAmountCents == nil || li.OriginalCurrency == nil || li.FXRate == nil {
+		return nil, ""
 	}
+	return &dto.Money{Amount: *li.OriginalAmountCents, Currency: *li.OriginalCurrency}, *li.FXRate
 }
 
 func validateAddLineItem(req *dto.AddLineItemRequest) []utils.ValidationError {
@@ -181,11 +185,13 @@ func validateAddLineItem(req *dto.AddLineItemRequest) []utils.ValidationError {
 	}
 	if req.FeeType == "" {
 		validationErrors = append(validationErrors, utils.ErrInvalidFeeType)
+	} else if entity.FeeType(req.FeeType).AppliedAtClose() {
+		validationErrors = append(validationErrors, utils.ErrFeeTypeAppliedAtClose)
 	}
 	if req.Amount.Amount <= 0 {
 		validationErrors = append(validationErrors, utils.ErrInvalidAmount)
 	}
-	if req.Amount.Currency == "" {
+	if !currency.IsValid(req.Amount.Currency) {
 		validationErrors = append(validationErrors, utils.ErrInvalidCurrency)
 	}
 
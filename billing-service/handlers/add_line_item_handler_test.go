@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
+	"encore.app/currency"
 	"encore.app/db/repository/mocks"
 	"encore.app/dto"
 	"encore.app/entity"
@@ -16,9 +18,35 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 	"go.uber.org/mock/gomock"
 )
 
+// mockUpdateHandle implements client.WorkflowUpdateHandle for testing UpdateWorkflow
+type mockUpdateHandle struct {
+	value interface{} // a pointer to the update's outcome
+	err   error
+}
+
+func (m *mockUpdateHandle) WorkflowID() string { return "" }
+func (m *mockUpdateHandle) RunID() string      { return "" }
+func (m *mockUpdateHandle) UpdateID() string   { return "" }
+
+func (m *mockUpdateHandle) Get(ctx context.Context, valuePtr interface{}) error {
+	if m.err != nil {
+		return m.err
+	}
+	if v := reflect.ValueOf(m.value); v.IsValid() && !v.IsNil() {
+		reflect.ValueOf(valuePtr).Elem().Set(v.Elem())
+	}
+	return nil
+}
+
+func newMockUpdateHandle(value *entity.LineItemEntity, err error) client.WorkflowUpdateHandle {
+	return &mockUpdateHandle{value: value, err: err}
+}
+
 func TestAddLineItemHandler_Handle(t *testing.T) {
 	t.Run("success - adds line item", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -50,12 +78,25 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
-			Return(newMockEncodedValue(tbill.BillStateQuery{Status: "OPEN"}), nil)
-
-		mockTemporalClient.EXPECT().
-			SignalWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.SignalAddLineItem, gomock.Any()).
-			Return(nil)
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				assert.Equal(t, "bill-"+billUUID, options.WorkflowID)
+				assert.Equal(t, tbill.UpdateAddLineItem, options.UpdateName)
+				assert.Equal(t, client.WorkflowUpdateStageCompleted, options.WaitForStage)
+
+				update := options.Args[0].(tbill.AddLineItemUpdate)
+				assert.Equal(t, "idem-key", update.IdempotencyKey)
+				assert.Equal(t, "USD", update.Currency)
+
+				return newMockUpdateHandle(&entity.LineItemEntity{
+					UUID:           update.UUID,
+					BillUUID:       billUUID,
+					IdempotencyKey: update.IdempotencyKey,
+					FeeType:        update.FeeType,
+					AmountCents:    update.AmountCents,
+					CreatedAt:      time.Now(),
+				}, nil), nil
+			})
 
 		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
 			BillUUID:       billUUID,
@@ -73,7 +114,7 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		assert.Equal(t, "TRANSACTION", resp.FeeType)
 		assert.Equal(t, int64(1000), resp.Amount.Amount)
 		assert.Equal(t, "USD", resp.Amount.Currency)
-		assert.Equal(t, "pending", resp.Status)
+		assert.Equal(t, "persisted", resp.Status)
 	})
 
 	t.Run("error - validation fails - missing bill UUID", func(t *testing.T) {
@@ -124,6 +165,30 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 
+	t.Run("error - validation fails - fee type applied at close", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &AddLineItemHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			LineItemRepo:   mocks.NewMockLineItemRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
+			BillUUID:       "bill-123",
+			IdempotencyKey: "idem-key",
+			FeeType:        "DISCOUNT",
+			Amount: dto.Money{
+				Amount:   1000,
+				Currency: "USD",
+			},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
 	t.Run("error - validation fails - invalid amount", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -241,6 +306,10 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			FetchByUUID(gomock.Any(), "bill-123").
 			Return(bill, nil)
 
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
 		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
 			BillUUID:       "bill-123",
 			IdempotencyKey: "idem-key",
@@ -255,6 +324,183 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		assert.Equal(t, utils.ErrCurrencyMismatch, err)
 	})
 
+	t.Run("success - converts line item into the bill currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &AddLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: mockTemporalClient,
+			FXRates:        currency.StaticRates{"EUR/USD": "1.08"},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				update := options.Args[0].(tbill.AddLineItemUpdate)
+				assert.Equal(t, int64(1080), update.AmountCents)
+				assert.Equal(t, "USD", update.Currency)
+				require.NotNil(t, update.FX)
+				assert.Equal(t, int64(1000), update.FX.OriginalAmountCents)
+				assert.Equal(t, "EUR", update.FX.OriginalCurrency)
+				assert.Equal(t, "1.08", update.FX.Rate)
+
+				return newMockUpdateHandle(&entity.LineItemEntity{
+					UUID:                update.UUID,
+					BillUUID:            "bill-123",
+					IdempotencyKey:      update.IdempotencyKey,
+					FeeType:             update.FeeType,
+					AmountCents:         update.AmountCents,
+					OriginalAmountCents: &update.FX.OriginalAmountCents,
+					OriginalCurrency:    &update.FX.OriginalCurrency,
+					FXRate:              &update.FX.Rate,
+					CreatedAt:           time.Now(),
+				}, nil), nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
+			BillUUID:        "bill-123",
+			IdempotencyKey:  "idem-key",
+			FeeType:         "TRANSACTION",
+			Amount:          dto.Money{Amount: 1000, Currency: "EUR"},
+			ConvertCurrency: true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, dto.Money{Amount: 1080, Currency: "USD"}, resp.Amount)
+		require.NotNil(t, resp.Original)
+		assert.Equal(t, dto.Money{Amount: 1000, Currency: "EUR"}, *resp.Original)
+		assert.Equal(t, "1.08", resp.FXRate)
+	})
+
+	t.Run("error - currency mismatch without conversion requested", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		handler := &AddLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+			FXRates:        currency.StaticRates{"EUR/USD": "1.08"},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
+			BillUUID:       "bill-123",
+			IdempotencyKey: "idem-key",
+			FeeType:        "TRANSACTION",
+			Amount:         dto.Money{Amount: 1000, Currency: "EUR"},
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCurrencyMismatch, err)
+	})
+
+	t.Run("error - fx rate unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		handler := &AddLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+			FXRates:        currency.StaticRates{"EUR/USD": "1.08"},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
+			BillUUID:        "bill-123",
+			IdempotencyKey:  "idem-key",
+			FeeType:         "TRANSACTION",
+			Amount:          dto.Money{Amount: 1000, Currency: "JPY"},
+			ConvertCurrency: true,
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrFXRateUnavailable, err)
+	})
+
+	t.Run("idempotent - converted replay skips the fx rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		// no rate for JPY, the replay must not need one
+		handler := &AddLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+			FXRates:        currency.StaticRates{"EUR/USD": "1.08"},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		originalAmount, originalCurrency, rate := int64(1000), "JPY", "0.0067"
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(&entity.LineItemEntity{
+				UUID:                "item-1",
+				BillUUID:            "bill-123",
+				IdempotencyKey:      "idem-key",
+				FeeType:             "TRANSACTION",
+				AmountCents:         7,
+				OriginalAmountCents: &originalAmount,
+				OriginalCurrency:    &originalCurrency,
+				FXRate:              &rate,
+				CreatedAt:           time.Now(),
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
+			BillUUID:        "bill-123",
+			IdempotencyKey:  "idem-key",
+			FeeType:         "TRANSACTION",
+			Amount:          dto.Money{Amount: 1000, Currency: "JPY"},
+			ConvertCurrency: true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "item-1", resp.UUID)
+		assert.Equal(t, dto.Money{Amount: 7, Currency: "USD"}, resp.Amount)
+		assert.Equal(t, rate, resp.FXRate)
+	})
+
 	t.Run("idempotent - returns existing line item", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -309,7 +555,7 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		assert.Equal(t, "persisted", resp.Status)
 	})
 
-	t.Run("error - workflow not found", func(t *testing.T) {
+	t.Run("error - workflow completed before the update", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -339,7 +585,7 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
 			Return(nil, &serviceerror.NotFound{})
 
 		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
@@ -353,10 +599,10 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		})
 
 		assert.Nil(t, resp)
-		assert.Equal(t, utils.ErrWorkflowNotFound, err)
+		assert.Equal(t, utils.ErrBillClosed, err)
 	})
 
-	t.Run("error - workflow query failed", func(t *testing.T) {
+	t.Run("error - workflow update failed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -386,7 +632,7 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
 			Return(nil, assert.AnError)
 
 		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
@@ -400,10 +646,10 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		})
 
 		assert.Nil(t, resp)
-		assert.Equal(t, utils.ErrWorkflowQueryFailed, err)
+		assert.Equal(t, utils.ErrWorkflowUpdateFailed, err)
 	})
 
-	t.Run("error - workflow signal failed", func(t *testing.T) {
+	t.Run("error - update rejected because bill is closing", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -432,13 +678,57 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
 			Return(nil, sqldb.ErrNoRows)
 
+		// Workflow accepted the close signal after the DB check
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
-			Return(newMockEncodedValue(tbill.BillStateQuery{Status: "OPEN"}), nil)
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(newMockUpdateHandle(nil, temporal.NewApplicationError("closed", tbill.ErrTypeBillClosed)), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "idem-key",
+			FeeType:        "TRANSACTION",
+			Amount: dto.Money{
+				Amount:   1000,
+				Currency: "USD",
+			},
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillClosed, err)
+	})
+
+	t.Run("error - persisting line item failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &AddLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		billUUID := "bill-123"
+		bill := &entity.BillEntity{
+			UUID:     billUUID,
+			Status:   "OPEN",
+			Currency: "USD",
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(bill, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
+			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			SignalWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.SignalAddLineItem, gomock.Any()).
-			Return(assert.AnError)
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(newMockUpdateHandle(nil, temporal.NewApplicationError("insert failed", "")), nil)
 
 		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
 			BillUUID:       billUUID,
@@ -451,10 +741,10 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 		})
 
 		assert.Nil(t, resp)
-		assert.Equal(t, utils.ErrWorkflowSignalFailed, err)
+		assert.Equal(t, utils.ErrWorkflowUpdateFailed, err)
 	})
 
-	t.Run("error - workflow completed between query and signal", func(t *testing.T) {
+	t.Run("idempotent - concurrent request with same key returns existing", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -483,14 +773,19 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
 			Return(nil, sqldb.ErrNoRows)
 
+		// A concurrent request persisted the same idempotency key first
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
-			Return(newMockEncodedValue(tbill.BillStateQuery{Status: "OPEN"}), nil)
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(newMockUpdateHandle(nil, temporal.NewApplicationError("duplicate", tbill.ErrTypeDuplicateKey)), nil)
 
-		// Workflow completed between query and signal
-		mockTemporalClient.EXPECT().
-			SignalWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.SignalAddLineItem, gomock.Any()).
-			Return(&serviceerror.NotFound{})
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
+			Return(&entity.LineItemEntity{
+				UUID:        "existing-line-item",
+				FeeType:     "TRANSACTION",
+				AmountCents: 1000,
+				CreatedAt:   time.Now(),
+			}, nil)
 
 		resp, err := handler.Handle(context.Background(), &dto.AddLineItemRequest{
 			BillUUID:       billUUID,
@@ -502,7 +797,8 @@ func TestAddLineItemHandler_Handle(t *testing.T) {
 			},
 		})
 
-		assert.Nil(t, resp)
-		assert.Equal(t, utils.ErrBillClosed, err)
+		require.NoError(t, err)
+		assert.Equal(t, "existing-line-item", resp.UUID)
+		assert.Equal(t, "persisted", resp.Status)
 	})
 }
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+)
+
+type ApplyPromoCodeHandler struct {
+	BillRepo      repository.BillRepository
+	PromotionRepo repository.PromotionRepository
+}
+
+// Handle redeems a promo code on an open bill. The redemption counts against
+// the code's usage limit right away; the discount is computed and added as a
+// line item when the bill closes. Applying a code twice to a bill is a no-op.
+func (h *ApplyPromoCodeHandler) Handle(ctx context.Context, req *dto.ApplyPromoCodeRequest) (*dto.ApplyPromoCodeResponse, error) {
+	if validationErrors := validateApplyPromoCode(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+	if !bill.IsOpen() {
+		return nil, utils.ErrBillClosed
+	}
+
+	code := normalizePromoCode(req.Code)
+	promotion, err := h.PromotionRepo.FetchByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrPromotionNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+	if promotion.Currency != nil && *promotion.Currency != bill.Currency {
+		return nil, utils.ErrCurrencyMismatch
+	}
+
+	if err := h.PromotionRepo.Redeem(ctx, bill.UUID, promotion.UUID, time.Now().UTC()); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrBillNotOpen):
+			return nil, utils.ErrBillClosed
+		case errors.Is(err, entity.ErrPromotionExpired):
+			return nil, utils.ErrPromotionExpired
+		case errors.Is(err, entity.ErrPromotionExhausted):
+			return nil, utils.ErrPromotionExhausted
+		}
+		slog.ErrorContext(ctx, "error redeeming promotion",
+			"bill_uuid", bill.UUID,
+			"code", code,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.ApplyPromoCodeResponse{
+		BillUUID:  bill.UUID,
+		Promotion: mapPromotionToSummary(promotion),
+	}, nil
+}
+
+func validateApplyPromoCode(req *dto.ApplyPromoCodeRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.BillUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidBillUUID)
+	}
+	if normalizePromoCode(req.Code) == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidPromoCode)
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestApplyPromoCodeHandler_Handle(t *testing.T) {
+	openBill := &entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}
+	percentBps := int64(1000)
+	percentPromotion := &entity.PromotionEntity{
+		UUID:       "promo-123",
+		Code:       "SPRING10",
+		Kind:       entity.PromotionKindPercent,
+		PercentBps: &percentBps,
+	}
+
+	t.Run("success - redeems promo code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &ApplyPromoCodeHandler{
+			BillRepo:      mockBillRepo,
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(openBill, nil)
+
+		mockPromotionRepo.EXPECT().
+			FetchByCode(gomock.Any(), "SPRING10").
+			Return(percentPromotion, nil)
+
+		mockPromotionRepo.EXPECT().
+			Redeem(gomock.Any(), "bill-123", "promo-123", gomock.Any()).
+			Return(nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ApplyPromoCodeRequest{
+			BillUUID: "bill-123",
+			Code:     "spring10",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "bill-123", resp.BillUUID)
+		assert.Equal(t, "SPRING10", resp.Promotion.Code)
+		assert.Equal(t, int64(1000), resp.Promotion.PercentOffBps)
+	})
+
+	t.Run("error - validation fails - missing code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ApplyPromoCodeHandler{
+			BillRepo:      mocks.NewMockBillRepository(ctrl),
+			PromotionRepo: mocks.NewMockPromotionRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.ApplyPromoCodeRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &ApplyPromoCodeHandler{
+			BillRepo:      mockBillRepo,
+			PromotionRepo: mocks.NewMockPromotionRepository(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ApplyPromoCodeRequest{
+			BillUUID: "bill-123",
+			Code:     "SPRING10",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillClosed, err)
+	})
+
+	t.Run("error - promotion not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &ApplyPromoCodeHandler{
+			BillRepo:      mockBillRepo,
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(openBill, nil)
+
+		mockPromotionRepo.EXPECT().
+			FetchByCode(gomock.Any(), "NOPE").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ApplyPromoCodeRequest{
+			BillUUID: "bill-123",
+			Code:     "NOPE",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrPromotionNotFound, err)
+	})
+
+	t.Run("error - fixed discount in another currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &ApplyPromoCodeHandler{
+			BillRepo:      mockBillRepo,
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		amountCents := int64(500)
+		gel := "GEL"
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(openBill, nil)
+
+		mockPromotionRepo.EXPECT().
+			FetchByCode(gomock.Any(), "LARI5").
+			Return(&entity.PromotionEntity{
+				UUID:        "promo-456",
+				Code:        "LARI5",
+				Kind:        entity.PromotionKindFixed,
+				AmountCents: &amountCents,
+				Currency:    &gel,
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ApplyPromoCodeRequest{
+			BillUUID: "bill-123",
+			Code:     "LARI5",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCurrencyMismatch, err)
+	})
+
+	t.Run("error - redemption rejected", func(t *testing.T) {
+		cases := map[error]error{
+			entity.ErrPromotionExpired:   utils.ErrPromotionExpired,
+			entity.ErrPromotionExhausted: utils.ErrPromotionExhausted,
+			entity.ErrBillNotOpen:        utils.ErrBillClosed,
+			assert.AnError:               utils.ErrInternal,
+		}
+		for redeemErr, wantErr := range cases {
+			ctrl := gomock.NewController(t)
+
+			mockBillRepo := mocks.NewMockBillRepository(ctrl)
+			mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+			handler := &ApplyPromoCodeHandler{
+				BillRepo:      mockBillRepo,
+				PromotionRepo: mockPromotionRepo,
+			}
+
+			mockBillRepo.EXPECT().
+				FetchByUUID(gomock.Any(), "bill-123").
+				Return(openBill, nil)
+
+			mockPromotionRepo.EXPECT().
+				FetchByCode(gomock.Any(), "SPRING10").
+				Return(percentPromotion, nil)
+
+			mockPromotionRepo.EXPECT().
+				Redeem(gomock.Any(), "bill-123", "promo-123", gomock.Any()).
+				Return(redeemErr)
+
+			resp, err := handler.Handle(context.Background(), &dto.ApplyPromoCodeRequest{
+				BillUUID: "bill-123",
+				Code:     "SPRING10",
+			})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, wantErr, err, redeemErr.Error())
+			ctrl.Finish()
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+	"encore.dev/storage/sqldb"
+)
+
+type ArchiveCustomerHandler struct {
+	CustomerRepo repository.CustomerRepository
+}
+
+// Handle archives a customer. Their bills stay as they are, open ones close
+// as usual, but no new bills are created for them and their email is free for
+// another customer. Archiving again returns the archived customer.
+func (h *ArchiveCustomerHandler) Handle(ctx context.Context, req *dto.ArchiveCustomerRequest) (*dto.CustomerSummary, error) {
+	if req.UUID == "" {
+		return nil, utils.ErrUUIDMissing
+	}
+
+	customer, err := h.CustomerRepo.Archive(ctx, req.UUID, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrNoRows):
+			return nil, utils.ErrCustomerNotFoundAPI
+		case errors.Is(err, entity.ErrCustomerMerged):
+			return nil, utils.ErrCustomerMerged
+		}
+		slog.ErrorContext(ctx, "error archiving customer",
+			"uuid", req.UUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	summary := mapCustomerToSummary(customer)
+	return &summary, nil
+}
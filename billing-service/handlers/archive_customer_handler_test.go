@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestArchiveCustomerHandler_Handle(t *testing.T) {
+	t.Run("success - archives the customer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ArchiveCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		archivedAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		mockCustomerRepo.EXPECT().
+			Archive(gomock.Any(), "customer-123", gomock.Any()).
+			Return(&entity.CustomerEntity{
+				UUID:       "customer-123",
+				Status:     entity.CustomerStatusArchived,
+				ArchivedAt: &archivedAt,
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ArchiveCustomerRequest{UUID: "customer-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "customer-123", resp.UUID)
+		assert.Equal(t, "ARCHIVED", resp.Status)
+		assert.Equal(t, &archivedAt, resp.ArchivedAt)
+	})
+
+	t.Run("error - missing uuid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ArchiveCustomerHandler{CustomerRepo: mocks.NewMockCustomerRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ArchiveCustomerRequest{})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrUUIDMissing, err)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ArchiveCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Archive(gomock.Any(), "customer-123", gomock.Any()).
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ArchiveCustomerRequest{UUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerNotFoundAPI, err)
+	})
+
+	t.Run("error - customer merged", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ArchiveCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Archive(gomock.Any(), "customer-123", gomock.Any()).
+			Return(nil, entity.ErrCustomerMerged)
+
+		resp, err := handler.Handle(context.Background(), &dto.ArchiveCustomerRequest{UUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerMerged, err)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ArchiveCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Archive(gomock.Any(), "customer-123", gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.ArchiveCustomerRequest{UUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
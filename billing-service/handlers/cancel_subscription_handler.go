@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+
+	"encore.dev/storage/sqldb"
+)
+
+type CancelSubscriptionHandler struct {
+	SubscriptionRepo repository.SubscriptionRepository
+	BillRepo         repository.BillRepository
+	TemporalClient   t.WorkflowClient
+}
+
+// Handle ends a subscription. The customer's open bills credit back what they
+// charged for the time after the end.
+func (h *CancelSubscriptionHandler) Handle(ctx context.Context, req *dto.CancelSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	if validationErrors := validateCancelSubscription(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	subscription, err := h.SubscriptionRepo.FetchByUUID(ctx, req.SubscriptionUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrSubscriptionNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+	if subscription.EndedAt != nil {
+		return nil, utils.ErrSubscriptionEnded
+	}
+
+	endsAt := time.Now().UTC()
+	if req.EndsAt != "" {
+		endsAt, _ = time.Parse(time.RFC3339, req.EndsAt)
+	}
+	if !endsAt.After(subscription.StartedAt) {
+		return nil, utils.ErrValidationFailedWithDetails([]utils.ValidationError{utils.ErrInvalidEndsAt})
+	}
+
+	ended, err := h.SubscriptionRepo.End(ctx, subscription.UUID, endsAt)
+	if err != nil {
+		if errors.Is(err, entity.ErrSubscriptionEnded) {
+			return nil, utils.ErrSubscriptionEnded
+		}
+		slog.ErrorContext(ctx, "error cancelling subscription",
+			"uuid", subscription.UUID,
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	signalSubscriptionChange(ctx, h.BillRepo, h.TemporalClient, ended.CustomerUUID, ended.UUID)
+
+	return mapSubscriptionToResponse(ended), nil
+}
+
+func validateCancelSubscription(req *dto.CancelSubscriptionRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.SubscriptionUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidSubscriptionID)
+	}
+	if req.EndsAt != "" {
+		if _, err := time.Parse(time.RFC3339, req.EndsAt); err != nil {
+			validationErrors = append(validationErrors, utils.ErrInvalidEndsAt)
+		}
+	}
+
+	return validationErrors
+}
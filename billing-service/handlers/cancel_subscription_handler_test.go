@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db"
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCancelSubscriptionHandler_Handle(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success - ends subscription and signals open bills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CancelSubscriptionHandler{
+			SubscriptionRepo: mockSubscriptionRepo,
+			BillRepo:         mockBillRepo,
+			TemporalClient:   mockTemporalClient,
+		}
+
+		endsAt := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+		mockSubscriptionRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "sub-123").
+			Return(&entity.SubscriptionEntity{UUID: "sub-123", Quantity: 1, StartedAt: startedAt}, nil)
+
+		mockSubscriptionRepo.EXPECT().
+			End(gomock.Any(), "sub-123", endsAt).
+			Return(&entity.SubscriptionEntity{UUID: "sub-123", CustomerUUID: "cust-123", Quantity: 1, StartedAt: startedAt, EndedAt: &endsAt}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchAll(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params db.BillQueryParams) ([]*entity.BillEntity, error) {
+				assert.Equal(t, "cust-123", params.CustomerUUID)
+				assert.Equal(t, "OPEN", params.Status)
+				return []*entity.BillEntity{{UUID: "bill-123"}}, nil
+			})
+
+		mockTemporalClient.EXPECT().
+			SignalWorkflow(gomock.Any(), "bill-bill-123", "", tbill.SignalSubscriptionChanged,
+				tbill.SubscriptionChangedSignal{SubscriptionUUID: "sub-123"}).
+			Return(nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CancelSubscriptionRequest{
+			SubscriptionUUID: "sub-123",
+			EndsAt:           "2024-03-15T00:00:00Z",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "sub-123", resp.UUID)
+		assert.Equal(t, "2024-03-15T00:00:00Z", resp.EndedAt)
+	})
+
+	t.Run("error - validation fails - missing uuid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CancelSubscriptionHandler{SubscriptionRepo: mocks.NewMockSubscriptionRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.CancelSubscriptionRequest{})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - subscription not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+
+		handler := &CancelSubscriptionHandler{SubscriptionRepo: mockSubscriptionRepo}
+
+		mockSubscriptionRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "sub-404").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.CancelSubscriptionRequest{SubscriptionUUID: "sub-404"})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrSubscriptionNotFound)
+	})
+
+	t.Run("error - already ended", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+
+		handler := &CancelSubscriptionHandler{SubscriptionRepo: mockSubscriptionRepo}
+
+		endedAt := startedAt.AddDate(0, 1, 0)
+		mockSubscriptionRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "sub-123").
+			Return(&entity.SubscriptionEntity{UUID: "sub-123", StartedAt: startedAt, EndedAt: &endedAt}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CancelSubscriptionRequest{SubscriptionUUID: "sub-123"})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrSubscriptionEnded)
+	})
+
+	t.Run("error - ends before it starts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+
+		handler := &CancelSubscriptionHandler{SubscriptionRepo: mockSubscriptionRepo}
+
+		mockSubscriptionRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "sub-123").
+			Return(&entity.SubscriptionEntity{UUID: "sub-123", StartedAt: startedAt}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CancelSubscriptionRequest{
+			SubscriptionUUID: "sub-123",
+			EndsAt:           "2023-12-31T00:00:00Z",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+}
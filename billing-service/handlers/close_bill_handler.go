@@ -6,6 +6,7 @@ import (
 
 	"encore.app/db/repository"
 	"encore.app/dto"
+	"encore.app/entity"
 	t "encore.app/temporal"
 	tbill "encore.app/temporal/bill"
 	"encore.app/utils"
@@ -32,7 +33,18 @@ func (h *CloseBillHandler) Handle(ctx context.Context, req *dto.CloseBillRequest
 		return nil, utils.ErrInternal
 	}
 
-	if !bill.IsOpen() {
+	status := entity.BillStatus(bill.Status)
+
+	// the close was already accepted, the workflow is draining line items
+	if status == entity.BillStatusClosing {
+		return &dto.CloseBillResponse{
+			UUID:    req.UUID,
+			Status:  entity.BillStatusClosing.String(),
+			Message: "Bill close already in progress. Poll GET /v1/bill/get for final state.",
+		}, nil
+	}
+
+	if !status.CanTransitionTo(entity.BillStatusClosing) {
 		return nil, utils.ErrBillAlreadyClosedAPI
 	}
 
@@ -49,7 +61,7 @@ func (h *CloseBillHandler) Handle(ctx context.Context, req *dto.CloseBillRequest
 
 	return &dto.CloseBillResponse{
 		UUID:    req.UUID,
-		Status:  "CLOSING",
+		Status:  entity.BillStatusClosing.String(),
 		Message: "Bill close initiated. Poll GET /v1/bill/get for final state.",
 	}, nil
 }
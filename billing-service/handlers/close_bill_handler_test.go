@@ -119,6 +119,55 @@ func TestCloseBillHandler_Handle(t *testing.T) {
 		assert.Equal(t, utils.ErrBillAlreadyClosedAPI, err)
 	})
 
+	t.Run("success - close already in progress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &CloseBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		billUUID := "bill-123"
+		bill := &entity.BillEntity{
+			UUID:   billUUID,
+			Status: "CLOSING",
+		}
+
+		// no signal expected, the workflow already accepted the close
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(bill, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CloseBillRequest{UUID: billUUID})
+
+		require.NoError(t, err)
+		assert.Equal(t, "CLOSING", resp.Status)
+	})
+
+	t.Run("error - voided bill cannot be closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &CloseBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "VOIDED"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CloseBillRequest{UUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillAlreadyClosedAPI, err)
+	})
+
 	t.Run("error - workflow not found", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
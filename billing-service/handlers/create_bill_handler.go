@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"encore.app/currency"
 	"encore.app/db/repository"
 	"encore.app/dto"
 	"encore.app/entity"
@@ -23,6 +24,19 @@ type CreateBillHandler struct {
 	BillRepo       repository.BillRepository
 	CustomerRepo   repository.CustomerRepository
 	TemporalClient t.WorkflowClient
+
+	// LargeChargeCents is handed to the bill workflow, see BillWorkflowInput
+	LargeChargeCents int64
+
+	// PaymentTermsDays is handed to the bill workflow, see BillWorkflowInput
+	PaymentTermsDays int
+
+	// Settlement holds the account and task queue bills are settled into, an
+	// empty target account disables settlement
+	Settlement tbill.SettlementAccounts
+
+	// DefaultCurrency is used when neither the request nor the customer names one
+	DefaultCurrency string
 }
 
 func (h *CreateBillHandler) Handle(ctx context.Context, req *dto.CreateBillRequest) (*dto.CreateBillResponse, error) {
@@ -30,8 +44,12 @@ func (h *CreateBillHandler) Handle(ctx context.Context, req *dto.CreateBillReque
 		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
 	}
 
+	if req.SettlementAccount != "" && h.Settlement.TargetAccount == "" {
+		return nil, utils.ErrSettlementDisabled
+	}
+
 	// check if customer exists
-	_, err := h.CustomerRepo.FetchByUUID(ctx, req.CustomerUUID)
+	customer, err := h.CustomerRepo.FetchByUUID(ctx, req.CustomerUUID)
 	if err != nil {
 		if errors.Is(err, sqldb.ErrNoRows) {
 			return nil, utils.ErrCustomerNotFoundAPI
@@ -39,6 +57,11 @@ func (h *CreateBillHandler) Handle(ctx context.Context, req *dto.CreateBillReque
 		return nil, utils.ErrInternal
 	}
 
+	billCurrency := h.resolveCurrency(req, customer)
+	if !currency.IsValid(billCurrency) {
+		return nil, utils.ErrValidationFailedWithDetails([]utils.ValidationError{utils.ErrInvalidCurrency})
+	}
+
 	existing, err := h.BillRepo.FetchByUUID(ctx, req.UUID)
 	if err != nil && !errors.Is(err, sqldb.ErrNoRows) {
 		return nil, utils.ErrInternal
@@ -50,18 +73,25 @@ func (h *CreateBillHandler) Handle(ctx context.Context, req *dto.CreateBillReque
 			Currency:    existing.Currency,
 			PeriodStart: existing.PeriodStart.Format(time.RFC3339),
 			PeriodEnd:   existing.PeriodEnd.Format(time.RFC3339),
+			Recurrence:  mapRecurrenceToDTO(existing.Recurrence),
 		}, nil
 	}
 
+	// archived and merged customers keep their bills but take no new ones
+	if err := customer.Status.NotActiveErr(); err != nil {
+		return nil, customerStatusError(err)
+	}
+
 	periodStart, _ := time.Parse(time.RFC3339, req.PeriodStart)
 	periodEnd, _ := time.Parse(time.RFC3339, req.PeriodEnd)
 
 	bill := &entity.BillEntity{
 		UUID:         req.UUID,
 		CustomerUUID: req.CustomerUUID,
-		Currency:     req.Currency,
+		Currency:     billCurrency,
 		PeriodStart:  periodStart,
 		PeriodEnd:    periodEnd,
+		Recurrence:   mapRecurrence(req.Recurrence, periodEnd),
 	}
 
 	if err := h.BillRepo.Insert(ctx, bill); err != nil {
@@ -73,18 +103,26 @@ func (h *CreateBillHandler) Handle(ctx context.Context, req *dto.CreateBillReque
 		TaskQueue: t.TaskQueue,
 	}
 	_, err = h.TemporalClient.ExecuteWorkflow(ctx, workflowOptions, tbill.BillWorkflow, tbill.BillWorkflowInput{
-		BillUUID:  req.UUID,
-		PeriodEnd: periodEnd,
+		BillUUID:     req.UUID,
+		CustomerUUID: req.CustomerUUID,
+		Currency:     billCurrency,
+		PeriodEnd:    periodEnd,
+		Recurrence:   bill.Recurrence,
+
+		LargeChargeCents: h.LargeChargeCents,
+		PaymentTermsDays: h.PaymentTermsDays,
+		Settlement:       h.settlementAccounts(req),
 	})
 	if err != nil {
 		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
 		if errors.As(err, &alreadyStarted) {
 			return &dto.CreateBillResponse{
 				UUID:        req.UUID,
-				Status:      "OPEN",
-				Currency:    req.Currency,
+				Status:      entity.BillStatusOpen.String(),
+				Currency:    billCurrency,
 				PeriodStart: req.PeriodStart,
 				PeriodEnd:   req.PeriodEnd,
+				Recurrence:  mapRecurrenceToDTO(bill.Recurrence),
 			}, nil
 		}
 		rlog.Error("workflow start failed",
@@ -95,13 +133,54 @@ func (h *CreateBillHandler) Handle(ctx context.Context, req *dto.CreateBillReque
 
 	return &dto.CreateBillResponse{
 		UUID:        req.UUID,
-		Status:      "OPEN",
-		Currency:    req.Currency,
+		Status:      entity.BillStatusOpen.String(),
+		Currency:    billCurrency,
 		PeriodStart: req.PeriodStart,
 		PeriodEnd:   req.PeriodEnd,
+		Recurrence:  mapRecurrenceToDTO(bill.Recurrence),
 	}, nil
 }
 
+func mapRecurrence(r *dto.BillRecurrence, periodEnd time.Time) *entity.BillRecurrence {
+	if r == nil {
+		return nil
+	}
+	return entity.NewBillRecurrence(entity.BillCadence(r.Cadence), r.IntervalDays, periodEnd)
+}
+
+func mapRecurrenceToDTO(r *entity.BillRecurrence) *dto.BillRecurrence {
+	if r == nil {
+		return nil
+	}
+	return &dto.BillRecurrence{
+		Cadence:      r.Cadence.String(),
+		IntervalDays: r.IntervalDays,
+	}
+}
+
+// resolveCurrency picks the bill currency: the requested one, else the
+// customer's default, else the billing currency.
+func (h *CreateBillHandler) resolveCurrency(req *dto.CreateBillRequest, customer *entity.CustomerEntity) string {
+	switch {
+	case req.Currency != "":
+		return req.Currency
+	case customer.Currency != nil:
+		return *customer.Currency
+	}
+	return h.DefaultCurrency
+}
+
+// settlementAccounts returns the accounts the bill is settled between, nil when
+// the customer pays it themselves.
+func (h *CreateBillHandler) settlementAccounts(req *dto.CreateBillRequest) *tbill.SettlementAccounts {
+	if req.SettlementAccount == "" {
+		return nil
+	}
+	accounts := h.Settlement
+	accounts.SourceAccount = req.SettlementAccount
+	return &accounts
+}
+
 func validateCreateBill(req *dto.CreateBillRequest) []utils.ValidationError {
 	var validationErrors []utils.ValidationError
 
@@ -111,7 +190,7 @@ func validateCreateBill(req *dto.CreateBillRequest) []utils.ValidationError {
 	if req.CustomerUUID == "" {
 		validationErrors = append(validationErrors, utils.ErrInvalidCustomerUUID)
 	}
-	if req.Currency == "" || (req.Currency != "USD" && req.Currency != "GEL") {
+	if req.Currency != "" && !currency.IsValid(req.Currency) {
 		validationErrors = append(validationErrors, utils.ErrInvalidCurrency)
 	}
 	if req.PeriodStart == "" {
@@ -135,5 +214,15 @@ func validateCreateBill(req *dto.CreateBillRequest) []utils.ValidationError {
 		}
 	}
 
+	if req.Recurrence != nil {
+		cadence := entity.BillCadence(req.Recurrence.Cadence)
+		if !cadence.IsValid() {
+			validationErrors = append(validationErrors, utils.ErrInvalidCadence)
+		}
+		if cadence == entity.BillCadenceCustom && req.Recurrence.IntervalDays <= 0 {
+			validationErrors = append(validationErrors, utils.ErrInvalidIntervalDays)
+		}
+	}
+
 	return validationErrors
 }
@@ -8,6 +8,7 @@ import (
 	"encore.app/db/repository/mocks"
 	"encore.app/dto"
 	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
 	temporalmocks "encore.app/temporal/mocks"
 	"encore.app/utils"
 
@@ -61,7 +62,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
-			Return(&entity.CustomerEntity{UUID: customerUUID}, nil)
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
 
 		mockBillRepo.EXPECT().
 			FetchByUUID(gomock.Any(), billUUID).
@@ -124,7 +125,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
 			UUID:         "bill-123",
 			CustomerUUID: "customer-123",
-			Currency:     "EUR", // Invalid - only USD and GEL supported
+			Currency:     "XYZ", // Invalid - not an ISO-4217 code
 			PeriodStart:  "2024-01-01T00:00:00Z",
 			PeriodEnd:    "2024-01-31T23:59:59Z",
 		})
@@ -155,6 +156,111 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 
+	t.Run("success - creates recurring bill", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:       mockBillRepo,
+			CustomerRepo:   mockCustomerRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		customerUUID := "customer-123"
+		billUUID := "bill-123"
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), customerUUID).
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(nil, sqldb.ErrNoRows)
+
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, bill *entity.BillEntity) error {
+				require.NotNil(t, bill.Recurrence)
+				assert.Equal(t, entity.BillCadenceMonthly, bill.Recurrence.Cadence)
+				return nil
+			})
+
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ client.StartWorkflowOptions, _ interface{}, args ...interface{}) (client.WorkflowRun, error) {
+				input := args[0].(tbill.BillWorkflowInput)
+				require.NotNil(t, input.Recurrence)
+				assert.Equal(t, entity.BillCadenceMonthly, input.Recurrence.Cadence)
+				assert.Equal(t, 1, input.Recurrence.AnchorDay)
+				assert.Equal(t, customerUUID, input.CustomerUUID)
+				assert.Equal(t, "USD", input.Currency)
+				return &mockWorkflowRun{workflowID: "bill-" + billUUID}, nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         billUUID,
+			CustomerUUID: customerUUID,
+			Currency:     "USD",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-02-01T00:00:00Z",
+			Recurrence:   &dto.BillRecurrence{Cadence: "MONTHLY"},
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, resp.Recurrence)
+		assert.Equal(t, "MONTHLY", resp.Recurrence.Cadence)
+	})
+
+	t.Run("error - validation fails - invalid cadence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreateBillHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			CustomerRepo:   mocks.NewMockCustomerRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-02-01T00:00:00Z",
+			Recurrence:   &dto.BillRecurrence{Cadence: "YEARLY"},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - validation fails - custom cadence without interval", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreateBillHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			CustomerRepo:   mocks.NewMockCustomerRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-02-01T00:00:00Z",
+			Recurrence:   &dto.BillRecurrence{Cadence: "CUSTOM"},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
 	t.Run("error - customer not found", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -185,6 +291,74 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 		assert.Equal(t, utils.ErrCustomerNotFoundAPI, err)
 	})
 
+	t.Run("error - customer archived", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:       mockBillRepo,
+			CustomerRepo:   mockCustomerRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusArchived}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-01-31T23:59:59Z",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerArchived, err)
+	})
+
+	t.Run("error - customer merged", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:       mockBillRepo,
+			CustomerRepo:   mockCustomerRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusMerged}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-01-31T23:59:59Z",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerMerged, err)
+	})
+
 	t.Run("idempotent - returns existing bill", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -212,7 +386,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
-			Return(&entity.CustomerEntity{UUID: customerUUID}, nil)
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
 
 		mockBillRepo.EXPECT().
 			FetchByUUID(gomock.Any(), billUUID).
@@ -231,6 +405,46 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 		assert.Equal(t, "OPEN", resp.Status)
 	})
 
+	t.Run("idempotent - returns the stored recurrence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:       mockBillRepo,
+			CustomerRepo:   mockCustomerRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusActive}, nil)
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{
+				UUID:       "bill-123",
+				Status:     "OPEN",
+				Currency:   "USD",
+				Recurrence: &entity.BillRecurrence{Cadence: entity.BillCadenceWeekly},
+			}, nil)
+
+		// the replay asks for a different schedule than the one stored
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-01-31T23:59:59Z",
+			Recurrence:   &dto.BillRecurrence{Cadence: "MONTHLY"},
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, resp.Recurrence)
+		assert.Equal(t, "WEEKLY", resp.Recurrence.Cadence)
+	})
+
 	t.Run("error - bill insert failed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -250,7 +464,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
-			Return(&entity.CustomerEntity{UUID: customerUUID}, nil)
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
 
 		mockBillRepo.EXPECT().
 			FetchByUUID(gomock.Any(), billUUID).
@@ -291,7 +505,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
-			Return(&entity.CustomerEntity{UUID: customerUUID}, nil)
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
 
 		mockBillRepo.EXPECT().
 			FetchByUUID(gomock.Any(), billUUID).
@@ -338,7 +552,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
-			Return(&entity.CustomerEntity{UUID: customerUUID}, nil)
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
 
 		mockBillRepo.EXPECT().
 			FetchByUUID(gomock.Any(), billUUID).
@@ -383,7 +597,7 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
-			Return(&entity.CustomerEntity{UUID: customerUUID}, nil)
+			Return(&entity.CustomerEntity{UUID: customerUUID, Status: entity.CustomerStatusActive}, nil)
 
 		mockBillRepo.EXPECT().
 			FetchByUUID(gomock.Any(), billUUID).
@@ -408,4 +622,169 @@ func TestCreateBillHandler_Handle(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "GEL", resp.Currency)
 	})
+
+	t.Run("success - defaults to the customer's currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:        mockBillRepo,
+			CustomerRepo:    mockCustomerRepo,
+			TemporalClient:  mockTemporalClient,
+			DefaultCurrency: "USD",
+		}
+
+		customerCurrency := "EUR"
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Currency: &customerCurrency, Status: entity.CustomerStatusActive}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, bill *entity.BillEntity) error {
+				assert.Equal(t, "EUR", bill.Currency)
+				return nil
+			})
+
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ client.StartWorkflowOptions, _ interface{}, args ...interface{}) (client.WorkflowRun, error) {
+				input := args[0].(tbill.BillWorkflowInput)
+				assert.Equal(t, "EUR", input.Currency)
+				return &mockWorkflowRun{workflowID: "bill-bill-123"}, nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-01-31T23:59:59Z",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", resp.Currency)
+	})
+
+	t.Run("success - falls back to the billing currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:        mockBillRepo,
+			CustomerRepo:    mockCustomerRepo,
+			TemporalClient:  mockTemporalClient,
+			DefaultCurrency: "GEL",
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusActive}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&mockWorkflowRun{workflowID: "bill-bill-123"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:         "bill-123",
+			CustomerUUID: "customer-123",
+			PeriodStart:  "2024-01-01T00:00:00Z",
+			PeriodEnd:    "2024-01-31T23:59:59Z",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "GEL", resp.Currency)
+	})
+
+	t.Run("success - creates bill settled by transfer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateBillHandler{
+			BillRepo:       mockBillRepo,
+			CustomerRepo:   mockCustomerRepo,
+			TemporalClient: mockTemporalClient,
+			Settlement:     tbill.SettlementAccounts{TargetAccount: "43-812", TaskQueue: "transfers"},
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusActive}, nil)
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ client.StartWorkflowOptions, _ interface{}, args ...interface{}) (client.WorkflowRun, error) {
+				input := args[0].(tbill.BillWorkflowInput)
+				require.NotNil(t, input.Settlement)
+				assert.Equal(t, tbill.SettlementAccounts{
+					SourceAccount: "85-150",
+					TargetAccount: "43-812",
+					TaskQueue:     "transfers",
+				}, *input.Settlement)
+				return &mockWorkflowRun{workflowID: "bill-bill-123"}, nil
+			})
+
+		_, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:              "bill-123",
+			CustomerUUID:      "customer-123",
+			Currency:          "USD",
+			PeriodStart:       "2024-01-01T00:00:00Z",
+			PeriodEnd:         "2024-01-31T23:59:59Z",
+			SettlementAccount: "85-150",
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("error - settlement account without settlement configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreateBillHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			CustomerRepo:   mocks.NewMockCustomerRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateBillRequest{
+			UUID:              "bill-123",
+			CustomerUUID:      "customer-123",
+			Currency:          "USD",
+			PeriodStart:       "2024-01-01T00:00:00Z",
+			PeriodEnd:         "2024-01-31T23:59:59Z",
+			SettlementAccount: "85-150",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrSettlementDisabled, err)
+	})
 }
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"go.temporal.io/api/serviceerror"
+	tclient "go.temporal.io/sdk/client"
+)
+
+// maxCreditNoteLines bounds the line items one credit note credits.
+const maxCreditNoteLines = 100
+
+type CreateCreditNoteHandler struct {
+	BillRepo       repository.BillRepository
+	LineItemRepo   repository.LineItemRepository
+	CreditNoteRepo repository.CreditNoteRepository
+	TemporalClient t.WorkflowClient
+}
+
+// Handle issues a credit note against a closed bill and starts the workflow
+// applying it as customer credit. The bill is left as it was closed. Repeating
+// a request with the same idempotency key returns the note issued the first
+// time, and starts its workflow again should the first start have failed.
+func (h *CreateCreditNoteHandler) Handle(ctx context.Context, req *dto.CreateCreditNoteRequest) (*dto.CreateCreditNoteResponse, error) {
+	if validationErrors := validateCreateCreditNote(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+	if !entity.BillStatus(bill.Status).AcceptsCreditNotes() {
+		return nil, utils.ErrBillNotClosed
+	}
+
+	for _, line := range req.Lines {
+		if err := h.checkCreditable(ctx, req.BillUUID, line.LineItemUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	note := &entity.CreditNoteEntity{
+		UUID:           uuid.New().String(),
+		BillUUID:       req.BillUUID,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	if req.Reason != "" {
+		note.Reason = &req.Reason
+	}
+	for _, line := range req.Lines {
+		note.Lines = append(note.Lines, &entity.CreditNoteLineEntity{
+			LineItemUUID: line.LineItemUUID,
+			AmountCents:  line.AmountCents,
+		})
+	}
+
+	generatedUUID := note.UUID
+	if err := h.CreditNoteRepo.Insert(ctx, note); err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrNoRows):
+			return nil, utils.ErrLineItemNotFoundAPI
+		case errors.Is(err, entity.ErrCreditNoteBillNotClosed):
+			return nil, utils.ErrBillNotClosed
+		case errors.Is(err, entity.ErrCreditExceedsLineItem):
+			return nil, utils.ErrCreditExceedsLineItem
+		}
+		slog.ErrorContext(ctx, "error issuing credit note",
+			"bill_uuid", req.BillUUID,
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	// the key was used before, only an identical request gets the stored note back
+	if note.UUID != generatedUUID && !sameCreditNoteLines(note, req.Lines) {
+		return nil, utils.ErrDuplicateIdempotencyKey
+	}
+
+	if note.Status == entity.CreditNoteStatusIssued {
+		if err := h.startCreditNoteWorkflow(ctx, note.UUID); err != nil {
+			return nil, err
+		}
+	}
+
+	return mapCreditNoteToResponse(note), nil
+}
+
+// checkCreditable accepts charges of the bill that were not reversed in full.
+// Whether earlier credit notes left enough of them is checked when the note is
+// stored.
+func (h *CreateCreditNoteHandler) checkCreditable(ctx context.Context, billUUID, lineItemUUID string) error {
+	lineItem, err := h.LineItemRepo.FetchByUUID(ctx, lineItemUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return utils.ErrLineItemNotFoundAPI
+		}
+		return utils.ErrInternal
+	}
+	if lineItem.BillUUID != billUUID {
+		return utils.ErrLineItemNotFoundAPI
+	}
+	if lineItem.FeeType == string(entity.FeeTypeReversal) || lineItem.AmountCents <= 0 {
+		return utils.ErrLineItemNotCreditable
+	}
+	if lineItem.RemainingReversibleCents() == 0 {
+		return utils.ErrAlreadyReversedAPI
+	}
+	return nil
+}
+
+func (h *CreateCreditNoteHandler) startCreditNoteWorkflow(ctx context.Context, creditNoteUUID string) error {
+	workflowOptions := tclient.StartWorkflowOptions{
+		ID:        tbill.CreditNoteWorkflowID(creditNoteUUID),
+		TaskQueue: t.TaskQueue,
+	}
+	_, err := h.TemporalClient.ExecuteWorkflow(ctx, workflowOptions, tbill.CreditNoteWorkflow, tbill.CreditNoteWorkflowInput{
+		CreditNoteUUID: creditNoteUUID,
+	})
+	if err != nil {
+		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+		if errors.As(err, &alreadyStarted) {
+			return nil
+		}
+		slog.ErrorContext(ctx, "workflow start failed",
+			"workflow_id", workflowOptions.ID,
+			"credit_note_uuid", creditNoteUUID,
+			"err", err.Error())
+		return utils.ErrWorkflowStartFailed
+	}
+	return nil
+}
+
+// sameCreditNoteLines reports whether a stored note credits what the request
+// asks for. A line without an amount matches whatever the note credited.
+func sameCreditNoteLines(note *entity.CreditNoteEntity, lines []dto.CreditNoteLineRequest) bool {
+	if len(note.Lines) != len(lines) {
+		return false
+	}
+	stored := make(map[string]int64, len(note.Lines))
+	for _, line := range note.Lines {
+		stored[line.LineItemUUID] = line.AmountCents
+	}
+	for _, line := range lines {
+		amountCents, ok := stored[line.LineItemUUID]
+		if !ok || (line.AmountCents != 0 && line.AmountCents != amountCents) {
+			return false
+		}
+	}
+	return true
+}
+
+func mapCreditNoteToResponse(note *entity.CreditNoteEntity) *dto.CreateCreditNoteResponse {
+	resp := &dto.CreateCreditNoteResponse{
+		UUID:         note.UUID,
+		Number:       note.Number,
+		BillUUID:     note.BillUUID,
+		CustomerUUID: note.CustomerUUID,
+		Amount:       dto.Money{Amount: note.AmountCents, Currency: note.Currency},
+		Lines:        make([]dto.CreditNoteLineResponse, 0, len(note.Lines)),
+		Status:       note.Status.String(),
+		IssuedAt:     note.IssuedAt.Format(time.RFC3339),
+	}
+	for _, line := range note.Lines {
+		resp.Lines = append(resp.Lines, dto.CreditNoteLineResponse{
+			LineItemUUID: line.LineItemUUID,
+			Amount:       dto.Money{Amount: line.AmountCents, Currency: note.Currency},
+		})
+	}
+	if note.Reason != nil {
+		resp.Reason = *note.Reason
+	}
+	return resp
+}
+
+func validateCreateCreditNote(req *dto.CreateCreditNoteRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.BillUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidBillUUID)
+	}
+	if req.IdempotencyKey == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidIdempotencyKey)
+	}
+	if len(req.Lines) == 0 || len(req.Lines) > maxCreditNoteLines {
+		validationErrors = append(validationErrors, utils.ErrInvalidCreditNoteLines)
+	}
+
+	seen := make(map[string]bool, len(req.Lines))
+	for _, line := range req.Lines {
+		if line.LineItemUUID == "" {
+			validationErrors = append(validationErrors, utils.ErrInvalidLineItemUUID)
+		} else if seen[line.LineItemUUID] {
+			validationErrors = append(validationErrors, utils.ErrInvalidCreditNoteLines)
+		}
+		seen[line.LineItemUUID] = true
+
+		if line.AmountCents < 0 {
+			validationErrors = append(validationErrors, utils.ErrInvalidCreditAmount)
+		}
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateCreditNoteHandler_Handle(t *testing.T) {
+	const billUUID = "bill-123"
+
+	closedBill := &entity.BillEntity{
+		UUID:         billUUID,
+		CustomerUUID: "customer-123",
+		Currency:     "USD",
+		Status:       entity.BillStatusFinalized.String(),
+	}
+
+	newHandler := func(ctrl *gomock.Controller) (*CreateCreditNoteHandler, *mocks.MockBillRepository, *mocks.MockLineItemRepository, *mocks.MockCreditNoteRepository, *temporalmocks.MockWorkflowClient) {
+		billRepo := mocks.NewMockBillRepository(ctrl)
+		lineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		creditNoteRepo := mocks.NewMockCreditNoteRepository(ctrl)
+		temporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+		return &CreateCreditNoteHandler{
+			BillRepo:       billRepo,
+			LineItemRepo:   lineItemRepo,
+			CreditNoteRepo: creditNoteRepo,
+			TemporalClient: temporalClient,
+		}, billRepo, lineItemRepo, creditNoteRepo, temporalClient
+	}
+
+	expectCreditable := func(lineItemRepo *mocks.MockLineItemRepository, lineItemUUID string, amountCents int64) {
+		lineItemRepo.EXPECT().
+			FetchByUUID(gomock.Any(), lineItemUUID).
+			Return(&entity.LineItemEntity{UUID: lineItemUUID, BillUUID: billUUID, FeeType: "ACH", AmountCents: amountCents}, nil)
+	}
+
+	t.Run("success - issues the note and starts its workflow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, creditNoteRepo, temporalClient := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		expectCreditable(lineItemRepo, "item-1", 1000)
+		expectCreditable(lineItemRepo, "item-2", 500)
+
+		creditNoteRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, note *entity.CreditNoteEntity) error {
+				assert.Equal(t, billUUID, note.BillUUID)
+				assert.Equal(t, "refund-1", note.IdempotencyKey)
+				require.Len(t, note.Lines, 2)
+				assert.Equal(t, int64(300), note.Lines[0].AmountCents)
+				assert.Equal(t, int64(0), note.Lines[1].AmountCents)
+
+				note.Lines[1].AmountCents = 500
+				note.CustomerUUID = "customer-123"
+				note.Currency = "USD"
+				note.AmountCents = 800
+				note.Number = "CN-CUSTOMER-000001"
+				note.Status = entity.CreditNoteStatusIssued
+				note.IssuedAt = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+				return nil
+			})
+
+		temporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.StartWorkflowOptions, _ interface{}, args ...interface{}) (client.WorkflowRun, error) {
+				input := args[0].(tbill.CreditNoteWorkflowInput)
+				assert.Equal(t, tbill.CreditNoteWorkflowID(input.CreditNoteUUID), options.ID)
+				return &mockWorkflowRun{workflowID: options.ID}, nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines: []dto.CreditNoteLineRequest{
+				{LineItemUUID: "item-1", AmountCents: 300},
+				{LineItemUUID: "item-2"},
+			},
+			Reason: "Service outage",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "CN-CUSTOMER-000001", resp.Number)
+		assert.Equal(t, dto.Money{Amount: 800, Currency: "USD"}, resp.Amount)
+		assert.Equal(t, []dto.CreditNoteLineResponse{
+			{LineItemUUID: "item-1", Amount: dto.Money{Amount: 300, Currency: "USD"}},
+			{LineItemUUID: "item-2", Amount: dto.Money{Amount: 500, Currency: "USD"}},
+		}, resp.Lines)
+		assert.Equal(t, "ISSUED", resp.Status)
+		assert.Equal(t, "Service outage", resp.Reason)
+		assert.Equal(t, "2024-02-01T00:00:00Z", resp.IssuedAt)
+	})
+
+	t.Run("success - repeated request returns the applied note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, creditNoteRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		expectCreditable(lineItemRepo, "item-1", 1000)
+
+		creditNoteRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, note *entity.CreditNoteEntity) error {
+				*note = entity.CreditNoteEntity{
+					UUID:        "note-1",
+					BillUUID:    billUUID,
+					Currency:    "USD",
+					AmountCents: 1000,
+					Status:      entity.CreditNoteStatusApplied,
+					Lines:       []*entity.CreditNoteLineEntity{{LineItemUUID: "item-1", AmountCents: 1000}},
+				}
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "note-1", resp.UUID)
+		assert.Equal(t, "APPLIED", resp.Status)
+	})
+
+	t.Run("error - validation fails - no lines", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, _, _, _, _ := newHandler(ctrl)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill still open", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, _, _, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Status: entity.BillStatusOpen.String()}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1"}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrBillNotClosed)
+	})
+
+	t.Run("error - line item on another bill", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, _, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		lineItemRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "item-1").
+			Return(&entity.LineItemEntity{UUID: "item-1", BillUUID: "bill-other", FeeType: "ACH", AmountCents: 1000}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1"}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrLineItemNotFoundAPI)
+	})
+
+	t.Run("error - line item is a credit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, _, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		lineItemRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "item-1").
+			Return(&entity.LineItemEntity{UUID: "item-1", BillUUID: billUUID, FeeType: "CREDIT", AmountCents: -500}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1"}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrLineItemNotCreditable)
+	})
+
+	t.Run("error - credit exceeds what is left of the line item", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, creditNoteRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		expectCreditable(lineItemRepo, "item-1", 1000)
+		creditNoteRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(entity.ErrCreditExceedsLineItem)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1", AmountCents: 1500}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrCreditExceedsLineItem)
+	})
+
+	t.Run("error - idempotency key reused with other lines", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, creditNoteRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		expectCreditable(lineItemRepo, "item-1", 1000)
+		creditNoteRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, note *entity.CreditNoteEntity) error {
+				*note = entity.CreditNoteEntity{
+					UUID:   "note-1",
+					Status: entity.CreditNoteStatusApplied,
+					Lines:  []*entity.CreditNoteLineEntity{{LineItemUUID: "item-1", AmountCents: 200}},
+				}
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1", AmountCents: 300}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrDuplicateIdempotencyKey)
+	})
+
+	t.Run("error - workflow start fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, lineItemRepo, creditNoteRepo, temporalClient := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), billUUID).Return(closedBill, nil)
+		expectCreditable(lineItemRepo, "item-1", 1000)
+		creditNoteRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, note *entity.CreditNoteEntity) error {
+				note.Status = entity.CreditNoteStatusIssued
+				return nil
+			})
+		temporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCreditNoteRequest{
+			BillUUID:       billUUID,
+			IdempotencyKey: "refund-1",
+			Lines:          []dto.CreditNoteLineRequest{{LineItemUUID: "item-1"}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrWorkflowStartFailed)
+	})
+}
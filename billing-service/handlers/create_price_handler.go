@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"encore.app/currency"
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+)
+
+type CreatePriceHandler struct {
+	PriceRepo repository.PriceRepository
+}
+
+// Handle prices a product in a currency. Bills use the price in effect when
+// their period starts, so an open bill keeps the price it opened with.
+func (h *CreatePriceHandler) Handle(ctx context.Context, req *dto.CreatePriceRequest) (*dto.CreatePriceResponse, error) {
+	if validationErrors := validateCreatePrice(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	product, err := h.PriceRepo.FetchProductByCode(ctx, strings.TrimSpace(req.ProductCode))
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrProductNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+
+	effectiveFrom := time.Now().UTC()
+	if req.EffectiveFrom != "" {
+		effectiveFrom, _ = time.Parse(time.RFC3339, req.EffectiveFrom)
+	}
+
+	price := &entity.PriceEntity{
+		UUID:          uuid.New().String(),
+		ProductUUID:   product.UUID,
+		Currency:      req.Amount.Currency,
+		AmountCents:   req.Amount.Amount,
+		EffectiveFrom: effectiveFrom,
+	}
+
+	if err := h.PriceRepo.InsertPrice(ctx, price); err != nil {
+		if errors.Is(err, entity.ErrPriceExists) {
+			return nil, utils.ErrPriceExists
+		}
+		slog.ErrorContext(ctx, "error creating price",
+			"product_uuid", product.UUID,
+			"currency", price.Currency,
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.CreatePriceResponse{
+		UUID:          price.UUID,
+		ProductCode:   product.Code,
+		Amount:        dto.Money{Amount: price.AmountCents, Currency: price.Currency},
+		EffectiveFrom: price.EffectiveFrom.Format(time.RFC3339),
+	}, nil
+}
+
+func validateCreatePrice(req *dto.CreatePriceRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if strings.TrimSpace(req.ProductCode) == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidProductCode)
+	}
+	if req.Amount.Amount < 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidPriceAmount)
+	}
+	if !currency.IsValid(req.Amount.Currency) {
+		validationErrors = append(validationErrors, utils.ErrInvalidCurrency)
+	}
+	if req.EffectiveFrom != "" {
+		if _, err := time.Parse(time.RFC3339, req.EffectiveFrom); err != nil {
+			validationErrors = append(validationErrors, utils.ErrInvalidEffectiveFrom)
+		}
+	}
+
+	return validationErrors
+}
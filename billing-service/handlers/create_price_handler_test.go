@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreatePriceHandler_Handle(t *testing.T) {
+	product := &entity.ProductEntity{UUID: "prod-123", Code: "pro", Name: "Pro plan"}
+
+	t.Run("success - prices product from effective time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreatePriceHandler{PriceRepo: mockPriceRepo}
+
+		mockPriceRepo.EXPECT().
+			FetchProductByCode(gomock.Any(), "pro").
+			Return(product, nil)
+
+		mockPriceRepo.EXPECT().
+			InsertPrice(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, price *entity.PriceEntity) error {
+				assert.Equal(t, "prod-123", price.ProductUUID)
+				assert.Equal(t, int64(4900), price.AmountCents)
+				assert.Equal(t, "USD", price.Currency)
+				assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), price.EffectiveFrom)
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePriceRequest{
+			ProductCode:   "pro",
+			Amount:        dto.Money{Amount: 4900, Currency: "USD"},
+			EffectiveFrom: "2024-02-01T00:00:00Z",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "pro", resp.ProductCode)
+		assert.Equal(t, dto.Money{Amount: 4900, Currency: "USD"}, resp.Amount)
+		assert.Equal(t, "2024-02-01T00:00:00Z", resp.EffectiveFrom)
+	})
+
+	t.Run("error - validation fails - negative amount and bad effective time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreatePriceHandler{PriceRepo: mocks.NewMockPriceRepository(ctrl)}
+
+		req := &dto.CreatePriceRequest{
+			ProductCode:   "pro",
+			Amount:        dto.Money{Amount: -1, Currency: "USD"},
+			EffectiveFrom: "next month",
+		}
+		assert.Equal(t,
+			[]utils.ValidationError{utils.ErrInvalidPriceAmount, utils.ErrInvalidEffectiveFrom},
+			validateCreatePrice(req))
+
+		resp, err := handler.Handle(context.Background(), req)
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreatePriceHandler{PriceRepo: mockPriceRepo}
+
+		mockPriceRepo.EXPECT().
+			FetchProductByCode(gomock.Any(), "missing").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePriceRequest{
+			ProductCode: "missing",
+			Amount:      dto.Money{Amount: 4900, Currency: "USD"},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrProductNotFound)
+	})
+
+	t.Run("error - price exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreatePriceHandler{PriceRepo: mockPriceRepo}
+
+		mockPriceRepo.EXPECT().
+			FetchProductByCode(gomock.Any(), "pro").
+			Return(product, nil)
+
+		mockPriceRepo.EXPECT().
+			InsertPrice(gomock.Any(), gomock.Any()).
+			Return(entity.ErrPriceExists)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePriceRequest{
+			ProductCode:   "pro",
+			Amount:        dto.Money{Amount: 4900, Currency: "USD"},
+			EffectiveFrom: "2024-02-01T00:00:00Z",
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrPriceExists)
+	})
+}
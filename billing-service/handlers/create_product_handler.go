@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/google/uuid"
+)
+
+type CreateProductHandler struct {
+	PriceRepo repository.PriceRepository
+}
+
+// Handle adds a product to the catalog. It has no price until one is created.
+func (h *CreateProductHandler) Handle(ctx context.Context, req *dto.CreateProductRequest) (*dto.CreateProductResponse, error) {
+	if validationErrors := validateCreateProduct(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	product := &entity.ProductEntity{
+		UUID: uuid.New().String(),
+		Code: strings.TrimSpace(req.Code),
+		Name: strings.TrimSpace(req.Name),
+	}
+
+	if err := h.PriceRepo.InsertProduct(ctx, product); err != nil {
+		if errors.Is(err, entity.ErrProductCodeTaken) {
+			return nil, utils.ErrProductCodeTaken
+		}
+		slog.ErrorContext(ctx, "error creating product",
+			"code", product.Code,
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.CreateProductResponse{
+		UUID:      product.UUID,
+		Code:      product.Code,
+		Name:      product.Name,
+		CreatedAt: product.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func validateCreateProduct(req *dto.CreateProductRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if strings.TrimSpace(req.Code) == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidProductCode)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidProductName)
+	}
+
+	return validationErrors
+}
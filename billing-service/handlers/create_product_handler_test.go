@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateProductHandler_Handle(t *testing.T) {
+	t.Run("success - creates product", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreateProductHandler{PriceRepo: mockPriceRepo}
+
+		mockPriceRepo.EXPECT().
+			InsertProduct(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, product *entity.ProductEntity) error {
+				assert.Equal(t, "pro", product.Code)
+				assert.Equal(t, "Pro plan", product.Name)
+				assert.NotEmpty(t, product.UUID)
+				product.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateProductRequest{Code: " pro ", Name: "Pro plan"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "pro", resp.Code)
+		assert.Equal(t, "Pro plan", resp.Name)
+		assert.Equal(t, "2024-01-01T00:00:00Z", resp.CreatedAt)
+	})
+
+	t.Run("error - validation fails - missing code and name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreateProductHandler{PriceRepo: mocks.NewMockPriceRepository(ctrl)}
+
+		assert.Equal(t,
+			[]utils.ValidationError{utils.ErrInvalidProductCode, utils.ErrInvalidProductName},
+			validateCreateProduct(&dto.CreateProductRequest{Code: " "}))
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateProductRequest{})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - code taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreateProductHandler{PriceRepo: mockPriceRepo}
+
+		mockPriceRepo.EXPECT().
+			InsertProduct(gomock.Any(), gomock.Any()).
+			Return(entity.ErrProductCodeTaken)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateProductRequest{Code: "pro", Name: "Pro plan"})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrProductCodeTaken)
+	})
+
+	t.Run("error - database error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreateProductHandler{PriceRepo: mockPriceRepo}
+
+		mockPriceRepo.EXPECT().
+			InsertProduct(gomock.Any(), gomock.Any()).
+			Return(errors.New("connection refused"))
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateProductRequest{Code: "pro", Name: "Pro plan"})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrInternal)
+	})
+}
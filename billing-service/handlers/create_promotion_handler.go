@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"encore.app/currency"
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/google/uuid"
+)
+
+type CreatePromotionHandler struct {
+	PromotionRepo repository.PromotionRepository
+}
+
+// Handle creates a promo code. Codes are case-insensitive and stored upper case.
+func (h *CreatePromotionHandler) Handle(ctx context.Context, req *dto.CreatePromotionRequest) (*dto.CreatePromotionResponse, error) {
+	if validationErrors := validateCreatePromotion(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	promotion := &entity.PromotionEntity{
+		UUID: uuid.New().String(),
+		Code: normalizePromoCode(req.Code),
+	}
+	if req.AmountOff != nil {
+		promotion.Kind = entity.PromotionKindFixed
+		promotion.AmountCents = &req.AmountOff.Amount
+		promotion.Currency = &req.AmountOff.Currency
+	} else {
+		promotion.Kind = entity.PromotionKindPercent
+		promotion.PercentBps = &req.PercentOffBps
+	}
+	if req.MaxRedemptions > 0 {
+		promotion.MaxRedemptions = &req.MaxRedemptions
+	}
+	if req.ExpiresAt != "" {
+		expiresAt, _ := time.Parse(time.RFC3339, req.ExpiresAt)
+		promotion.ExpiresAt = &expiresAt
+	}
+
+	if err := h.PromotionRepo.Insert(ctx, promotion); err != nil {
+		if errors.Is(err, entity.ErrPromotionCodeTaken) {
+			return nil, utils.ErrPromoCodeTaken
+		}
+		slog.ErrorContext(ctx, "error creating promotion",
+			"code", promotion.Code,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.CreatePromotionResponse{Promotion: mapPromotionToSummary(promotion)}, nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validateCreatePromotion(req *dto.CreatePromotionRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if normalizePromoCode(req.Code) == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidPromoCode)
+	}
+
+	switch {
+	case req.AmountOff != nil && req.PercentOffBps != 0:
+		validationErrors = append(validationErrors, utils.ErrInvalidDiscount)
+	case req.AmountOff != nil:
+		if req.AmountOff.Amount <= 0 {
+			validationErrors = append(validationErrors, utils.ErrInvalidDiscount)
+		}
+		if !currency.IsValid(req.AmountOff.Currency) {
+			validationErrors = append(validationErrors, utils.ErrInvalidCurrency)
+		}
+	case req.PercentOffBps <= 0 || req.PercentOffBps > 10000:
+		validationErrors = append(validationErrors, utils.ErrInvalidDiscount)
+	}
+
+	if req.MaxRedemptions < 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidMaxRedemptions)
+	}
+	if req.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, req.ExpiresAt); err != nil {
+			validationErrors = append(validationErrors, utils.ErrInvalidExpiresAt)
+		}
+	}
+
+	return validationErrors
+}
+
+func mapPromotionToSummary(promotion *entity.PromotionEntity) dto.PromotionSummary {
+	summary := dto.PromotionSummary{
+		UUID:        promotion.UUID,
+		Code:        promotion.Code,
+		Kind:        promotion.Kind.String(),
+		Redemptions: promotion.RedemptionCount,
+	}
+	if promotion.PercentBps != nil {
+		summary.PercentOffBps = *promotion.PercentBps
+	}
+	if promotion.AmountCents != nil && promotion.Currency != nil {
+		summary.AmountOff = &dto.Money{Amount: *promotion.AmountCents, Currency: *promotion.Currency}
+	}
+	if promotion.MaxRedemptions != nil {
+		summary.MaxRedemptions = *promotion.MaxRedemptions
+	}
+	if promotion.ExpiresAt != nil {
+		summary.ExpiresAt = promotion.ExpiresAt.Format(time.RFC3339)
+	}
+	return summary
+}
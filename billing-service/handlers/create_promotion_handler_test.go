@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreatePromotionHandler_Handle(t *testing.T) {
+	t.Run("success - creates percent promotion", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		mockPromotionRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, promotion *entity.PromotionEntity) error {
+				assert.Equal(t, "SPRING10", promotion.Code)
+				assert.Equal(t, entity.PromotionKindPercent, promotion.Kind)
+				require.NotNil(t, promotion.PercentBps)
+				assert.Equal(t, int64(1000), *promotion.PercentBps)
+				require.NotNil(t, promotion.MaxRedemptions)
+				assert.Equal(t, 100, *promotion.MaxRedemptions)
+				require.NotNil(t, promotion.ExpiresAt)
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:           " spring10 ",
+			PercentOffBps:  1000,
+			MaxRedemptions: 100,
+			ExpiresAt:      "2024-06-01T00:00:00Z",
+		})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Promotion.UUID)
+		assert.Equal(t, "SPRING10", resp.Promotion.Code)
+		assert.Equal(t, "PERCENT", resp.Promotion.Kind)
+		assert.Equal(t, int64(1000), resp.Promotion.PercentOffBps)
+		assert.Equal(t, "2024-06-01T00:00:00Z", resp.Promotion.ExpiresAt)
+	})
+
+	t.Run("success - creates fixed promotion", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		mockPromotionRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:      "WELCOME",
+			AmountOff: &dto.Money{Amount: 500, Currency: "USD"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "FIXED", resp.Promotion.Kind)
+		require.NotNil(t, resp.Promotion.AmountOff)
+		assert.Equal(t, dto.Money{Amount: 500, Currency: "USD"}, *resp.Promotion.AmountOff)
+		assert.Zero(t, resp.Promotion.MaxRedemptions)
+	})
+
+	t.Run("error - validation fails - both percent and amount off", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mocks.NewMockPromotionRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:          "BOTH",
+			PercentOffBps: 1000,
+			AmountOff:     &dto.Money{Amount: 500, Currency: "USD"},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - validation fails - percent above 100%", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mocks.NewMockPromotionRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:          "TOOMUCH",
+			PercentOffBps: 10001,
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - validation fails - invalid expiry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mocks.NewMockPromotionRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:          "SOON",
+			PercentOffBps: 1000,
+			ExpiresAt:     "tomorrow",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - code taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		mockPromotionRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(entity.ErrPromotionCodeTaken)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:          "SPRING10",
+			PercentOffBps: 1000,
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrPromoCodeTaken, err)
+	})
+
+	t.Run("error - insert failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		handler := &CreatePromotionHandler{
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		mockPromotionRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreatePromotionRequest{
+			Code:          "SPRING10",
+			PercentOffBps: 1000,
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+
+	"encore.dev/storage/sqldb"
+)
+
+type CreateSubscriptionHandler struct {
+	CustomerRepo     repository.CustomerRepository
+	PriceRepo        repository.PriceRepository
+	SubscriptionRepo repository.SubscriptionRepository
+	BillRepo         repository.BillRepository
+	TemporalClient   t.WorkflowClient
+}
+
+// Handle subscribes a customer to a product. The customer's open bills prorate
+// it from its start. Retrying with the same UUID returns the stored
+// subscription, a different customer, product or quantity under that UUID is
+// a conflict.
+func (h *CreateSubscriptionHandler) Handle(ctx context.Context, req *dto.CreateSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	if validationErrors := validateCreateSubscription(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	if _, err := h.CustomerRepo.FetchByUUID(ctx, req.CustomerUUID); err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrCustomerNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+
+	product, err := h.PriceRepo.FetchProductByCode(ctx, strings.TrimSpace(req.ProductCode))
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrProductNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+
+	startsAt := time.Now().UTC()
+	if req.StartsAt != "" {
+		startsAt, _ = time.Parse(time.RFC3339, req.StartsAt)
+	}
+
+	subscription := &entity.SubscriptionEntity{
+		UUID:         req.UUID,
+		CustomerUUID: req.CustomerUUID,
+		ProductUUID:  product.UUID,
+		Quantity:     req.Quantity,
+		StartedAt:    startsAt,
+	}
+
+	if err := h.SubscriptionRepo.Insert(ctx, subscription); err != nil {
+		slog.ErrorContext(ctx, "error creating subscription",
+			"uuid", req.UUID,
+			"customer_uuid", req.CustomerUUID,
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	if subscription.CustomerUUID != req.CustomerUUID ||
+		subscription.ProductUUID != product.UUID ||
+		subscription.Quantity != req.Quantity {
+		return nil, utils.ErrSubscriptionConflict
+	}
+
+	signalSubscriptionChange(ctx, h.BillRepo, h.TemporalClient, subscription.CustomerUUID, subscription.UUID)
+
+	return mapSubscriptionToResponse(subscription), nil
+}
+
+func mapSubscriptionToResponse(subscription *entity.SubscriptionEntity) *dto.SubscriptionResponse {
+	resp := &dto.SubscriptionResponse{
+		UUID:         subscription.UUID,
+		CustomerUUID: subscription.CustomerUUID,
+		ProductUUID:  subscription.ProductUUID,
+		Quantity:     subscription.Quantity,
+		StartedAt:    subscription.StartedAt.Format(time.RFC3339),
+	}
+	if subscription.EndedAt != nil {
+		resp.EndedAt = subscription.EndedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func validateCreateSubscription(req *dto.CreateSubscriptionRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.UUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidSubscriptionID)
+	}
+	if req.CustomerUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidCustomerUUID)
+	}
+	if strings.TrimSpace(req.ProductCode) == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidProductCode)
+	}
+	if req.Quantity <= 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidQuantity)
+	}
+	if req.StartsAt != "" {
+		if _, err := time.Parse(time.RFC3339, req.StartsAt); err != nil {
+			validationErrors = append(validationErrors, utils.ErrInvalidStartsAt)
+		}
+	}
+
+	return validationErrors
+}
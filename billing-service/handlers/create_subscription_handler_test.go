@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateSubscriptionHandler_Handle(t *testing.T) {
+	customer := &entity.CustomerEntity{UUID: "cust-123"}
+	product := &entity.ProductEntity{UUID: "prod-123", Code: "pro", Name: "Pro plan"}
+
+	t.Run("success - subscribes customer and signals open bills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &CreateSubscriptionHandler{
+			CustomerRepo:     mockCustomerRepo,
+			PriceRepo:        mockPriceRepo,
+			SubscriptionRepo: mockSubscriptionRepo,
+			BillRepo:         mockBillRepo,
+			TemporalClient:   mockTemporalClient,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-123").
+			Return(customer, nil)
+
+		mockPriceRepo.EXPECT().
+			FetchProductByCode(gomock.Any(), "pro").
+			Return(product, nil)
+
+		mockSubscriptionRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, subscription *entity.SubscriptionEntity) error {
+				assert.Equal(t, "sub-123", subscription.UUID)
+				assert.Equal(t, "prod-123", subscription.ProductUUID)
+				assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), subscription.StartedAt)
+				return nil
+			})
+
+		mockBillRepo.EXPECT().
+			FetchAll(gomock.Any(), gomock.Any()).
+			Return([]*entity.BillEntity{{UUID: "bill-123"}, {UUID: "bill-456"}}, nil)
+
+		mockTemporalClient.EXPECT().
+			SignalWorkflow(gomock.Any(), "bill-bill-123", "", tbill.SignalSubscriptionChanged,
+				tbill.SubscriptionChangedSignal{SubscriptionUUID: "sub-123"}).
+			Return(nil)
+		// a bill that started closing in the meantime reconciles at close instead
+		mockTemporalClient.EXPECT().
+			SignalWorkflow(gomock.Any(), "bill-bill-456", "", tbill.SignalSubscriptionChanged, gomock.Any()).
+			Return(errors.New("workflow execution already completed"))
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateSubscriptionRequest{
+			UUID:         "sub-123",
+			CustomerUUID: "cust-123",
+			ProductCode:  "pro",
+			Quantity:     2,
+			StartsAt:     "2024-03-01T00:00:00Z",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "sub-123", resp.UUID)
+		assert.Equal(t, "prod-123", resp.ProductUUID)
+		assert.Equal(t, int64(2), resp.Quantity)
+		assert.Equal(t, "2024-03-01T00:00:00Z", resp.StartedAt)
+		assert.Empty(t, resp.EndedAt)
+	})
+
+	t.Run("error - validation fails - missing uuid and zero quantity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreateSubscriptionHandler{
+			CustomerRepo:     mocks.NewMockCustomerRepository(ctrl),
+			PriceRepo:        mocks.NewMockPriceRepository(ctrl),
+			SubscriptionRepo: mocks.NewMockSubscriptionRepository(ctrl),
+		}
+
+		req := &dto.CreateSubscriptionRequest{CustomerUUID: "cust-123", ProductCode: "pro"}
+		assert.Equal(t,
+			[]utils.ValidationError{utils.ErrInvalidSubscriptionID, utils.ErrInvalidQuantity},
+			validateCreateSubscription(req))
+
+		resp, err := handler.Handle(context.Background(), req)
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+
+		handler := &CreateSubscriptionHandler{
+			CustomerRepo:     mockCustomerRepo,
+			PriceRepo:        mocks.NewMockPriceRepository(ctrl),
+			SubscriptionRepo: mocks.NewMockSubscriptionRepository(ctrl),
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-404").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateSubscriptionRequest{
+			UUID:         "sub-123",
+			CustomerUUID: "cust-404",
+			ProductCode:  "pro",
+			Quantity:     1,
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrCustomerNotFoundAPI)
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		handler := &CreateSubscriptionHandler{
+			CustomerRepo:     mockCustomerRepo,
+			PriceRepo:        mockPriceRepo,
+			SubscriptionRepo: mocks.NewMockSubscriptionRepository(ctrl),
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-123").
+			Return(customer, nil)
+
+		mockPriceRepo.EXPECT().
+			FetchProductByCode(gomock.Any(), "missing").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateSubscriptionRequest{
+			UUID:         "sub-123",
+			CustomerUUID: "cust-123",
+			ProductCode:  "missing",
+			Quantity:     1,
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrProductNotFound)
+	})
+
+	t.Run("error - uuid already used for another subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+
+		handler := &CreateSubscriptionHandler{
+			CustomerRepo:     mockCustomerRepo,
+			PriceRepo:        mockPriceRepo,
+			SubscriptionRepo: mockSubscriptionRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-123").
+			Return(customer, nil)
+
+		mockPriceRepo.EXPECT().
+			FetchProductByCode(gomock.Any(), "pro").
+			Return(product, nil)
+
+		mockSubscriptionRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, subscription *entity.SubscriptionEntity) error {
+				// the stored subscription has 5 seats
+				subscription.Quantity = 5
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateSubscriptionRequest{
+			UUID:         "sub-123",
+			CustomerUUID: "cust-123",
+			ProductCode:  "pro",
+			Quantity:     2,
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrSubscriptionConflict)
+	})
+}
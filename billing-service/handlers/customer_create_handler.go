@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 
+	"encore.app/currency"
 	"encore.app/db/repository"
 	"encore.app/dto"
 	"encore.app/entity"
@@ -40,16 +41,24 @@ func (h *CreateCustomerHandler) Handle(ctx context.Context, req *dto.CreateCusto
 		Name:  req.Name,
 		Email: req.Email,
 	}
+	if req.Currency != "" {
+		cust.Currency = &req.Currency
+	}
 
 	insertErr := h.CustomerRepo.Insert(ctx, cust)
+	if errors.Is(insertErr, entity.ErrEmailTaken) {
+		// created concurrently
+		return nil, utils.ErrEmailAlreadyUsed
+	}
 	if insertErr != nil {
 		return nil, insertErr
 	}
 
 	return &dto.CreateCustomerResponse{
-		UUID:  cust.UUID,
-		Name:  req.Name,
-		Email: req.Email,
+		UUID:     cust.UUID,
+		Name:     req.Name,
+		Email:    req.Email,
+		Currency: req.Currency,
 	}, nil
 }
 
@@ -61,5 +70,8 @@ func validateCreateCustomer(req *dto.CreateCustomerRequest) []utils.ValidationEr
 	if req.Email == "" {
 		errs = append(errs, utils.ErrInvalidEmail)
 	}
+	if req.Currency != "" && !currency.IsValid(req.Currency) {
+		errs = append(errs, utils.ErrInvalidCurrency)
+	}
 	return errs
 }
@@ -79,6 +79,56 @@ func TestCreateCustomerHandler_Handle(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 
+	t.Run("success - creates customer with a default currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+
+		handler := &CreateCustomerHandler{
+			CustomerRepo: mockCustomerRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByEmail(gomock.Any(), "test@example.com").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockCustomerRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, cust *entity.CustomerEntity) error {
+				require.NotNil(t, cust.Currency)
+				assert.Equal(t, "EUR", *cust.Currency)
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCustomerRequest{
+			Name:     "Test User",
+			Email:    "test@example.com",
+			Currency: "EUR",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", resp.Currency)
+	})
+
+	t.Run("error - validation fails - invalid currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &CreateCustomerHandler{
+			CustomerRepo: mocks.NewMockCustomerRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCustomerRequest{
+			Name:     "Test User",
+			Email:    "test@example.com",
+			Currency: "XYZ",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
 	t.Run("error - email already used", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -131,6 +181,33 @@ func TestCreateCustomerHandler_Handle(t *testing.T) {
 		assert.Equal(t, utils.ErrInternal, err)
 	})
 
+	t.Run("error - email taken concurrently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+
+		handler := &CreateCustomerHandler{
+			CustomerRepo: mockCustomerRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByEmail(gomock.Any(), "test@example.com").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockCustomerRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(entity.ErrEmailTaken)
+
+		resp, err := handler.Handle(context.Background(), &dto.CreateCustomerRequest{
+			Name:  "Test User",
+			Email: "test@example.com",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrEmailAlreadyUsed, err)
+	})
+
 	t.Run("error - insert failed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
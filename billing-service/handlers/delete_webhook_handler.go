@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+)
+
+type DeleteWebhookHandler struct {
+	WebhookRepo repository.WebhookRepository
+}
+
+// Handle deletes a subscription. Pending deliveries to it stop retrying; its
+// delivery log stays readable.
+func (h *DeleteWebhookHandler) Handle(ctx context.Context, req *dto.DeleteWebhookRequest) (*dto.DeleteWebhookResponse, error) {
+	if req.UUID == "" {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidUUID})
+	}
+
+	deletedAt := time.Now().UTC()
+	if err := h.WebhookRepo.DeleteSubscription(ctx, req.UUID, deletedAt); err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrWebhookSubscriptionNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.DeleteWebhookResponse{
+		UUID:      req.UUID,
+		DeletedAt: deletedAt,
+	}, nil
+}
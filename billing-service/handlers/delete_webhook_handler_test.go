@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeleteWebhookHandler_Handle(t *testing.T) {
+	t.Run("success - deletes subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &DeleteWebhookHandler{WebhookRepo: mockWebhookRepo}
+
+		mockWebhookRepo.EXPECT().
+			DeleteSubscription(gomock.Any(), "sub-1", gomock.Any()).
+			Return(nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.DeleteWebhookRequest{UUID: "sub-1"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "sub-1", resp.UUID)
+		assert.False(t, resp.DeletedAt.IsZero())
+	})
+
+	t.Run("error - validation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &DeleteWebhookHandler{WebhookRepo: mocks.NewMockWebhookRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.DeleteWebhookRequest{})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - subscription not found or already deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &DeleteWebhookHandler{WebhookRepo: mockWebhookRepo}
+
+		mockWebhookRepo.EXPECT().
+			DeleteSubscription(gomock.Any(), "sub-1", gomock.Any()).
+			Return(sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.DeleteWebhookRequest{UUID: "sub-1"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWebhookSubscriptionNotFound, err)
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+)
+
+type DiscardLineItemHandler struct {
+	BillRepo       repository.BillRepository
+	TemporalClient t.WorkflowClient
+}
+
+// Handle drops a dead-lettered line item that will never persist, so the bill
+// can close without it. The line item is not charged.
+func (h *DiscardLineItemHandler) Handle(ctx context.Context, req *dto.DiscardLineItemRequest) (*dto.DiscardLineItemResponse, error) {
+	if validationErrors := validateDiscardLineItem(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		rlog.Error("error fetching bill", "bill_uuid", req.BillUUID, "error", err)
+		return nil, utils.ErrInternal
+	}
+
+	if !bill.HasRunningWorkflow() {
+		return nil, utils.ErrBillClosed
+	}
+
+	var discarded tbill.FailedLineItem
+	err = runBillUpdate(ctx, h.TemporalClient, req.BillUUID, "", tbill.UpdateDiscardLineItem,
+		tbill.DiscardLineItemUpdate{UUID: req.LineItemUUID, Reason: req.Reason}, &discarded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DiscardLineItemResponse{
+		UUID:    discarded.Signal.UUID,
+		FeeType: discarded.Signal.FeeType,
+		Amount: dto.Money{
+			Amount:   discarded.Signal.AmountCents,
+			Currency: bill.Currency,
+		},
+		Error:  discarded.Error,
+		Status: "discarded",
+	}, nil
+}
+
+func validateDiscardLineItem(req *dto.DiscardLineItemRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.BillUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidBillUUID)
+	}
+	if req.LineItemUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidLineItemUUID)
+	}
+	if req.Reason == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidDiscardReason)
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDiscardLineItemHandler_Handle(t *testing.T) {
+	t.Run("success - discards failed line item", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &DiscardLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		billUUID := "bill-123"
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Status: "CLOSING", Currency: "USD"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				assert.Equal(t, "bill-"+billUUID, options.WorkflowID)
+				assert.Equal(t, tbill.UpdateDiscardLineItem, options.UpdateName)
+				assert.Equal(t, tbill.DiscardLineItemUpdate{UUID: "li-failed", Reason: "reversal of a refunded charge"}, options.Args[0])
+
+				return &mockUpdateHandle{value: &tbill.FailedLineItem{
+					Signal: tbill.AddLineItemSignal{
+						UUID:        "li-failed",
+						FeeType:     "REVERSAL",
+						AmountCents: -1000,
+					},
+					Error: "reversal exceeds line item",
+				}}, nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.DiscardLineItemRequest{
+			BillUUID:     billUUID,
+			LineItemUUID: "li-failed",
+			Reason:       "reversal of a refunded charge",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "li-failed", resp.UUID)
+		assert.Equal(t, int64(-1000), resp.Amount.Amount)
+		assert.Equal(t, "USD", resp.Amount.Currency)
+		assert.Equal(t, "reversal exceeds line item", resp.Error)
+		assert.Equal(t, "discarded", resp.Status)
+	})
+
+	t.Run("error - validation fails - missing reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &DiscardLineItemHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.DiscardLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-failed",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &DiscardLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.DiscardLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-failed",
+			Reason:       "poison",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotFoundAPI, err)
+	})
+
+	t.Run("error - bill closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &DiscardLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.DiscardLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-failed",
+			Reason:       "poison",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillClosed, err)
+	})
+
+	t.Run("error - line item not failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &DiscardLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(newMockUpdateHandle(nil,
+				temporal.NewApplicationError("not failed", tbill.ErrTypeLineItemNotFailed)), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.DiscardLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-unknown",
+			Reason:       "poison",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrLineItemNotFailed, err)
+	})
+}
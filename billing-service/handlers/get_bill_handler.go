@@ -8,12 +8,17 @@ import (
 
 	"encore.app/db/repository"
 	"encore.app/dto"
+	"encore.app/entity"
 	"encore.app/utils"
 	"encore.dev/storage/sqldb"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
 )
 
 type GetBillHandler struct {
-	BillRepo repository.BillRepository
+	BillRepo       repository.BillRepository
+	TemporalClient t.WorkflowClient
 }
 
 func (h *GetBillHandler) Handle(ctx context.Context, req *dto.GetBillRequest) (*dto.GetBillResponse, error) {
@@ -54,5 +59,88 @@ func (h *GetBillHandler) Handle(ctx context.Context, req *dto.GetBillRequest) (*
 		response.ClosedAt = bill.ClosedAt.Format(time.RFC3339)
 	}
 
+	if bill.DueDate != nil {
+		response.DueDate = bill.DueDate.Format(time.RFC3339)
+	}
+
+	// the workflow runs ahead of the database row, but its in-memory total is
+	// not authoritative, so the live figure is only reported under InFlight
+	if bill.HasRunningWorkflow() {
+		if state := h.queryBillState(ctx, bill.UUID); state != nil {
+			response.InFlight = mapBillStateToInFlight(state, bill)
+		}
+	}
+
+	response.PaidCents = bill.PaidCents
+	response.BalanceCents = response.TotalCents - bill.PaidCents
+
 	return response, nil
 }
+
+// queryBillState returns the live workflow state, or nil when it is unavailable.
+// The database row is still a valid answer, so query failures are not surfaced.
+func (h *GetBillHandler) queryBillState(ctx context.Context, billUUID string) *tbill.BillStateQuery {
+	queryResp, err := h.TemporalClient.QueryWorkflow(ctx, t.BillWorkflowIDPrefix+billUUID, "", tbill.QueryGetBillState)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to query bill state",
+			"uuid", billUUID,
+			"err", err)
+		return nil
+	}
+
+	var state tbill.BillStateQuery
+	if err := queryResp.Get(&state); err != nil {
+		slog.WarnContext(ctx, "failed to decode bill state",
+			"uuid", billUUID,
+			"err", err)
+		return nil
+	}
+
+	return &state
+}
+
+func mapBillStateToInFlight(state *tbill.BillStateQuery, bill *entity.BillEntity) *dto.BillInFlight {
+	inFlight := &dto.BillInFlight{
+		Status: state.Status,
+		Total: dto.Money{
+			Amount:   state.TotalCents,
+			Currency: bill.Currency,
+		},
+		ItemCount:        state.ItemCount,
+		FailedItemCount:  state.FailedItemCount,
+		PendingLineItems: make([]dto.InFlightLineItem, len(state.PendingLineItems)),
+		RecentLineItems:  make([]dto.InFlightLineItem, len(state.RecentLineItems)),
+		ScheduledCloseAt: state.ScheduledCloseAt.Format(time.RFC3339),
+		WorkflowID:       state.WorkflowID,
+		RunID:            state.RunID,
+	}
+
+	for i, pending := range state.PendingLineItems {
+		inFlight.PendingLineItems[i] = mapSignalToInFlightLineItem(pending, bill.Currency)
+	}
+
+	for i, recent := range state.RecentLineItems {
+		item := mapSignalToInFlightLineItem(recent.Signal, bill.Currency)
+		item.ProcessedAt = recent.ProcessedAt.Format(time.RFC3339)
+		inFlight.RecentLineItems[i] = item
+	}
+
+	return inFlight
+}
+
+func mapSignalToInFlightLineItem(signal tbill.AddLineItemSignal, currency string) dto.InFlightLineItem {
+	item := dto.InFlightLineItem{
+		UUID:           signal.UUID,
+		IdempotencyKey: signal.IdempotencyKey,
+		FeeType:        signal.FeeType,
+		Description:    signal.Description,
+		Amount: dto.Money{
+			Amount:   signal.AmountCents,
+			Currency: currency,
+		},
+	}
+	if signal.ReferenceUUID != nil {
+		item.ReferenceUUID = *signal.ReferenceUUID
+	}
+	return item
+}
@@ -8,6 +8,8 @@ import (
 	"encore.app/db/repository/mocks"
 	"encore.app/dto"
 	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
 	"encore.app/utils"
 
 	"encore.dev/storage/sqldb"
@@ -59,14 +61,89 @@ func TestGetBillHandler_Handle(t *testing.T) {
 		assert.NotEmpty(t, resp.ClosedAt)
 	})
 
-	t.Run("success - returns open bill without total", func(t *testing.T) {
+	t.Run("success - reports in-flight state of open bill next to the stored total", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
 
 		handler := &GetBillHandler{
-			BillRepo: mockBillRepo,
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		billUUID := "bill-123"
+		totalCents := int64(1000)
+		periodEnd := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+		bill := &entity.BillEntity{
+			UUID:         billUUID,
+			CustomerUUID: "customer-123",
+			Status:       "OPEN",
+			Currency:     "USD",
+			TotalCents:   &totalCents,
+			PeriodStart:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:    periodEnd,
+			CreatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(bill, nil)
+
+		mockTemporalClient.EXPECT().
+			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
+			Return(newMockEncodedValue(tbill.BillStateQuery{
+				Status:     "OPEN",
+				TotalCents: 1500,
+				ItemCount:  2,
+				PendingLineItems: []tbill.AddLineItemSignal{
+					{UUID: "item-3", IdempotencyKey: "idem-3", FeeType: "ACH", AmountCents: 250},
+				},
+				RecentLineItems: []tbill.ProcessedLineItem{
+					{
+						Signal:      tbill.AddLineItemSignal{UUID: "item-2", IdempotencyKey: "idem-2", FeeType: "ACH", AmountCents: 500},
+						ProcessedAt: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+					},
+				},
+				ScheduledCloseAt: periodEnd,
+				WorkflowID:       "bill-" + billUUID,
+				RunID:            "run-2",
+			}), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetBillRequest{UUID: billUUID})
+
+		require.NoError(t, err)
+		assert.Equal(t, "OPEN", resp.Status)
+		assert.Equal(t, int64(1000), resp.TotalCents)
+		assert.Equal(t, int64(1000), resp.BalanceCents)
+		assert.Empty(t, resp.ClosedAt)
+
+		require.NotNil(t, resp.InFlight)
+		assert.Equal(t, int64(1500), resp.InFlight.Total.Amount)
+		assert.Equal(t, 2, resp.InFlight.ItemCount)
+		assert.Equal(t, "USD", resp.InFlight.Total.Currency)
+		require.Len(t, resp.InFlight.PendingLineItems, 1)
+		assert.Equal(t, "item-3", resp.InFlight.PendingLineItems[0].UUID)
+		assert.Empty(t, resp.InFlight.PendingLineItems[0].ProcessedAt)
+		require.Len(t, resp.InFlight.RecentLineItems, 1)
+		assert.Equal(t, "item-2", resp.InFlight.RecentLineItems[0].UUID)
+		assert.Equal(t, "2024-01-10T12:00:00Z", resp.InFlight.RecentLineItems[0].ProcessedAt)
+		assert.Equal(t, "2024-01-31T23:59:59Z", resp.InFlight.ScheduledCloseAt)
+		assert.Equal(t, "run-2", resp.InFlight.RunID)
+	})
+
+	t.Run("success - returns open bill without total when query fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &GetBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
 		}
 
 		billUUID := "bill-123"
@@ -87,6 +164,10 @@ func TestGetBillHandler_Handle(t *testing.T) {
 			FetchByUUID(gomock.Any(), billUUID).
 			Return(bill, nil)
 
+		mockTemporalClient.EXPECT().
+			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
+			Return(nil, assert.AnError)
+
 		resp, err := handler.Handle(context.Background(), &dto.GetBillRequest{UUID: billUUID})
 
 		require.NoError(t, err)
@@ -94,6 +175,7 @@ func TestGetBillHandler_Handle(t *testing.T) {
 		assert.Equal(t, "OPEN", resp.Status)
 		assert.Equal(t, int64(0), resp.TotalCents)
 		assert.Empty(t, resp.ClosedAt)
+		assert.Nil(t, resp.InFlight)
 	})
 
 	t.Run("error - missing UUID", func(t *testing.T) {
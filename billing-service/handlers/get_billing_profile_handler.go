@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+	"encore.dev/storage/sqldb"
+)
+
+type GetBillingProfileHandler struct {
+	BillingProfileRepo repository.BillingProfileRepository
+}
+
+func (h *GetBillingProfileHandler) Handle(ctx context.Context, req *dto.GetBillingProfileRequest) (*dto.BillingProfileResponse, error) {
+	if req.CustomerUUID == "" {
+		return nil, utils.ErrUUIDMissing
+	}
+
+	profile, err := h.BillingProfileRepo.FetchByCustomerUUID(ctx, req.CustomerUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillingProfileNotFound
+		}
+		slog.ErrorContext(ctx, "error fetching billing profile",
+			"customer_uuid", req.CustomerUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return mapBillingProfileToResponse(profile), nil
+}
+
+func mapBillingProfileToResponse(p *entity.BillingProfileEntity) *dto.BillingProfileResponse {
+	resp := &dto.BillingProfileResponse{
+		CustomerUUID: p.CustomerUUID,
+		Address: dto.BillingAddress{
+			Line1:   p.AddressLine1,
+			City:    p.City,
+			Country: p.Country,
+		},
+		PaymentTerms:    p.PaymentTerms.String(),
+		InvoiceDelivery: p.InvoiceDelivery.String(),
+		UpdatedAt:       p.UpdatedAt,
+	}
+	if p.AddressLine2 != nil {
+		resp.Address.Line2 = *p.AddressLine2
+	}
+	if p.PostalCode != nil {
+		resp.Address.PostalCode = *p.PostalCode
+	}
+	if p.Region != nil {
+		resp.Address.Region = *p.Region
+	}
+	if p.TaxID != nil {
+		resp.TaxID = *p.TaxID
+	}
+	if p.Currency != nil {
+		resp.Currency = *p.Currency
+	}
+	return resp
+}
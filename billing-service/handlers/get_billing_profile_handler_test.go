@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetBillingProfileHandler_Handle(t *testing.T) {
+	t.Run("success - returns the profile", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+		handler := &GetBillingProfileHandler{BillingProfileRepo: mockBillingProfileRepo}
+
+		region, taxID := "Bavaria", "DE123456789"
+		mockBillingProfileRepo.EXPECT().
+			FetchByCustomerUUID(gomock.Any(), "customer-123").
+			Return(&entity.BillingProfileEntity{
+				CustomerUUID:    "customer-123",
+				AddressLine1:    "Marienplatz 1",
+				City:            "Munich",
+				Region:          &region,
+				Country:         "DE",
+				TaxID:           &taxID,
+				InvoiceDelivery: entity.InvoiceDeliveryPortal,
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetBillingProfileRequest{CustomerUUID: "customer-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Marienplatz 1", resp.Address.Line1)
+		assert.Equal(t, "Bavaria", resp.Address.Region)
+		assert.Empty(t, resp.Address.PostalCode)
+		assert.Equal(t, "DE123456789", resp.TaxID)
+		assert.Empty(t, resp.Currency)
+		assert.Empty(t, resp.PaymentTerms)
+		assert.Equal(t, "PORTAL", resp.InvoiceDelivery)
+	})
+
+	t.Run("error - missing customer uuid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &GetBillingProfileHandler{BillingProfileRepo: mocks.NewMockBillingProfileRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.GetBillingProfileRequest{})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrUUIDMissing, err)
+	})
+
+	t.Run("error - profile not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+		handler := &GetBillingProfileHandler{BillingProfileRepo: mockBillingProfileRepo}
+
+		mockBillingProfileRepo.EXPECT().
+			FetchByCustomerUUID(gomock.Any(), "customer-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetBillingProfileRequest{CustomerUUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillingProfileNotFound, err)
+	})
+
+	t.Run("error - fetch fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+		handler := &GetBillingProfileHandler{BillingProfileRepo: mockBillingProfileRepo}
+
+		mockBillingProfileRepo.EXPECT().
+			FetchByCustomerUUID(gomock.Any(), "customer-123").
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetBillingProfileRequest{CustomerUUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
@@ -13,6 +13,7 @@ import (
 
 type GetCustomerHandler struct {
 	CustomerRepo repository.CustomerRepository
+	LedgerRepo   repository.LedgerRepository
 }
 
 func (h *GetCustomerHandler) Handle(ctx context.Context, req *dto.GetCustomerRequest) (*dto.GetCustomerResponse, error) {
@@ -33,10 +34,33 @@ func (h *GetCustomerHandler) Handle(ctx context.Context, req *dto.GetCustomerReq
 		return nil, utils.ErrInternal
 	}
 
-	return &dto.GetCustomerResponse{
+	balances, err := h.LedgerRepo.FetchCustomerBalances(ctx, req.UUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching customer balances",
+			"uuid", req.UUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	resp := &dto.GetCustomerResponse{
 		UUID:      customer.UUID,
 		Name:      customer.Name,
 		Email:     customer.Email,
+		Status:    customer.Status.String(),
+		Balances:  make([]dto.CustomerBalance, 0, len(balances)),
 		CreatedAt: customer.CreatedAt,
-	}, nil
+	}
+	for _, balance := range balances {
+		resp.Balances = append(resp.Balances, dto.CustomerBalance{
+			Receivable: dto.Money{Amount: balance.ReceivableCents, Currency: balance.Currency},
+			Credit:     dto.Money{Amount: balance.CreditCents, Currency: balance.Currency},
+		})
+	}
+	if customer.Currency != nil {
+		resp.Currency = *customer.Currency
+	}
+	if customer.MergedIntoUUID != nil {
+		resp.MergedIntoUUID = *customer.MergedIntoUUID
+	}
+	return resp, nil
 }
@@ -22,9 +22,11 @@ func TestGetCustomerHandler_Handle(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
 
 		handler := &GetCustomerHandler{
 			CustomerRepo: mockCustomerRepo,
+			LedgerRepo:   mockLedgerRepo,
 		}
 
 		customerUUID := "customer-123"
@@ -32,12 +34,18 @@ func TestGetCustomerHandler_Handle(t *testing.T) {
 			UUID:      customerUUID,
 			Name:      "Test User",
 			Email:     "test@example.com",
+			Status:    entity.CustomerStatusActive,
 			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 		}
 
 		mockCustomerRepo.EXPECT().
 			FetchByUUID(gomock.Any(), customerUUID).
 			Return(customer, nil)
+		mockLedgerRepo.EXPECT().
+			FetchCustomerBalances(gomock.Any(), customerUUID).
+			Return([]*entity.CustomerBalanceEntity{
+				{Currency: "USD", ReceivableCents: 4500, CreditCents: 1000},
+			}, nil)
 
 		resp, err := handler.Handle(context.Background(), &dto.GetCustomerRequest{UUID: customerUUID})
 
@@ -45,7 +53,70 @@ func TestGetCustomerHandler_Handle(t *testing.T) {
 		assert.Equal(t, customerUUID, resp.UUID)
 		assert.Equal(t, "Test User", resp.Name)
 		assert.Equal(t, "test@example.com", resp.Email)
+		assert.Equal(t, "ACTIVE", resp.Status)
+		assert.Empty(t, resp.MergedIntoUUID)
 		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), resp.CreatedAt)
+		require.Len(t, resp.Balances, 1)
+		assert.Equal(t, dto.Money{Amount: 4500, Currency: "USD"}, resp.Balances[0].Receivable)
+		assert.Equal(t, dto.Money{Amount: 1000, Currency: "USD"}, resp.Balances[0].Credit)
+	})
+
+	t.Run("success - returns the customer a merged customer was merged into", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+
+		handler := &GetCustomerHandler{
+			CustomerRepo: mockCustomerRepo,
+			LedgerRepo:   mockLedgerRepo,
+		}
+
+		targetUUID := "customer-456"
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{
+				UUID:           "customer-123",
+				Status:         entity.CustomerStatusMerged,
+				MergedIntoUUID: &targetUUID,
+			}, nil)
+		mockLedgerRepo.EXPECT().
+			FetchCustomerBalances(gomock.Any(), "customer-123").
+			Return(nil, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetCustomerRequest{UUID: "customer-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "MERGED", resp.Status)
+		assert.Equal(t, targetUUID, resp.MergedIntoUUID)
+	})
+
+	t.Run("success - returns customer currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+
+		handler := &GetCustomerHandler{
+			CustomerRepo: mockCustomerRepo,
+			LedgerRepo:   mockLedgerRepo,
+		}
+
+		customerCurrency := "GEL"
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Currency: &customerCurrency}, nil)
+		mockLedgerRepo.EXPECT().
+			FetchCustomerBalances(gomock.Any(), "customer-123").
+			Return(nil, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetCustomerRequest{UUID: "customer-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "GEL", resp.Currency)
+		assert.Empty(t, resp.Balances)
 	})
 
 	t.Run("error - missing UUID", func(t *testing.T) {
@@ -101,4 +172,29 @@ func TestGetCustomerHandler_Handle(t *testing.T) {
 		assert.Nil(t, resp)
 		assert.Equal(t, utils.ErrInternal, err)
 	})
+
+	t.Run("error - balances unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+
+		handler := &GetCustomerHandler{
+			CustomerRepo: mockCustomerRepo,
+			LedgerRepo:   mockLedgerRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123"}, nil)
+		mockLedgerRepo.EXPECT().
+			FetchCustomerBalances(gomock.Any(), "customer-123").
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetCustomerRequest{UUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
 }
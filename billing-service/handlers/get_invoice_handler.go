@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/invoice"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+)
+
+type GetInvoiceHandler struct {
+	InvoiceRepo repository.InvoiceRepository
+	BlobStore   invoice.BlobStore
+}
+
+// Handle loads a stored rendering of a bill's invoice. Bills get an invoice once
+// closed, until then the invoice is not found.
+func (h *GetInvoiceHandler) Handle(ctx context.Context, req *dto.GetInvoiceRequest) (*dto.GetInvoiceResponse, error) {
+	if req.Format == "" {
+		req.Format = string(invoice.FormatJSON)
+	}
+
+	if validationErrors := validateGetInvoice(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	inv, err := h.InvoiceRepo.FetchByBillUUID(ctx, req.UUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrInvoiceNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+
+	format := invoice.Format(req.Format)
+	content, err := h.BlobStore.Get(ctx, invoice.ArtifactKey(inv.BillUUID, format))
+	if err != nil {
+		// the number is issued before the artifacts are stored
+		if errors.Is(err, invoice.ErrBlobNotFound) {
+			return nil, utils.ErrInvoiceNotFound
+		}
+		slog.ErrorContext(ctx, "error reading invoice artifact",
+			"bill_uuid", inv.BillUUID,
+			"format", req.Format,
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.GetInvoiceResponse{
+		InvoiceNumber: inv.Number,
+		ContentType:   format.ContentType(),
+		Content:       content,
+	}, nil
+}
+
+func validateGetInvoice(req *dto.GetInvoiceRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.UUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidUUID)
+	}
+	if !invoice.Format(req.Format).IsValid() {
+		validationErrors = append(validationErrors, utils.ErrInvalidInvoiceFormat)
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/invoice"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetInvoiceHandler_Handle(t *testing.T) {
+	billUUID := "bill-123"
+	storedInvoice := &entity.InvoiceEntity{
+		BillUUID:     billUUID,
+		CustomerUUID: "cust-456",
+		Sequence:     7,
+		Number:       "INV-CUST456-000007",
+	}
+
+	t.Run("success - defaults to json", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+		store := &invoice.LocalBlobStore{Dir: t.TempDir()}
+		require.NoError(t, store.Put(context.Background(), invoice.ArtifactKey(billUUID, invoice.FormatJSON), []byte(`{"number":"INV-CUST456-000007"}`), "application/json"))
+
+		handler := &GetInvoiceHandler{
+			InvoiceRepo: mockInvoiceRepo,
+			BlobStore:   store,
+		}
+
+		mockInvoiceRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID).
+			Return(storedInvoice, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetInvoiceRequest{UUID: billUUID})
+
+		require.NoError(t, err)
+		assert.Equal(t, "INV-CUST456-000007", resp.InvoiceNumber)
+		assert.Equal(t, "application/json", resp.ContentType)
+		assert.JSONEq(t, `{"number":"INV-CUST456-000007"}`, string(resp.Content))
+	})
+
+	t.Run("success - pdf format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+		store := &invoice.LocalBlobStore{Dir: t.TempDir()}
+		require.NoError(t, store.Put(context.Background(), invoice.ArtifactKey(billUUID, invoice.FormatPDF), []byte("%PDF-1.4"), "application/pdf"))
+
+		handler := &GetInvoiceHandler{
+			InvoiceRepo: mockInvoiceRepo,
+			BlobStore:   store,
+		}
+
+		mockInvoiceRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID).
+			Return(storedInvoice, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetInvoiceRequest{UUID: billUUID, Format: "pdf"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", resp.ContentType)
+		assert.Equal(t, "%PDF-1.4", string(resp.Content))
+	})
+
+	t.Run("error - validation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &GetInvoiceHandler{
+			InvoiceRepo: mocks.NewMockInvoiceRepository(ctrl),
+			BlobStore:   &invoice.LocalBlobStore{Dir: t.TempDir()},
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.GetInvoiceRequest{Format: "docx"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - invoice not issued", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+
+		handler := &GetInvoiceHandler{
+			InvoiceRepo: mockInvoiceRepo,
+			BlobStore:   &invoice.LocalBlobStore{Dir: t.TempDir()},
+		}
+
+		mockInvoiceRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID).
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetInvoiceRequest{UUID: billUUID})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInvoiceNotFound, err)
+	})
+
+	t.Run("error - artifact not stored yet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+
+		handler := &GetInvoiceHandler{
+			InvoiceRepo: mockInvoiceRepo,
+			BlobStore:   &invoice.LocalBlobStore{Dir: t.TempDir()},
+		}
+
+		mockInvoiceRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID).
+			Return(storedInvoice, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetInvoiceRequest{UUID: billUUID, Format: "html"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInvoiceNotFound, err)
+	})
+
+	t.Run("error - database failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+
+		handler := &GetInvoiceHandler{
+			InvoiceRepo: mockInvoiceRepo,
+			BlobStore:   &invoice.LocalBlobStore{Dir: t.TempDir()},
+		}
+
+		mockInvoiceRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID).
+			Return(nil, errors.New("connection refused"))
+
+		resp, err := handler.Handle(context.Background(), &dto.GetInvoiceRequest{UUID: billUUID})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
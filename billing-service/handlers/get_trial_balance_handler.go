@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+)
+
+type GetTrialBalanceHandler struct {
+	LedgerRepo repository.LedgerRepository
+}
+
+// Handle totals the ledger per currency and account type. The books balance
+// when every currency's debits equal its credits.
+func (h *GetTrialBalanceHandler) Handle(ctx context.Context, req *dto.GetTrialBalanceRequest) (*dto.GetTrialBalanceResponse, error) {
+	lines, err := h.LedgerRepo.FetchTrialBalance(ctx, req.Currency)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching trial balance",
+			"currency", req.Currency,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return mapTrialBalance(lines), nil
+}
+
+// mapTrialBalance groups the lines, ordered by currency, into one trial
+// balance per currency.
+func mapTrialBalance(lines []*entity.TrialBalanceLineEntity) *dto.GetTrialBalanceResponse {
+	resp := &dto.GetTrialBalanceResponse{Currencies: []dto.TrialBalanceCurrency{}, Balanced: true}
+	for _, line := range lines {
+		n := len(resp.Currencies)
+		if n == 0 || resp.Currencies[n-1].Currency != line.Currency {
+			resp.Currencies = append(resp.Currencies, dto.TrialBalanceCurrency{Currency: line.Currency})
+			n++
+		}
+		currency := &resp.Currencies[n-1]
+		currency.Accounts = append(currency.Accounts, dto.TrialBalanceAccount{
+			AccountType:  line.AccountType.String(),
+			DebitCents:   line.DebitCents,
+			CreditCents:  line.CreditCents,
+			BalanceCents: line.DebitCents - line.CreditCents,
+		})
+		currency.DebitCents += line.DebitCents
+		currency.CreditCents += line.CreditCents
+	}
+
+	for i := range resp.Currencies {
+		currency := &resp.Currencies[i]
+		currency.Balanced = currency.DebitCents == currency.CreditCents
+		resp.Balanced = resp.Balanced && currency.Balanced
+	}
+	return resp
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetTrialBalanceHandler_Handle(t *testing.T) {
+	t.Run("success - totals each currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+		handler := &GetTrialBalanceHandler{LedgerRepo: mockLedgerRepo}
+
+		mockLedgerRepo.EXPECT().
+			FetchTrialBalance(gomock.Any(), "").
+			Return([]*entity.TrialBalanceLineEntity{
+				{Currency: "GEL", AccountType: entity.AccountReceivable, DebitCents: 1180},
+				{Currency: "GEL", AccountType: entity.AccountRevenue, CreditCents: 1000},
+				{Currency: "GEL", AccountType: entity.AccountTaxPayable, CreditCents: 180},
+				{Currency: "USD", AccountType: entity.AccountCash, DebitCents: 500},
+				{Currency: "USD", AccountType: entity.AccountReceivable, DebitCents: 2000, CreditCents: 500},
+				{Currency: "USD", AccountType: entity.AccountRevenue, CreditCents: 2000},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetTrialBalanceRequest{})
+
+		require.NoError(t, err)
+		assert.True(t, resp.Balanced)
+		require.Len(t, resp.Currencies, 2)
+
+		gel := resp.Currencies[0]
+		assert.Equal(t, "GEL", gel.Currency)
+		assert.Len(t, gel.Accounts, 3)
+		assert.Equal(t, int64(1180), gel.DebitCents)
+		assert.Equal(t, int64(1180), gel.CreditCents)
+		assert.True(t, gel.Balanced)
+
+		usd := resp.Currencies[1]
+		assert.Equal(t, dto.TrialBalanceAccount{AccountType: "RECEIVABLE", DebitCents: 2000, CreditCents: 500, BalanceCents: 1500}, usd.Accounts[1])
+		assert.Equal(t, int64(2500), usd.DebitCents)
+		assert.True(t, usd.Balanced)
+	})
+
+	t.Run("success - reports books that do not balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+		handler := &GetTrialBalanceHandler{LedgerRepo: mockLedgerRepo}
+
+		mockLedgerRepo.EXPECT().
+			FetchTrialBalance(gomock.Any(), "USD").
+			Return([]*entity.TrialBalanceLineEntity{
+				{Currency: "USD", AccountType: entity.AccountReceivable, DebitCents: 2000},
+				{Currency: "USD", AccountType: entity.AccountRevenue, CreditCents: 1900},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetTrialBalanceRequest{Currency: "USD"})
+
+		require.NoError(t, err)
+		assert.False(t, resp.Balanced)
+		require.Len(t, resp.Currencies, 1)
+		assert.False(t, resp.Currencies[0].Balanced)
+	})
+
+	t.Run("success - empty ledger balances", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+		handler := &GetTrialBalanceHandler{LedgerRepo: mockLedgerRepo}
+
+		mockLedgerRepo.EXPECT().FetchTrialBalance(gomock.Any(), "").Return(nil, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetTrialBalanceRequest{})
+
+		require.NoError(t, err)
+		assert.True(t, resp.Balanced)
+		assert.Empty(t, resp.Currencies)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+		handler := &GetTrialBalanceHandler{LedgerRepo: mockLedgerRepo}
+
+		mockLedgerRepo.EXPECT().FetchTrialBalance(gomock.Any(), "").Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.GetTrialBalanceRequest{})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/currency"
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+)
+
+type GrantCreditHandler struct {
+	CustomerRepo repository.CustomerRepository
+	CreditRepo   repository.CreditRepository
+}
+
+// Handle grants credit to a customer. Repeating a request with the same
+// idempotency key returns the credit granted the first time.
+func (h *GrantCreditHandler) Handle(ctx context.Context, req *dto.GrantCreditRequest) (*dto.GrantCreditResponse, error) {
+	if validationErrors := validateGrantCredit(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	if _, err := h.CustomerRepo.FetchByUUID(ctx, req.CustomerUUID); err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrCustomerNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+
+	credit := &entity.CreditEntity{
+		UUID:           uuid.New().String(),
+		CustomerUUID:   req.CustomerUUID,
+		IdempotencyKey: req.IdempotencyKey,
+		Currency:       req.Amount.Currency,
+		AmountCents:    req.Amount.Amount,
+	}
+	if req.Description != "" {
+		credit.Description = &req.Description
+	}
+
+	generatedUUID := credit.UUID
+	if err := h.CreditRepo.Insert(ctx, credit); err != nil {
+		slog.ErrorContext(ctx, "error granting credit",
+			"customer_uuid", req.CustomerUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	// the key was used before, only an identical request gets the stored credit back
+	if credit.UUID != generatedUUID &&
+		(credit.AmountCents != req.Amount.Amount || credit.Currency != req.Amount.Currency) {
+		return nil, utils.ErrDuplicateIdempotencyKey
+	}
+
+	balance, err := h.CreditRepo.FetchAvailable(ctx, credit.CustomerUUID, credit.Currency)
+	if err != nil {
+		return nil, utils.ErrInternal
+	}
+
+	resp := &dto.GrantCreditResponse{
+		UUID:         credit.UUID,
+		CustomerUUID: credit.CustomerUUID,
+		Amount:       dto.Money{Amount: credit.AmountCents, Currency: credit.Currency},
+		Remaining:    dto.Money{Amount: credit.RemainingCents, Currency: credit.Currency},
+		CreatedAt:    credit.CreatedAt.Format(time.RFC3339),
+		Balance:      dto.Money{Amount: balance, Currency: credit.Currency},
+	}
+	if credit.Description != nil {
+		resp.Description = *credit.Description
+	}
+	return resp, nil
+}
+
+func validateGrantCredit(req *dto.GrantCreditRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.CustomerUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidCustomerUUID)
+	}
+	if req.IdempotencyKey == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidIdempotencyKey)
+	}
+	if req.Amount.Amount <= 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidAmount)
+	}
+	if !currency.IsValid(req.Amount.Currency) {
+		validationErrors = append(validationErrors, utils.ErrInvalidCurrency)
+	}
+
+	return validationErrors
+}
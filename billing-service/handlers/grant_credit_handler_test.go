@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGrantCreditHandler_Handle(t *testing.T) {
+	t.Run("success - grants credit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+
+		handler := &GrantCreditHandler{
+			CustomerRepo: mockCustomerRepo,
+			CreditRepo:   mockCreditRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123"}, nil)
+
+		mockCreditRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, credit *entity.CreditEntity) error {
+				assert.Equal(t, "customer-123", credit.CustomerUUID)
+				assert.Equal(t, int64(2500), credit.AmountCents)
+				assert.Equal(t, "USD", credit.Currency)
+				credit.RemainingCents = credit.AmountCents
+				credit.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				return nil
+			})
+
+		mockCreditRepo.EXPECT().
+			FetchAvailable(gomock.Any(), "customer-123", "USD").
+			Return(int64(4000), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.GrantCreditRequest{
+			CustomerUUID:   "customer-123",
+			IdempotencyKey: "goodwill-1",
+			Amount:         dto.Money{Amount: 2500, Currency: "USD"},
+			Description:    "Goodwill credit",
+		})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.UUID)
+		assert.Equal(t, dto.Money{Amount: 2500, Currency: "USD"}, resp.Remaining)
+		assert.Equal(t, dto.Money{Amount: 4000, Currency: "USD"}, resp.Balance)
+		assert.Equal(t, "Goodwill credit", resp.Description)
+		assert.Equal(t, "2024-01-01T00:00:00Z", resp.CreatedAt)
+	})
+
+	t.Run("error - validation fails - invalid amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &GrantCreditHandler{
+			CustomerRepo: mocks.NewMockCustomerRepository(ctrl),
+			CreditRepo:   mocks.NewMockCreditRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.GrantCreditRequest{
+			CustomerUUID:   "customer-123",
+			IdempotencyKey: "goodwill-1",
+			Amount:         dto.Money{Amount: -100, Currency: "USD"},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+
+		handler := &GrantCreditHandler{
+			CustomerRepo: mockCustomerRepo,
+			CreditRepo:   mocks.NewMockCreditRepository(ctrl),
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.GrantCreditRequest{
+			CustomerUUID:   "customer-123",
+			IdempotencyKey: "goodwill-1",
+			Amount:         dto.Money{Amount: 2500, Currency: "USD"},
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerNotFoundAPI, err)
+	})
+
+	t.Run("error - idempotency key reused with another amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+
+		handler := &GrantCreditHandler{
+			CustomerRepo: mockCustomerRepo,
+			CreditRepo:   mockCreditRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123"}, nil)
+
+		mockCreditRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, credit *entity.CreditEntity) error {
+				*credit = entity.CreditEntity{
+					UUID:           "credit-existing",
+					CustomerUUID:   "customer-123",
+					IdempotencyKey: "goodwill-1",
+					Currency:       "USD",
+					AmountCents:    1000,
+					RemainingCents: 1000,
+				}
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.GrantCreditRequest{
+			CustomerUUID:   "customer-123",
+			IdempotencyKey: "goodwill-1",
+			Amount:         dto.Money{Amount: 2500, Currency: "USD"},
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrDuplicateIdempotencyKey, err)
+	})
+
+	t.Run("error - insert failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+
+		handler := &GrantCreditHandler{
+			CustomerRepo: mockCustomerRepo,
+			CreditRepo:   mockCreditRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123"}, nil)
+
+		mockCreditRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.GrantCreditRequest{
+			CustomerUUID:   "customer-123",
+			IdempotencyKey: "goodwill-1",
+			Amount:         dto.Money{Amount: 2500, Currency: "USD"},
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
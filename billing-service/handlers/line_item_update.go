@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"encore.app/entity"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+
+	"encore.dev/rlog"
+	"go.temporal.io/api/serviceerror"
+	tclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// updateLineItem sends the add line item update to the bill workflow and waits
+// for the persisted row. The idempotency key doubles as the update ID so a
+// retried request is deduplicated by Temporal as well as by the database.
+func updateLineItem(ctx context.Context, client t.WorkflowClient, billUUID string, update tbill.AddLineItemUpdate) (*entity.LineItemEntity, error) {
+	return runLineItemUpdate(ctx, client, billUUID, "line-item-"+update.IdempotencyKey, tbill.UpdateAddLineItem, update)
+}
+
+// runLineItemUpdate sends a line item update to the bill workflow and waits for
+// the persisted row. An empty updateID lets Temporal generate one.
+func runLineItemUpdate(ctx context.Context, client t.WorkflowClient, billUUID, updateID, updateName string, arg interface{}) (*entity.LineItemEntity, error) {
+	var lineItem entity.LineItemEntity
+	if err := runBillUpdate(ctx, client, billUUID, updateID, updateName, arg, &lineItem); err != nil {
+		return nil, err
+	}
+	return &lineItem, nil
+}
+
+// runBillUpdate sends an update to the bill workflow and decodes its outcome
+// into result.
+func runBillUpdate(ctx context.Context, client t.WorkflowClient, billUUID, updateID, updateName string, arg, result interface{}) error {
+	workflowID := t.BillWorkflowIDPrefix + billUUID
+
+	handle, err := client.UpdateWorkflow(ctx, tclient.UpdateWorkflowOptions{
+		UpdateID:     updateID,
+		WorkflowID:   workflowID,
+		UpdateName:   updateName,
+		Args:         []interface{}{arg},
+		WaitForStage: tclient.WorkflowUpdateStageCompleted,
+	})
+	if err != nil {
+		return mapLineItemUpdateError(err, billUUID, workflowID)
+	}
+
+	if err := handle.Get(ctx, result); err != nil {
+		return mapLineItemUpdateError(err, billUUID, workflowID)
+	}
+
+	return nil
+}
+
+func mapLineItemUpdateError(err error, billUUID, workflowID string) error {
+	// the bill was open when the handler checked it, so its workflow completed
+	// the close in between
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		rlog.Warn("workflow completed between bill check and update",
+			"bill_uuid", billUUID,
+			"workflow_id", workflowID)
+		return utils.ErrBillClosed
+	}
+
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		switch appErr.Type() {
+		case tbill.ErrTypeBillClosed:
+			return utils.ErrBillClosed
+		case tbill.ErrTypeCurrencyMismatch:
+			return utils.ErrCurrencyMismatch
+		case tbill.ErrTypeDuplicateKey:
+			return utils.ErrDuplicateIdempotencyKey
+		case tbill.ErrTypeInvalidLineItem:
+			return utils.ErrInvalidLineItem
+		case tbill.ErrTypeLineItemNotFailed:
+			return utils.ErrLineItemNotFailed
+		case tbill.ErrTypeReversalExceedsLineItem:
+			return utils.ErrReversalExceedsRemaining
+		}
+	}
+
+	rlog.Error("failed to update workflow",
+		"bill_uuid", billUUID,
+		"error", err)
+	return utils.ErrWorkflowUpdateFailed
+}
@@ -22,23 +22,28 @@ type ListBillsHandler struct {
 }
 
 func (h *ListBillsHandler) Handle(ctx context.Context, req *dto.ListBillsRequest) (*dto.ListBillsResponse, error) {
-	// 1. Apply default/max limit
+	// 1. Validate status filter, CLOSING finds bills stuck mid-close
+	if req.Status != "" && !entity.BillStatus(req.Status).IsValid() {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidStatus})
+	}
+
+	// 2. Apply default/max limit
 	limit := req.Limit
 	if limit <= 0 || limit > maxLimit {
 		limit = defaultLimit
 	}
 
-	// 2. Determine sort order (default: desc)
+	// 3. Determine sort order (default: desc)
 	sortDesc := req.SortOrder != dto.SortOrderAsc
 
-	// 3. Decode cursor
+	// 4. Decode cursor
 	cursorTime, cursorID, err := utils.DecodeCursor(req.Cursor)
 	if err != nil {
 		slog.ErrorContext(ctx, "invalid cursor", "cursor", req.Cursor, "err", err)
 		return nil, utils.ErrInvalidCursor
 	}
 
-	// 4. Fetch bills from DB (fetch limit+1 to determine has_more)
+	// 5. Fetch bills from DB (fetch limit+1 to determine has_more)
 	bills, err := h.BillRepo.FetchAll(ctx, db.BillQueryParams{
 		CustomerUUID: req.CustomerUUID,
 		Status:       req.Status,
@@ -56,7 +61,7 @@ func (h *ListBillsHandler) Handle(ctx context.Context, req *dto.ListBillsRequest
 		return nil, utils.ErrInternal
 	}
 
-	// 5. Determine pagination
+	// 6. Determine pagination
 	hasMore := len(bills) > limit
 	if hasMore {
 		bills = bills[:limit]
@@ -68,13 +73,13 @@ func (h *ListBillsHandler) Handle(ctx context.Context, req *dto.ListBillsRequest
 		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
 	}
 
-	// 6. Map entities to DTOs
+	// 7. Map entities to DTOs
 	data := make([]dto.BillSummary, len(bills))
 	for i, bill := range bills {
 		data[i] = mapBillToSummary(bill)
 	}
 
-	// 7. Return response
+	// 8. Return response
 	return &dto.ListBillsResponse{
 		Data: data,
 		Pagination: dto.PaginationResponse{
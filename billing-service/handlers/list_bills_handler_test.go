@@ -225,6 +225,22 @@ func TestListBillsHandler_Handle(t *testing.T) {
 		assert.Equal(t, utils.ErrInvalidCursor, err)
 	})
 
+	t.Run("error - invalid status filter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListBillsHandler{
+			BillRepo: mocks.NewMockBillRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListBillsRequest{
+			Status: "PENDING",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
 	t.Run("error - internal error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/db"
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+)
+
+type ListCustomersHandler struct {
+	CustomerRepo repository.CustomerRepository
+}
+
+// Handle pages through the customers, newest first, optionally searching by
+// name or email prefix.
+func (h *ListCustomersHandler) Handle(ctx context.Context, req *dto.ListCustomersRequest) (*dto.ListCustomersResponse, error) {
+	if req.Status != "" && !entity.CustomerStatus(req.Status).IsValid() {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidCustomerStatus})
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	cursorTime, cursorID, err := utils.DecodeCursor(req.Cursor)
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid cursor", "cursor", req.Cursor, "err", err)
+		return nil, utils.ErrInvalidCursor
+	}
+
+	// fetch limit+1 to determine has_more
+	customers, err := h.CustomerRepo.FetchAll(ctx, db.CustomerQueryParams{
+		Name:       req.Name,
+		Email:      req.Email,
+		Status:     req.Status,
+		CursorTime: cursorTime,
+		CursorID:   cursorID,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching customers",
+			"status", req.Status,
+			"cursor", req.Cursor,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	hasMore := len(customers) > limit
+	if hasMore {
+		customers = customers[:limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(customers) > 0 {
+		last := customers[len(customers)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	data := make([]dto.CustomerSummary, len(customers))
+	for i, customer := range customers {
+		data[i] = mapCustomerToSummary(customer)
+	}
+
+	return &dto.ListCustomersResponse{
+		Data: data,
+		Pagination: dto.PaginationResponse{
+			NextCursor: nextCursor,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
+
+func mapCustomerToSummary(c *entity.CustomerEntity) dto.CustomerSummary {
+	summary := dto.CustomerSummary{
+		UUID:       c.UUID,
+		Name:       c.Name,
+		Email:      c.Email,
+		Status:     c.Status.String(),
+		ArchivedAt: c.ArchivedAt,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+	}
+	if c.Currency != nil {
+		summary.Currency = *c.Currency
+	}
+	if c.MergedIntoUUID != nil {
+		summary.MergedIntoUUID = *c.MergedIntoUUID
+	}
+	return summary
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db"
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListCustomersHandler_Handle(t *testing.T) {
+	t.Run("success - searches by name prefix", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ListCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		currency := "EUR"
+
+		mockCustomerRepo.EXPECT().
+			FetchAll(gomock.Any(), db.CustomerQueryParams{Name: "ali", Status: "ACTIVE", Limit: 2}).
+			Return([]*entity.CustomerEntity{
+				{ID: 2, UUID: "customer-2", Name: "Alice", Email: "alice@example.com", Currency: &currency,
+					Status: entity.CustomerStatusActive, CreatedAt: createdAt},
+				{ID: 1, UUID: "customer-1", Name: "Alina", Email: "alina@example.com",
+					Status: entity.CustomerStatusActive, CreatedAt: createdAt},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListCustomersRequest{
+			Name:   "ali",
+			Status: "ACTIVE",
+			Limit:  1,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "customer-2", resp.Data[0].UUID)
+		assert.Equal(t, "Alice", resp.Data[0].Name)
+		assert.Equal(t, "EUR", resp.Data[0].Currency)
+		assert.Equal(t, "ACTIVE", resp.Data[0].Status)
+		assert.True(t, resp.Pagination.HasMore)
+		assert.Equal(t, utils.EncodeCursor(createdAt, 2), resp.Pagination.NextCursor)
+	})
+
+	t.Run("success - next page by email prefix", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ListCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		cursorTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		targetUUID := "customer-2"
+
+		mockCustomerRepo.EXPECT().
+			FetchAll(gomock.Any(), db.CustomerQueryParams{
+				Email:      "bob@",
+				CursorTime: cursorTime,
+				CursorID:   7,
+				Limit:      defaultLimit + 1,
+			}).
+			Return([]*entity.CustomerEntity{
+				{ID: 3, UUID: "customer-3", Email: "bob@example.com", Status: entity.CustomerStatusMerged,
+					MergedIntoUUID: &targetUUID},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListCustomersRequest{
+			Email:  "bob@",
+			Cursor: utils.EncodeCursor(cursorTime, 7),
+		})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "MERGED", resp.Data[0].Status)
+		assert.Equal(t, targetUUID, resp.Data[0].MergedIntoUUID)
+		assert.False(t, resp.Pagination.HasMore)
+		assert.Empty(t, resp.Pagination.NextCursor)
+	})
+
+	t.Run("error - invalid status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListCustomersHandler{CustomerRepo: mocks.NewMockCustomerRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListCustomersRequest{Status: "DELETED"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - invalid cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListCustomersHandler{CustomerRepo: mocks.NewMockCustomerRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListCustomersRequest{Cursor: "not-a-cursor"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInvalidCursor, err)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &ListCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			FetchAll(gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListCustomersRequest{})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
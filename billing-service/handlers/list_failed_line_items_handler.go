@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+	"go.temporal.io/api/serviceerror"
+)
+
+type ListFailedLineItemsHandler struct {
+	BillRepo       repository.BillRepository
+	TemporalClient t.WorkflowClient
+}
+
+func (h *ListFailedLineItemsHandler) Handle(ctx context.Context, req *dto.ListFailedLineItemsRequest) (*dto.ListFailedLineItemsResponse, error) {
+	if req.BillUUID == "" {
+		return nil, utils.ErrUUIDMissing
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		rlog.Error("error fetching bill", "bill_uuid", req.BillUUID, "error", err)
+		return nil, utils.ErrInternal
+	}
+
+	// a bill is only closed once its failed line items were replayed
+	if !bill.HasRunningWorkflow() {
+		return &dto.ListFailedLineItemsResponse{Data: []dto.FailedLineItemSummary{}}, nil
+	}
+
+	failed, err := h.queryFailedLineItems(ctx, req.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.FailedLineItemSummary, len(failed))
+	for i, f := range failed {
+		data[i] = mapFailedLineItemToSummary(f, bill.Currency)
+	}
+
+	return &dto.ListFailedLineItemsResponse{Data: data}, nil
+}
+
+func (h *ListFailedLineItemsHandler) queryFailedLineItems(ctx context.Context, billUUID string) ([]tbill.FailedLineItem, error) {
+	workflowID := t.BillWorkflowIDPrefix + billUUID
+
+	queryResp, err := h.TemporalClient.QueryWorkflow(ctx, workflowID, "", tbill.QueryGetFailedLineItems)
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			rlog.Error("data inconsistency: bill exists but workflow not found",
+				"bill_uuid", billUUID,
+				"workflow_id", workflowID)
+			return nil, utils.ErrWorkflowNotFound
+		}
+
+		rlog.Error("failed to query failed line items",
+			"bill_uuid", billUUID,
+			"error", err)
+		return nil, utils.ErrWorkflowQueryFailed
+	}
+
+	var failed []tbill.FailedLineItem
+	if err := queryResp.Get(&failed); err != nil {
+		rlog.Error("failed to decode failed line items",
+			"bill_uuid", billUUID,
+			"error", err)
+		return nil, utils.ErrWorkflowQueryFailed
+	}
+
+	return failed, nil
+}
+
+func mapFailedLineItemToSummary(f tbill.FailedLineItem, currency string) dto.FailedLineItemSummary {
+	summary := dto.FailedLineItemSummary{
+		UUID:           f.Signal.UUID,
+		IdempotencyKey: f.Signal.IdempotencyKey,
+		FeeType:        f.Signal.FeeType,
+		Description:    f.Signal.Description,
+		Amount: dto.Money{
+			Amount:   f.Signal.AmountCents,
+			Currency: currency,
+		},
+		Error:    f.Error,
+		Attempts: f.Attempts,
+		FailedAt: f.FailedAt.Format(time.RFC3339),
+	}
+	if f.Signal.ReferenceUUID != nil {
+		summary.ReferenceUUID = *f.Signal.ReferenceUUID
+	}
+	return summary
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/converter"
+	"go.uber.org/mock/gomock"
+)
+
+// mockEncodedValue implements converter.EncodedValue for testing QueryWorkflow
+type mockEncodedValue struct {
+	value interface{}
+}
+
+func (m *mockEncodedValue) Get(valuePtr interface{}) error {
+	if m.value != nil {
+		reflect.ValueOf(valuePtr).Elem().Set(reflect.ValueOf(m.value))
+	}
+	return nil
+}
+
+func (m *mockEncodedValue) HasValue() bool {
+	return m.value != nil
+}
+
+func newMockEncodedValue(value interface{}) converter.EncodedValue {
+	return &mockEncodedValue{value: value}
+}
+
+func TestListFailedLineItemsHandler_Handle(t *testing.T) {
+	t.Run("success - lists failed line items", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ListFailedLineItemsHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		billUUID := "bill-123"
+		referenceUUID := "li-original"
+		failedAt := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Status: "OPEN", Currency: "USD"}, nil)
+
+		mockTemporalClient.EXPECT().
+			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetFailedLineItems).
+			Return(newMockEncodedValue([]tbill.FailedLineItem{
+				{
+					Signal: tbill.AddLineItemSignal{
+						UUID:           "li-failed",
+						IdempotencyKey: "idem-key",
+						FeeType:        "REVERSAL",
+						AmountCents:    -500,
+						ReferenceUUID:  &referenceUUID,
+					},
+					Error:    "connection refused",
+					FailedAt: failedAt,
+					Attempts: 2,
+				},
+			}), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListFailedLineItemsRequest{BillUUID: billUUID})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "li-failed", resp.Data[0].UUID)
+		assert.Equal(t, "idem-key", resp.Data[0].IdempotencyKey)
+		assert.Equal(t, int64(-500), resp.Data[0].Amount.Amount)
+		assert.Equal(t, "USD", resp.Data[0].Amount.Currency)
+		assert.Equal(t, referenceUUID, resp.Data[0].ReferenceUUID)
+		assert.Equal(t, "connection refused", resp.Data[0].Error)
+		assert.Equal(t, 2, resp.Data[0].Attempts)
+		assert.Equal(t, "2026-01-15T10:00:00Z", resp.Data[0].FailedAt)
+	})
+
+	t.Run("success - closed bill has no failed line items", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &ListFailedLineItemsHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListFailedLineItemsRequest{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Data)
+	})
+
+	t.Run("error - missing bill UUID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListFailedLineItemsHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListFailedLineItemsRequest{})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrUUIDMissing, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &ListFailedLineItemsHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListFailedLineItemsRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotFoundAPI, err)
+	})
+
+	t.Run("error - query failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ListFailedLineItemsHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockTemporalClient.EXPECT().
+			QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("temporal unavailable"))
+
+		resp, err := handler.Handle(context.Background(), &dto.ListFailedLineItemsRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWorkflowQueryFailed, err)
+	})
+}
@@ -97,5 +97,9 @@ func mapLineItemToSummary(li *entity.LineItemEntity, currency string) dto.LineIt
 	if li.ReferenceUUID != nil {
 		summary.ReferenceUUID = *li.ReferenceUUID
 	}
+	summary.Original, summary.FXRate = mapLineItemConversion(li)
+	if li.FeeType != entity.FeeTypeReversal.String() {
+		summary.RemainingReversible = &dto.Money{Amount: li.RemainingReversibleCents(), Currency: currency}
+	}
 	return summary
 }
@@ -198,6 +198,40 @@ func TestListLineItemsHandler_Handle(t *testing.T) {
 		assert.Equal(t, originalUUID, resp.Data[0].ReferenceUUID)
 	})
 
+	t.Run("success - returns what is left to reverse", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		handler := &ListLineItemsHandler{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		originalUUID := "item-1"
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), "bill-123", time.Time{}, int64(0), 21).
+			Return([]*entity.LineItemEntity{
+				{ID: 1, UUID: "item-1", BillUUID: "bill-123", FeeType: "ACH", AmountCents: 1000, ReversedCents: 300},
+				{ID: 2, UUID: "item-2", BillUUID: "bill-123", FeeType: "REVERSAL", AmountCents: -300, ReferenceUUID: &originalUUID},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListLineItemsRequest{
+			BillUUID: "bill-123",
+		})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, &dto.Money{Amount: 700, Currency: "USD"}, resp.Data[0].RemainingReversible)
+		assert.Nil(t, resp.Data[1].RemainingReversible)
+	})
+
 	t.Run("error - missing bill UUID", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+)
+
+type ListPaymentsHandler struct {
+	BillRepo       repository.BillRepository
+	PaymentRepo    repository.PaymentRepository
+	SettlementRepo repository.SettlementRepository
+}
+
+// Handle lists a bill's payments with the balance they leave and the bank
+// transfer settling the bill, if any.
+func (h *ListPaymentsHandler) Handle(ctx context.Context, req *dto.ListPaymentsRequest) (*dto.ListPaymentsResponse, error) {
+	if req.BillUUID == "" {
+		return nil, utils.ErrValidationFailedWithDetails([]utils.ValidationError{utils.ErrInvalidBillUUID})
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+
+	payments, err := h.PaymentRepo.FetchByBillUUID(ctx, req.BillUUID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching payments",
+			"bill_uuid", req.BillUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	data := make([]dto.PaymentSummary, len(payments))
+	for i, payment := range payments {
+		data[i] = mapPaymentToSummary(payment)
+	}
+
+	var totalCents int64
+	if bill.TotalCents != nil {
+		totalCents = *bill.TotalCents
+	}
+
+	response := &dto.ListPaymentsResponse{
+		Data:    data,
+		Status:  bill.Status,
+		Total:   dto.Money{Amount: totalCents, Currency: bill.Currency},
+		Paid:    dto.Money{Amount: bill.PaidCents, Currency: bill.Currency},
+		Balance: dto.Money{Amount: bill.BalanceCents(), Currency: bill.Currency},
+	}
+	if bill.DueDate != nil {
+		response.DueDate = bill.DueDate.Format(time.RFC3339)
+	}
+
+	settlement, err := h.SettlementRepo.FetchByBillUUID(ctx, req.BillUUID)
+	switch {
+	case err == nil:
+		response.Settlement = mapSettlementToSummary(settlement, bill.Currency)
+	case !errors.Is(err, sqldb.ErrNoRows):
+		slog.ErrorContext(ctx, "error fetching settlement",
+			"bill_uuid", req.BillUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return response, nil
+}
+
+func mapSettlementToSummary(settlement *entity.SettlementEntity, currency string) *dto.SettlementSummary {
+	summary := &dto.SettlementSummary{
+		UUID:          settlement.UUID,
+		Status:        settlement.Status.String(),
+		SourceAccount: settlement.SourceAccount,
+		TargetAccount: settlement.TargetAccount,
+		Amount:        dto.Money{Amount: settlement.AmountCents, Currency: currency},
+	}
+	if settlement.PaymentUUID != nil {
+		summary.PaymentUUID = *settlement.PaymentUUID
+	}
+	if settlement.CreditUUID != nil {
+		summary.CreditUUID = *settlement.CreditUUID
+	}
+	if settlement.FailureReason != nil {
+		summary.FailureReason = *settlement.FailureReason
+	}
+	return summary
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListPaymentsHandler_Handle(t *testing.T) {
+	total := int64(1000)
+	dueDate := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success - lists payments with balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		billRepo := mocks.NewMockBillRepository(ctrl)
+		paymentRepo := mocks.NewMockPaymentRepository(ctrl)
+		settlementRepo := mocks.NewMockSettlementRepository(ctrl)
+		handler := &ListPaymentsHandler{BillRepo: billRepo, PaymentRepo: paymentRepo, SettlementRepo: settlementRepo}
+
+		settlementRepo.EXPECT().FetchByBillUUID(gomock.Any(), "bill-123").Return(nil, sqldb.ErrNoRows)
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{
+				UUID:       "bill-123",
+				Status:     "PARTIALLY_PAID",
+				Currency:   "USD",
+				TotalCents: &total,
+				PaidCents:  700,
+				DueDate:    &dueDate,
+			}, nil)
+		paymentRepo.EXPECT().FetchByBillUUID(gomock.Any(), "bill-123").
+			Return([]*entity.PaymentEntity{
+				{UUID: "p1", BillUUID: "bill-123", IdempotencyKey: "k1", AmountCents: 300, Currency: "USD"},
+				{UUID: "p2", BillUUID: "bill-123", IdempotencyKey: "k2", AmountCents: 400, Currency: "USD"},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListPaymentsRequest{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, "p1", resp.Data[0].UUID)
+		assert.Equal(t, "PARTIALLY_PAID", resp.Status)
+		assert.Equal(t, "2026-03-01T00:00:00Z", resp.DueDate)
+		assert.Equal(t, int64(1000), resp.Total.Amount)
+		assert.Equal(t, int64(700), resp.Paid.Amount)
+		assert.Equal(t, int64(300), resp.Balance.Amount)
+		assert.Nil(t, resp.Settlement)
+	})
+
+	t.Run("success - includes failed settlement", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		billRepo := mocks.NewMockBillRepository(ctrl)
+		paymentRepo := mocks.NewMockPaymentRepository(ctrl)
+		settlementRepo := mocks.NewMockSettlementRepository(ctrl)
+		handler := &ListPaymentsHandler{BillRepo: billRepo, PaymentRepo: paymentRepo, SettlementRepo: settlementRepo}
+
+		reason := "deposit failed, money returned"
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "FINALIZED", Currency: "USD", TotalCents: &total}, nil)
+		paymentRepo.EXPECT().FetchByBillUUID(gomock.Any(), "bill-123").Return(nil, nil)
+		settlementRepo.EXPECT().FetchByBillUUID(gomock.Any(), "bill-123").
+			Return(&entity.SettlementEntity{
+				UUID:          "settlement-1",
+				BillUUID:      "bill-123",
+				SourceAccount: "85-150",
+				TargetAccount: "43-812",
+				AmountCents:   1000,
+				Status:        entity.SettlementStatusFailed,
+				FailureReason: &reason,
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListPaymentsRequest{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Data)
+		require.NotNil(t, resp.Settlement)
+		assert.Equal(t, "FAILED", resp.Settlement.Status)
+		assert.Equal(t, reason, resp.Settlement.FailureReason)
+		assert.Equal(t, dto.Money{Amount: 1000, Currency: "USD"}, resp.Settlement.Amount)
+	})
+
+	t.Run("error - missing bill uuid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListPaymentsHandler{}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListPaymentsRequest{})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		billRepo := mocks.NewMockBillRepository(ctrl)
+		handler := &ListPaymentsHandler{BillRepo: billRepo}
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListPaymentsRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotFoundAPI, err)
+	})
+
+	t.Run("error - fetching payments fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		billRepo := mocks.NewMockBillRepository(ctrl)
+		paymentRepo := mocks.NewMockPaymentRepository(ctrl)
+		handler := &ListPaymentsHandler{BillRepo: billRepo, PaymentRepo: paymentRepo}
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD"}, nil)
+		paymentRepo.EXPECT().FetchByBillUUID(gomock.Any(), "bill-123").Return(nil, errors.New("boom"))
+
+		resp, err := handler.Handle(context.Background(), &dto.ListPaymentsRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
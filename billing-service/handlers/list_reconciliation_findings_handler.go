@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/db"
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+)
+
+type ListReconciliationFindingsHandler struct {
+	ReconciliationRepo repository.ReconciliationRepository
+}
+
+// Handle pages through the reconciliation job's findings, newest first.
+func (h *ListReconciliationFindingsHandler) Handle(ctx context.Context, req *dto.ListReconciliationFindingsRequest) (*dto.ListReconciliationFindingsResponse, error) {
+	if req.Status != "" && !entity.ReconciliationFindingStatus(req.Status).IsValid() {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidFindingStatus})
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	cursorTime, cursorID, err := utils.DecodeCursor(req.Cursor)
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid cursor", "cursor", req.Cursor, "err", err)
+		return nil, utils.ErrInvalidCursor
+	}
+
+	// fetch limit+1 to determine has_more
+	findings, err := h.ReconciliationRepo.FetchFindings(ctx, db.ReconciliationFindingQueryParams{
+		BillUUID:   req.BillUUID,
+		Status:     req.Status,
+		CursorTime: cursorTime,
+		CursorID:   cursorID,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching reconciliation findings",
+			"bill_uuid", req.BillUUID,
+			"status", req.Status,
+			"cursor", req.Cursor,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	hasMore := len(findings) > limit
+	if hasMore {
+		findings = findings[:limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(findings) > 0 {
+		last := findings[len(findings)-1]
+		nextCursor = utils.EncodeCursor(last.DetectedAt, last.ID)
+	}
+
+	data := make([]dto.ReconciliationFindingSummary, len(findings))
+	for i, finding := range findings {
+		data[i] = mapReconciliationFindingToSummary(finding)
+	}
+
+	return &dto.ListReconciliationFindingsResponse{
+		Data: data,
+		Pagination: dto.PaginationResponse{
+			NextCursor: nextCursor,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
+
+func mapReconciliationFindingToSummary(f *entity.ReconciliationFindingEntity) dto.ReconciliationFindingSummary {
+	return dto.ReconciliationFindingSummary{
+		UUID:               f.UUID,
+		BillUUID:           f.BillUUID,
+		Kind:               f.Kind.String(),
+		Status:             f.Status.String(),
+		BillStatus:         f.BillStatus.String(),
+		BillTotalCents:     f.BillTotalCents,
+		LineItemsCents:     f.LineItemsCents,
+		LedgerCents:        f.LedgerCents,
+		WorkflowTotalCents: f.WorkflowTotalCents,
+		DetectedAt:         f.DetectedAt,
+		LastSeenAt:         f.LastSeenAt,
+		ResolvedAt:         f.ResolvedAt,
+	}
+}
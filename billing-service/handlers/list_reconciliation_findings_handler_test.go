@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db"
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListReconciliationFindingsHandler_Handle(t *testing.T) {
+	t.Run("success - pages through findings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReconciliationRepository(ctrl)
+		handler := &ListReconciliationFindingsHandler{ReconciliationRepo: mockRepo}
+
+		detectedAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		workflowTotal := int64(1500)
+
+		mockRepo.EXPECT().
+			FetchFindings(gomock.Any(), db.ReconciliationFindingQueryParams{Status: "OPEN", Limit: 2}).
+			Return([]*entity.ReconciliationFindingEntity{
+				{ID: 2, UUID: "finding-2", BillUUID: "bill-1", Kind: entity.FindingWorkflowTotalMismatch,
+					Status: entity.FindingStatusOpen, BillStatus: entity.BillStatusOpen, BillTotalCents: 1000,
+					LineItemsCents: 1000, WorkflowTotalCents: &workflowTotal, DetectedAt: detectedAt, LastSeenAt: detectedAt},
+				{ID: 1, UUID: "finding-1", BillUUID: "bill-2", Kind: entity.FindingBillTotalMismatch,
+					Status: entity.FindingStatusOpen, DetectedAt: detectedAt, LastSeenAt: detectedAt},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListReconciliationFindingsRequest{
+			Status: "OPEN",
+			Limit:  1,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "finding-2", resp.Data[0].UUID)
+		assert.Equal(t, "WORKFLOW_TOTAL_MISMATCH", resp.Data[0].Kind)
+		assert.Equal(t, "OPEN", resp.Data[0].BillStatus)
+		assert.Equal(t, int64(1000), resp.Data[0].LineItemsCents)
+		assert.Equal(t, &workflowTotal, resp.Data[0].WorkflowTotalCents)
+		assert.True(t, resp.Pagination.HasMore)
+		assert.Equal(t, utils.EncodeCursor(detectedAt, 2), resp.Pagination.NextCursor)
+	})
+
+	t.Run("error - invalid status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListReconciliationFindingsHandler{ReconciliationRepo: mocks.NewMockReconciliationRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListReconciliationFindingsRequest{Status: "CLOSED"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - invalid cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListReconciliationFindingsHandler{ReconciliationRepo: mocks.NewMockReconciliationRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListReconciliationFindingsRequest{Cursor: "not-a-cursor"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInvalidCursor, err)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReconciliationRepository(ctrl)
+		handler := &ListReconciliationFindingsHandler{ReconciliationRepo: mockRepo}
+
+		mockRepo.EXPECT().
+			FetchFindings(gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListReconciliationFindingsRequest{})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
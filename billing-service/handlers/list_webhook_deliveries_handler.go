@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+)
+
+type ListWebhookDeliveriesHandler struct {
+	WebhookRepo repository.WebhookRepository
+}
+
+// Handle pages through a subscription's delivery log, newest first.
+func (h *ListWebhookDeliveriesHandler) Handle(ctx context.Context, req *dto.ListWebhookDeliveriesRequest) (*dto.ListWebhookDeliveriesResponse, error) {
+	if req.SubscriptionUUID == "" {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidUUID})
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	cursorTime, cursorID, err := utils.DecodeCursor(req.Cursor)
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid cursor", "cursor", req.Cursor, "err", err)
+		return nil, utils.ErrInvalidCursor
+	}
+
+	if _, err := h.WebhookRepo.FetchSubscription(ctx, req.SubscriptionUUID); err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrWebhookSubscriptionNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+
+	// fetch limit+1 to determine has_more
+	deliveries, err := h.WebhookRepo.FetchDeliveries(ctx, req.SubscriptionUUID, cursorTime, cursorID, limit+1)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching webhook deliveries",
+			"subscription_uuid", req.SubscriptionUUID,
+			"cursor", req.Cursor,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	hasMore := len(deliveries) > limit
+	if hasMore {
+		deliveries = deliveries[:limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(deliveries) > 0 {
+		last := deliveries[len(deliveries)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	data := make([]dto.WebhookDeliverySummary, len(deliveries))
+	for i, delivery := range deliveries {
+		data[i] = mapWebhookDeliveryToSummary(delivery)
+	}
+
+	return &dto.ListWebhookDeliveriesResponse{
+		Data: data,
+		Pagination: dto.PaginationResponse{
+			NextCursor: nextCursor,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
+
+func mapWebhookDeliveryToSummary(d *entity.WebhookDeliveryEntity) dto.WebhookDeliverySummary {
+	return dto.WebhookDeliverySummary{
+		UUID:           d.UUID,
+		EventUUID:      d.EventUUID,
+		EventType:      d.EventType,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
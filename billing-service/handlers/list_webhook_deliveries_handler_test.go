@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListWebhookDeliveriesHandler_Handle(t *testing.T) {
+	t.Run("success - pages through deliveries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &ListWebhookDeliveriesHandler{WebhookRepo: mockWebhookRepo}
+
+		createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		statusCode := 500
+		lastError := "subscriber responded 500"
+
+		mockWebhookRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1"}, nil)
+		mockWebhookRepo.EXPECT().
+			FetchDeliveries(gomock.Any(), "sub-1", time.Time{}, int64(0), 2).
+			Return([]*entity.WebhookDeliveryEntity{
+				{ID: 2, UUID: "delivery-2", EventType: "bill.closed", Status: entity.WebhookDeliveryFailed,
+					Attempts: 30, LastStatusCode: &statusCode, LastError: &lastError, CreatedAt: createdAt},
+				{ID: 1, UUID: "delivery-1", EventType: "bill.created", Status: entity.WebhookDeliveryDelivered, CreatedAt: createdAt},
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListWebhookDeliveriesRequest{
+			SubscriptionUUID: "sub-1",
+			Limit:            1,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "delivery-2", resp.Data[0].UUID)
+		assert.Equal(t, "FAILED", resp.Data[0].Status)
+		assert.Equal(t, 30, resp.Data[0].Attempts)
+		assert.Equal(t, &statusCode, resp.Data[0].LastStatusCode)
+		assert.True(t, resp.Pagination.HasMore)
+		assert.Equal(t, utils.EncodeCursor(createdAt, 2), resp.Pagination.NextCursor)
+	})
+
+	t.Run("error - validation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListWebhookDeliveriesHandler{WebhookRepo: mocks.NewMockWebhookRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListWebhookDeliveriesRequest{})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - invalid cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ListWebhookDeliveriesHandler{WebhookRepo: mocks.NewMockWebhookRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.ListWebhookDeliveriesRequest{
+			SubscriptionUUID: "sub-1",
+			Cursor:           "not-a-cursor",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInvalidCursor, err)
+	})
+
+	t.Run("error - subscription not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &ListWebhookDeliveriesHandler{WebhookRepo: mockWebhookRepo}
+
+		mockWebhookRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ListWebhookDeliveriesRequest{SubscriptionUUID: "sub-1"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWebhookSubscriptionNotFound, err)
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+)
+
+type ListWebhooksHandler struct {
+	WebhookRepo repository.WebhookRepository
+}
+
+// Handle lists the active subscriptions. Secrets are not included.
+func (h *ListWebhooksHandler) Handle(ctx context.Context) (*dto.ListWebhooksResponse, error) {
+	subs, err := h.WebhookRepo.FetchActiveSubscriptions(ctx, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching webhook subscriptions", "err", err)
+		return nil, utils.ErrInternal
+	}
+
+	data := make([]dto.WebhookSubscriptionSummary, len(subs))
+	for i, sub := range subs {
+		data[i] = mapWebhookSubscriptionToSummary(sub)
+	}
+
+	return &dto.ListWebhooksResponse{Data: data}, nil
+}
+
+func mapWebhookSubscriptionToSummary(sub *entity.WebhookSubscriptionEntity) dto.WebhookSubscriptionSummary {
+	return dto.WebhookSubscriptionSummary{
+		UUID:      sub.UUID,
+		URL:       sub.URL,
+		Events:    sub.Events,
+		CreatedAt: sub.CreatedAt,
+	}
+}
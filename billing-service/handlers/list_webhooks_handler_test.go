@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListWebhooksHandler_Handle(t *testing.T) {
+	t.Run("success - lists active subscriptions without secrets", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &ListWebhooksHandler{WebhookRepo: mockWebhookRepo}
+
+		createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		mockWebhookRepo.EXPECT().
+			FetchActiveSubscriptions(gomock.Any(), "").
+			Return([]*entity.WebhookSubscriptionEntity{{
+				UUID:      "sub-1",
+				URL:       "https://example.com/hooks",
+				Secret:    "whsec_test",
+				Events:    []string{"bill.closed"},
+				CreatedAt: createdAt,
+			}}, nil)
+
+		resp, err := handler.Handle(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "sub-1", resp.Data[0].UUID)
+		assert.Equal(t, []string{"bill.closed"}, resp.Data[0].Events)
+		assert.Equal(t, createdAt, resp.Data[0].CreatedAt)
+	})
+
+	t.Run("success - no subscriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &ListWebhooksHandler{WebhookRepo: mockWebhookRepo}
+
+		mockWebhookRepo.EXPECT().
+			FetchActiveSubscriptions(gomock.Any(), "").
+			Return(nil, nil)
+
+		resp, err := handler.Handle(context.Background())
+
+		require.NoError(t, err)
+		assert.NotNil(t, resp.Data)
+		assert.Empty(t, resp.Data)
+	})
+
+	t.Run("error - query failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &ListWebhooksHandler{WebhookRepo: mockWebhookRepo}
+
+		mockWebhookRepo.EXPECT().
+			FetchActiveSubscriptions(gomock.Any(), "").
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+	"encore.dev/storage/sqldb"
+)
+
+type MergeCustomersHandler struct {
+	CustomerRepo repository.CustomerRepository
+}
+
+// Handle merges a duplicate customer into the target: the duplicate's bills,
+// credits, subscriptions and ledger balances move to the target and the
+// duplicate is kept as merged. The duplicate's open bills have to be closed
+// or voided first.
+func (h *MergeCustomersHandler) Handle(ctx context.Context, req *dto.MergeCustomersRequest) (*dto.MergeCustomersResponse, error) {
+	var validationErrors utils.ValidationErrors
+	if req.SourceUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidSourceUUID)
+	}
+	if req.TargetUUID == "" || req.TargetUUID == req.SourceUUID {
+		validationErrors = append(validationErrors, utils.ErrInvalidTargetUUID)
+	}
+	if len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	merge, err := h.CustomerRepo.Merge(ctx, req.SourceUUID, req.TargetUUID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrNoRows):
+			return nil, utils.ErrCustomerNotFoundAPI
+		case errors.Is(err, entity.ErrCustomerHasOpenBills):
+			return nil, utils.ErrCustomerHasOpenBills
+		case errors.Is(err, entity.ErrCustomerArchived), errors.Is(err, entity.ErrCustomerMerged):
+			return nil, customerStatusError(err)
+		}
+		slog.ErrorContext(ctx, "error merging customers",
+			"source_uuid", req.SourceUUID,
+			"target_uuid", req.TargetUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.MergeCustomersResponse{
+		SourceUUID:    merge.SourceUUID,
+		TargetUUID:    merge.TargetUUID,
+		Bills:         merge.Bills,
+		Credits:       merge.Credits,
+		Subscriptions: merge.Subscriptions,
+	}, nil
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMergeCustomersHandler_Handle(t *testing.T) {
+	t.Run("success - merges the source into the target", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &MergeCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Merge(gomock.Any(), "customer-dup", "customer-123").
+			Return(&entity.CustomerMergeEntity{
+				SourceUUID:    "customer-dup",
+				TargetUUID:    "customer-123",
+				Bills:         3,
+				Credits:       1,
+				Subscriptions: 2,
+			}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.MergeCustomersRequest{
+			SourceUUID: "customer-dup",
+			TargetUUID: "customer-123",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "customer-dup", resp.SourceUUID)
+		assert.Equal(t, "customer-123", resp.TargetUUID)
+		assert.Equal(t, int64(3), resp.Bills)
+		assert.Equal(t, int64(1), resp.Credits)
+		assert.Equal(t, int64(2), resp.Subscriptions)
+	})
+
+	t.Run("error - merging a customer into itself", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &MergeCustomersHandler{CustomerRepo: mocks.NewMockCustomerRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.MergeCustomersRequest{
+			SourceUUID: "customer-123",
+			TargetUUID: "customer-123",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &MergeCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Merge(gomock.Any(), "customer-dup", "customer-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.MergeCustomersRequest{
+			SourceUUID: "customer-dup",
+			TargetUUID: "customer-123",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerNotFoundAPI, err)
+	})
+
+	t.Run("error - source has open bills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &MergeCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Merge(gomock.Any(), "customer-dup", "customer-123").
+			Return(nil, entity.ErrCustomerHasOpenBills)
+
+		resp, err := handler.Handle(context.Background(), &dto.MergeCustomersRequest{
+			SourceUUID: "customer-dup",
+			TargetUUID: "customer-123",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerHasOpenBills, err)
+	})
+
+	t.Run("error - target archived", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &MergeCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Merge(gomock.Any(), "customer-dup", "customer-123").
+			Return(nil, entity.ErrCustomerArchived)
+
+		resp, err := handler.Handle(context.Background(), &dto.MergeCustomersRequest{
+			SourceUUID: "customer-dup",
+			TargetUUID: "customer-123",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerArchived, err)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &MergeCustomersHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			Merge(gomock.Any(), "customer-dup", "customer-123").
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.MergeCustomersRequest{
+			SourceUUID: "customer-dup",
+			TargetUUID: "customer-123",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"go.temporal.io/api/serviceerror"
+)
+
+type RecordPaymentHandler struct {
+	BillRepo       repository.BillRepository
+	PaymentRepo    repository.PaymentRepository
+	TemporalClient t.WorkflowClient
+}
+
+// Handle records a payment against a closed bill and tells its dunning workflow,
+// which moves the bill to PARTIALLY_PAID or PAID. Repeating a request with the
+// same idempotency key returns the payment recorded the first time.
+func (h *RecordPaymentHandler) Handle(ctx context.Context, req *dto.RecordPaymentRequest) (*dto.RecordPaymentResponse, error) {
+	if validationErrors := validateRecordPayment(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.fetchBill(ctx, req.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+	if bill.Currency != req.Amount.Currency {
+		return nil, utils.ErrCurrencyMismatch
+	}
+
+	receivedAt := time.Now().UTC()
+	if req.ReceivedAt != "" {
+		receivedAt, _ = time.Parse(time.RFC3339, req.ReceivedAt)
+	}
+
+	payment := &entity.PaymentEntity{
+		UUID:           uuid.New().String(),
+		BillUUID:       req.BillUUID,
+		IdempotencyKey: req.IdempotencyKey,
+		AmountCents:    req.Amount.Amount,
+		ReceivedAt:     receivedAt,
+	}
+	if req.Reference != "" {
+		payment.Reference = &req.Reference
+	}
+
+	generatedUUID := payment.UUID
+	if err := h.PaymentRepo.Insert(ctx, payment); err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrNoRows):
+			return nil, utils.ErrBillNotFoundAPI
+		case errors.Is(err, entity.ErrBillNotPayable):
+			return nil, utils.ErrBillNotPayable
+		case errors.Is(err, entity.ErrPaymentExceedsBalance):
+			return nil, utils.ErrPaymentExceedsBalance
+		}
+		slog.ErrorContext(ctx, "error recording payment",
+			"bill_uuid", req.BillUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	// the key was used before, only an identical request gets the stored payment back
+	if payment.UUID != generatedUUID && payment.AmountCents != req.Amount.Amount {
+		return nil, utils.ErrDuplicateIdempotencyKey
+	}
+
+	h.signalDunning(ctx, payment)
+
+	// read the bill back so the balance includes concurrent payments
+	bill, err = h.fetchBill(ctx, req.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecordPaymentResponse{
+		Payment: mapPaymentToSummary(payment),
+		Paid:    dto.Money{Amount: bill.PaidCents, Currency: bill.Currency},
+		Balance: dto.Money{Amount: bill.BalanceCents(), Currency: bill.Currency},
+	}, nil
+}
+
+func (h *RecordPaymentHandler) fetchBill(ctx context.Context, billUUID string) (*entity.BillEntity, error) {
+	bill, err := h.BillRepo.FetchByUUID(ctx, billUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+	return bill, nil
+}
+
+// signalDunning wakes the dunning workflow to re-read the balance. The payment
+// is already recorded and the workflow reads it on its next wake-up anyway, so
+// a failed signal is logged rather than failing the request.
+func (h *RecordPaymentHandler) signalDunning(ctx context.Context, payment *entity.PaymentEntity) {
+	err := h.TemporalClient.SignalWorkflow(ctx, tbill.DunningWorkflowID(payment.BillUUID), "",
+		tbill.SignalPaymentReceived, tbill.PaymentReceivedSignal{PaymentUUID: payment.UUID})
+	if err == nil {
+		return
+	}
+
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		slog.InfoContext(ctx, "no dunning workflow for paid bill",
+			"bill_uuid", payment.BillUUID)
+		return
+	}
+	slog.WarnContext(ctx, "failed to signal dunning workflow",
+		"bill_uuid", payment.BillUUID,
+		"payment_uuid", payment.UUID,
+		"err", err)
+}
+
+func validateRecordPayment(req *dto.RecordPaymentRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.BillUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidBillUUID)
+	}
+	if req.IdempotencyKey == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidIdempotencyKey)
+	}
+	if req.Amount.Amount <= 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidAmount)
+	}
+	if req.Amount.Currency == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidCurrency)
+	}
+	if req.ReceivedAt != "" {
+		if _, err := time.Parse(time.RFC3339, req.ReceivedAt); err != nil {
+			validationErrors = append(validationErrors, utils.ErrInvalidReceivedAt)
+		}
+	}
+
+	return validationErrors
+}
+
+func mapPaymentToSummary(payment *entity.PaymentEntity) dto.PaymentSummary {
+	summary := dto.PaymentSummary{
+		UUID:           payment.UUID,
+		BillUUID:       payment.BillUUID,
+		IdempotencyKey: payment.IdempotencyKey,
+		Amount: dto.Money{
+			Amount:   payment.AmountCents,
+			Currency: payment.Currency,
+		},
+		ReceivedAt: payment.ReceivedAt.Format(time.RFC3339),
+		CreatedAt:  payment.CreatedAt.Format(time.RFC3339),
+	}
+	if payment.Reference != nil {
+		summary.Reference = *payment.Reference
+	}
+	return summary
+}
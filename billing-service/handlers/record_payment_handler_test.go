@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecordPaymentHandler_Handle(t *testing.T) {
+	total := int64(1000)
+
+	newHandler := func(ctrl *gomock.Controller) (*RecordPaymentHandler, *mocks.MockBillRepository, *mocks.MockPaymentRepository, *temporalmocks.MockWorkflowClient) {
+		billRepo := mocks.NewMockBillRepository(ctrl)
+		paymentRepo := mocks.NewMockPaymentRepository(ctrl)
+		temporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+		return &RecordPaymentHandler{
+			BillRepo:       billRepo,
+			PaymentRepo:    paymentRepo,
+			TemporalClient: temporalClient,
+		}, billRepo, paymentRepo, temporalClient
+	}
+
+	validRequest := func() *dto.RecordPaymentRequest {
+		return &dto.RecordPaymentRequest{
+			BillUUID:       "bill-123",
+			IdempotencyKey: "pay-1",
+			Amount:         dto.Money{Amount: 400, Currency: "USD"},
+			Reference:      "wire-42",
+		}
+	}
+
+	t.Run("success - records payment and signals dunning", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, temporalClient := newHandler(ctrl)
+
+		gomock.InOrder(
+			billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+				Return(&entity.BillEntity{UUID: "bill-123", Status: "FINALIZED", Currency: "USD", TotalCents: &total}, nil),
+			billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+				Return(&entity.BillEntity{UUID: "bill-123", Status: "FINALIZED", Currency: "USD", TotalCents: &total, PaidCents: 400}, nil),
+		)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, p *entity.PaymentEntity) error {
+				assert.Equal(t, "bill-123", p.BillUUID)
+				assert.Equal(t, "pay-1", p.IdempotencyKey)
+				assert.Equal(t, int64(400), p.AmountCents)
+				require.NotNil(t, p.Reference)
+				assert.Equal(t, "wire-42", *p.Reference)
+				assert.NotEmpty(t, p.UUID)
+				p.Currency = "USD"
+				return nil
+			})
+		temporalClient.EXPECT().
+			SignalWorkflow(gomock.Any(), tbill.DunningWorkflowID("bill-123"), "", tbill.SignalPaymentReceived, gomock.Any()).
+			Return(nil)
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(400), resp.Payment.Amount.Amount)
+		assert.Equal(t, "USD", resp.Payment.Amount.Currency)
+		assert.Equal(t, "wire-42", resp.Payment.Reference)
+		assert.Equal(t, int64(400), resp.Paid.Amount)
+		assert.Equal(t, int64(600), resp.Balance.Amount)
+	})
+
+	t.Run("success - missing dunning workflow is not an error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, temporalClient := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD", TotalCents: &total}, nil).
+			Times(2)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil)
+		temporalClient.EXPECT().
+			SignalWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(serviceerror.NewNotFound("workflow not found"))
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		require.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+
+	t.Run("success - replayed key returns stored payment", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, temporalClient := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "PARTIALLY_PAID", Currency: "USD", TotalCents: &total, PaidCents: 400}, nil).
+			Times(2)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, p *entity.PaymentEntity) error {
+				p.UUID = "payment-original"
+				return nil
+			})
+		temporalClient.EXPECT().
+			SignalWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, "payment-original", resp.Payment.UUID)
+	})
+
+	t.Run("error - replayed key with different amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "PARTIALLY_PAID", Currency: "USD", TotalCents: &total}, nil)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, p *entity.PaymentEntity) error {
+				p.UUID = "payment-original"
+				p.AmountCents = 100
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrDuplicateIdempotencyKey, err)
+	})
+
+	t.Run("error - validation failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, _, _, _ := newHandler(ctrl)
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordPaymentRequest{
+			Amount:     dto.Money{Amount: -5},
+			ReceivedAt: "yesterday",
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, _, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotFoundAPI, err)
+	})
+
+	t.Run("error - currency mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, _, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "EUR", TotalCents: &total}, nil)
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCurrencyMismatch, err)
+	})
+
+	t.Run("error - bill does not accept payments", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(entity.ErrBillNotPayable)
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotPayable, err)
+	})
+
+	t.Run("error - overpayment", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD", TotalCents: &total}, nil)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(entity.ErrPaymentExceedsBalance)
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrPaymentExceedsBalance, err)
+	})
+
+	t.Run("error - insert fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler, billRepo, paymentRepo, _ := newHandler(ctrl)
+
+		billRepo.EXPECT().FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD", TotalCents: &total}, nil)
+		paymentRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+
+		resp, err := handler.Handle(context.Background(), validRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/usage"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+)
+
+// maxUsageEventsPerRequest caps the batch size of a usage request.
+const maxUsageEventsPerRequest = 1000
+
+type RecordUsageHandler struct {
+	BillRepo  repository.BillRepository
+	UsageRepo repository.UsageRepository
+	// Catalog holds the meters usage can be recorded against, nil disables metering
+	Catalog usage.Catalog
+}
+
+// Handle records a batch of usage events against an open bill. Events go
+// straight to the database, the bill workflow only sees their per-meter totals
+// when the bill closes.
+func (h *RecordUsageHandler) Handle(ctx context.Context, req *dto.RecordUsageRequest) (*dto.RecordUsageResponse, error) {
+	if h.Catalog == nil {
+		return nil, utils.ErrUsageMeteringDisabled
+	}
+	if validationErrors := validateRecordUsage(req, h.Catalog); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+	if !bill.IsOpen() {
+		return nil, utils.ErrBillClosed
+	}
+
+	now := time.Now().UTC()
+	events := make([]*entity.UsageEventEntity, 0, len(req.Events))
+	for _, e := range req.Events {
+		if _, ok := h.Catalog.Price(e.Meter, bill.Currency); !ok {
+			return nil, utils.ErrMeterNotPriced
+		}
+
+		occurredAt := now
+		if e.OccurredAt != "" {
+			occurredAt, _ = time.Parse(time.RFC3339, e.OccurredAt)
+		}
+		events = append(events, &entity.UsageEventEntity{
+			UUID:       uuid.New().String(),
+			BillUUID:   bill.UUID,
+			Meter:      e.Meter,
+			Quantity:   e.Quantity,
+			DedupKey:   e.DedupKey,
+			OccurredAt: occurredAt,
+		})
+	}
+
+	accepted, err := h.UsageRepo.Insert(ctx, bill.UUID, events)
+	if err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrNoRows):
+			return nil, utils.ErrBillNotFoundAPI
+		case errors.Is(err, entity.ErrBillNotOpen):
+			return nil, utils.ErrBillClosed
+		}
+		slog.ErrorContext(ctx, "error recording usage",
+			"bill_uuid", bill.UUID,
+			"count", len(events),
+			"err", err.Error())
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.RecordUsageResponse{
+		BillUUID:   bill.UUID,
+		Accepted:   accepted,
+		Duplicates: len(events) - accepted,
+	}, nil
+}
+
+func validateRecordUsage(req *dto.RecordUsageRequest, catalog usage.Catalog) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.BillUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidBillUUID)
+	}
+	if len(req.Events) == 0 || len(req.Events) > maxUsageEventsPerRequest {
+		validationErrors = append(validationErrors, utils.ErrInvalidUsageEvents)
+		return validationErrors
+	}
+
+	// report each kind of problem once, not once per event
+	seen := make(map[string]bool)
+	add := func(err utils.ValidationError) {
+		if !seen[err.Code] {
+			seen[err.Code] = true
+			validationErrors = append(validationErrors, err)
+		}
+	}
+	for _, e := range req.Events {
+		if e.DedupKey == "" {
+			add(utils.ErrInvalidDedupKey)
+		}
+		if _, ok := catalog[e.Meter]; !ok {
+			add(utils.ErrInvalidMeter)
+		}
+		if e.Quantity <= 0 {
+			add(utils.ErrInvalidQuantity)
+		}
+		if e.OccurredAt != "" {
+			if _, err := time.Parse(time.RFC3339, e.OccurredAt); err != nil {
+				add(utils.ErrInvalidOccurredAt)
+			}
+		}
+	}
+
+	return validationErrors
+}
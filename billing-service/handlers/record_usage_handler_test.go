@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/usage"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecordUsageHandler_Handle(t *testing.T) {
+	catalog := usage.Catalog{
+		"api_calls": {Name: "api_calls", Unit: "calls", Prices: map[string]usage.Price{
+			"USD": {Model: usage.PricingFlat, PerUnits: 1000, UnitPriceCents: 10},
+		}},
+		"storage_gb": {Name: "storage_gb", Unit: "GB", Prices: map[string]usage.Price{
+			"GEL": {Model: usage.PricingFlat, UnitPriceCents: 65},
+		}},
+	}
+	openBill := &entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}
+
+	t.Run("success - records events and counts duplicates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockUsageRepo := mocks.NewMockUsageRepository(ctrl)
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mockBillRepo,
+			UsageRepo: mockUsageRepo,
+			Catalog:   catalog,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(openBill, nil)
+
+		mockUsageRepo.EXPECT().
+			Insert(gomock.Any(), "bill-123", gomock.Len(2)).
+			DoAndReturn(func(_ context.Context, _ string, events []*entity.UsageEventEntity) (int, error) {
+				assert.Equal(t, "evt-1", events[0].DedupKey)
+				assert.Equal(t, int64(500), events[0].Quantity)
+				assert.Equal(t, 2024, events[0].OccurredAt.Year())
+				assert.False(t, events[1].OccurredAt.IsZero())
+				return 1, nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events: []dto.UsageEvent{
+				{DedupKey: "evt-1", Meter: "api_calls", Quantity: 500, OccurredAt: "2024-01-15T10:00:00Z"},
+				{DedupKey: "evt-2", Meter: "api_calls", Quantity: 250},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "bill-123", resp.BillUUID)
+		assert.Equal(t, 1, resp.Accepted)
+		assert.Equal(t, 1, resp.Duplicates)
+	})
+
+	t.Run("error - metering disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mocks.NewMockBillRepository(ctrl),
+			UsageRepo: mocks.NewMockUsageRepository(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events:   []dto.UsageEvent{{DedupKey: "evt-1", Meter: "api_calls", Quantity: 1}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrUsageMeteringDisabled)
+	})
+
+	t.Run("error - validation fails - no events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mocks.NewMockBillRepository(ctrl),
+			UsageRepo: mocks.NewMockUsageRepository(ctrl),
+			Catalog:   catalog,
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - validation fails - unknown meter and zero quantity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mocks.NewMockBillRepository(ctrl),
+			UsageRepo: mocks.NewMockUsageRepository(ctrl),
+			Catalog:   catalog,
+		}
+
+		errs := validateRecordUsage(&dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events: []dto.UsageEvent{
+				{DedupKey: "evt-1", Meter: "unknown", Quantity: 0},
+				{DedupKey: "evt-2", Meter: "unknown", Quantity: 0},
+			},
+		}, catalog)
+		assert.Equal(t, []utils.ValidationError{utils.ErrInvalidMeter, utils.ErrInvalidQuantity}, errs)
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events:   []dto.UsageEvent{{DedupKey: "evt-1", Meter: "unknown", Quantity: 0}},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mockBillRepo,
+			UsageRepo: mocks.NewMockUsageRepository(ctrl),
+			Catalog:   catalog,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-404").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-404",
+			Events:   []dto.UsageEvent{{DedupKey: "evt-1", Meter: "api_calls", Quantity: 1}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrBillNotFoundAPI)
+	})
+
+	t.Run("error - bill closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mockBillRepo,
+			UsageRepo: mocks.NewMockUsageRepository(ctrl),
+			Catalog:   catalog,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSING", Currency: "USD"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events:   []dto.UsageEvent{{DedupKey: "evt-1", Meter: "api_calls", Quantity: 1}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrBillClosed)
+	})
+
+	t.Run("error - meter not priced in bill currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mockBillRepo,
+			UsageRepo: mocks.NewMockUsageRepository(ctrl),
+			Catalog:   catalog,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(openBill, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events:   []dto.UsageEvent{{DedupKey: "evt-1", Meter: "storage_gb", Quantity: 3}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrMeterNotPriced)
+	})
+
+	t.Run("error - bill started closing during insert", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockUsageRepo := mocks.NewMockUsageRepository(ctrl)
+
+		handler := &RecordUsageHandler{
+			BillRepo:  mockBillRepo,
+			UsageRepo: mockUsageRepo,
+			Catalog:   catalog,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(openBill, nil)
+
+		mockUsageRepo.EXPECT().
+			Insert(gomock.Any(), "bill-123", gomock.Any()).
+			Return(0, entity.ErrBillNotOpen)
+
+		resp, err := handler.Handle(context.Background(), &dto.RecordUsageRequest{
+			BillUUID: "bill-123",
+			Events:   []dto.UsageEvent{{DedupKey: "evt-1", Meter: "api_calls", Quantity: 1}},
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrBillClosed)
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+	"encore.app/temporal/webhook"
+
+	"encore.dev/storage/sqldb"
+)
+
+type ReplayWebhookDeliveryHandler struct {
+	WebhookRepo    repository.WebhookRepository
+	TemporalClient t.WorkflowClient
+}
+
+// Handle sends a recorded delivery again with its original payload, whether it
+// failed or was delivered. A delivery still being retried keeps its workflow.
+func (h *ReplayWebhookDeliveryHandler) Handle(ctx context.Context, req *dto.ReplayWebhookDeliveryRequest) (*dto.ReplayWebhookDeliveryResponse, error) {
+	if req.DeliveryUUID == "" {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidDeliveryUUID})
+	}
+
+	delivery, err := h.WebhookRepo.FetchDelivery(ctx, req.DeliveryUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrWebhookDeliveryNotFound
+		}
+		return nil, utils.ErrInternal
+	}
+
+	sub, err := h.WebhookRepo.FetchSubscription(ctx, delivery.SubscriptionUUID)
+	if err != nil {
+		return nil, utils.ErrInternal
+	}
+	if sub.DeletedAt != nil {
+		return nil, utils.ErrWebhookSubscriptionNotFound
+	}
+
+	if delivery.Status != entity.WebhookDeliveryPending {
+		err := h.WebhookRepo.UpdateDeliveryStatus(ctx, delivery.UUID, delivery.Status, entity.WebhookDeliveryPending)
+		// a concurrent replay already reset it
+		if err != nil && !errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrInternal
+		}
+	}
+
+	if err := webhook.StartDelivery(ctx, h.TemporalClient, t.TaskQueue, delivery.UUID); err != nil {
+		slog.ErrorContext(ctx, "error starting webhook delivery",
+			"delivery_uuid", delivery.UUID,
+			"err", err)
+		return nil, utils.ErrWorkflowStartFailed
+	}
+
+	return &dto.ReplayWebhookDeliveryResponse{
+		DeliveryUUID: delivery.UUID,
+		Status:       string(entity.WebhookDeliveryPending),
+	}, nil
+}
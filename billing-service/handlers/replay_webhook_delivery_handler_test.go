@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/temporal/webhook"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReplayWebhookDeliveryHandler_Handle(t *testing.T) {
+	t.Run("success - failed delivery is reset and restarted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ReplayWebhookDeliveryHandler{
+			WebhookRepo:    mockWebhookRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockWebhookRepo.EXPECT().
+			FetchDelivery(gomock.Any(), "delivery-1").
+			Return(&entity.WebhookDeliveryEntity{UUID: "delivery-1", SubscriptionUUID: "sub-1", Status: entity.WebhookDeliveryFailed}, nil)
+		mockWebhookRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1"}, nil)
+		mockWebhookRepo.EXPECT().
+			UpdateDeliveryStatus(gomock.Any(), "delivery-1", entity.WebhookDeliveryFailed, entity.WebhookDeliveryPending).
+			Return(nil)
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), client.StartWorkflowOptions{ID: "webhook-delivery-delivery-1", TaskQueue: "billing-task-queue"},
+				gomock.Any(), webhook.DeliveryWorkflowInput{DeliveryUUID: "delivery-1"}).
+			Return(nil, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ReplayWebhookDeliveryRequest{DeliveryUUID: "delivery-1"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "delivery-1", resp.DeliveryUUID)
+		assert.Equal(t, "PENDING", resp.Status)
+	})
+
+	t.Run("success - pending delivery keeps its running workflow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ReplayWebhookDeliveryHandler{
+			WebhookRepo:    mockWebhookRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockWebhookRepo.EXPECT().
+			FetchDelivery(gomock.Any(), "delivery-1").
+			Return(&entity.WebhookDeliveryEntity{UUID: "delivery-1", SubscriptionUUID: "sub-1", Status: entity.WebhookDeliveryPending}, nil)
+		mockWebhookRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1"}, nil)
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("running", "", ""))
+
+		resp, err := handler.Handle(context.Background(), &dto.ReplayWebhookDeliveryRequest{DeliveryUUID: "delivery-1"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "PENDING", resp.Status)
+	})
+
+	t.Run("error - validation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &ReplayWebhookDeliveryHandler{
+			WebhookRepo:    mocks.NewMockWebhookRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.ReplayWebhookDeliveryRequest{})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - delivery not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+
+		handler := &ReplayWebhookDeliveryHandler{
+			WebhookRepo:    mockWebhookRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockWebhookRepo.EXPECT().
+			FetchDelivery(gomock.Any(), "delivery-1").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ReplayWebhookDeliveryRequest{DeliveryUUID: "delivery-1"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWebhookDeliveryNotFound, err)
+	})
+
+	t.Run("error - subscription deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+
+		handler := &ReplayWebhookDeliveryHandler{
+			WebhookRepo:    mockWebhookRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		deletedAt := time.Now()
+		mockWebhookRepo.EXPECT().
+			FetchDelivery(gomock.Any(), "delivery-1").
+			Return(&entity.WebhookDeliveryEntity{UUID: "delivery-1", SubscriptionUUID: "sub-1", Status: entity.WebhookDeliveryFailed}, nil)
+		mockWebhookRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1", DeletedAt: &deletedAt}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ReplayWebhookDeliveryRequest{DeliveryUUID: "delivery-1"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWebhookSubscriptionNotFound, err)
+	})
+
+	t.Run("error - workflow start failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ReplayWebhookDeliveryHandler{
+			WebhookRepo:    mockWebhookRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockWebhookRepo.EXPECT().
+			FetchDelivery(gomock.Any(), "delivery-1").
+			Return(&entity.WebhookDeliveryEntity{UUID: "delivery-1", SubscriptionUUID: "sub-1", Status: entity.WebhookDeliveryPending}, nil)
+		mockWebhookRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1"}, nil)
+		mockTemporalClient.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.ReplayWebhookDeliveryRequest{DeliveryUUID: "delivery-1"})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWorkflowStartFailed, err)
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+)
+
+type RetryLineItemHandler struct {
+	BillRepo       repository.BillRepository
+	TemporalClient t.WorkflowClient
+}
+
+// Handle replays a dead-lettered line item through the bill workflow.
+// Retries are allowed while the bill is closing, the close waits for them.
+func (h *RetryLineItemHandler) Handle(ctx context.Context, req *dto.RetryLineItemRequest) (*dto.RetryLineItemResponse, error) {
+	if validationErrors := validateRetryLineItem(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.BillUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		rlog.Error("error fetching bill", "bill_uuid", req.BillUUID, "error", err)
+		return nil, utils.ErrInternal
+	}
+
+	if !bill.HasRunningWorkflow() {
+		return nil, utils.ErrBillClosed
+	}
+
+	lineItem, err := runLineItemUpdate(ctx, h.TemporalClient, req.BillUUID, "", tbill.UpdateRetryLineItem,
+		tbill.RetryLineItemUpdate{UUID: req.LineItemUUID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RetryLineItemResponse{
+		UUID:    lineItem.UUID,
+		FeeType: lineItem.FeeType,
+		Amount: dto.Money{
+			Amount:   lineItem.AmountCents,
+			Currency: bill.Currency,
+		},
+		Status:    entity.LineItemStatusPersisted.String(),
+		CreatedAt: lineItem.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func validateRetryLineItem(req *dto.RetryLineItemRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.BillUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidBillUUID)
+	}
+	if req.LineItemUUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidLineItemUUID)
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRetryLineItemHandler_Handle(t *testing.T) {
+	t.Run("success - retries failed line item", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &RetryLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		billUUID := "bill-123"
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Status: "OPEN", Currency: "USD"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				assert.Equal(t, "bill-"+billUUID, options.WorkflowID)
+				assert.Equal(t, tbill.UpdateRetryLineItem, options.UpdateName)
+				assert.Equal(t, tbill.RetryLineItemUpdate{UUID: "li-failed"}, options.Args[0])
+
+				return newMockUpdateHandle(&entity.LineItemEntity{
+					UUID:        "li-failed",
+					BillUUID:    billUUID,
+					FeeType:     "TRANSACTION",
+					AmountCents: 1000,
+					CreatedAt:   time.Now(),
+				}, nil), nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.RetryLineItemRequest{
+			BillUUID:     billUUID,
+			LineItemUUID: "li-failed",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "li-failed", resp.UUID)
+		assert.Equal(t, int64(1000), resp.Amount.Amount)
+		assert.Equal(t, "USD", resp.Amount.Currency)
+		assert.Equal(t, "persisted", resp.Status)
+	})
+
+	t.Run("error - validation fails - missing line item UUID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &RetryLineItemHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.RetryLineItemRequest{BillUUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &RetryLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.RetryLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-failed",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotFoundAPI, err)
+	})
+
+	t.Run("error - bill closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &RetryLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED", Currency: "USD"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.RetryLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-failed",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillClosed, err)
+	})
+
+	t.Run("error - line item not failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &RetryLineItemHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(newMockUpdateHandle(nil,
+				temporal.NewApplicationError("not failed", tbill.ErrTypeLineItemNotFailed)), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.RetryLineItemRequest{
+			BillUUID:     "bill-123",
+			LineItemUUID: "li-unknown",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrLineItemNotFailed, err)
+	})
+}
@@ -15,7 +15,6 @@ import (
 	"encore.dev/rlog"
 	"encore.dev/storage/sqldb"
 	"github.com/google/uuid"
-	"go.temporal.io/api/serviceerror"
 )
 
 type ReverseLineItemHandler struct {
@@ -51,28 +50,30 @@ func (h *ReverseLineItemHandler) Handle(ctx context.Context, req *dto.ReverseLin
 		return nil, utils.ErrCannotReverseReversal
 	}
 
-	if err := h.checkAlreadyReversed(ctx, req.LineItemUUID); err != nil {
-		return nil, err
-	}
-
+	// a repeated request gets its reversal back even when it left nothing to reverse
 	if existingResp, err := h.checkIdempotency(ctx, req, bill); existingResp != nil || err != nil {
 		return existingResp, err
 	}
 
-	reversalUUID := uuid.New().String()
-	workflowID := t.BillWorkflowIDPrefix + req.BillUUID
-
-	if err := h.queryWorkflowState(ctx, workflowID, req.BillUUID); err != nil {
+	amountCents, err := reversalAmount(req, originalLineItem)
+	if err != nil {
 		return nil, err
 	}
 
-	signal := h.buildReversalSignal(reversalUUID, req, originalLineItem)
+	update := h.buildReversalUpdate(uuid.New().String(), req, amountCents, bill.Currency)
 
-	if err := h.signalWorkflow(ctx, workflowID, req.BillUUID, signal); err != nil {
+	reversal, err := updateLineItem(ctx, h.TemporalClient, req.BillUUID, update)
+	if err != nil {
+		// lost a race against a concurrent request with the same idempotency key
+		if err == utils.ErrDuplicateIdempotencyKey {
+			if existingResp, err := h.checkIdempotency(ctx, req, bill); existingResp != nil || err != nil {
+				return existingResp, err
+			}
+		}
 		return nil, err
 	}
 
-	return h.buildPendingResponse(reversalUUID, req, originalLineItem, bill.Currency), nil
+	return mapLineItemToReverseResponse(reversal, bill.Currency), nil
 }
 
 func (h *ReverseLineItemHandler) fetchBill(ctx context.Context, billUUID string) (*entity.BillEntity, error) {
@@ -99,17 +100,29 @@ func (h *ReverseLineItemHandler) fetchOriginalLineItem(ctx context.Context, line
 	return lineItem, nil
 }
 
-func (h *ReverseLineItemHandler) checkAlreadyReversed(ctx context.Context, originalUUID string) error {
-	_, err := h.LineItemRepo.FetchReversalByOriginalUUID(ctx, originalUUID)
-	if err != nil {
-		if errors.Is(err, sqldb.ErrNoRows) {
-			// Not reversed yet, this is expected
-			return nil
-		}
-		rlog.Error("error checking if line item is reversed", "original_uuid", originalUUID, "error", err)
-		return utils.ErrInternal
+// reversalAmount is the signed amount reversing what the request asks for of the
+// original: the requested part, or all that earlier reversals left. The bill
+// workflow checks it against the original's running total again when it
+// persists the reversal, so concurrent reversals cannot overshoot either.
+func reversalAmount(req *dto.ReverseLineItemRequest, original *entity.LineItemEntity) (int64, error) {
+	remainingCents := original.RemainingReversibleCents()
+	if remainingCents <= 0 {
+		return 0, utils.ErrAlreadyReversedAPI
 	}
-	return utils.ErrAlreadyReversedAPI
+
+	amountCents := req.AmountCents
+	if amountCents == 0 {
+		amountCents = remainingCents
+	}
+	if amountCents > remainingCents {
+		return 0, utils.ErrReversalExceedsRemaining
+	}
+
+	// the reversal has the opposite sign of the original
+	if original.AmountCents > 0 {
+		return -amountCents, nil
+	}
+	return amountCents, nil
 }
 
 func (h *ReverseLineItemHandler) checkIdempotency(ctx context.Context, req *dto.ReverseLineItemRequest, bill *entity.BillEntity) (*dto.ReverseLineItemResponse, error) {
@@ -123,90 +136,39 @@ func (h *ReverseLineItemHandler) checkIdempotency(ctx context.Context, req *dto.
 	}
 
 	// Return existing reversal
-	referenceUUID := ""
-	if existing.ReferenceUUID != nil {
-		referenceUUID = *existing.ReferenceUUID
-	}
-
-	return &dto.ReverseLineItemResponse{
-		UUID:          existing.UUID,
-		FeeType:       existing.FeeType,
-		ReferenceUUID: referenceUUID,
-		Amount: dto.Money{
-			Amount:   existing.AmountCents,
-			Currency: bill.Currency,
-		},
-		CreatedAt: existing.CreatedAt.Format(time.RFC3339),
-	}, nil
+	return mapLineItemToReverseResponse(existing, bill.Currency), nil
 }
 
-func (h *ReverseLineItemHandler) queryWorkflowState(ctx context.Context, workflowID, billUUID string) error {
-	queryResp, err := h.TemporalClient.QueryWorkflow(ctx, workflowID, "", tbill.QueryGetBillState)
-	if err != nil {
-		var notFound *serviceerror.NotFound
-		if errors.As(err, &notFound) {
-			rlog.Error("data inconsistency: bill exists but workflow not found",
-				"bill_uuid", billUUID,
-				"workflow_id", workflowID)
-			return utils.ErrWorkflowNotFound
-		}
-
-		rlog.Error("failed to query workflow state",
-			"bill_uuid", billUUID,
-			"error", err)
-		return utils.ErrWorkflowQueryFailed
-	}
-
-	var billState tbill.BillStateQuery
-	if err := queryResp.Get(&billState); err != nil {
-		rlog.Error("failed to decode workflow state",
-			"bill_uuid", billUUID,
-			"error", err)
-		return utils.ErrWorkflowQueryFailed
-	}
-
-	return nil
-}
-
-func (h *ReverseLineItemHandler) buildReversalSignal(reversalUUID string, req *dto.ReverseLineItemRequest, original *entity.LineItemEntity) tbill.AddLineItemSignal {
-	return tbill.AddLineItemSignal{
-		UUID:           reversalUUID,
-		IdempotencyKey: req.IdempotencyKey,
-		FeeType:        string(entity.FeeTypeReversal),
-		Description:    req.Reason,
-		AmountCents:    -original.AmountCents, // Negative amount for reversal
-		ReferenceUUID:  &req.LineItemUUID,     // Points to original line item
+func (h *ReverseLineItemHandler) buildReversalUpdate(reversalUUID string, req *dto.ReverseLineItemRequest, amountCents int64, currency string) tbill.AddLineItemUpdate {
+	return tbill.AddLineItemUpdate{
+		AddLineItemSignal: tbill.AddLineItemSignal{
+			UUID:           reversalUUID,
+			IdempotencyKey: req.IdempotencyKey,
+			FeeType:        string(entity.FeeTypeReversal),
+			Description:    req.Reason,
+			AmountCents:    amountCents,
+			ReferenceUUID:  &req.LineItemUUID, // Points to original line item
+		},
+		Currency: currency,
 	}
 }
 
-func (h *ReverseLineItemHandler) signalWorkflow(ctx context.Context, workflowID, billUUID string, signal tbill.AddLineItemSignal) error {
-	err := h.TemporalClient.SignalWorkflow(ctx, workflowID, "", tbill.SignalAddLineItem, signal)
-	if err != nil {
-		var notFound *serviceerror.NotFound
-		if errors.As(err, &notFound) {
-			rlog.Warn("workflow completed between query and signal",
-				"bill_uuid", billUUID)
-			return utils.ErrBillClosed
-		}
-
-		rlog.Error("failed to signal workflow",
-			"bill_uuid", billUUID,
-			"error", err)
-		return utils.ErrWorkflowSignalFailed
+func mapLineItemToReverseResponse(li *entity.LineItemEntity, currency string) *dto.ReverseLineItemResponse {
+	referenceUUID := ""
+	if li.ReferenceUUID != nil {
+		referenceUUID = *li.ReferenceUUID
 	}
-	return nil
-}
 
-func (h *ReverseLineItemHandler) buildPendingResponse(reversalUUID string, req *dto.ReverseLineItemRequest, original *entity.LineItemEntity, currency string) *dto.ReverseLineItemResponse {
 	return &dto.ReverseLineItemResponse{
-		UUID:          reversalUUID,
-		FeeType:       string(entity.FeeTypeReversal),
-		ReferenceUUID: req.LineItemUUID,
+		UUID:          li.UUID,
+		FeeType:       li.FeeType,
+		ReferenceUUID: referenceUUID,
 		Amount: dto.Money{
-			Amount:   -original.AmountCents,
+			Amount:   li.AmountCents,
 			Currency: currency,
 		},
-		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Status:    entity.LineItemStatusPersisted.String(),
+		CreatedAt: li.CreatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -222,6 +184,9 @@ func validateReverseLineItem(req *dto.ReverseLineItemRequest) []utils.Validation
 	if req.IdempotencyKey == "" {
 		validationErrors = append(validationErrors, utils.ErrInvalidIdempotencyKey)
 	}
+	if req.AmountCents < 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidReversalAmount)
+	}
 
 	return validationErrors
 }
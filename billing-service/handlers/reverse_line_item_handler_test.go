@@ -16,31 +16,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.temporal.io/api/serviceerror"
-	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 	"go.uber.org/mock/gomock"
 )
 
-// mockEncodedValue implements converter.EncodedValue for testing QueryWorkflow
-type mockEncodedValue struct {
-	value interface{}
-}
-
-func (m *mockEncodedValue) Get(valuePtr interface{}) error {
-	// Type assert and assign the value
-	if v, ok := valuePtr.(*tbill.BillStateQuery); ok && m.value != nil {
-		*v = m.value.(tbill.BillStateQuery)
-	}
-	return nil
-}
-
-func (m *mockEncodedValue) HasValue() bool {
-	return m.value != nil
-}
-
-func newMockEncodedValue(value interface{}) converter.EncodedValue {
-	return &mockEncodedValue{value: value}
-}
-
 func TestReverseLineItemHandler_Handle(t *testing.T) {
 	t.Run("success - reverses line item", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -82,21 +62,31 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 			FetchByUUID(gomock.Any(), lineItemUUID).
 			Return(originalLineItem, nil)
 
-		mockLineItemRepo.EXPECT().
-			FetchReversalByOriginalUUID(gomock.Any(), lineItemUUID).
-			Return(nil, sqldb.ErrNoRows)
-
 		mockLineItemRepo.EXPECT().
 			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
 			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
-			Return(newMockEncodedValue(tbill.BillStateQuery{Status: "OPEN"}), nil)
-
-		mockTemporalClient.EXPECT().
-			SignalWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.SignalAddLineItem, gomock.Any()).
-			Return(nil)
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				assert.Equal(t, "bill-"+billUUID, options.WorkflowID)
+				assert.Equal(t, tbill.UpdateAddLineItem, options.UpdateName)
+
+				update := options.Args[0].(tbill.AddLineItemUpdate)
+				assert.Equal(t, "REVERSAL", update.FeeType)
+				assert.Equal(t, int64(-1000), update.AmountCents)
+				require.NotNil(t, update.ReferenceUUID)
+				assert.Equal(t, lineItemUUID, *update.ReferenceUUID)
+
+				return newMockUpdateHandle(&entity.LineItemEntity{
+					UUID:          update.UUID,
+					BillUUID:      billUUID,
+					FeeType:       update.FeeType,
+					AmountCents:   update.AmountCents,
+					ReferenceUUID: update.ReferenceUUID,
+					CreatedAt:     time.Now(),
+				}, nil), nil
+			})
 
 		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
 			BillUUID:       billUUID,
@@ -111,6 +101,7 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 		assert.Equal(t, lineItemUUID, resp.ReferenceUUID)
 		assert.Equal(t, int64(-1000), resp.Amount.Amount)
 		assert.Equal(t, "USD", resp.Amount.Currency)
+		assert.Equal(t, "persisted", resp.Status)
 	})
 
 	t.Run("error - validation fails - missing bill UUID", func(t *testing.T) {
@@ -356,15 +347,11 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 		}
 
 		lineItem := &entity.LineItemEntity{
-			UUID:     "line-item-456",
-			BillUUID: "bill-123",
-			FeeType:  "TRANSACTION",
-		}
-
-		existingReversal := &entity.LineItemEntity{
-			UUID:     "reversal-789",
-			BillUUID: "bill-123",
-			FeeType:  "REVERSAL",
+			UUID:          "line-item-456",
+			BillUUID:      "bill-123",
+			FeeType:       "TRANSACTION",
+			AmountCents:   1000,
+			ReversedCents: 1000,
 		}
 
 		mockBillRepo.EXPECT().
@@ -376,8 +363,8 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 			Return(lineItem, nil)
 
 		mockLineItemRepo.EXPECT().
-			FetchReversalByOriginalUUID(gomock.Any(), "line-item-456").
-			Return(existingReversal, nil)
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
 
 		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
 			BillUUID:       "bill-123",
@@ -389,6 +376,147 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 		assert.Equal(t, utils.ErrAlreadyReversedAPI, err)
 	})
 
+	t.Run("success - reverses part of what is left", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ReverseLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "line-item-456").
+			Return(&entity.LineItemEntity{
+				UUID:          "line-item-456",
+				BillUUID:      "bill-123",
+				FeeType:       "TRANSACTION",
+				AmountCents:   1000,
+				ReversedCents: 400,
+			}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				update := options.Args[0].(tbill.AddLineItemUpdate)
+				assert.Equal(t, int64(-250), update.AmountCents)
+
+				return newMockUpdateHandle(&entity.LineItemEntity{
+					UUID:          update.UUID,
+					BillUUID:      "bill-123",
+					FeeType:       update.FeeType,
+					AmountCents:   update.AmountCents,
+					ReferenceUUID: update.ReferenceUUID,
+				}, nil), nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
+			BillUUID:       "bill-123",
+			LineItemUUID:   "line-item-456",
+			IdempotencyKey: "idem-key",
+			AmountCents:    250,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(-250), resp.Amount.Amount)
+	})
+
+	t.Run("error - reversal exceeds what is left", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		handler := &ReverseLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "line-item-456").
+			Return(&entity.LineItemEntity{
+				UUID:          "line-item-456",
+				BillUUID:      "bill-123",
+				FeeType:       "TRANSACTION",
+				AmountCents:   1000,
+				ReversedCents: 800,
+			}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
+			BillUUID:       "bill-123",
+			LineItemUUID:   "line-item-456",
+			IdempotencyKey: "idem-key",
+			AmountCents:    300,
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrReversalExceedsRemaining)
+	})
+
+	t.Run("error - concurrent reversal took what was left", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &ReverseLineItemHandler{
+			BillRepo:       mockBillRepo,
+			LineItemRepo:   mockLineItemRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "line-item-456").
+			Return(&entity.LineItemEntity{UUID: "line-item-456", BillUUID: "bill-123", FeeType: "TRANSACTION", AmountCents: 1000}, nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), "bill-123", "idem-key").
+			Return(nil, sqldb.ErrNoRows)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(newMockUpdateHandle(nil, temporal.NewApplicationError("reversal exceeds the line item's remaining amount",
+				tbill.ErrTypeReversalExceedsLineItem)), nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
+			BillUUID:       "bill-123",
+			LineItemUUID:   "line-item-456",
+			IdempotencyKey: "idem-key",
+		})
+
+		assert.Nil(t, resp)
+		assert.True(t, err == utils.ErrReversalExceedsRemaining)
+	})
+
 	t.Run("idempotent - returns existing reversal", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -437,10 +565,6 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 			FetchByUUID(gomock.Any(), lineItemUUID).
 			Return(originalLineItem, nil)
 
-		mockLineItemRepo.EXPECT().
-			FetchReversalByOriginalUUID(gomock.Any(), lineItemUUID).
-			Return(nil, sqldb.ErrNoRows)
-
 		mockLineItemRepo.EXPECT().
 			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
 			Return(existingReversal, nil)
@@ -458,7 +582,7 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 		assert.Equal(t, int64(-1000), resp.Amount.Amount)
 	})
 
-	t.Run("error - workflow not found", func(t *testing.T) {
+	t.Run("error - workflow completed before the update", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -496,16 +620,12 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 			FetchByUUID(gomock.Any(), lineItemUUID).
 			Return(originalLineItem, nil)
 
-		mockLineItemRepo.EXPECT().
-			FetchReversalByOriginalUUID(gomock.Any(), lineItemUUID).
-			Return(nil, sqldb.ErrNoRows)
-
 		mockLineItemRepo.EXPECT().
 			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
 			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
 			Return(nil, &serviceerror.NotFound{})
 
 		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
@@ -515,10 +635,10 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 		})
 
 		assert.Nil(t, resp)
-		assert.Equal(t, utils.ErrWorkflowNotFound, err)
+		assert.Equal(t, utils.ErrBillClosed, err)
 	})
 
-	t.Run("error - workflow signal failed", func(t *testing.T) {
+	t.Run("error - workflow update failed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -556,21 +676,13 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 			FetchByUUID(gomock.Any(), lineItemUUID).
 			Return(originalLineItem, nil)
 
-		mockLineItemRepo.EXPECT().
-			FetchReversalByOriginalUUID(gomock.Any(), lineItemUUID).
-			Return(nil, sqldb.ErrNoRows)
-
 		mockLineItemRepo.EXPECT().
 			FetchByBillAndKey(gomock.Any(), billUUID, "idem-key").
 			Return(nil, sqldb.ErrNoRows)
 
 		mockTemporalClient.EXPECT().
-			QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.QueryGetBillState).
-			Return(newMockEncodedValue(tbill.BillStateQuery{Status: "OPEN"}), nil)
-
-		mockTemporalClient.EXPECT().
-			SignalWorkflow(gomock.Any(), "bill-"+billUUID, "", tbill.SignalAddLineItem, gomock.Any()).
-			Return(assert.AnError)
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
 
 		resp, err := handler.Handle(context.Background(), &dto.ReverseLineItemRequest{
 			BillUUID:       billUUID,
@@ -579,6 +691,6 @@ func TestReverseLineItemHandler_Handle(t *testing.T) {
 		})
 
 		assert.Nil(t, resp)
-		assert.Equal(t, utils.ErrWorkflowSignalFailed, err)
+		assert.Equal(t, utils.ErrWorkflowUpdateFailed, err)
 	})
 }
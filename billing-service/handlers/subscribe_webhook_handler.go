@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/url"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+	"github.com/google/uuid"
+)
+
+// webhookSecretPrefix marks subscription secrets so they are recognisable in config.
+const webhookSecretPrefix = "whsec_"
+
+type SubscribeWebhookHandler struct {
+	WebhookRepo repository.WebhookRepository
+}
+
+// Handle registers an endpoint for billing events. The response carries the
+// signing secret, the only time it is returned.
+func (h *SubscribeWebhookHandler) Handle(ctx context.Context, req *dto.SubscribeWebhookRequest) (*dto.SubscribeWebhookResponse, error) {
+	if validationErrors := validateSubscribeWebhook(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		slog.ErrorContext(ctx, "error generating webhook secret", "err", err)
+		return nil, utils.ErrInternal
+	}
+
+	sub := &entity.WebhookSubscriptionEntity{
+		UUID:   uuid.New().String(),
+		URL:    req.URL,
+		Secret: secret,
+		Events: uniqueEvents(req.Events),
+	}
+	if err := h.WebhookRepo.InsertSubscription(ctx, sub); err != nil {
+		return nil, utils.ErrInternal
+	}
+
+	return &dto.SubscribeWebhookResponse{
+		UUID:      sub.UUID,
+		URL:       sub.URL,
+		Events:    sub.Events,
+		Secret:    sub.Secret,
+		CreatedAt: sub.CreatedAt,
+	}, nil
+}
+
+func validateSubscribeWebhook(req *dto.SubscribeWebhookRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidWebhookURL)
+	}
+
+	if len(req.Events) == 0 {
+		validationErrors = append(validationErrors, utils.ErrInvalidWebhookEvents)
+	}
+	for _, event := range req.Events {
+		if !entity.WebhookEventType(event).IsValid() {
+			validationErrors = append(validationErrors, utils.ErrInvalidWebhookEvents)
+			break
+		}
+	}
+
+	return validationErrors
+}
+
+func uniqueEvents(events []string) []string {
+	seen := make(map[string]bool, len(events))
+	unique := make([]string, 0, len(events))
+	for _, event := range events {
+		if !seen[event] {
+			seen[event] = true
+			unique = append(unique, event)
+		}
+	}
+	return unique
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
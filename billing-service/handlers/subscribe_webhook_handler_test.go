@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSubscribeWebhookHandler_Handle(t *testing.T) {
+	t.Run("success - creates subscription with a secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &SubscribeWebhookHandler{WebhookRepo: mockWebhookRepo}
+
+		createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		mockWebhookRepo.EXPECT().
+			InsertSubscription(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, sub *entity.WebhookSubscriptionEntity) error {
+				assert.NotEmpty(t, sub.UUID)
+				assert.Equal(t, "https://example.com/hooks", sub.URL)
+				assert.Equal(t, []string{"bill.closed", "line_item.persisted"}, sub.Events)
+				sub.CreatedAt = createdAt
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.SubscribeWebhookRequest{
+			URL:    "https://example.com/hooks",
+			Events: []string{"bill.closed", "line_item.persisted", "bill.closed"},
+		})
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(resp.Secret, "whsec_"))
+		assert.Len(t, resp.Secret, len("whsec_")+64)
+		assert.Equal(t, []string{"bill.closed", "line_item.persisted"}, resp.Events)
+		assert.Equal(t, createdAt, resp.CreatedAt)
+	})
+
+	t.Run("error - validation fails - invalid url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &SubscribeWebhookHandler{WebhookRepo: mocks.NewMockWebhookRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.SubscribeWebhookRequest{
+			URL:    "ftp://example.com",
+			Events: []string{"bill.closed"},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - validation fails - unknown event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &SubscribeWebhookHandler{WebhookRepo: mocks.NewMockWebhookRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.SubscribeWebhookRequest{
+			URL:    "https://example.com/hooks",
+			Events: []string{"bill.paid"},
+		})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - insert fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+		handler := &SubscribeWebhookHandler{WebhookRepo: mockWebhookRepo}
+
+		mockWebhookRepo.EXPECT().
+			InsertSubscription(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.SubscribeWebhookRequest{
+			URL:    "https://example.com/hooks",
+			Events: []string{"bill.created"},
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
+
+func TestValidateSubscribeWebhook(t *testing.T) {
+	assert.Empty(t, validateSubscribeWebhook(&dto.SubscribeWebhookRequest{
+		URL:    "http://localhost:8080/hooks",
+		Events: []string{"bill.created", "line_item.reversed"},
+	}))
+
+	assert.Equal(t, []utils.ValidationError{utils.ErrInvalidWebhookURL, utils.ErrInvalidWebhookEvents},
+		validateSubscribeWebhook(&dto.SubscribeWebhookRequest{URL: "/relative"}))
+}
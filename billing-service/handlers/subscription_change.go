@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"encore.app/db"
+	"encore.app/db/repository"
+	"encore.app/entity"
+
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+)
+
+// maxOpenBillsSignalled bounds how many open bills of a customer are told
+// about a subscription change.
+const maxOpenBillsSignalled = 100
+
+// signalSubscriptionChange tells the customer's open bills that a subscription
+// started or ended, so they prorate it right away. Failures are only logged:
+// every bill reconciles its subscription fees again when it closes.
+func signalSubscriptionChange(ctx context.Context, billRepo repository.BillRepository, client t.WorkflowClient, customerUUID, subscriptionUUID string) {
+	bills, err := billRepo.FetchAll(ctx, db.BillQueryParams{
+		CustomerUUID: customerUUID,
+		Status:       entity.BillStatusOpen.String(),
+		Limit:        maxOpenBillsSignalled,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "error fetching open bills for subscription change",
+			"customer_uuid", customerUUID,
+			"subscription_uuid", subscriptionUUID,
+			"err", err.Error())
+		return
+	}
+
+	signal := tbill.SubscriptionChangedSignal{SubscriptionUUID: subscriptionUUID}
+	for _, bill := range bills {
+		err := client.SignalWorkflow(ctx, t.BillWorkflowIDPrefix+bill.UUID, "", tbill.SignalSubscriptionChanged, signal)
+		if err != nil {
+			slog.WarnContext(ctx, "error signalling subscription change",
+				"bill_uuid", bill.UUID,
+				"subscription_uuid", subscriptionUUID,
+				"err", err.Error())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"encore.app/currency"
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/taxid"
+	"encore.app/utils"
+	"encore.dev/storage/sqldb"
+)
+
+type UpdateBillingProfileHandler struct {
+	CustomerRepo       repository.CustomerRepository
+	BillingProfileRepo repository.BillingProfileRepository
+}
+
+// Handle replaces a customer's billing profile. Bills created afterwards
+// default to its currency, bills closed afterwards are due by its payment terms.
+func (h *UpdateBillingProfileHandler) Handle(ctx context.Context, req *dto.UpdateBillingProfileRequest) (*dto.BillingProfileResponse, error) {
+	req.Address.Country = strings.ToUpper(strings.TrimSpace(req.Address.Country))
+	req.TaxID = taxid.Normalize(req.TaxID)
+	req.Currency = currency.Normalize(req.Currency)
+	if req.InvoiceDelivery == "" {
+		req.InvoiceDelivery = entity.InvoiceDeliveryEmail.String()
+	}
+
+	if validationErrs := validateUpdateBillingProfile(req); len(validationErrs) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrs)
+	}
+
+	customer, err := h.CustomerRepo.FetchByUUID(ctx, req.CustomerUUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrCustomerNotFoundAPI
+		}
+		slog.ErrorContext(ctx, "error fetching customer",
+			"uuid", req.CustomerUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+	// an archived customer's open bills are still invoiced, a merged customer has none
+	if customer.Status == entity.CustomerStatusMerged {
+		return nil, utils.ErrCustomerMerged
+	}
+
+	profile := &entity.BillingProfileEntity{
+		CustomerUUID:    req.CustomerUUID,
+		AddressLine1:    req.Address.Line1,
+		AddressLine2:    optionalString(req.Address.Line2),
+		City:            req.Address.City,
+		PostalCode:      optionalString(req.Address.PostalCode),
+		Region:          optionalString(req.Address.Region),
+		Country:         req.Address.Country,
+		TaxID:           optionalString(req.TaxID),
+		PaymentTerms:    entity.PaymentTerms(req.PaymentTerms),
+		InvoiceDelivery: entity.InvoiceDelivery(req.InvoiceDelivery),
+		Currency:        optionalString(req.Currency),
+	}
+	if err := h.BillingProfileRepo.Upsert(ctx, profile); err != nil {
+		slog.ErrorContext(ctx, "error storing billing profile",
+			"customer_uuid", req.CustomerUUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	return mapBillingProfileToResponse(profile), nil
+}
+
+func validateUpdateBillingProfile(req *dto.UpdateBillingProfileRequest) []utils.ValidationError {
+	var errs []utils.ValidationError
+	if req.CustomerUUID == "" {
+		errs = append(errs, utils.ErrInvalidCustomerUUID)
+	}
+	if req.Address.Line1 == "" || req.Address.City == "" {
+		errs = append(errs, utils.ErrInvalidAddress)
+	}
+	if !taxid.IsValidCountry(req.Address.Country) {
+		errs = append(errs, utils.ErrInvalidCountry)
+	} else if req.TaxID != "" && !taxid.Validate(req.Address.Country, req.TaxID) {
+		errs = append(errs, utils.ErrInvalidTaxID)
+	}
+	if req.Currency != "" && !currency.IsValid(req.Currency) {
+		errs = append(errs, utils.ErrInvalidCurrency)
+	}
+	if req.PaymentTerms != "" && !entity.PaymentTerms(req.PaymentTerms).IsValid() {
+		errs = append(errs, utils.ErrInvalidPaymentTerms)
+	}
+	if !entity.InvoiceDelivery(req.InvoiceDelivery).IsValid() {
+		errs = append(errs, utils.ErrInvalidInvoiceDelivery)
+	}
+	return errs
+}
+
+// optionalString stores an empty request field as NULL
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/beta/errs"
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func validBillingProfileRequest() *dto.UpdateBillingProfileRequest {
+	return &dto.UpdateBillingProfileRequest{
+		CustomerUUID: "customer-123",
+		Address: dto.BillingAddress{
+			Line1:      "1 Rustaveli Ave",
+			City:       "Tbilisi",
+			PostalCode: "0108",
+			Country:    "ge",
+		},
+		TaxID:        "123-456-789",
+		Currency:     "gel",
+		PaymentTerms: "NET15",
+	}
+}
+
+func TestUpdateBillingProfileHandler_Handle(t *testing.T) {
+	t.Run("success - stores the normalized profile", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+		handler := &UpdateBillingProfileHandler{
+			CustomerRepo:       mockCustomerRepo,
+			BillingProfileRepo: mockBillingProfileRepo,
+		}
+
+		updatedAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusActive}, nil)
+		mockBillingProfileRepo.EXPECT().
+			Upsert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, p *entity.BillingProfileEntity) error {
+				assert.Equal(t, "GE", p.Country)
+				require.NotNil(t, p.TaxID)
+				assert.Equal(t, "123456789", *p.TaxID)
+				require.NotNil(t, p.Currency)
+				assert.Equal(t, "GEL", *p.Currency)
+				assert.Nil(t, p.AddressLine2)
+				assert.Equal(t, entity.PaymentTermsNet15, p.PaymentTerms)
+				assert.Equal(t, entity.InvoiceDeliveryEmail, p.InvoiceDelivery)
+				p.UpdatedAt = updatedAt
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), validBillingProfileRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, "customer-123", resp.CustomerUUID)
+		assert.Equal(t, "0108", resp.Address.PostalCode)
+		assert.Equal(t, "123456789", resp.TaxID)
+		assert.Equal(t, "GEL", resp.Currency)
+		assert.Equal(t, "NET15", resp.PaymentTerms)
+		assert.Equal(t, "EMAIL", resp.InvoiceDelivery)
+		assert.Equal(t, updatedAt, resp.UpdatedAt)
+	})
+
+	t.Run("error - validation fails", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(req *dto.UpdateBillingProfileRequest)
+			want   utils.ValidationError
+		}{
+			{"missing customer uuid", func(req *dto.UpdateBillingProfileRequest) { req.CustomerUUID = "" }, utils.ErrInvalidCustomerUUID},
+			{"missing city", func(req *dto.UpdateBillingProfileRequest) { req.Address.City = "" }, utils.ErrInvalidAddress},
+			{"invalid country", func(req *dto.UpdateBillingProfileRequest) { req.Address.Country = "GEO" }, utils.ErrInvalidCountry},
+			{"tax id of another country", func(req *dto.UpdateBillingProfileRequest) { req.TaxID = "DE123456789" }, utils.ErrInvalidTaxID},
+			{"unsupported currency", func(req *dto.UpdateBillingProfileRequest) { req.Currency = "XXX" }, utils.ErrInvalidCurrency},
+			{"unknown payment terms", func(req *dto.UpdateBillingProfileRequest) { req.PaymentTerms = "NET60" }, utils.ErrInvalidPaymentTerms},
+			{"unknown delivery", func(req *dto.UpdateBillingProfileRequest) { req.InvoiceDelivery = "FAX" }, utils.ErrInvalidInvoiceDelivery},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				handler := &UpdateBillingProfileHandler{
+					CustomerRepo:       mocks.NewMockCustomerRepository(ctrl),
+					BillingProfileRepo: mocks.NewMockBillingProfileRepository(ctrl),
+				}
+
+				req := validBillingProfileRequest()
+				tt.modify(req)
+				resp, err := handler.Handle(context.Background(), req)
+
+				assert.Nil(t, resp)
+				var apiErr *errs.Error
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, utils.ValidationErrors{tt.want}, apiErr.Details)
+			})
+		}
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateBillingProfileHandler{
+			CustomerRepo:       mockCustomerRepo,
+			BillingProfileRepo: mocks.NewMockBillingProfileRepository(ctrl),
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), validBillingProfileRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerNotFoundAPI, err)
+	})
+
+	t.Run("error - customer merged", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateBillingProfileHandler{
+			CustomerRepo:       mockCustomerRepo,
+			BillingProfileRepo: mocks.NewMockBillingProfileRepository(ctrl),
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusMerged}, nil)
+
+		resp, err := handler.Handle(context.Background(), validBillingProfileRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerMerged, err)
+	})
+
+	t.Run("error - upsert fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+		handler := &UpdateBillingProfileHandler{
+			CustomerRepo:       mockCustomerRepo,
+			BillingProfileRepo: mockBillingProfileRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(&entity.CustomerEntity{UUID: "customer-123", Status: entity.CustomerStatusArchived}, nil)
+		mockBillingProfileRepo.EXPECT().
+			Upsert(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), validBillingProfileRequest())
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
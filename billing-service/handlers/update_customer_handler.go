@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+	"encore.dev/storage/sqldb"
+)
+
+type UpdateCustomerHandler struct {
+	CustomerRepo repository.CustomerRepository
+}
+
+// Handle changes an active customer's name and email, a field left empty
+// keeps its value. The email must not be used by another active customer.
+func (h *UpdateCustomerHandler) Handle(ctx context.Context, req *dto.UpdateCustomerRequest) (*dto.CustomerSummary, error) {
+	if req.UUID == "" {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidUUID})
+	}
+	if req.Name == "" && req.Email == "" {
+		return nil, utils.ErrValidationFailedWithDetails(utils.ValidationErrors{utils.ErrInvalidName, utils.ErrInvalidEmail})
+	}
+
+	customer, err := h.CustomerRepo.FetchByUUID(ctx, req.UUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrCustomerNotFoundAPI
+		}
+		slog.ErrorContext(ctx, "error fetching customer",
+			"uuid", req.UUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	if req.Name != "" {
+		customer.Name = req.Name
+	}
+	if req.Email != "" {
+		customer.Email = req.Email
+	}
+
+	if err := h.CustomerRepo.Update(ctx, customer); err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrNoRows):
+			return nil, utils.ErrCustomerNotFoundAPI
+		case errors.Is(err, entity.ErrEmailTaken):
+			return nil, utils.ErrEmailAlreadyUsed
+		case errors.Is(err, entity.ErrCustomerArchived), errors.Is(err, entity.ErrCustomerMerged):
+			return nil, customerStatusError(err)
+		}
+		slog.ErrorContext(ctx, "error updating customer",
+			"uuid", req.UUID,
+			"err", err)
+		return nil, utils.ErrInternal
+	}
+
+	summary := mapCustomerToSummary(customer)
+	return &summary, nil
+}
+
+// customerStatusError maps why a customer is not active to its API error
+func customerStatusError(err error) error {
+	if errors.Is(err, entity.ErrCustomerMerged) {
+		return utils.ErrCustomerMerged
+	}
+	return utils.ErrCustomerArchived
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateCustomerHandler_Handle(t *testing.T) {
+	activeCustomer := func() *entity.CustomerEntity {
+		return &entity.CustomerEntity{
+			ID:     1,
+			UUID:   "customer-123",
+			Name:   "Test User",
+			Email:  "test@example.com",
+			Status: entity.CustomerStatusActive,
+		}
+	}
+
+	t.Run("success - updates the email and keeps the name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(activeCustomer(), nil)
+		mockCustomerRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, c *entity.CustomerEntity) error {
+				assert.Equal(t, "Test User", c.Name)
+				assert.Equal(t, "new@example.com", c.Email)
+				return nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{
+			UUID:  "customer-123",
+			Email: "new@example.com",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "customer-123", resp.UUID)
+		assert.Equal(t, "Test User", resp.Name)
+		assert.Equal(t, "new@example.com", resp.Email)
+		assert.Equal(t, "ACTIVE", resp.Status)
+	})
+
+	t.Run("error - missing uuid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &UpdateCustomerHandler{CustomerRepo: mocks.NewMockCustomerRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{Name: "Test User"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - nothing to update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &UpdateCustomerHandler{CustomerRepo: mocks.NewMockCustomerRepository(ctrl)}
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{UUID: "customer-123"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{
+			UUID: "customer-123",
+			Name: "New Name",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerNotFoundAPI, err)
+	})
+
+	t.Run("error - email used by another customer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(activeCustomer(), nil)
+		mockCustomerRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any()).
+			Return(entity.ErrEmailTaken)
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{
+			UUID:  "customer-123",
+			Email: "taken@example.com",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrEmailAlreadyUsed, err)
+	})
+
+	t.Run("error - customer archived", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(activeCustomer(), nil)
+		mockCustomerRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any()).
+			Return(entity.ErrCustomerArchived)
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{
+			UUID: "customer-123",
+			Name: "New Name",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrCustomerArchived, err)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		handler := &UpdateCustomerHandler{CustomerRepo: mockCustomerRepo}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "customer-123").
+			Return(activeCustomer(), nil)
+		mockCustomerRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		resp, err := handler.Handle(context.Background(), &dto.UpdateCustomerRequest{
+			UUID: "customer-123",
+			Name: "New Name",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrInternal, err)
+	})
+}
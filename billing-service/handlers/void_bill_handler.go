@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"encore.app/db/repository"
+	"encore.app/dto"
+	"encore.app/entity"
+	t "encore.app/temporal"
+	tbill "encore.app/temporal/bill"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+)
+
+type VoidBillHandler struct {
+	BillRepo       repository.BillRepository
+	TemporalClient t.WorkflowClient
+}
+
+// Handle voids an open bill through the bill workflow and returns once it is
+// stored. Voiding a voided bill succeeds without asking the workflow again;
+// bills past OPEN, or closing while the request ran, are rejected.
+func (h *VoidBillHandler) Handle(ctx context.Context, req *dto.VoidBillRequest) (*dto.VoidBillResponse, error) {
+	if validationErrors := validateVoidBill(req); len(validationErrors) != 0 {
+		return nil, utils.ErrValidationFailedWithDetails(validationErrors)
+	}
+
+	bill, err := h.BillRepo.FetchByUUID(ctx, req.UUID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, utils.ErrBillNotFoundAPI
+		}
+		return nil, utils.ErrInternal
+	}
+
+	status := entity.BillStatus(bill.Status)
+
+	if status == entity.BillStatusVoided {
+		return &dto.VoidBillResponse{
+			UUID:    req.UUID,
+			Status:  entity.BillStatusVoided.String(),
+			Message: "Bill is already voided.",
+		}, nil
+	}
+
+	if !status.CanTransitionTo(entity.BillStatusVoided) {
+		return nil, utils.ErrBillAlreadyClosedAPI
+	}
+
+	// the update ID is per bill, so concurrent voids share one outcome
+	var voidedStatus entity.BillStatus
+	err = runBillUpdate(ctx, h.TemporalClient, req.UUID, "void-"+req.UUID, tbill.UpdateVoidBill, tbill.VoidBillUpdate{
+		Reason: req.Reason,
+	}, &voidedStatus)
+	if err != nil {
+		// the workflow rejected the void or completed since the status check
+		if err == utils.ErrBillClosed {
+			return nil, utils.ErrBillAlreadyClosedAPI
+		}
+		return nil, err
+	}
+
+	return &dto.VoidBillResponse{
+		UUID:    req.UUID,
+		Status:  voidedStatus.String(),
+		Message: "Bill voided.",
+	}, nil
+}
+
+func validateVoidBill(req *dto.VoidBillRequest) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+
+	if req.UUID == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidUUID)
+	}
+	if req.Reason == "" {
+		validationErrors = append(validationErrors, utils.ErrInvalidVoidReason)
+	}
+
+	return validationErrors
+}
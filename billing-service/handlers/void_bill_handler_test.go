@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/dto"
+	"encore.app/entity"
+	tbill "encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/utils"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVoidBillHandler_Handle(t *testing.T) {
+	t.Run("success - voids the bill", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &VoidBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		billUUID := "bill-123"
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Status: "OPEN"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+				assert.Equal(t, "bill-"+billUUID, options.WorkflowID)
+				assert.Equal(t, "void-"+billUUID, options.UpdateID)
+				assert.Equal(t, tbill.UpdateVoidBill, options.UpdateName)
+				assert.Equal(t, client.WorkflowUpdateStageCompleted, options.WaitForStage)
+				assert.Equal(t, tbill.VoidBillUpdate{Reason: "created by mistake"}, options.Args[0])
+
+				status := entity.BillStatusVoided
+				return &mockUpdateHandle{value: &status}, nil
+			})
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{
+			UUID:   billUUID,
+			Reason: "created by mistake",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, billUUID, resp.UUID)
+		assert.Equal(t, "VOIDED", resp.Status)
+	})
+
+	t.Run("success - already voided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &VoidBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		// no update expected, the workflow already completed
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "VOIDED"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{
+			UUID:   "bill-123",
+			Reason: "created by mistake",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "VOIDED", resp.Status)
+	})
+
+	t.Run("error - validation fails - missing reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := &VoidBillHandler{
+			BillRepo:       mocks.NewMockBillRepository(ctrl),
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{UUID: "bill-123"})
+
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error - bill not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &VoidBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(nil, sqldb.ErrNoRows)
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{
+			UUID:   "bill-123",
+			Reason: "created by mistake",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillNotFoundAPI, err)
+	})
+
+	t.Run("error - bill already closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		handler := &VoidBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: temporalmocks.NewMockWorkflowClient(ctrl),
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "CLOSED"}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{
+			UUID:   "bill-123",
+			Reason: "created by mistake",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillAlreadyClosedAPI, err)
+	})
+
+	t.Run("error - workflow rejects the void", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &VoidBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		// the bill started closing after the status check
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(nil, temporal.NewApplicationError("bill can no longer be voided", tbill.ErrTypeBillClosed))
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{
+			UUID:   "bill-123",
+			Reason: "created by mistake",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrBillAlreadyClosedAPI, err)
+	})
+
+	t.Run("error - workflow update fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockTemporalClient := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		handler := &VoidBillHandler{
+			BillRepo:       mockBillRepo,
+			TemporalClient: mockTemporalClient,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Status: "OPEN"}, nil)
+
+		mockTemporalClient.EXPECT().
+			UpdateWorkflow(gomock.Any(), gomock.Any()).
+			Return(&mockUpdateHandle{err: assert.AnError}, nil)
+
+		resp, err := handler.Handle(context.Background(), &dto.VoidBillRequest{
+			UUID:   "bill-123",
+			Reason: "created by mistake",
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, utils.ErrWorkflowUpdateFailed, err)
+	})
+}
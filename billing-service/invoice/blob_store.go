@@ -0,0 +1,90 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"encore.dev/storage/objects"
+)
+
+// ErrBlobNotFound is returned by BlobStore.Get when no blob is stored under the key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores invoice artifacts. Put overwrites, so storing the same
+// rendering twice is safe.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// LocalBlobStore keeps blobs as files under Dir, for tests and local development.
+type LocalBlobStore struct {
+	Dir string
+}
+
+// Ensure LocalBlobStore implements BlobStore.
+var _ BlobStore = (*LocalBlobStore)(nil)
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s *LocalBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return data, err
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
+
+// BucketBlobStore keeps blobs in an Encore object storage bucket.
+type BucketBlobStore struct {
+	Bucket *objects.Bucket
+}
+
+// Ensure BucketBlobStore implements BlobStore.
+var _ BlobStore = (*BucketBlobStore)(nil)
+
+func (s *BucketBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	w := s.Bucket.Upload(ctx, key, objects.WithUploadAttrs(objects.UploadAttrs{ContentType: contentType}))
+	if _, err := w.Write(data); err != nil {
+		w.Abort(err)
+		return err
+	}
+	return w.Close()
+}
+
+func (s *BucketBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	r := s.Bucket.Download(ctx, key)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if errors.Is(err, objects.ErrObjectNotFound) {
+		return nil, ErrBlobNotFound
+	}
+	return data, err
+}
@@ -0,0 +1,102 @@
+package invoice
+
+import (
+	"time"
+
+	"encore.app/entity"
+)
+
+// Document is the canonical invoice. The JSON rendering is the document itself,
+// the HTML and PDF renderings are views of it.
+type Document struct {
+	Number      string     `json:"number"`
+	BillUUID    string     `json:"billUuid"`
+	IssuedAt    time.Time  `json:"issuedAt"`
+	Customer    Customer   `json:"customer"`
+	Currency    string     `json:"currency"`
+	PeriodStart time.Time  `json:"periodStart"`
+	PeriodEnd   time.Time  `json:"periodEnd"`
+	LineItems   []LineItem `json:"lineItems"`
+	TotalCents  int64      `json:"totalCents"`
+}
+
+type Customer struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+
+	// Address and TaxID come from the customer's billing profile, empty
+	// when the customer has none
+	Address []string `json:"address,omitempty"`
+	TaxID   string   `json:"taxId,omitempty"`
+}
+
+type LineItem struct {
+	UUID        string    `json:"uuid"`
+	FeeType     string    `json:"feeType"`
+	Description string    `json:"description,omitempty"`
+	AmountCents int64     `json:"amountCents"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// NewDocument assembles the invoice of a closed bill. The total is the bill's
+// locked total, which already includes tax line items. profile is nil for
+// customers without a billing profile.
+func NewDocument(inv *entity.InvoiceEntity, bill *entity.BillEntity, customer *entity.CustomerEntity, profile *entity.BillingProfileEntity, lineItems []*entity.LineItemEntity) *Document {
+	doc := &Document{
+		Number:   inv.Number,
+		BillUUID: bill.UUID,
+		IssuedAt: inv.IssuedAt.UTC(),
+		Customer: Customer{
+			UUID:  customer.UUID,
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		Currency:    bill.Currency,
+		PeriodStart: bill.PeriodStart.UTC(),
+		PeriodEnd:   bill.PeriodEnd.UTC(),
+		LineItems:   make([]LineItem, len(lineItems)),
+	}
+	if profile != nil {
+		doc.Customer.Address = addressLines(profile)
+		if profile.TaxID != nil {
+			doc.Customer.TaxID = *profile.TaxID
+		}
+	}
+
+	var sum int64
+	for i, li := range lineItems {
+		doc.LineItems[i] = LineItem{
+			UUID:        li.UUID,
+			FeeType:     li.FeeType,
+			Description: li.Description,
+			AmountCents: li.AmountCents,
+			CreatedAt:   li.CreatedAt.UTC(),
+		}
+		sum += li.AmountCents
+	}
+
+	doc.TotalCents = sum
+	if bill.TotalCents != nil {
+		doc.TotalCents = *bill.TotalCents
+	}
+
+	return doc
+}
+
+// addressLines formats a postal address the way it is printed on the invoice.
+func addressLines(p *entity.BillingProfileEntity) []string {
+	lines := []string{p.AddressLine1}
+	if p.AddressLine2 != nil {
+		lines = append(lines, *p.AddressLine2)
+	}
+	city := p.City
+	if p.PostalCode != nil {
+		city = *p.PostalCode + " " + city
+	}
+	lines = append(lines, city)
+	if p.Region != nil {
+		lines = append(lines, *p.Region)
+	}
+	return append(lines, p.Country)
+}
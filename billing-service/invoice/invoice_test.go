@@ -0,0 +1,125 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"encore.app/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocument() *Document {
+	totalCents := int64(1075)
+	createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	postalCode, taxID := "0108", "123456789"
+
+	return NewDocument(
+		&entity.InvoiceEntity{Number: "INV-CUST4567-000001", IssuedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		&entity.BillEntity{
+			UUID:        "bill-123",
+			Currency:    "USD",
+			PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			TotalCents:  &totalCents,
+		},
+		&entity.CustomerEntity{UUID: "cust-4567", Name: "Acme (Tbilisi)", Email: "billing@acme.test"},
+		&entity.BillingProfileEntity{
+			AddressLine1: "1 Rustaveli Ave",
+			City:         "Tbilisi",
+			PostalCode:   &postalCode,
+			Country:      "GE",
+			TaxID:        &taxID,
+		},
+		[]*entity.LineItemEntity{
+			{UUID: "li-1", FeeType: "ACH", Description: "ACH transfer", AmountCents: 1000, CreatedAt: createdAt},
+			{UUID: "li-2", FeeType: "TAX", Description: "Tax on ACH at 750 bps", AmountCents: 75, CreatedAt: createdAt},
+		},
+	)
+}
+
+func TestRender(t *testing.T) {
+	doc := testDocument()
+
+	t.Run("json round-trips the document", func(t *testing.T) {
+		data, err := Render(doc, FormatJSON)
+		require.NoError(t, err)
+
+		var decoded Document
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, *doc, decoded)
+	})
+
+	t.Run("html lists line items and total", func(t *testing.T) {
+		data, err := Render(doc, FormatHTML)
+		require.NoError(t, err)
+
+		html := string(data)
+		assert.Contains(t, html, "Invoice INV-CUST4567-000001")
+		assert.Contains(t, html, "ACH transfer")
+		assert.Contains(t, html, "10.75 USD")
+		assert.Contains(t, html, "&lt;billing@acme.test&gt;")
+		assert.Contains(t, html, "1 Rustaveli Ave<br>0108 Tbilisi<br>GE")
+		assert.Contains(t, html, "Tax ID 123456789")
+	})
+
+	t.Run("pdf is a complete document", func(t *testing.T) {
+		data, err := Render(doc, FormatPDF)
+		require.NoError(t, err)
+
+		assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+		assert.True(t, bytes.HasSuffix(bytes.TrimSpace(data), []byte("%%EOF")))
+		assert.Contains(t, string(data), `Acme \(Tbilisi\)`)
+		assert.Contains(t, string(data), "Tax ID 123456789")
+	})
+
+	t.Run("customers without a billing profile have no address", func(t *testing.T) {
+		doc := NewDocument(&entity.InvoiceEntity{}, &entity.BillEntity{}, &entity.CustomerEntity{Name: "Acme"}, nil, nil)
+		assert.Empty(t, doc.Customer.Address)
+
+		data, err := Render(doc, FormatHTML)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "<address>")
+		assert.NotContains(t, string(data), "Tax ID")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		data, err := Render(doc, Format("docx"))
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "10.50 USD", FormatAmount(1050, "USD"))
+	assert.Equal(t, "-0.05 GEL", FormatAmount(-5, "GEL"))
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store := &LocalBlobStore{Dir: t.TempDir()}
+
+	t.Run("put then get", func(t *testing.T) {
+		key := ArtifactKey("bill-123", FormatHTML)
+		require.NoError(t, store.Put(ctx, key, []byte("<html>"), FormatHTML.ContentType()))
+		require.NoError(t, store.Put(ctx, key, []byte("<html></html>"), FormatHTML.ContentType()))
+
+		data, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, "<html></html>", string(data))
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		data, err := store.Get(ctx, ArtifactKey("bill-404", FormatPDF))
+		assert.Nil(t, data)
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("rejects keys outside the directory", func(t *testing.T) {
+		assert.Error(t, store.Put(ctx, "../escape", []byte("x"), "text/plain"))
+	})
+}
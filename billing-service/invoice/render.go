@@ -0,0 +1,141 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// Format is a rendering of the invoice document.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+	FormatPDF  Format = "pdf"
+)
+
+// Formats lists every rendering stored for an invoice.
+var Formats = []Format{FormatJSON, FormatHTML, FormatPDF}
+
+// IsValid checks if the format is a supported rendering
+func (f Format) IsValid() bool {
+	for _, valid := range Formats {
+		if f == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the MIME type served for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/json"
+	}
+}
+
+// ArtifactKey is the blob store key of an invoice rendering.
+func ArtifactKey(billUUID string, format Format) string {
+	return fmt.Sprintf("invoices/%s/invoice.%s", billUUID, format)
+}
+
+// Render renders the document in the given format.
+func Render(doc *Document, format Format) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	case FormatHTML:
+		return renderHTML(doc)
+	case FormatPDF:
+		return renderPDF(doc), nil
+	default:
+		return nil, fmt.Errorf("unsupported invoice format %q", format)
+	}
+}
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount": FormatAmount,
+	"date":   formatDate,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Issued {{date .IssuedAt}}</p>
+<p>Billed to {{.Customer.Name}} &lt;{{.Customer.Email}}&gt;</p>
+{{- if .Customer.Address}}
+<address>{{range $i, $line := .Customer.Address}}{{if $i}}<br>{{end}}{{$line}}{{end}}</address>
+{{- end}}
+{{- if .Customer.TaxID}}
+<p>Tax ID {{.Customer.TaxID}}</p>
+{{- end}}
+<p>Period {{date .PeriodStart}} to {{date .PeriodEnd}}</p>
+<table>
+<thead><tr><th>Date</th><th>Fee type</th><th>Description</th><th>Amount</th></tr></thead>
+<tbody>
+{{- range .LineItems}}
+<tr><td>{{date .CreatedAt}}</td><td>{{.FeeType}}</td><td>{{.Description}}</td><td>{{amount .AmountCents $.Currency}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot><tr><td colspan="3">Total</td><td>{{amount .TotalCents .Currency}}</td></tr></tfoot>
+</table>
+</body>
+</html>
+`))
+
+func renderHTML(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderPDF lays the invoice out as plain text lines.
+func renderPDF(doc *Document) []byte {
+	lines := []string{
+		"Invoice " + doc.Number,
+		"Issued " + formatDate(doc.IssuedAt),
+		"Billed to " + doc.Customer.Name + " <" + doc.Customer.Email + ">",
+	}
+	lines = append(lines, doc.Customer.Address...)
+	if doc.Customer.TaxID != "" {
+		lines = append(lines, "Tax ID "+doc.Customer.TaxID)
+	}
+	lines = append(lines,
+		"Period "+formatDate(doc.PeriodStart)+" to "+formatDate(doc.PeriodEnd),
+		"",
+	)
+	for _, li := range doc.LineItems {
+		lines = append(lines, fmt.Sprintf("%s  %-14s %-40.40s %16s",
+			formatDate(li.CreatedAt), li.FeeType, li.Description, FormatAmount(li.AmountCents, doc.Currency)))
+	}
+	lines = append(lines, "", fmt.Sprintf("Total %s", FormatAmount(doc.TotalCents, doc.Currency)))
+
+	return newTextPDF(lines)
+}
+
+// FormatAmount renders minor units as a decimal amount, e.g. -1050 USD as "-10.50 USD".
+func FormatAmount(cents int64, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, cents/100, cents%100, currency)
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
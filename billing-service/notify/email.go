@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailConfig points the email notifier at an SMTP server. Locally this is a
+// catch-all stand-in such as MailHog listening on 127.0.0.1:1025.
+type EmailConfig struct {
+	Addr string
+	From string
+}
+
+// EmailNotifier sends notifications as plain text email over SMTP.
+type EmailNotifier struct {
+	Addr string
+	From string
+
+	// Auth is optional, local stand-ins accept unauthenticated mail
+	Auth smtp.Auth
+}
+
+// Ensure EmailNotifier implements Notifier.
+var _ Notifier = (*EmailNotifier)(nil)
+
+func (n *EmailNotifier) Channel() string {
+	return "email"
+}
+
+func (n *EmailNotifier) Send(ctx context.Context, notification Notification) error {
+	if notification.Recipient == "" {
+		return fmt.Errorf("notification %s has no recipient", notification.IdempotencyKey)
+	}
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{notification.Recipient}, n.message(notification))
+}
+
+// message builds the RFC 5322 message. The idempotency key doubles as the
+// Message-ID so mail servers can drop a redelivered copy.
+func (n *EmailNotifier) message(notification Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", notification.Recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(notification.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@billing>\r\n", headerValue(notification.IdempotencyKey))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(notification.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// headerValue strips line breaks so values cannot inject headers.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
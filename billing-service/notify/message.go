@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"encore.app/entity"
+	"encore.app/invoice"
+)
+
+// MessageData is what a bill notification reports on.
+type MessageData struct {
+	BillUUID      string
+	Currency      string
+	AmountCents   int64
+	Description   string
+	InvoiceNumber string
+	DueDate       *time.Time
+}
+
+// NewNotification composes the message a customer receives for a bill event.
+func NewNotification(event Event, idempotencyKey string, customer *entity.CustomerEntity, data MessageData) Notification {
+	n := Notification{
+		IdempotencyKey: idempotencyKey,
+		Event:          event,
+		CustomerUUID:   customer.UUID,
+		Recipient:      customer.Email,
+		BillUUID:       data.BillUUID,
+	}
+
+	switch event {
+	case EventBillOpened:
+		n.Subject = "Your new bill is open"
+		n.Body = fmt.Sprintf("Hello %s,\n\nBill %s is now open and collecting charges.", customer.Name, data.BillUUID)
+	case EventLargeCharge:
+		n.Subject = fmt.Sprintf("Charge of %s added to your bill", invoice.FormatAmount(data.AmountCents, data.Currency))
+		n.Body = fmt.Sprintf("Hello %s,\n\nA charge of %s (%s) was added to bill %s.",
+			customer.Name, invoice.FormatAmount(data.AmountCents, data.Currency), data.Description, data.BillUUID)
+	case EventBillClosed:
+		n.Subject = "Your bill is closed"
+		n.Body = fmt.Sprintf("Hello %s,\n\nBill %s closed with a total of %s.",
+			customer.Name, data.BillUUID, invoice.FormatAmount(data.AmountCents, data.Currency))
+		if data.InvoiceNumber != "" {
+			n.Subject = "Invoice " + data.InvoiceNumber
+			n.Body += fmt.Sprintf("\nInvoice %s is available.", data.InvoiceNumber)
+		}
+	case EventPaymentReminder:
+		n.Subject = "Payment reminder"
+		n.Body = fmt.Sprintf("Hello %s,\n\n%s is left to pay on bill %s%s.",
+			customer.Name, invoice.FormatAmount(data.AmountCents, data.Currency), data.BillUUID, dueOn(data.DueDate))
+	case EventBillOverdue:
+		n.Subject = "Your bill is overdue"
+		n.Body = fmt.Sprintf("Hello %s,\n\nBill %s is overdue%s with %s left to pay.",
+			customer.Name, data.BillUUID, dueOn(data.DueDate), invoice.FormatAmount(data.AmountCents, data.Currency))
+	case EventBillPaid:
+		n.Subject = "Thank you for your payment"
+		n.Body = fmt.Sprintf("Hello %s,\n\nBill %s is paid in full.", customer.Name, data.BillUUID)
+	}
+
+	return n
+}
+
+func dueOn(dueDate *time.Time) string {
+	if dueDate == nil {
+		return ""
+	}
+	return ", due on " + dueDate.Format("2006-01-02")
+}
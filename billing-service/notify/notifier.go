@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Event is the bill event a notification reports.
+type Event string
+
+const (
+	EventBillOpened  Event = "BILL_OPENED"
+	EventLargeCharge Event = "LARGE_CHARGE"
+	EventBillClosed  Event = "BILL_CLOSED"
+
+	// dunning events, sent while a closed bill awaits payment
+	EventPaymentReminder Event = "PAYMENT_REMINDER"
+	EventBillOverdue     Event = "BILL_OVERDUE"
+	EventBillPaid        Event = "BILL_PAID"
+)
+
+func (e Event) String() string {
+	return string(e)
+}
+
+// Notification is a message to one customer. IdempotencyKey identifies the
+// delivery, receivers can use it to drop duplicates.
+type Notification struct {
+	IdempotencyKey string `json:"idempotencyKey"`
+	Event          Event  `json:"event"`
+	CustomerUUID   string `json:"customerUuid"`
+	Recipient      string `json:"recipient"`
+	BillUUID       string `json:"billUuid"`
+	Subject        string `json:"subject"`
+	Body           string `json:"body"`
+}
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	// Channel names the channel deliveries are recorded under
+	Channel() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the service log instead of delivering them.
+type LogNotifier struct{}
+
+// Ensure LogNotifier implements Notifier.
+var _ Notifier = (*LogNotifier)(nil)
+
+func (n *LogNotifier) Channel() string {
+	return "log"
+}
+
+func (n *LogNotifier) Send(ctx context.Context, notification Notification) error {
+	slog.InfoContext(ctx, "customer notification",
+		"event", notification.Event,
+		"idempotency_key", notification.IdempotencyKey,
+		"customer_uuid", notification.CustomerUUID,
+		"recipient", notification.Recipient,
+		"bill_uuid", notification.BillUUID,
+		"subject", notification.Subject)
+	return nil
+}
+
+// New builds the notifier of a configured channel: email, webhook or log.
+func New(channel string, email EmailConfig, webhookURL string) (Notifier, error) {
+	switch channel {
+	case "email":
+		return &EmailNotifier{Addr: email.Addr, From: email.From}, nil
+	case "webhook":
+		if webhookURL == "" {
+			return nil, fmt.Errorf("webhook notifier requires a URL")
+		}
+		return &WebhookNotifier{URL: webhookURL}, nil
+	case "log":
+		return &LogNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("unknown notification channel %q", channel)
+	}
+}
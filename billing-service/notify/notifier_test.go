@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	n, err := New("email", EmailConfig{Addr: "127.0.0.1:1025", From: "billing@localhost"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "email", n.Channel())
+
+	n, err = New("log", EmailConfig{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "log", n.Channel())
+
+	_, err = New("webhook", EmailConfig{}, "")
+	assert.Error(t, err)
+
+	_, err = New("sms", EmailConfig{}, "")
+	assert.Error(t, err)
+}
+
+func TestNewNotification(t *testing.T) {
+	customer := &entity.CustomerEntity{UUID: "cust-456", Name: "Acme", Email: "billing@acme.test"}
+
+	n := NewNotification(EventLargeCharge, "key-1", customer, MessageData{
+		BillUUID:    "bill-123",
+		Currency:    "GEL",
+		AmountCents: 250000,
+		Description: "Wire to supplier",
+	})
+
+	assert.Equal(t, "billing@acme.test", n.Recipient)
+	assert.Equal(t, "cust-456", n.CustomerUUID)
+	assert.Equal(t, "Charge of 2500.00 GEL added to your bill", n.Subject)
+	assert.Contains(t, n.Body, "Wire to supplier")
+
+	dueDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	n = NewNotification(EventBillOverdue, "key-2", customer, MessageData{
+		BillUUID:    "bill-123",
+		Currency:    "GEL",
+		AmountCents: 5000,
+		DueDate:     &dueDate,
+	})
+
+	assert.Equal(t, "Your bill is overdue", n.Subject)
+	assert.Contains(t, n.Body, "overdue, due on 2024-03-01 with 50.00 GEL left to pay")
+}
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	notification := Notification{
+		IdempotencyKey: "bill:bill-123:BILL_OPENED",
+		Event:          EventBillOpened,
+		Recipient:      "billing@acme.test",
+		BillUUID:       "bill-123",
+	}
+
+	t.Run("posts the notification", func(t *testing.T) {
+		var received Notification
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, notification.IdempotencyKey, r.Header.Get("Idempotency-Key"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		n := &WebhookNotifier{URL: server.URL, Client: server.Client()}
+
+		require.NoError(t, n.Send(context.Background(), notification))
+		assert.Equal(t, notification, received)
+	})
+
+	t.Run("non-2xx response fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		n := &WebhookNotifier{URL: server.URL, Client: server.Client()}
+
+		assert.Error(t, n.Send(context.Background(), notification))
+	})
+}
+
+func TestEmailNotifier_message(t *testing.T) {
+	n := &EmailNotifier{From: "billing@localhost"}
+
+	msg := string(n.message(Notification{
+		IdempotencyKey: "bill:bill-123:BILL_CLOSED",
+		Recipient:      "billing@acme.test",
+		Subject:        "Your bill is closed\r\nBcc: someone@else.test",
+		Body:           "line one\nline two",
+	}))
+
+	assert.Contains(t, msg, "To: billing@acme.test\r\n")
+	assert.Contains(t, msg, "Message-ID: <bill:bill-123:BILL_CLOSED@billing>\r\n")
+	assert.NotContains(t, msg, "\r\nBcc:")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nline one\r\nline two\r\n"))
+}
+
+func TestEmailNotifier_SendRequiresRecipient(t *testing.T) {
+	n := &EmailNotifier{Addr: "127.0.0.1:1", From: "billing@localhost"}
+
+	assert.Error(t, n.Send(context.Background(), Notification{IdempotencyKey: "key-1"}))
+}
@@ -0,0 +1,112 @@
+// Package proration charges the part of a bill period a charge covers, for
+// charges that start, stop or change within the period and bills that close
+// before their period ends.
+package proration
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Mode tells how a period is measured.
+type Mode string
+
+const (
+	// ModeSecond measures periods to the second
+	ModeSecond Mode = "SECOND"
+	// ModeDay measures periods in UTC calendar days; a day the charge covers
+	// at all counts in full
+	ModeDay Mode = "DAY"
+)
+
+// IsValid checks if the mode is a supported proration mode
+func (m Mode) IsValid() bool {
+	return m == ModeSecond || m == ModeDay
+}
+
+func (m Mode) String() string {
+	return string(m)
+}
+
+// Span is the time range [Start, End).
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Clip returns the part of s within other, and false when they do not overlap.
+func (s Span) Clip(other Span) (Span, bool) {
+	clipped := s
+	if other.Start.After(clipped.Start) {
+		clipped.Start = other.Start
+	}
+	if other.End.Before(clipped.End) {
+		clipped.End = other.End
+	}
+	return clipped, clipped.Start.Before(clipped.End)
+}
+
+// Calculator prorates amounts by the share of a period they cover. The zero
+// value measures in seconds.
+type Calculator struct {
+	Mode Mode
+}
+
+// Result is a prorated amount and the share it was computed from.
+type Result struct {
+	AmountCents int64
+	Covered     int64 // units of the period covered
+	Period      int64 // units in the whole period
+	Unit        string
+}
+
+// IsFull reports whether the whole period is covered, the amount is then not prorated.
+func (r Result) IsFull() bool {
+	return r.Covered >= r.Period
+}
+
+// Explain describes the share, e.g. "12 of 31 days".
+func (r Result) Explain() string {
+	return fmt.Sprintf("%d of %d %s", r.Covered, r.Period, r.Unit)
+}
+
+// Prorate returns the part of amountCents, charged for the whole period, that
+// the covered span accounts for. The covered span is clipped to the period and
+// the amount rounded half up to the cent.
+func (c Calculator) Prorate(amountCents int64, period, covered Span) Result {
+	result := Result{Unit: c.unit(), Period: c.measure(period)}
+
+	if clipped, ok := covered.Clip(period); ok {
+		result.Covered = min(c.measure(clipped), result.Period)
+	}
+
+	switch {
+	case result.Period <= 0 || result.IsFull():
+		result.AmountCents = amountCents
+	case result.Covered > 0:
+		amount := new(big.Int).Mul(big.NewInt(amountCents), big.NewInt(result.Covered))
+		amount.Add(amount, big.NewInt(result.Period/2))
+		result.AmountCents = amount.Quo(amount, big.NewInt(result.Period)).Int64()
+	}
+	return result
+}
+
+func (c Calculator) measure(s Span) int64 {
+	if c.Mode == ModeDay {
+		start := s.Start.UTC().Truncate(24 * time.Hour)
+		end := s.End.UTC().Truncate(24 * time.Hour)
+		if end.Before(s.End) {
+			end = end.Add(24 * time.Hour)
+		}
+		return int64(end.Sub(start) / (24 * time.Hour))
+	}
+	return int64(s.End.Sub(s.Start) / time.Second)
+}
+
+func (c Calculator) unit() string {
+	if c.Mode == ModeDay {
+		return "days"
+	}
+	return "seconds"
+}
@@ -0,0 +1,91 @@
+package proration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculator_Prorate(t *testing.T) {
+	april := Span{
+		Start: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	day := func(d, h int) time.Time { return time.Date(2024, 4, d, h, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		mode    Mode
+		amount  int64
+		covered Span
+		want    Result
+	}{
+		{
+			name:    "day - whole period",
+			mode:    ModeDay,
+			amount:  3000,
+			covered: april,
+			want:    Result{AmountCents: 3000, Covered: 30, Period: 30, Unit: "days"},
+		},
+		{
+			name:    "day - started mid-period",
+			mode:    ModeDay,
+			amount:  3000,
+			covered: Span{Start: day(21, 0), End: april.End},
+			want:    Result{AmountCents: 1000, Covered: 10, Period: 30, Unit: "days"},
+		},
+		{
+			name:    "day - partial days count in full",
+			mode:    ModeDay,
+			amount:  3000,
+			covered: Span{Start: april.Start, End: day(11, 15)},
+			want:    Result{AmountCents: 1100, Covered: 11, Period: 30, Unit: "days"},
+		},
+		{
+			name:    "day - rounds half up",
+			mode:    ModeDay,
+			amount:  1000,
+			covered: Span{Start: april.Start, End: day(2, 0)},
+			want:    Result{AmountCents: 33, Covered: 1, Period: 30, Unit: "days"},
+		},
+		{
+			name:    "second - half a period",
+			mode:    ModeSecond,
+			amount:  3001,
+			covered: Span{Start: day(16, 0), End: april.End},
+			want:    Result{AmountCents: 1501, Covered: 15 * 86400, Period: 30 * 86400, Unit: "seconds"},
+		},
+		{
+			name:    "second - zero value mode",
+			amount:  3000,
+			covered: Span{Start: april.Start, End: day(11, 12)},
+			want:    Result{AmountCents: 1050, Covered: 10*86400 + 12*3600, Period: 30 * 86400, Unit: "seconds"},
+		},
+		{
+			name:    "clipped to the period",
+			mode:    ModeDay,
+			amount:  3000,
+			covered: Span{Start: april.Start.AddDate(0, -1, 0), End: april.End.AddDate(0, 1, 0)},
+			want:    Result{AmountCents: 3000, Covered: 30, Period: 30, Unit: "days"},
+		},
+		{
+			name:    "outside the period",
+			mode:    ModeDay,
+			amount:  3000,
+			covered: Span{Start: april.End, End: april.End.AddDate(0, 1, 0)},
+			want:    Result{AmountCents: 0, Covered: 0, Period: 30, Unit: "days"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Calculator{Mode: tt.mode}.Prorate(tt.amount, april, tt.covered)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResult_Explain(t *testing.T) {
+	assert.Equal(t, "12 of 31 days", Result{Covered: 12, Period: 31, Unit: "days"}.Explain())
+}
@@ -6,7 +6,13 @@ import (
 	"log"
 
 	"encore.app/db/repository"
+	"encore.app/invoice"
 	t "encore.app/temporal"
+	"encore.app/temporal/bill"
+	"encore.app/temporal/outbox"
+	"encore.app/temporal/reconcile"
+	"encore.app/temporal/webhook"
+	"encore.app/usage"
 
 	tworker "go.temporal.io/sdk/worker"
 )
@@ -18,9 +24,29 @@ type Service struct {
 	temporalWorker tworker.Worker
 
 	// Repositories
-	billRepo     repository.BillRepository
-	lineItemRepo repository.LineItemRepository
-	customerRepo repository.CustomerRepository
+	billRepo       repository.BillRepository
+	lineItemRepo   repository.LineItemRepository
+	customerRepo   repository.CustomerRepository
+	invoiceRepo    repository.InvoiceRepository
+	webhookRepo    repository.WebhookRepository
+	paymentRepo    repository.PaymentRepository
+	settlementRepo repository.SettlementRepository
+	promotionRepo  repository.PromotionRepository
+	creditRepo     repository.CreditRepository
+	creditNoteRepo repository.CreditNoteRepository
+	usageRepo      repository.UsageRepository
+	priceRepo      repository.PriceRepository
+
+	subscriptionRepo   repository.SubscriptionRepository
+	reconciliationRepo repository.ReconciliationRepository
+	ledgerRepo         repository.LedgerRepository
+	billingProfileRepo repository.BillingProfileRepository
+
+	// usageCatalog prices the meters usage is recorded against, nil disables metering
+	usageCatalog usage.Catalog
+
+	// invoiceStore keeps the rendered invoice artifacts
+	invoiceStore invoice.BlobStore
 }
 
 // encore automatically triggers initService as part
@@ -39,8 +65,85 @@ func initService() (*Service, error) {
 	billRepo := &repository.BillRepo{DB: db}
 	lineItemRepo := &repository.LineItemRepo{DB: db}
 	customerRepo := &repository.CustomerRepo{DB: db}
+	invoiceRepo := &repository.InvoiceRepo{DB: db}
+	webhookRepo := &repository.WebhookRepo{DB: db}
+	paymentRepo := &repository.PaymentRepo{DB: db}
+	settlementRepo := &repository.SettlementRepo{DB: db}
+	promotionRepo := &repository.PromotionRepo{DB: db}
+	creditRepo := &repository.CreditRepo{DB: db}
+	creditNoteRepo := &repository.CreditNoteRepo{DB: db}
+	usageRepo := &repository.UsageRepo{DB: db}
+	priceRepo := &repository.PriceRepo{DB: db}
+	subscriptionRepo := &repository.SubscriptionRepo{DB: db}
+	reconciliationRepo := &repository.ReconciliationRepo{DB: db}
+	ledgerRepo := &repository.LedgerRepo{DB: db}
+	billingProfileRepo := &repository.BillingProfileRepo{DB: db}
+
+	invoiceStore := &invoice.BucketBlobStore{Bucket: invoiceBucket}
+
+	prorationCalculator, err := cfg.ProrationCalculator()
+	if err != nil {
+		return nil, fmt.Errorf("init proration: %w", err)
+	}
+
+	usageCatalog, err := cfg.UsageCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("init usage catalog: %w", err)
+	}
+
+	notifier, err := cfg.Notifier()
+	if err != nil {
+		return nil, fmt.Errorf("init notifier: %w", err)
+	}
+
+	// events recorded in the outbox reach webhook subscribers and the configured sink
+	sinks := []outbox.Sink{&outbox.WebhookSink{Publisher: &webhook.Publisher{
+		Repo:      webhookRepo,
+		Starter:   tc,
+		TaskQueue: t.TaskQueue,
+	}}}
+	sink, err := cfg.OutboxSink()
+	if err != nil {
+		return nil, fmt.Errorf("init outbox sink: %w", err)
+	}
+	if sink != nil {
+		sinks = append(sinks, sink)
+	}
+
+	w := t.NewWorker(tc, &bill.BillActivities{
+		BillRepo:     billRepo,
+		LineItemRepo: lineItemRepo,
+		CustomerRepo: customerRepo,
+		InvoiceRepo:  invoiceRepo,
+
+		BillingProfileRepo: billingProfileRepo,
+
+		SubscriptionRepo: subscriptionRepo,
+		PriceRepo:        priceRepo,
+		Proration:        prorationCalculator,
+
+		UsageRepo:     usageRepo,
+		UsageCatalog:  usageCatalog,
+		TaxCalculator: &bill.RateTableTaxCalculator{Rates: cfg.TaxRateTable()},
+		PromotionRepo: promotionRepo,
+		CreditRepo:    creditRepo,
+		BlobStore:     invoiceStore,
 
-	w := t.NewWorker(tc, billRepo, lineItemRepo)
+		CreditNoteRepo: creditNoteRepo,
+
+		Notifier:         notifier,
+		NotificationRepo: &repository.NotificationDeliveryRepo{DB: db},
+
+		PaymentRepo:    paymentRepo,
+		SettlementRepo: settlementRepo,
+	}, &webhook.Activities{Repo: webhookRepo}, &outbox.Activities{
+		Repo:  &repository.OutboxRepo{DB: db},
+		Sinks: sinks,
+	}, &reconcile.Activities{
+		Repo:         reconciliationRepo,
+		LineItemRepo: lineItemRepo,
+		Workflows:    tc,
+	})
 
 	go func() {
 		if err := w.Run(tworker.InterruptCh()); err != nil {
@@ -48,6 +151,14 @@ func initService() (*Service, error) {
 		}
 	}()
 
+	if err := outbox.StartRelay(context.Background(), tc, t.TaskQueue, cfg.RelayInput()); err != nil {
+		return nil, fmt.Errorf("start outbox relay: %w", err)
+	}
+
+	if err := reconcile.StartReconciliation(context.Background(), tc, t.TaskQueue, cfg.ReconciliationInput()); err != nil {
+		return nil, fmt.Errorf("start reconciliation: %w", err)
+	}
+
 	return &Service{
 		cfg:            cfg,
 		temporalClient: tc,
@@ -55,6 +166,22 @@ func initService() (*Service, error) {
 		billRepo:       billRepo,
 		lineItemRepo:   lineItemRepo,
 		customerRepo:   customerRepo,
+		invoiceRepo:    invoiceRepo,
+		webhookRepo:    webhookRepo,
+		paymentRepo:    paymentRepo,
+		settlementRepo: settlementRepo,
+		promotionRepo:  promotionRepo,
+		creditRepo:     creditRepo,
+		creditNoteRepo: creditNoteRepo,
+		usageRepo:      usageRepo,
+		priceRepo:      priceRepo,
+		usageCatalog:   usageCatalog,
+		invoiceStore:   invoiceStore,
+
+		subscriptionRepo:   subscriptionRepo,
+		reconciliationRepo: reconciliationRepo,
+		ledgerRepo:         ledgerRepo,
+		billingProfileRepo: billingProfileRepo,
 	}, nil
 }
 
@@ -0,0 +1,6 @@
+package billing
+
+import "encore.dev/storage/objects"
+
+// invoiceBucket stores the rendered invoice artifacts, keyed by invoice.ArtifactKey.
+var invoiceBucket = objects.NewBucket("invoices", objects.BucketConfig{})
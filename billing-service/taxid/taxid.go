@@ -0,0 +1,64 @@
+// Package taxid validates the tax IDs customers are invoiced under against the
+// format of their country.
+package taxid
+
+import (
+	"regexp"
+	"strings"
+)
+
+// formats holds the tax ID format of a country, keyed by ISO 3166-1 alpha-2
+// code. EU VAT numbers carry their country prefix.
+var formats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^ATU\d{8}$`),
+	"BE": regexp.MustCompile(`^BE[01]\d{9}$`),
+	"CA": regexp.MustCompile(`^\d{9}(RT\d{4})?$`),
+	"CH": regexp.MustCompile(`^CHE\d{9}(MWST|TVA|IVA)?$`),
+	"CZ": regexp.MustCompile(`^CZ\d{8,10}$`),
+	"DE": regexp.MustCompile(`^DE\d{9}$`),
+	"DK": regexp.MustCompile(`^DK\d{8}$`),
+	"ES": regexp.MustCompile(`^ES[A-Z0-9]\d{7}[A-Z0-9]$`),
+	"FI": regexp.MustCompile(`^FI\d{8}$`),
+	"FR": regexp.MustCompile(`^FR[A-HJ-NP-Z0-9]{2}\d{9}$`),
+	"GB": regexp.MustCompile(`^GB(\d{9}|\d{12}|GD\d{3}|HA\d{3})$`),
+	"GE": regexp.MustCompile(`^(\d{9}|\d{11})$`),
+	"IE": regexp.MustCompile(`^IE\d[A-Z0-9+*]\d{5}[A-Z]{1,2}$`),
+	"IT": regexp.MustCompile(`^IT\d{11}$`),
+	"NL": regexp.MustCompile(`^NL\d{9}B\d{2}$`),
+	"NO": regexp.MustCompile(`^NO\d{9}(MVA)?$`),
+	"PL": regexp.MustCompile(`^PL\d{10}$`),
+	"PT": regexp.MustCompile(`^PT\d{9}$`),
+	"SE": regexp.MustCompile(`^SE\d{10}01$`),
+	"US": regexp.MustCompile(`^\d{9}$`), // EIN
+}
+
+// other accepts the tax IDs of countries without a known format.
+var other = regexp.MustCompile(`^[A-Z0-9]{4,20}$`)
+
+var country = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// IsValidCountry reports whether code is shaped like an ISO 3166-1 alpha-2
+// country code, codes are upper case.
+func IsValidCountry(code string) bool {
+	return country.MatchString(code)
+}
+
+// Normalize upper-cases a tax ID from a request and drops the spaces, dots and
+// dashes it is often written with.
+func Normalize(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(id)))
+}
+
+// Validate reports whether a normalized tax ID has the format of the country.
+func Validate(countryCode, id string) bool {
+	if format, ok := formats[countryCode]; ok {
+		return format.MatchString(id)
+	}
+	return other.MatchString(id)
+}
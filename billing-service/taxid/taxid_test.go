@@ -0,0 +1,53 @@
+package taxid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "DE123456789", Normalize(" de 123 456 789 "))
+	assert.Equal(t, "123456789", Normalize("12-3456789"))
+	assert.Equal(t, "CHE123456789MWST", Normalize("CHE-123.456.789 MWST"))
+}
+
+func TestValidate(t *testing.T) {
+	valid := map[string]string{
+		"DE": "DE123456789",
+		"FR": "FRXX123456789",
+		"GB": "GB123456789",
+		"NL": "NL123456789B01",
+		"IE": "IE1234567WA",
+		"US": "123456789",
+		"GE": "12345678901",
+		"BR": "12345678000195", // no known format
+	}
+	for country, id := range valid {
+		assert.True(t, Validate(country, id), "%s %s", country, id)
+	}
+
+	invalid := map[string]string{
+		"DE": "DE12345678",      // one digit short
+		"FR": "FRIO123456789",   // I and O are not used in the key
+		"NL": "NL123456789X01",  // missing the B
+		"US": "DE123456789",     // another country's format
+		"GE": "1234567890",      // neither 9 nor 11 digits
+		"BR": "12/345",          // not alphanumeric
+		"AT": "AT12345678",      // missing the U
+		"SE": "SE123456789012",  // must end in 01
+		"IT": "IT1234567890",    // one digit short
+		"PL": "PL12345678901",   // one digit too many
+		"CH": "CHE123456789XYZ", // unknown suffix
+	}
+	for country, id := range invalid {
+		assert.False(t, Validate(country, id), "%s %s", country, id)
+	}
+}
+
+func TestIsValidCountry(t *testing.T) {
+	assert.True(t, IsValidCountry("GE"))
+	assert.False(t, IsValidCountry("ge"))
+	assert.False(t, IsValidCountry("GEO"))
+	assert.False(t, IsValidCountry(""))
+}
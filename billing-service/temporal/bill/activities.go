@@ -2,19 +2,70 @@ package bill
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"slices"
 	"time"
 
 	"encore.app/db/repository"
 	"encore.app/entity"
+	"encore.app/invoice"
+	"encore.app/notify"
+	"encore.app/proration"
+	"encore.app/usage"
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
 )
 
 type BillActivities struct {
 	BillRepo     repository.BillRepository
 	LineItemRepo repository.LineItemRepository
+
+	CustomerRepo repository.CustomerRepository
+	InvoiceRepo  repository.InvoiceRepository
+
+	// BillingProfileRepo backs the payment terms, invoice address and
+	// delivery preference of customers, nil leaves them unset
+	BillingProfileRepo repository.BillingProfileRepository
+
+	// SubscriptionRepo and PriceRepo back the subscription fees added when a
+	// bill opens, nil disables them
+	SubscriptionRepo repository.SubscriptionRepository
+	PriceRepo        repository.PriceRepository
+
+	// Proration measures the share of the period subscriptions are charged for
+	Proration proration.Calculator
+
+	// UsageRepo and UsageCatalog price the metered usage aggregated at close,
+	// nil disables the usage stage
+	UsageRepo    repository.UsageRepository
+	UsageCatalog usage.Catalog
+
+	// TaxCalculator computes the taxes added at close, nil disables the tax stage
+	TaxCalculator TaxCalculator
+
+	// PromotionRepo and CreditRepo back the discounts and credits applied at
+	// close, nil disables the stage
+	PromotionRepo repository.PromotionRepository
+	CreditRepo    repository.CreditRepository
+
+	// CreditNoteRepo backs the credit notes applied as customer credit
+	CreditNoteRepo repository.CreditNoteRepository
+
+	// BlobStore keeps the rendered invoices, nil disables invoice generation
+	BlobStore invoice.BlobStore
+
+	// Notifier delivers customer notifications, nil disables them
+	Notifier         notify.Notifier
+	NotificationRepo repository.NotificationDeliveryRepository
+
+	PaymentRepo    repository.PaymentRepository
+	SettlementRepo repository.SettlementRepository
 }
 
 func (a *BillActivities) InsertLineItem(ctx context.Context, input InsertLineItemInput) (*InsertLineItemResult, error) {
-	err := a.LineItemRepo.InsertWithBillUpdate(ctx, &entity.LineItemEntity{
+	lineItem := &entity.LineItemEntity{
 		UUID:           input.UUID,
 		BillUUID:       input.BillUUID,
 		IdempotencyKey: input.IdempotencyKey,
@@ -22,17 +73,495 @@ func (a *BillActivities) InsertLineItem(ctx context.Context, input InsertLineIte
 		Description:    input.Description,
 		AmountCents:    input.AmountCents,
 		ReferenceUUID:  input.ReferenceUUID,
-	})
+	}
+	if input.FX != nil {
+		lineItem.OriginalAmountCents = &input.FX.OriginalAmountCents
+		lineItem.OriginalCurrency = &input.FX.OriginalCurrency
+		lineItem.FXRate = &input.FX.Rate
+	}
+
+	err := a.LineItemRepo.InsertWithBillUpdate(ctx, lineItem)
+	if errors.Is(err, entity.ErrReversalExceedsLineItem) {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeReversalExceedsLineItem, err)
+	}
 	if err != nil {
 		return nil, err
 	}
+
 	return &InsertLineItemResult{UUID: input.UUID}, nil
 }
 
+// PersistLineItem inserts a line item and reads back the stored row, so update
+// callers get the persisted entity. When the idempotency key was already used the
+// returned row is the existing one, whose UUID differs from input.UUID.
+func (a *BillActivities) PersistLineItem(ctx context.Context, input InsertLineItemInput) (*PersistLineItemResult, error) {
+	if _, err := a.InsertLineItem(ctx, input); err != nil {
+		return nil, err
+	}
+
+	lineItem, err := a.LineItemRepo.FetchByBillAndKey(ctx, input.BillUUID, input.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersistLineItemResult{LineItem: lineItem}, nil
+}
+
+// PriceSubscriptions prices the subscriptions the bill's customer has in the
+// bill period, oldest first. Fees that price to zero get no line.
+func (a *BillActivities) PriceSubscriptions(ctx context.Context, input PriceSubscriptionsInput) (*PriceSubscriptionsResult, error) {
+	if a.SubscriptionRepo == nil || a.PriceRepo == nil {
+		return &PriceSubscriptionsResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, unpriced, err := a.subscriptionLines(ctx, bill, bill.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PriceSubscriptionsResult{Unpriced: unpriced}
+	for _, line := range lines {
+		if line.AmountCents != 0 {
+			result.Lines = append(result.Lines, line)
+		}
+	}
+	return result, nil
+}
+
+// ProrateSubscriptions brings what the bill charged each subscription in line
+// with what it covers of the period up to Until: a subscription created, ended
+// or cut short by an early close gets the difference as a charge or a credit.
+// The target is derived from the subscriptions as stored, so running it again
+// without a change returns no lines.
+func (a *BillActivities) ProrateSubscriptions(ctx context.Context, input ProrateSubscriptionsInput) (*ProrateSubscriptionsResult, error) {
+	if a.SubscriptionRepo == nil || a.PriceRepo == nil {
+		return &ProrateSubscriptionsResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	until := bill.PeriodEnd
+	if !input.Until.IsZero() && input.Until.Before(until) {
+		until = input.Until
+	}
+
+	billed, err := a.LineItemRepo.SumByReference(ctx, bill.UUID, entity.FeeTypeMonthlyFee.String())
+	if err != nil {
+		return nil, err
+	}
+
+	lines, _, err := a.subscriptionLines(ctx, bill, until)
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[string]SubscriptionLine, len(lines))
+	for _, line := range lines {
+		targets[line.SubscriptionUUID] = line
+	}
+
+	scope := input.SubscriptionUUIDs
+	if len(scope) == 0 {
+		scope = slices.Sorted(maps.Keys(targets))
+		for subscriptionUUID := range billed {
+			if _, ok := targets[subscriptionUUID]; !ok {
+				scope = append(scope, subscriptionUUID)
+			}
+		}
+	}
+
+	result := &ProrateSubscriptionsResult{}
+	for _, subscriptionUUID := range scope {
+		target, ok := targets[subscriptionUUID]
+		if !ok {
+			if billed[subscriptionUUID] == 0 {
+				continue
+			}
+			// nothing of the subscription is left in the period
+			target, err = a.uncoveredSubscriptionLine(ctx, bill, subscriptionUUID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		amountCents := target.AmountCents - billed[subscriptionUUID]
+		if amountCents == 0 {
+			continue
+		}
+		result.Lines = append(result.Lines, ProrationLine{
+			Target:      target,
+			BilledCents: billed[subscriptionUUID],
+			AmountCents: amountCents,
+		})
+	}
+	return result, nil
+}
+
+// subscriptionLines prices the customer's subscriptions for the part of the
+// bill period before until, each at the product price in effect when its part
+// of the period starts. Products without a price in the bill currency are
+// returned by code instead.
+func (a *BillActivities) subscriptionLines(ctx context.Context, bill *entity.BillEntity, until time.Time) ([]SubscriptionLine, []string, error) {
+	subscriptions, err := a.SubscriptionRepo.FetchActive(ctx, bill.CustomerUUID, bill.PeriodStart, until)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	period := proration.Span{Start: bill.PeriodStart, End: bill.PeriodEnd}
+
+	var lines []SubscriptionLine
+	var unpriced []string
+	products := make(map[string]*entity.ProductEntity)
+	for _, subscription := range subscriptions {
+		from, to, ok := subscription.Coverage(bill.PeriodStart, until)
+		if !ok {
+			continue
+		}
+
+		product, ok := products[subscription.ProductUUID]
+		if !ok {
+			product, err = a.PriceRepo.FetchProductByUUID(ctx, subscription.ProductUUID)
+			if err != nil {
+				return nil, nil, err
+			}
+			products[subscription.ProductUUID] = product
+		}
+
+		price, err := a.PriceRepo.FetchEffective(ctx, product.UUID, bill.Currency, from)
+		if err != nil {
+			if errors.Is(err, sqldb.ErrNoRows) {
+				unpriced = append(unpriced, product.Code)
+				continue
+			}
+			return nil, nil, err
+		}
+
+		share := a.Proration.Prorate(price.AmountCents*subscription.Quantity, period, proration.Span{Start: from, End: to})
+		lines = append(lines, SubscriptionLine{
+			SubscriptionUUID: subscription.UUID,
+			ProductCode:      product.Code,
+			ProductName:      product.Name,
+			Quantity:         subscription.Quantity,
+			UnitAmountCents:  price.AmountCents,
+			AmountCents:      share.AmountCents,
+			Share:            share,
+		})
+	}
+	return lines, unpriced, nil
+}
+
+// uncoveredSubscriptionLine describes a subscription the bill charged that no
+// longer covers any of the period, its target is zero.
+func (a *BillActivities) uncoveredSubscriptionLine(ctx context.Context, bill *entity.BillEntity, subscriptionUUID string) (SubscriptionLine, error) {
+	subscription, err := a.SubscriptionRepo.FetchByUUID(ctx, subscriptionUUID)
+	if err != nil {
+		return SubscriptionLine{}, err
+	}
+	product, err := a.PriceRepo.FetchProductByUUID(ctx, subscription.ProductUUID)
+	if err != nil {
+		return SubscriptionLine{}, err
+	}
+
+	period := proration.Span{Start: bill.PeriodStart, End: bill.PeriodEnd}
+	return SubscriptionLine{
+		SubscriptionUUID: subscription.UUID,
+		ProductCode:      product.Code,
+		ProductName:      product.Name,
+		Quantity:         subscription.Quantity,
+		Share:            a.Proration.Prorate(0, period, proration.Span{}),
+	}, nil
+}
+
+// AggregateUsage prices the bill's recorded usage, one line per meter in meter
+// name order. Usage is only recorded on open bills, so the lines do not change
+// once the bill is closing. Meters that price to zero get no line.
+func (a *BillActivities) AggregateUsage(ctx context.Context, input AggregateUsageInput) (*AggregateUsageResult, error) {
+	if a.UsageRepo == nil || a.UsageCatalog == nil {
+		return &AggregateUsageResult{}, nil
+	}
+
+	quantities, err := a.UsageRepo.SumByMeter(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(quantities) == 0 {
+		return &AggregateUsageResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AggregateUsageResult{}
+	for _, meter := range slices.Sorted(maps.Keys(quantities)) {
+		price, ok := a.UsageCatalog.Price(meter, bill.Currency)
+		if !ok {
+			result.Unpriced = append(result.Unpriced, meter)
+			continue
+		}
+
+		quantity := quantities[meter]
+		amountCents := price.AmountCents(quantity)
+		if amountCents == 0 {
+			continue
+		}
+
+		result.Lines = append(result.Lines, UsageLine{
+			Meter:       meter,
+			Unit:        a.UsageCatalog[meter].Unit,
+			Quantity:    quantity,
+			AmountCents: amountCents,
+		})
+	}
+	return result, nil
+}
+
+// CalculateTax computes the tax lines of a bill from its persisted line item
+// subtotals, in the bill's currency. Reversals count against the fee type of
+// the line item they reverse.
+func (a *BillActivities) CalculateTax(ctx context.Context, input CalculateTaxInput) (*CalculateTaxResult, error) {
+	if a.TaxCalculator == nil {
+		return &CalculateTaxResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := a.LineItemRepo.SumByFeeType(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotals := make(map[entity.FeeType]int64, len(sums))
+	for feeType, amountCents := range sums {
+		subtotals[entity.FeeType(feeType)] = amountCents
+	}
+
+	if subtotals[entity.FeeTypeReversal] != 0 {
+		if err := a.netReversals(ctx, input.BillUUID, subtotals); err != nil {
+			return nil, err
+		}
+	}
+
+	lines, err := a.TaxCalculator.Calculate(ctx, bill.Currency, subtotals)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CalculateTaxResult{Lines: lines}, nil
+}
+
+// netReversals moves each reversal into the subtotal of the fee type it takes
+// back, so a reversed charge is not taxed. A reversal whose line item is not on
+// the bill stays under REVERSAL, which is never taxed.
+func (a *BillActivities) netReversals(ctx context.Context, billUUID string, subtotals map[entity.FeeType]int64) error {
+	lineItems, err := a.fetchAllLineItems(ctx, billUUID)
+	if err != nil {
+		return err
+	}
+
+	feeTypes := make(map[string]entity.FeeType, len(lineItems))
+	for _, li := range lineItems {
+		feeTypes[li.UUID] = entity.FeeType(li.FeeType)
+	}
+
+	for _, li := range lineItems {
+		if li.FeeType != entity.FeeTypeReversal.String() || li.ReferenceUUID == nil {
+			continue
+		}
+		feeType, ok := feeTypes[*li.ReferenceUUID]
+		if !ok {
+			continue
+		}
+		subtotals[feeType] += li.AmountCents
+		subtotals[entity.FeeTypeReversal] -= li.AmountCents
+	}
+	return nil
+}
+
+// CalculateDiscounts computes the discounts of the promo codes redeemed on the
+// bill. They apply to the bill total without earlier discounts and credits, so
+// a retried calculation returns the same lines.
+func (a *BillActivities) CalculateDiscounts(ctx context.Context, input CalculateDiscountsInput) (*CalculateDiscountsResult, error) {
+	if a.PromotionRepo == nil {
+		return &CalculateDiscountsResult{}, nil
+	}
+
+	promotions, err := a.PromotionRepo.FetchByBillUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(promotions) == 0 {
+		return &CalculateDiscountsResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCents, err := a.sumExcluding(ctx, input.BillUUID, entity.FeeTypeDiscount, entity.FeeTypeCredit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CalculateDiscountsResult{Lines: calculateDiscounts(totalCents, bill.Currency, promotions)}, nil
+}
+
+// ApplyCredits consumes the customer's credit in the bill currency towards the
+// bill total net of discounts. The consumption is stored with the credits, so
+// a retried activity returns the lines of the first attempt.
+func (a *BillActivities) ApplyCredits(ctx context.Context, input ApplyCreditsInput) (*ApplyCreditsResult, error) {
+	if a.CreditRepo == nil {
+		return &ApplyCreditsResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	dueCents, err := a.sumExcluding(ctx, input.BillUUID, entity.FeeTypeCredit)
+	if err != nil {
+		return nil, err
+	}
+
+	applications, err := a.CreditRepo.Apply(ctx, input.BillUUID, input.CustomerUUID, bill.Currency, dueCents)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]CreditLine, 0, len(applications))
+	for _, application := range applications {
+		lines = append(lines, CreditLine{
+			CreditUUID:  application.CreditUUID,
+			AmountCents: application.AmountCents,
+		})
+	}
+	return &ApplyCreditsResult{Lines: lines}, nil
+}
+
+// ApplyCreditNote grants an issued credit note's amount to the customer as
+// credit, which their next bill in the currency consumes at close. The grant is
+// keyed by the note, so a retried activity grants it once.
+func (a *BillActivities) ApplyCreditNote(ctx context.Context, input ApplyCreditNoteInput) (*ApplyCreditNoteResult, error) {
+	note, err := a.CreditNoteRepo.FetchByUUID(ctx, input.CreditNoteUUID)
+	if err != nil {
+		return nil, err
+	}
+	if note.CreditUUID != nil {
+		return &ApplyCreditNoteResult{
+			CreditUUID:  *note.CreditUUID,
+			Currency:    note.Currency,
+			AmountCents: note.AmountCents,
+		}, nil
+	}
+
+	description := "Credit note " + note.Number
+	credit := &entity.CreditEntity{
+		UUID:           uuid.New().String(),
+		CustomerUUID:   note.CustomerUUID,
+		IdempotencyKey: CreditNoteCreditKey(note.UUID),
+		Currency:       note.Currency,
+		AmountCents:    note.AmountCents,
+		Description:    &description,
+	}
+	if err := a.CreditRepo.Insert(ctx, credit); err != nil {
+		return nil, err
+	}
+
+	if err := a.CreditNoteRepo.MarkApplied(ctx, note.UUID, credit.UUID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return &ApplyCreditNoteResult{
+		CreditUUID:  credit.UUID,
+		Currency:    credit.Currency,
+		AmountCents: credit.AmountCents,
+	}, nil
+}
+
+// sumExcluding totals the bill's persisted line items, leaving out the given fee types.
+func (a *BillActivities) sumExcluding(ctx context.Context, billUUID string, excluded ...entity.FeeType) (int64, error) {
+	sums, err := a.LineItemRepo.SumByFeeType(ctx, billUUID)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalCents int64
+	for feeType, amountCents := range sums {
+		if slices.Contains(excluded, entity.FeeType(feeType)) {
+			continue
+		}
+		totalCents += amountCents
+	}
+	return totalCents, nil
+}
+
+// UpdateBillStatus persists a lifecycle transition. A transition the bill can no
+// longer make is not retried, retrying would not change the stored status.
+func (a *BillActivities) UpdateBillStatus(ctx context.Context, input UpdateBillStatusInput) error {
+	err := a.BillRepo.UpdateStatus(ctx, input.BillUUID, input.From, input.To)
+	if errors.Is(err, entity.ErrInvalidBillTransition) {
+		return temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidTransition, err)
+	}
+	return err
+}
+
+// VoidBill stores the bill as VOIDED with a zero total. Voiding is idempotent;
+// a bill that left OPEN some other way is not retried.
+func (a *BillActivities) VoidBill(ctx context.Context, input VoidBillInput) (*VoidBillResult, error) {
+	now := time.Now().UTC()
+
+	err := a.BillRepo.Void(ctx, input.BillUUID, input.Reason, now)
+	if errors.Is(err, entity.ErrInvalidBillTransition) {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidTransition, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &VoidBillResult{VoidedAt: now}, nil
+}
+
+// CloseBill locks the bill's total and sets its due date. The payment terms of
+// the customer's billing profile take precedence over the workflow's.
 func (a *BillActivities) CloseBill(ctx context.Context, input CloseBillInput) (*CloseBillResult, error) {
 	now := time.Now().UTC()
 
-	if err := a.BillRepo.Close(ctx, input.BillUUID, now); err != nil {
+	paymentTermsDays := input.PaymentTermsDays
+	if a.BillingProfileRepo != nil {
+		// the bill's current customer, it moves when its customer is merged
+		bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+		if err != nil {
+			return nil, err
+		}
+		profile, err := a.fetchBillingProfile(ctx, bill.CustomerUUID)
+		if err != nil {
+			return nil, err
+		}
+		if profile != nil && profile.PaymentTerms.Days() > 0 {
+			paymentTermsDays = profile.PaymentTerms.Days()
+		}
+	}
+
+	var dueDate *time.Time
+	if paymentTermsDays > 0 {
+		due := now.AddDate(0, 0, paymentTermsDays)
+		dueDate = &due
+	}
+
+	if err := a.BillRepo.Close(ctx, input.BillUUID, now, dueDate); err != nil {
 		return nil, err
 	}
 
@@ -42,7 +571,275 @@ func (a *BillActivities) CloseBill(ctx context.Context, input CloseBillInput) (*
 	}
 
 	return &CloseBillResult{
-		TotalCents: totalCents,
-		ClosedAt:   closedAt,
+		TotalCents:       totalCents,
+		ClosedAt:         closedAt,
+		PaymentTermsDays: paymentTermsDays,
 	}, nil
 }
+
+// GenerateInvoice issues the invoice of a closed bill and stores its renderings.
+// The invoice number is allocated once per bill, so a retry re-renders the same
+// invoice and overwrites the artifacts it may have partially stored.
+func (a *BillActivities) GenerateInvoice(ctx context.Context, input GenerateInvoiceInput) (*GenerateInvoiceResult, error) {
+	if a.BlobStore == nil {
+		return &GenerateInvoiceResult{}, nil
+	}
+
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := a.CustomerRepo.FetchByUUID(ctx, bill.CustomerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := a.fetchBillingProfile(ctx, bill.CustomerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	lineItems, err := a.fetchAllLineItems(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &entity.InvoiceEntity{
+		BillUUID:     bill.UUID,
+		CustomerUUID: bill.CustomerUUID,
+	}
+	if err := a.InvoiceRepo.Insert(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	doc := invoice.NewDocument(inv, bill, customer, profile, lineItems)
+	for _, format := range invoice.Formats {
+		data, err := invoice.Render(doc, format)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.BlobStore.Put(ctx, invoice.ArtifactKey(bill.UUID, format), data, format.ContentType()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &GenerateInvoiceResult{InvoiceNumber: inv.Number}, nil
+}
+
+// fetchAllLineItems pages through every line item of a bill in creation order.
+func (a *BillActivities) fetchAllLineItems(ctx context.Context, billUUID string) ([]*entity.LineItemEntity, error) {
+	var (
+		all        []*entity.LineItemEntity
+		cursorTime time.Time
+		cursorID   int64
+	)
+
+	for {
+		page, err := a.LineItemRepo.FetchByBillUUID(ctx, billUUID, cursorTime, cursorID, invoiceLineItemPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+
+		if len(page) < invoiceLineItemPageSize {
+			return all, nil
+		}
+		last := page[len(page)-1]
+		cursorTime, cursorID = last.CreatedAt, last.ID
+	}
+}
+
+// NotifyCustomer sends a bill notification to the bill's customer. Deliveries are
+// claimed by idempotency key first, so a retry after a successful send is a no-op.
+// A send that succeeds but is not marked before the activity fails may repeat;
+// receivers get the idempotency key to drop that duplicate.
+func (a *BillActivities) NotifyCustomer(ctx context.Context, input NotifyCustomerInput) error {
+	if a.Notifier == nil {
+		return nil
+	}
+
+	customer, err := a.CustomerRepo.FetchByUUID(ctx, input.CustomerUUID)
+	if err != nil {
+		return err
+	}
+
+	// portal customers collect their invoices themselves
+	if input.Event == notify.EventBillClosed {
+		profile, err := a.fetchBillingProfile(ctx, customer.UUID)
+		if err != nil {
+			return err
+		}
+		if profile != nil && profile.InvoiceDelivery == entity.InvoiceDeliveryPortal {
+			return nil
+		}
+	}
+
+	notification := notify.NewNotification(input.Event, input.IdempotencyKey, customer, notify.MessageData{
+		BillUUID:      input.BillUUID,
+		Currency:      input.Currency,
+		AmountCents:   input.AmountCents,
+		Description:   input.Description,
+		InvoiceNumber: input.InvoiceNumber,
+		DueDate:       input.DueDate,
+	})
+
+	delivery := &entity.NotificationDeliveryEntity{
+		IdempotencyKey: input.IdempotencyKey,
+		BillUUID:       input.BillUUID,
+		CustomerUUID:   customer.UUID,
+		Event:          input.Event.String(),
+		Channel:        a.Notifier.Channel(),
+		Recipient:      notification.Recipient,
+	}
+	if err := a.NotificationRepo.Claim(ctx, delivery); err != nil {
+		return err
+	}
+	if delivery.IsSent() {
+		return nil
+	}
+
+	if err := a.Notifier.Send(ctx, notification); err != nil {
+		return err
+	}
+
+	return a.NotificationRepo.MarkSent(ctx, input.IdempotencyKey, time.Now().UTC())
+}
+
+// fetchBillingProfile loads a customer's billing profile, nil when the
+// customer has none or profiles are disabled.
+func (a *BillActivities) fetchBillingProfile(ctx context.Context, customerUUID string) (*entity.BillingProfileEntity, error) {
+	if a.BillingProfileRepo == nil {
+		return nil, nil
+	}
+	profile, err := a.BillingProfileRepo.FetchByCustomerUUID(ctx, customerUUID)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, nil
+	}
+	return profile, err
+}
+
+// FetchBillBalance loads what the dunning workflow decides on: the bill status,
+// its total, the payments received so far and the due date.
+func (a *BillActivities) FetchBillBalance(ctx context.Context, input FetchBillBalanceInput) (*FetchBillBalanceResult, error) {
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FetchBillBalanceResult{
+		Status:    entity.BillStatus(bill.Status),
+		PaidCents: bill.PaidCents,
+		DueDate:   bill.DueDate,
+	}
+	if bill.TotalCents != nil {
+		result.TotalCents = *bill.TotalCents
+	}
+	return result, nil
+}
+
+// OpenNextBill inserts the bill for the next period of a recurring series.
+// The bill UUID is derived deterministically, so a retried activity finds the
+// row it already inserted and returns it instead of failing. A series whose
+// customer was merged meanwhile continues on the customer it was merged into,
+// see db.InsertBill.
+func (a *BillActivities) OpenNextBill(ctx context.Context, input OpenNextBillInput) (*OpenNextBillResult, error) {
+	existing, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err == nil {
+		return &OpenNextBillResult{BillUUID: existing.UUID, CustomerUUID: existing.CustomerUUID}, nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		return nil, err
+	}
+
+	bill := &entity.BillEntity{
+		UUID:         input.BillUUID,
+		CustomerUUID: input.CustomerUUID,
+		Currency:     input.Currency,
+		PeriodStart:  input.PeriodStart,
+		PeriodEnd:    input.PeriodEnd,
+		Recurrence:   input.Recurrence,
+	}
+	if err := a.BillRepo.Insert(ctx, bill); err != nil {
+		return nil, err
+	}
+
+	return &OpenNextBillResult{BillUUID: bill.UUID, CustomerUUID: bill.CustomerUUID}, nil
+}
+
+// StartSettlement records the pending settlement of a bill. Its UUID is the
+// reference of the bank transfer, a retry finds the stored row and reuses it.
+func (a *BillActivities) StartSettlement(ctx context.Context, input StartSettlementInput) (*StartSettlementResult, error) {
+	settlement := &entity.SettlementEntity{
+		UUID:          uuid.NewString(),
+		BillUUID:      input.BillUUID,
+		SourceAccount: input.SourceAccount,
+		TargetAccount: input.TargetAccount,
+		AmountCents:   input.AmountCents,
+	}
+	if err := a.SettlementRepo.Insert(ctx, settlement); err != nil {
+		return nil, err
+	}
+
+	return &StartSettlementResult{SettlementUUID: settlement.UUID}, nil
+}
+
+// CompleteSettlement records the transferred amount as a payment on the bill
+// and marks the settlement settled. The payment is keyed on the settlement, so
+// a retry does not record it twice.
+func (a *BillActivities) CompleteSettlement(ctx context.Context, input CompleteSettlementInput) (*CompleteSettlementResult, error) {
+	payment := &entity.PaymentEntity{
+		UUID:           uuid.NewString(),
+		BillUUID:       input.BillUUID,
+		IdempotencyKey: "settlement:" + input.SettlementUUID,
+		AmountCents:    input.AmountCents,
+		Reference:      &input.Confirmation,
+		ReceivedAt:     time.Now().UTC(),
+	}
+	if err := a.PaymentRepo.Insert(ctx, payment); err != nil {
+		if errors.Is(err, entity.ErrBillNotPayable) || errors.Is(err, entity.ErrPaymentExceedsBalance) {
+			return nil, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeSettlementNotRecorded, err)
+		}
+		return nil, err
+	}
+
+	if err := a.SettlementRepo.MarkSettled(ctx, input.BillUUID, payment.UUID); err != nil {
+		return nil, err
+	}
+
+	return &CompleteSettlementResult{PaymentUUID: payment.UUID}, nil
+}
+
+// CreditSettlement grants a transferred amount the bill no longer accepted to
+// the customer as credit, keyed by the settlement so a retry grants it once.
+// Their next bill in the currency consumes it at close.
+func (a *BillActivities) CreditSettlement(ctx context.Context, input CreditSettlementInput) (*CreditSettlementResult, error) {
+	bill, err := a.BillRepo.FetchByUUID(ctx, input.BillUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	description := "Settlement of bill " + input.BillUUID + " not applied"
+	credit := &entity.CreditEntity{
+		UUID:           uuid.New().String(),
+		CustomerUUID:   bill.CustomerUUID,
+		IdempotencyKey: SettlementCreditKey(input.SettlementUUID),
+		Currency:       bill.Currency,
+		AmountCents:    input.AmountCents,
+		Description:    &description,
+	}
+	if err := a.CreditRepo.Insert(ctx, credit); err != nil {
+		return nil, err
+	}
+
+	if err := a.SettlementRepo.MarkCredited(ctx, input.BillUUID, credit.UUID, input.Reason); err != nil {
+		return nil, err
+	}
+
+	return &CreditSettlementResult{CreditUUID: credit.UUID}, nil
+}
+
+// FailSettlement marks the settlement of a bill failed with the reason.
+func (a *BillActivities) FailSettlement(ctx context.Context, input FailSettlementInput) error {
+	return a.SettlementRepo.MarkFailed(ctx, input.BillUUID, input.Reason)
+}
@@ -0,0 +1,23 @@
+package bill
+
+import "go.temporal.io/sdk/workflow"
+
+// startBackground runs an activity without waiting for it. The run collects it
+// in awaitBackground before it completes, so it is not cancelled mid-flight.
+func (w *billWorkflow) startBackground(ctx workflow.Context, activity any, input any) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	w.background = append(w.background, workflow.ExecuteActivity(activityCtx, activity, input))
+}
+
+// awaitBackground waits for the background activities started by this run. A
+// failure is logged and dropped, it does not fail the bill.
+func (w *billWorkflow) awaitBackground(ctx workflow.Context) {
+	for _, future := range w.background {
+		if err := future.Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Warn("background activity failed",
+				"bill_uuid", w.input.BillUUID,
+				"err", err)
+		}
+	}
+	w.background = nil
+}
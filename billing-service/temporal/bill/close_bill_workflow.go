@@ -1,30 +1,65 @@
 package bill
 
-import "go.temporal.io/sdk/workflow"
+import (
+	"encore.app/entity"
+	"go.temporal.io/sdk/workflow"
+)
 
 func (w *billWorkflow) closeBill(ctx workflow.Context) (*BillWorkflowResult, error) {
 	// manually cancel timer if the bill is closed manually
 	w.timerCancel()
 
-	// Process any remaining buffered signals
-	w.drainPendingSignals(ctx)
-
-	w.state.Status = "CLOSED"
-
 	// creates a separate context that's not linked to parent to ensure activity ran without interruption
 	// when parents context get's cancelled, AI proposed this, good to know
 	disconnectedCtx, _ := workflow.NewDisconnectedContext(ctx)
 	activityCtx := workflow.WithActivityOptions(disconnectedCtx, defaultActivityOptions())
 
+	// persist CLOSING as soon as the close is accepted so a bill stuck mid-close is visible
+	if err := w.transitionStatus(disconnectedCtx, entity.BillStatusClosing); err != nil {
+		return nil, err
+	}
+
+	// Process any remaining buffered signals
+	w.drainPendingSignals(ctx)
+
+	// refuse to finalize while line items are dead-lettered, operators replay
+	// them through the retry update which stays available while closing
+	if err := w.awaitFailedLineItems(ctx); err != nil {
+		return nil, err
+	}
+	if err := w.awaitHandlers(ctx); err != nil {
+		return nil, err
+	}
+
+	// subscription prorations, usage, tax, then discounts, then credits go in as
+	// line items so the closed total already includes them; each stage reads the
+	// totals the previous one left
+	stages := []func(workflow.Context) error{w.applyProration, w.applyUsage, w.applyTax, w.applyDiscounts, w.applyCredits}
+	for _, apply := range stages {
+		if err := apply(disconnectedCtx); err != nil {
+			return nil, err
+		}
+		if err := w.awaitFailedLineItems(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.checkTransition(entity.BillStatusClosed); err != nil {
+		return nil, err
+	}
+
 	var closeResult CloseBillResult
 	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).CloseBill, CloseBillInput{
-		BillUUID: w.input.BillUUID,
+		BillUUID:         w.input.BillUUID,
+		PaymentTermsDays: w.input.PaymentTermsDays,
 	}).Get(disconnectedCtx, &closeResult)
 
 	if err != nil {
 		return nil, err
 	}
 
+	w.state.Status = entity.BillStatusClosed
+
 	return &BillWorkflowResult{
 		BillUUID:   w.input.BillUUID,
 		TotalCents: closeResult.TotalCents,
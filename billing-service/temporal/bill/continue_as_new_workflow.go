@@ -0,0 +1,86 @@
+package bill
+
+import "go.temporal.io/sdk/workflow"
+
+const (
+	// maxHistoryLength is well below Temporal's 50k event hard limit so the
+	// carried state and buffered signals still fit in the final events of a run.
+	maxHistoryLength = 10000
+
+	// defaultMaxLineItemsPerRun caps the line items handled by a single run.
+	// Every line item costs a signal plus the activity events, so this keeps
+	// a run comfortably under maxHistoryLength even on a busy bill.
+	defaultMaxLineItemsPerRun = 1000
+)
+
+// shouldContinueAsNew reports whether the current run has grown large enough
+// that the workflow should hand over to a fresh run.
+func (w *billWorkflow) shouldContinueAsNew(ctx workflow.Context) bool {
+	info := workflow.GetInfo(ctx)
+	if info.GetContinueAsNewSuggested() || info.GetCurrentHistoryLength() >= maxHistoryLength {
+		return true
+	}
+
+	limit := w.input.MaxLineItemsPerRun
+	if limit <= 0 {
+		limit = defaultMaxLineItemsPerRun
+	}
+	return w.processedInRun >= limit
+}
+
+// processCarriedSignals replays the line items and subscription changes that
+// were buffered when the previous run continued as new.
+func (w *billWorkflow) processCarriedSignals(ctx workflow.Context) {
+	pending := w.pendingSignals
+	w.pendingSignals = nil
+
+	for _, signal := range pending {
+		w.processLineItem(ctx, signal)
+	}
+
+	changes := w.pendingSubscriptionChanges
+	w.pendingSubscriptionChanges = nil
+
+	for _, signal := range changes {
+		w.onSubscriptionChanged(ctx, signal)
+	}
+}
+
+// continueAsNewError stops the timer, moves any buffered line item and
+// subscription signals into the carried state and returns the error that
+// starts the next run. Close and void signals are never carried, the run acts
+// on them before deciding to continue as new.
+func (w *billWorkflow) continueAsNewError(ctx workflow.Context) error {
+	w.timerCancel()
+
+	for {
+		var signal AddLineItemSignal
+		if !w.addItemChan.ReceiveAsync(&signal) {
+			break
+		}
+		w.pendingSignals = append(w.pendingSignals, signal)
+	}
+	for {
+		var signal SubscriptionChangedSignal
+		if !w.subscriptionChan.ReceiveAsync(&signal) {
+			break
+		}
+		w.pendingSubscriptionChanges = append(w.pendingSubscriptionChanges, signal)
+	}
+
+	workflow.GetLogger(ctx).Info("continuing bill workflow as new",
+		"bill_uuid", w.input.BillUUID,
+		"history_length", workflow.GetInfo(ctx).GetCurrentHistoryLength(),
+		"pending_signals", len(w.pendingSignals),
+		"pending_subscription_changes", len(w.pendingSubscriptionChanges))
+
+	next := w.input
+	next.Carry = &BillWorkflowCarry{
+		State:          w.state,
+		PendingSignals: w.pendingSignals,
+
+		PendingSubscriptionChanges: w.pendingSubscriptionChanges,
+	}
+
+	return workflow.NewContinueAsNewError(ctx, BillWorkflow, next)
+}
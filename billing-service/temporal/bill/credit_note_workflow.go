@@ -0,0 +1,42 @@
+package bill
+
+import "go.temporal.io/sdk/workflow"
+
+// CreditNoteWorkflowID builds the ID of the workflow applying a credit note.
+func CreditNoteWorkflowID(creditNoteUUID string) string {
+	return CreditNoteWorkflowIDPrefix + creditNoteUUID
+}
+
+// CreditNoteCreditKey is the idempotency key of the customer credit a credit
+// note is applied as.
+func CreditNoteCreditKey(creditNoteUUID string) string {
+	return "credit-note:" + creditNoteUUID
+}
+
+// CreditNoteWorkflow applies a credit note issued against a closed bill. The
+// bill stays as it was closed; the note's amount is granted to the customer as
+// credit, and the credit stage of their next bill in the currency takes it off
+// that bill at close. Credit no open bill consumes stays on the customer's
+// balance for later bills.
+func CreditNoteWorkflow(ctx workflow.Context, input CreditNoteWorkflowInput) (*CreditNoteWorkflowResult, error) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var applied ApplyCreditNoteResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).ApplyCreditNote, ApplyCreditNoteInput{
+		CreditNoteUUID: input.CreditNoteUUID,
+	}).Get(ctx, &applied)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow.GetLogger(ctx).Info("credit note applied",
+		"credit_note_uuid", input.CreditNoteUUID,
+		"credit_uuid", applied.CreditUUID,
+		"amount_cents", applied.AmountCents)
+
+	return &CreditNoteWorkflowResult{
+		CreditUUID:  applied.CreditUUID,
+		Currency:    applied.Currency,
+		AmountCents: applied.AmountCents,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package bill
+
+import (
+	"encore.app/entity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// deadLetter records a line item whose insert failed so operators can replay it.
+func (w *billWorkflow) deadLetter(ctx workflow.Context, signal AddLineItemSignal, err error) {
+	w.state.FailedLineItems = append(w.state.FailedLineItems, FailedLineItem{
+		Signal:   signal,
+		Error:    err.Error(),
+		FailedAt: workflow.Now(ctx),
+		Attempts: 1,
+	})
+}
+
+func (w *billWorkflow) findFailedLineItem(uuid string) int {
+	for i, failed := range w.state.FailedLineItems {
+		if failed.Signal.UUID == uuid {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateRetryLineItemUpdate rejects a retry once the bill is voided or in a
+// terminal status, a replayed line item would otherwise be stored on a bill
+// that no longer takes any.
+func (w *billWorkflow) validateRetryLineItemUpdate(update RetryLineItemUpdate) error {
+	if w.voided || w.state.Status.IsTerminal() {
+		return temporal.NewApplicationError("bill no longer accepts line items", ErrTypeBillClosed)
+	}
+	if w.findFailedLineItem(update.UUID) < 0 {
+		return temporal.NewApplicationError("line item is not in the failed list", ErrTypeLineItemNotFailed)
+	}
+	return nil
+}
+
+// handleRetryLineItemUpdate replays a dead-lettered line item. On success the
+// item leaves the failed list and is counted; on failure it stays parked with
+// the latest error. Retries are accepted while the bill is closing, since the
+// close waits for the failed list to be empty.
+func (w *billWorkflow) handleRetryLineItemUpdate(ctx workflow.Context, update RetryLineItemUpdate) (*entity.LineItemEntity, error) {
+	idx := w.findFailedLineItem(update.UUID)
+	if idx < 0 {
+		return nil, temporal.NewApplicationError("line item is not in the failed list", ErrTypeLineItemNotFailed)
+	}
+	signal := w.state.FailedLineItems[idx].Signal
+
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result PersistLineItemResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).PersistLineItem, InsertLineItemInput{
+		UUID:           signal.UUID,
+		BillUUID:       w.input.BillUUID,
+		IdempotencyKey: signal.IdempotencyKey,
+		FeeType:        signal.FeeType,
+		Description:    signal.Description,
+		AmountCents:    signal.AmountCents,
+		ReferenceUUID:  signal.ReferenceUUID,
+		FX:             signal.FX,
+	}).Get(ctx, &result)
+
+	// the list may have changed while the activity ran, look the item up again
+	idx = w.findFailedLineItem(update.UUID)
+	if idx < 0 {
+		return nil, temporal.NewApplicationError("line item is not in the failed list", ErrTypeLineItemNotFailed)
+	}
+
+	if err != nil {
+		w.state.FailedLineItems[idx].Error = err.Error()
+		w.state.FailedLineItems[idx].FailedAt = workflow.Now(ctx)
+		w.state.FailedLineItems[idx].Attempts++
+		return nil, err
+	}
+
+	w.state.FailedLineItems = append(w.state.FailedLineItems[:idx], w.state.FailedLineItems[idx+1:]...)
+
+	// the idempotency key belongs to another line item, nothing was inserted
+	if result.LineItem.UUID != signal.UUID {
+		return nil, temporal.NewApplicationError("idempotency key already used", ErrTypeDuplicateKey, result.LineItem)
+	}
+
+	w.recordLineItem(ctx, signal)
+
+	return result.LineItem, nil
+}
+
+func (w *billWorkflow) validateDiscardLineItemUpdate(update DiscardLineItemUpdate) error {
+	if w.findFailedLineItem(update.UUID) < 0 {
+		return temporal.NewApplicationError("line item is not in the failed list", ErrTypeLineItemNotFailed)
+	}
+	return nil
+}
+
+// handleDiscardLineItemUpdate drops a dead-lettered line item that will never
+// persist, such as a reversal exceeding what is left of its line item. The
+// bill is finalized without it, which lets a close stuck on it complete.
+func (w *billWorkflow) handleDiscardLineItemUpdate(ctx workflow.Context, update DiscardLineItemUpdate) (*FailedLineItem, error) {
+	idx := w.findFailedLineItem(update.UUID)
+	if idx < 0 {
+		return nil, temporal.NewApplicationError("line item is not in the failed list", ErrTypeLineItemNotFailed)
+	}
+	discarded := w.state.FailedLineItems[idx]
+	w.state.FailedLineItems = append(w.state.FailedLineItems[:idx], w.state.FailedLineItems[idx+1:]...)
+
+	workflow.GetLogger(ctx).Warn("failed line item discarded",
+		"bill_uuid", w.input.BillUUID,
+		"uuid", update.UUID,
+		"amount_cents", discarded.Signal.AmountCents,
+		"reason", update.Reason)
+
+	return &discarded, nil
+}
+
+// awaitFailedLineItems blocks the close until every dead-lettered line item has
+// been replayed or discarded, so a bill is never finalized with a total that
+// silently misses items.
+func (w *billWorkflow) awaitFailedLineItems(ctx workflow.Context) error {
+	if len(w.state.FailedLineItems) == 0 {
+		return nil
+	}
+
+	workflow.GetLogger(ctx).Warn("bill close waiting for failed line items",
+		"bill_uuid", w.input.BillUUID,
+		"failed_count", len(w.state.FailedLineItems))
+
+	return workflow.Await(ctx, func() bool {
+		return len(w.state.FailedLineItems) == 0
+	})
+}
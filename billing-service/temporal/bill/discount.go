@@ -0,0 +1,55 @@
+package bill
+
+import "encore.app/entity"
+
+// DiscountLine is the discount one redeemed promotion takes off the bill.
+type DiscountLine struct {
+	PromotionUUID string
+	Code          string
+	Kind          entity.PromotionKind
+	PercentBps    int64
+	AmountCents   int64 // positive, the line item itself is negative
+}
+
+// calculateDiscounts applies the promotions to totalCents in redemption order,
+// each one to what the previous ones left. Percentages are rounded half up to
+// the cent, and no discount takes the total below zero. Fixed discounts in
+// another currency than the bill's are skipped.
+func calculateDiscounts(totalCents int64, currency string, promotions []*entity.PromotionEntity) []DiscountLine {
+	var lines []DiscountLine
+	for _, promotion := range promotions {
+		if totalCents <= 0 {
+			break
+		}
+
+		line := DiscountLine{
+			PromotionUUID: promotion.UUID,
+			Code:          promotion.Code,
+			Kind:          promotion.Kind,
+		}
+		switch promotion.Kind {
+		case entity.PromotionKindPercent:
+			if promotion.PercentBps == nil {
+				continue
+			}
+			line.PercentBps = *promotion.PercentBps
+			line.AmountCents = (totalCents*line.PercentBps + 5000) / 10000
+		case entity.PromotionKindFixed:
+			if promotion.AmountCents == nil || promotion.Currency == nil || *promotion.Currency != currency {
+				continue
+			}
+			line.AmountCents = *promotion.AmountCents
+		default:
+			continue
+		}
+
+		line.AmountCents = min(line.AmountCents, totalCents)
+		if line.AmountCents <= 0 {
+			continue
+		}
+
+		lines = append(lines, line)
+		totalCents -= line.AmountCents
+	}
+	return lines
+}
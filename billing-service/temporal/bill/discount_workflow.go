@@ -0,0 +1,96 @@
+package bill
+
+import (
+	"fmt"
+
+	"encore.app/entity"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+var (
+	// discountLineItemNamespace seeds the deterministic UUIDs of discount line items.
+	discountLineItemNamespace = uuid.MustParse("4f0b7c2e-8d15-4a6b-9e3f-b1c7a2d95e04")
+	// creditLineItemNamespace seeds the deterministic UUIDs of credit line items.
+	creditLineItemNamespace = uuid.MustParse("c83a1e5d-27f4-4b09-a6d2-5e9f0b4c7a18")
+)
+
+// DiscountLineItemUUID derives the UUID of the discount line item of a promotion.
+func DiscountLineItemUUID(billUUID, promotionUUID string) string {
+	return uuid.NewSHA1(discountLineItemNamespace, []byte(billUUID+"/"+promotionUUID)).String()
+}
+
+// CreditLineItemUUID derives the UUID of the line item consuming a customer credit.
+func CreditLineItemUUID(billUUID, creditUUID string) string {
+	return uuid.NewSHA1(creditLineItemNamespace, []byte(billUUID+"/"+creditUUID)).String()
+}
+
+// applyDiscounts adds the discounts of the promo codes redeemed on the bill as
+// DISCOUNT line items, after tax so they come off the taxed total. Like tax,
+// they are computed from the persisted line items and go through processLineItem.
+//
+// Promo codes are priced as a reduction of what the customer pays, tax
+// included: the tax lines are not recomputed, so tax stays on the undiscounted
+// subtotals and a percent discount takes the same share of the tax.
+func (w *billWorkflow) applyDiscounts(ctx workflow.Context) error {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result CalculateDiscountsResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).CalculateDiscounts, CalculateDiscountsInput{
+		BillUUID: w.input.BillUUID,
+	}).Get(ctx, &result)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range result.Lines {
+		promotionUUID := line.PromotionUUID
+		w.processLineItem(ctx, AddLineItemSignal{
+			UUID:           DiscountLineItemUUID(w.input.BillUUID, promotionUUID),
+			IdempotencyKey: "system:discount:" + promotionUUID,
+			FeeType:        entity.FeeTypeDiscount.String(),
+			Description:    discountDescription(line),
+			AmountCents:    -line.AmountCents,
+			ReferenceUUID:  &promotionUUID,
+		})
+	}
+
+	return nil
+}
+
+// applyCredits consumes the customer's credit towards what is left to pay once
+// discounts are in, as CREDIT line items. The credit is reserved for the bill
+// by the activity, so a retried close consumes it once.
+func (w *billWorkflow) applyCredits(ctx workflow.Context) error {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result ApplyCreditsResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).ApplyCredits, ApplyCreditsInput{
+		BillUUID:     w.input.BillUUID,
+		CustomerUUID: w.input.CustomerUUID,
+	}).Get(ctx, &result)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range result.Lines {
+		creditUUID := line.CreditUUID
+		w.processLineItem(ctx, AddLineItemSignal{
+			UUID:           CreditLineItemUUID(w.input.BillUUID, creditUUID),
+			IdempotencyKey: "system:credit:" + creditUUID,
+			FeeType:        entity.FeeTypeCredit.String(),
+			Description:    "Customer credit applied",
+			AmountCents:    -line.AmountCents,
+			ReferenceUUID:  &creditUUID,
+		})
+	}
+
+	return nil
+}
+
+func discountDescription(line DiscountLine) string {
+	if line.Kind == entity.PromotionKindPercent {
+		return fmt.Sprintf("Promo code %s at %d bps", line.Code, line.PercentBps)
+	}
+	return "Promo code " + line.Code
+}
@@ -1,16 +1,109 @@
 package bill
 
-import "time"
+import (
+	"time"
+
+	"encore.app/entity"
+	"encore.app/notify"
+)
 
 const (
 	SignalAddLineItem = "add_line_item"
 	SignalCloseBill   = "close_bill"
-	QueryGetBillState = "get_bill_state"
+	SignalVoidBill    = "void_bill"
+
+	SignalSubscriptionChanged = "subscription_changed"
+
+	SignalPaymentReceived = "payment_received"
+	QueryGetBillState     = "get_bill_state"
+	UpdateAddLineItem     = "add_line_item_update"
+
+	QueryGetFailedLineItems = "get_failed_line_items"
+	UpdateRetryLineItem     = "retry_line_item"
+	UpdateDiscardLineItem   = "discard_line_item"
+
+	UpdateVoidBill = "void_bill_update"
 )
 
+// Application error types returned by the add line item update. Handlers match
+// on these to translate a rejected or failed update into an API error.
+const (
+	ErrTypeBillClosed        = "BillClosed"
+	ErrTypeCurrencyMismatch  = "CurrencyMismatch"
+	ErrTypeDuplicateKey      = "DuplicateIdempotencyKey"
+	ErrTypeInvalidLineItem   = "InvalidLineItem"
+	ErrTypeLineItemNotFailed = "LineItemNotFailed"
+	ErrTypeInvalidTransition = "InvalidBillTransition"
+
+	ErrTypeReversalExceedsLineItem = "ReversalExceedsLineItem"
+)
+
+// WorkflowIDPrefix is prepended to the bill UUID to build the workflow ID.
+const WorkflowIDPrefix = "bill-"
+
+// DunningWorkflowIDPrefix is prepended to the bill UUID to build the ID of the
+// workflow following the bill's payment once it is closed.
+const DunningWorkflowIDPrefix = "dunning-"
+
+// SettlementWorkflowIDPrefix is prepended to the bill UUID to build the ID of the
+// workflow collecting the bill by bank transfer, and of its transfer child.
+const (
+	SettlementWorkflowIDPrefix = "settlement-"
+	TransferWorkflowIDPrefix   = "settlement-transfer-"
+)
+
+// CreditNoteWorkflowIDPrefix is prepended to the credit note UUID to build the
+// ID of the workflow applying the note.
+const CreditNoteWorkflowIDPrefix = "credit-note-"
+
+// MoneyTransferWorkflow is the workflow type run by the money transfer worker.
+// It lives in its own module, so it is started by name on the transfer task queue.
+const MoneyTransferWorkflow = "MoneyTransfer"
+
+// ErrTypeSettlementNotRecorded is returned when a transfer went through but the
+// bill no longer accepts the payment, e.g. because it was paid in the meantime.
+const ErrTypeSettlementNotRecorded = "SettlementNotRecorded"
+
 type BillWorkflowInput struct {
-	BillUUID  string
-	PeriodEnd time.Time
+	BillUUID     string
+	CustomerUUID string
+	Currency     string
+	PeriodEnd    time.Time
+
+	// Recurrence is set for recurring bills. When the period timer fires the
+	// workflow closes this bill and opens the next period. Nil for one-off bills.
+	Recurrence *entity.BillRecurrence
+
+	// LargeChargeCents is the line item amount from which the customer is
+	// notified of the charge. Zero disables large charge notifications.
+	LargeChargeCents int64
+
+	// PaymentTermsDays is the time between close and the due date. Zero leaves
+	// the bill without a due date and skips dunning. The terms of the
+	// customer's billing profile take precedence.
+	PaymentTermsDays int
+
+	// Settlement collects the closed bill by bank transfer. Nil leaves paying
+	// the bill to the customer.
+	Settlement *SettlementAccounts
+
+	// MaxLineItemsPerRun bounds how many line items a single run processes
+	// before it continues as new. Zero falls back to defaultMaxLineItemsPerRun.
+	MaxLineItemsPerRun int
+
+	// Carry holds the state handed over by a previous run through continue-as-new.
+	// It is nil for the first run of a bill.
+	Carry *BillWorkflowCarry
+}
+
+// BillWorkflowCarry is the state carried into the next run when the workflow
+// continues as new. PeriodEnd stays on the input so the close timer keeps the
+// same deadline across runs.
+type BillWorkflowCarry struct {
+	State          billWorkflowState
+	PendingSignals []AddLineItemSignal
+
+	PendingSubscriptionChanges []SubscriptionChangedSignal
 }
 
 type BillWorkflowResult struct {
@@ -18,6 +111,17 @@ type BillWorkflowResult struct {
 	TotalCents int64
 	ItemCount  int
 	ClosedAt   time.Time
+
+	// NextBillUUID is the bill opened for the next period of a recurring bill
+	NextBillUUID string
+
+	// InvoiceNumber is the invoice issued for the closed bill, empty when
+	// invoice generation is disabled
+	InvoiceNumber string
+
+	// Voided is set when the bill was voided instead of closed; ClosedAt then
+	// holds the void time and TotalCents is zero
+	Voided bool
 }
 
 type AddLineItemSignal struct {
@@ -27,12 +131,81 @@ type AddLineItemSignal struct {
 	Description    string
 	AmountCents    int64
 	ReferenceUUID  *string
+
+	// FX is set when the amount was converted from another currency
+	FX *FXConversion
+}
+
+// FXConversion records how a line item amount was converted into the bill
+// currency: what was submitted and the rate it was converted at.
+type FXConversion struct {
+	OriginalAmountCents int64
+	OriginalCurrency    string
+	Rate                string
+}
+
+type VoidBillSignal struct {
+	Reason string
+}
+
+// VoidBillUpdate is the argument of the void update, which returns once the
+// bill is stored as VOIDED.
+type VoidBillUpdate struct {
+	Reason string
+}
+
+// SubscriptionChangedSignal tells an open bill that one of its customer's
+// subscriptions started or ended, so the bill prorates it right away.
+type SubscriptionChangedSignal struct {
+	SubscriptionUUID string
+}
+
+// AddLineItemUpdate is the argument of the add line item update. Unlike the
+// signal it carries the currency so the workflow can reject a mismatch itself.
+type AddLineItemUpdate struct {
+	AddLineItemSignal
+	Currency string
 }
 
 type BillStateQuery struct {
-	Status     string
-	TotalCents int64
-	ItemCount  int
+	Status          string
+	TotalCents      int64
+	ItemCount       int
+	FailedItemCount int
+
+	// PendingLineItems were received by the workflow but are not persisted yet
+	PendingLineItems []AddLineItemSignal
+	// RecentLineItems are the last persisted line items, newest last
+	RecentLineItems []ProcessedLineItem
+
+	ScheduledCloseAt time.Time
+	WorkflowID       string
+	RunID            string
+}
+
+// ProcessedLineItem is a persisted line item kept in the workflow's recent history.
+type ProcessedLineItem struct {
+	Signal      AddLineItemSignal
+	ProcessedAt time.Time
+}
+
+// FailedLineItem is a dead-lettered line item whose insert failed after all retries.
+type FailedLineItem struct {
+	Signal   AddLineItemSignal
+	Error    string
+	FailedAt time.Time
+	Attempts int
+}
+
+// RetryLineItemUpdate is the argument of the retry line item update.
+type RetryLineItemUpdate struct {
+	UUID string
+}
+
+// DiscardLineItemUpdate is the argument of the discard line item update.
+type DiscardLineItemUpdate struct {
+	UUID   string
+	Reason string
 }
 
 type InsertLineItemInput struct {
@@ -43,17 +216,271 @@ type InsertLineItemInput struct {
 	Description    string
 	AmountCents    int64
 	ReferenceUUID  *string
+	FX             *FXConversion
 }
 
 type InsertLineItemResult struct {
 	UUID string
 }
 
-type CloseBillInput struct {
+type PersistLineItemResult struct {
+	LineItem *entity.LineItemEntity
+}
+
+type CalculateTaxInput struct {
+	BillUUID string
+}
+
+type CalculateTaxResult struct {
+	Lines []TaxLine
+}
+
+type PriceSubscriptionsInput struct {
 	BillUUID string
 }
 
+type PriceSubscriptionsResult struct {
+	Lines []SubscriptionLine
+	// Unpriced lists the codes of subscribed products with no price in the
+	// bill currency
+	Unpriced []string
+}
+
+// ProrateSubscriptionsInput reconciles the subscription fees of a bill up to
+// Until, the close time of a bill closed early. SubscriptionUUIDs limits it to
+// the subscriptions that changed, empty covers all of them.
+type ProrateSubscriptionsInput struct {
+	BillUUID          string
+	Until             time.Time
+	SubscriptionUUIDs []string
+}
+
+type ProrateSubscriptionsResult struct {
+	Lines []ProrationLine
+}
+
+type AggregateUsageInput struct {
+	BillUUID string
+}
+
+type AggregateUsageResult struct {
+	Lines []UsageLine
+	// Unpriced lists the meters with usage but no price in the bill currency
+	Unpriced []string
+}
+
+// UsageLine is the usage of one meter priced in the bill currency.
+type UsageLine struct {
+	Meter       string
+	Unit        string
+	Quantity    int64
+	AmountCents int64
+}
+
+type CalculateDiscountsInput struct {
+	BillUUID string
+}
+
+type CalculateDiscountsResult struct {
+	Lines []DiscountLine
+}
+
+type ApplyCreditsInput struct {
+	BillUUID     string
+	CustomerUUID string
+}
+
+type ApplyCreditsResult struct {
+	Lines []CreditLine
+}
+
+// CreditLine is the part of a customer credit consumed by the bill.
+type CreditLine struct {
+	CreditUUID  string
+	AmountCents int64 // positive, the line item itself is negative
+}
+
+type UpdateBillStatusInput struct {
+	BillUUID string
+	From     entity.BillStatus
+	To       entity.BillStatus
+}
+
+type VoidBillInput struct {
+	BillUUID string
+	Reason   string
+}
+
+type VoidBillResult struct {
+	VoidedAt time.Time
+}
+
+type CloseBillInput struct {
+	BillUUID         string
+	PaymentTermsDays int
+}
+
 type CloseBillResult struct {
 	TotalCents int64
 	ClosedAt   time.Time
+
+	// PaymentTermsDays is what the due date was set by, the customer's terms
+	// when their billing profile has them
+	PaymentTermsDays int
+}
+
+type GenerateInvoiceInput struct {
+	BillUUID string
+}
+
+// GenerateInvoiceResult is empty when invoice generation is disabled
+type GenerateInvoiceResult struct {
+	InvoiceNumber string
+}
+
+type NotifyCustomerInput struct {
+	BillUUID       string
+	CustomerUUID   string
+	Event          notify.Event
+	IdempotencyKey string
+	Currency       string
+	AmountCents    int64
+	Description    string
+	InvoiceNumber  string
+	DueDate        *time.Time
+}
+
+type OpenNextBillInput struct {
+	BillUUID     string
+	CustomerUUID string
+	Currency     string
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	Recurrence   *entity.BillRecurrence
+}
+
+type OpenNextBillResult struct {
+	BillUUID string
+
+	// CustomerUUID is who the bill was opened for, the customer the series'
+	// customer was merged into if it was merged meanwhile
+	CustomerUUID string
+}
+
+type DunningWorkflowInput struct {
+	BillUUID     string
+	CustomerUUID string
+	Currency     string
+}
+
+type DunningWorkflowResult struct {
+	Status    entity.BillStatus
+	PaidCents int64
+}
+
+// PaymentReceivedSignal tells the dunning workflow a payment was recorded; the
+// workflow reads the balance back from the payments themselves.
+type PaymentReceivedSignal struct {
+	PaymentUUID string
+}
+
+type FetchBillBalanceInput struct {
+	BillUUID string
+}
+
+type FetchBillBalanceResult struct {
+	Status     entity.BillStatus
+	TotalCents int64
+	PaidCents  int64
+	DueDate    *time.Time
+}
+
+// SettlementAccounts are the accounts a bill is settled between and the task
+// queue of the money transfer worker moving the money.
+type SettlementAccounts struct {
+	SourceAccount string
+	TargetAccount string
+	TaskQueue     string
+}
+
+type SettlementWorkflowInput struct {
+	BillUUID string
+	Accounts SettlementAccounts
+}
+
+// SettlementWorkflowResult has an empty status when nothing was left to collect.
+type SettlementWorkflowResult struct {
+	Status      entity.SettlementStatus
+	AmountCents int64
+	PaymentUUID string
+	// CreditUUID is set when the transferred amount became customer credit
+	CreditUUID string
+}
+
+type CreditNoteWorkflowInput struct {
+	CreditNoteUUID string
+}
+
+// CreditNoteWorkflowResult is the customer credit the note was applied as.
+type CreditNoteWorkflowResult struct {
+	CreditUUID  string
+	Currency    string
+	AmountCents int64
+}
+
+type ApplyCreditNoteInput struct {
+	CreditNoteUUID string
+}
+
+type ApplyCreditNoteResult struct {
+	CreditUUID  string
+	Currency    string
+	AmountCents int64
+}
+
+// TransferDetails is the input of the money transfer workflow, matching its
+// PaymentDetails field for field.
+type TransferDetails struct {
+	SourceAccount string
+	TargetAccount string
+	Amount        int
+	ReferenceID   string
+}
+
+type StartSettlementInput struct {
+	BillUUID      string
+	SourceAccount string
+	TargetAccount string
+	AmountCents   int64
+}
+
+type StartSettlementResult struct {
+	SettlementUUID string
+}
+
+type CompleteSettlementInput struct {
+	BillUUID       string
+	SettlementUUID string
+	AmountCents    int64
+	Confirmation   string
+}
+
+type CompleteSettlementResult struct {
+	PaymentUUID string
+}
+
+type CreditSettlementInput struct {
+	BillUUID       string
+	SettlementUUID string
+	AmountCents    int64
+	Reason         string
+}
+
+type CreditSettlementResult struct {
+	CreditUUID string
+}
+
+type FailSettlementInput struct {
+	BillUUID string
+	Reason   string
 }
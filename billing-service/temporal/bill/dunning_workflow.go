@@ -0,0 +1,223 @@
+package bill
+
+import (
+	"fmt"
+	"time"
+
+	"encore.app/entity"
+	"encore.app/notify"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// reminderLeadTime is how long before the due date the customer is reminded
+	reminderLeadTime = 3 * 24 * time.Hour
+
+	// overdueReminderInterval spaces the reminders of an overdue bill, up to
+	// maxOverdueReminders of them
+	overdueReminderInterval = 7 * 24 * time.Hour
+	maxOverdueReminders     = 3
+)
+
+// dunningStep is what the dunning timer fires for.
+type dunningStep int
+
+const (
+	dunningStepNone dunningStep = iota
+	dunningStepReminder
+	dunningStepDue
+	dunningStepOverdueReminder
+)
+
+// DunningWorkflowID builds the ID of a bill's dunning workflow.
+func DunningWorkflowID(billUUID string) string {
+	return DunningWorkflowIDPrefix + billUUID
+}
+
+// startDunning hands the closed bill over to its dunning workflow.
+func (w *billWorkflow) startDunning(ctx workflow.Context) error {
+	return startAbandonedChild(ctx, DunningWorkflowID(w.input.BillUUID), DunningWorkflow, DunningWorkflowInput{
+		BillUUID:     w.input.BillUUID,
+		CustomerUUID: w.input.CustomerUUID,
+		Currency:     w.input.Currency,
+	})
+}
+
+type dunningWorkflow struct {
+	input       DunningWorkflowInput
+	paymentChan workflow.ReceiveChannel
+	balance     FetchBillBalanceResult
+
+	reminded         bool
+	overdueReminders int
+}
+
+// DunningWorkflow follows a closed bill until it is paid. Payment signals and
+// timers both make it read the balance back from the database, so payments
+// recorded before it started are never missed. The bill moves to
+// PARTIALLY_PAID, OVERDUE and PAID as the balance and the due date dictate, and
+// the customer is reminded before the due date and a few times after it.
+func DunningWorkflow(ctx workflow.Context, input DunningWorkflowInput) (*DunningWorkflowResult, error) {
+	d := &dunningWorkflow{
+		input:       input,
+		paymentChan: workflow.GetSignalChannel(ctx, SignalPaymentReceived),
+	}
+	return d.run(ctx)
+}
+
+func (d *dunningWorkflow) run(ctx workflow.Context) (*DunningWorkflowResult, error) {
+	step := dunningStepNone
+	for {
+		if err := d.refresh(ctx); err != nil {
+			return nil, err
+		}
+		if d.balance.Status == entity.BillStatusPaid {
+			return &DunningWorkflowResult{
+				Status:    d.balance.Status,
+				PaidCents: d.balance.PaidCents,
+			}, nil
+		}
+
+		d.remind(ctx, step)
+		step = d.await(ctx)
+	}
+}
+
+// refresh reloads the balance and moves the bill to the status it calls for.
+func (d *dunningWorkflow) refresh(ctx workflow.Context) error {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).FetchBillBalance, FetchBillBalanceInput{
+		BillUUID: d.input.BillUUID,
+	}).Get(ctx, &d.balance)
+	if err != nil {
+		return err
+	}
+
+	next := dunningStatus(d.balance, workflow.Now(ctx))
+	if next == d.balance.Status || !d.balance.Status.CanTransitionTo(next) {
+		return nil
+	}
+
+	err = workflow.ExecuteActivity(activityCtx, (*BillActivities).UpdateBillStatus, UpdateBillStatusInput{
+		BillUUID: d.input.BillUUID,
+		From:     d.balance.Status,
+		To:       next,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	d.balance.Status = next
+
+	switch next {
+	case entity.BillStatusOverdue:
+		d.notify(ctx, notify.EventBillOverdue, notificationKey(d.input.BillUUID, notify.EventBillOverdue))
+	case entity.BillStatusPaid:
+		d.notify(ctx, notify.EventBillPaid, notificationKey(d.input.BillUUID, notify.EventBillPaid))
+	}
+	return nil
+}
+
+// dunningStatus is the status a bill's balance calls for at now.
+func dunningStatus(balance FetchBillBalanceResult, now time.Time) entity.BillStatus {
+	switch {
+	case balance.PaidCents >= balance.TotalCents:
+		return entity.BillStatusPaid
+	case balance.DueDate != nil && !now.Before(*balance.DueDate):
+		return entity.BillStatusOverdue
+	case balance.PaidCents > 0:
+		return entity.BillStatusPartiallyPaid
+	}
+	return balance.Status
+}
+
+// await blocks until a payment is signalled or the next dunning step is due,
+// returning the step whose timer fired.
+func (d *dunningWorkflow) await(ctx workflow.Context) dunningStep {
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+
+	fired := dunningStepNone
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(d.paymentChan, func(c workflow.ReceiveChannel, _ bool) {
+		var signal PaymentReceivedSignal
+		c.Receive(ctx, &signal)
+		// later payments are covered by the same balance refresh
+		for c.ReceiveAsync(&signal) {
+		}
+	})
+
+	if at, step := d.nextStep(workflow.Now(ctx)); step != dunningStepNone {
+		delay := at.Sub(workflow.Now(ctx))
+		if delay < 0 {
+			delay = 0
+		}
+		selector.AddFuture(workflow.NewTimer(timerCtx, delay), func(f workflow.Future) {
+			if f.Get(ctx, nil) == nil {
+				fired = step
+			}
+		})
+	}
+
+	selector.Select(ctx)
+	return fired
+}
+
+// nextStep schedules the dunning steps off the due date: a reminder ahead of
+// it unless that moment has passed, the due date itself, then the overdue
+// reminders. Bills without a due date only wait for payments.
+func (d *dunningWorkflow) nextStep(now time.Time) (time.Time, dunningStep) {
+	if d.balance.DueDate == nil {
+		return time.Time{}, dunningStepNone
+	}
+	due := *d.balance.DueDate
+
+	if d.balance.Status != entity.BillStatusOverdue {
+		if remindAt := due.Add(-reminderLeadTime); !d.reminded && remindAt.After(now) {
+			return remindAt, dunningStepReminder
+		}
+		return due, dunningStepDue
+	}
+
+	if d.overdueReminders < maxOverdueReminders {
+		return due.Add(time.Duration(d.overdueReminders+1) * overdueReminderInterval), dunningStepOverdueReminder
+	}
+	return time.Time{}, dunningStepNone
+}
+
+// remind sends the reminder of a fired step, after the balance was refreshed so
+// it reports what is actually left to pay.
+func (d *dunningWorkflow) remind(ctx workflow.Context, step dunningStep) {
+	key := notificationKey(d.input.BillUUID, notify.EventPaymentReminder)
+
+	switch step {
+	case dunningStepReminder:
+		d.reminded = true
+		d.notify(ctx, notify.EventPaymentReminder, key)
+	case dunningStepOverdueReminder:
+		d.overdueReminders++
+		d.notify(ctx, notify.EventPaymentReminder, fmt.Sprintf("%s:overdue:%d", key, d.overdueReminders))
+	}
+}
+
+// notify sends a dunning notification. A failed notification is logged and
+// never holds up dunning.
+func (d *dunningWorkflow) notify(ctx workflow.Context, event notify.Event, idempotencyKey string) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).NotifyCustomer, NotifyCustomerInput{
+		BillUUID:       d.input.BillUUID,
+		CustomerUUID:   d.input.CustomerUUID,
+		Currency:       d.input.Currency,
+		Event:          event,
+		IdempotencyKey: idempotencyKey,
+		AmountCents:    d.balance.TotalCents - d.balance.PaidCents,
+		DueDate:        d.balance.DueDate,
+	}).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("dunning notification failed",
+			"bill_uuid", d.input.BillUUID,
+			"event", event,
+			"err", err)
+	}
+}
@@ -3,7 +3,7 @@ package bill
 import "go.temporal.io/sdk/workflow"
 
 func (w *billWorkflow) eventLoop(ctx workflow.Context, timerFuture workflow.Future) {
-	for !w.closed {
+	for !w.closed && !w.continueAsNew {
 		selector := workflow.NewSelector(ctx)
 
 		// handles adding line item
@@ -20,12 +20,52 @@ func (w *billWorkflow) eventLoop(ctx workflow.Context, timerFuture workflow.Futu
 			w.closed = true
 		})
 
+		// handles void signal, the bill is cancelled instead of closed
+		selector.AddReceive(w.voidChan, func(c workflow.ReceiveChannel, more bool) {
+			var signal VoidBillSignal
+			c.Receive(ctx, &signal)
+			w.onVoidSignal(signal)
+		})
+
+		// the void update already marked the bill voided, it only ends the wait
+		selector.AddReceive(w.voidUpdateChan, func(c workflow.ReceiveChannel, more bool) {
+			c.Receive(ctx, nil)
+		})
+
+		// handles subscription changes; a change still buffered when the run
+		// continues as new is carried into the next run
+		selector.AddReceive(w.subscriptionChan, func(c workflow.ReceiveChannel, more bool) {
+			var signal SubscriptionChangedSignal
+			c.Receive(ctx, &signal)
+			w.onSubscriptionChanged(ctx, signal)
+		})
+
 		// handles timer expiration, bill closing on configured day
 		selector.AddFuture(timerFuture, func(f workflow.Future) {
 			_ = f.Get(ctx, nil)
 			w.closed = true
+			w.closedByTimer = true
 		})
 
 		selector.Select(ctx)
+
+		if !w.closed && w.shouldContinueAsNew(ctx) && !w.receiveBufferedCloseOrVoid() {
+			w.continueAsNew = true
+		}
+	}
+}
+
+// receiveBufferedCloseOrVoid acts on a void or close signal still buffered,
+// which continue-as-new would otherwise drop. It reports whether it found one.
+func (w *billWorkflow) receiveBufferedCloseOrVoid() bool {
+	var void VoidBillSignal
+	if w.voidChan.ReceiveAsync(&void) {
+		w.onVoidSignal(void)
+		return true
+	}
+	if w.closeChan.ReceiveAsync(nil) {
+		w.closed = true
+		return true
 	}
+	return false
 }
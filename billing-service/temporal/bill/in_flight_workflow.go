@@ -0,0 +1,70 @@
+package bill
+
+import (
+	"encore.app/notify"
+	"go.temporal.io/sdk/workflow"
+)
+
+// recentLineItemsLimit bounds the processed line items kept for the state query.
+// The list is carried through continue-as-new, so it must stay small.
+const recentLineItemsLimit = 20
+
+// trackPending marks a line item as received but not yet persisted.
+func (w *billWorkflow) trackPending(signal AddLineItemSignal) {
+	w.pendingItems = append(w.pendingItems, signal)
+}
+
+// untrackPending drops a line item from the pending list once its insert finished,
+// whether it succeeded or was dead-lettered.
+func (w *billWorkflow) untrackPending(uuid string) {
+	for i, pending := range w.pendingItems {
+		if pending.UUID == uuid {
+			w.pendingItems = append(w.pendingItems[:i], w.pendingItems[i+1:]...)
+			return
+		}
+	}
+}
+
+// recordLineItem counts a persisted line item and keeps it in the recent history.
+func (w *billWorkflow) recordLineItem(ctx workflow.Context, signal AddLineItemSignal) {
+	w.state.TotalCents += signal.AmountCents
+	w.state.ItemCount++
+
+	w.state.RecentLineItems = append(w.state.RecentLineItems, ProcessedLineItem{
+		Signal:      signal,
+		ProcessedAt: workflow.Now(ctx),
+	})
+	if overflow := len(w.state.RecentLineItems) - recentLineItemsLimit; overflow > 0 {
+		w.state.RecentLineItems = w.state.RecentLineItems[overflow:]
+	}
+
+	if w.isLargeCharge(signal) {
+		w.notifyCustomer(ctx, notify.EventLargeCharge, NotifyCustomerInput{
+			IdempotencyKey: largeChargeNotificationKey(w.input.BillUUID, signal.IdempotencyKey),
+			AmountCents:    signal.AmountCents,
+			Description:    signal.Description,
+		})
+	}
+}
+
+// billStateQuery builds the live view of the bill returned by the state query.
+func (w *billWorkflow) billStateQuery(ctx workflow.Context) *BillStateQuery {
+	info := workflow.GetInfo(ctx)
+
+	// line items buffered for the next run have not been picked up yet
+	pending := make([]AddLineItemSignal, 0, len(w.pendingSignals)+len(w.pendingItems))
+	pending = append(pending, w.pendingSignals...)
+	pending = append(pending, w.pendingItems...)
+
+	return &BillStateQuery{
+		Status:           w.state.Status.String(),
+		TotalCents:       w.state.TotalCents,
+		ItemCount:        w.state.ItemCount,
+		FailedItemCount:  len(w.state.FailedLineItems),
+		PendingLineItems: pending,
+		RecentLineItems:  w.state.RecentLineItems,
+		ScheduledCloseAt: w.input.PeriodEnd,
+		WorkflowID:       info.WorkflowExecution.ID,
+		RunID:            info.WorkflowExecution.RunID,
+	}
+}
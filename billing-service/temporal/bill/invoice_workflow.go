@@ -0,0 +1,51 @@
+package bill
+
+import (
+	"time"
+
+	"encore.app/entity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// invoiceLineItemPageSize is the page size used to load a bill's line items
+// when rendering its invoice.
+const invoiceLineItemPageSize = 100
+
+// invoiceActivityOptions retries invoice generation until it succeeds: the bill
+// is already closed, so an unavailable blob store only delays the invoice.
+func invoiceActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    5 * time.Minute,
+		},
+	}
+}
+
+// issueInvoice generates the invoice of the closed bill and finalizes it. It
+// returns an empty number, leaving the bill CLOSED, when invoices are disabled.
+func (w *billWorkflow) issueInvoice(ctx workflow.Context) (string, error) {
+	disconnectedCtx, _ := workflow.NewDisconnectedContext(ctx)
+	activityCtx := workflow.WithActivityOptions(disconnectedCtx, invoiceActivityOptions())
+
+	var result GenerateInvoiceResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).GenerateInvoice, GenerateInvoiceInput{
+		BillUUID: w.input.BillUUID,
+	}).Get(disconnectedCtx, &result)
+	if err != nil {
+		return "", err
+	}
+
+	if result.InvoiceNumber == "" {
+		return "", nil
+	}
+
+	if err := w.transitionStatus(disconnectedCtx, entity.BillStatusFinalized); err != nil {
+		return "", err
+	}
+
+	return result.InvoiceNumber, nil
+}
@@ -0,0 +1,40 @@
+package bill
+
+import (
+	"encore.app/entity"
+	"encore.app/notify"
+	"go.temporal.io/sdk/workflow"
+)
+
+// notificationKey is the delivery idempotency key of a once-per-bill event.
+func notificationKey(billUUID string, event notify.Event) string {
+	return "bill:" + billUUID + ":" + event.String()
+}
+
+// largeChargeNotificationKey scopes the large charge notification to the line
+// item, keyed on its idempotency key so a retried add does not notify twice.
+func largeChargeNotificationKey(billUUID, lineItemKey string) string {
+	return notificationKey(billUUID, notify.EventLargeCharge) + ":" + lineItemKey
+}
+
+// isLargeCharge reports whether a customer charge reaches the notification
+// threshold. Tax is derived from other charges and is never reported on its own.
+func (w *billWorkflow) isLargeCharge(signal AddLineItemSignal) bool {
+	return w.input.LargeChargeCents > 0 &&
+		signal.AmountCents >= w.input.LargeChargeCents &&
+		signal.FeeType != entity.FeeTypeTax.String()
+}
+
+// notifyCustomer starts a notification in the background, notifications never
+// hold up billing. input carries the event details, the bill fields are filled here.
+func (w *billWorkflow) notifyCustomer(ctx workflow.Context, event notify.Event, input NotifyCustomerInput) {
+	input.BillUUID = w.input.BillUUID
+	input.CustomerUUID = w.input.CustomerUUID
+	input.Currency = w.input.Currency
+	input.Event = event
+	if input.IdempotencyKey == "" {
+		input.IdempotencyKey = notificationKey(w.input.BillUUID, event)
+	}
+
+	w.startBackground(ctx, (*BillActivities).NotifyCustomer, input)
+}
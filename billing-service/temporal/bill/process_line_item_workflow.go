@@ -6,6 +6,9 @@ func (w *billWorkflow) processLineItem(ctx workflow.Context, signal AddLineItemS
 	logger := workflow.GetLogger(ctx)
 	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
 
+	w.trackPending(signal)
+	defer w.untrackPending(signal.UUID)
+
 	var result InsertLineItemResult
 	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).InsertLineItem, InsertLineItemInput{
 		UUID:           signal.UUID,
@@ -15,14 +18,16 @@ func (w *billWorkflow) processLineItem(ctx workflow.Context, signal AddLineItemS
 		Description:    signal.Description,
 		AmountCents:    signal.AmountCents,
 		ReferenceUUID:  signal.ReferenceUUID,
+		FX:             signal.FX,
 	}).Get(ctx, &result)
 
 	if err != nil {
-		// Log but continue - retry policy exhausted, bill will still close
-		logger.Error("failed to insert line item", "error", err, "uuid", signal.UUID)
+		// retry policy exhausted, park the item so the totals stay in line with the database
+		logger.Error("failed to insert line item, moving to dead letters", "error", err, "uuid", signal.UUID)
+		w.deadLetter(ctx, signal, err)
+		return
 	}
 
-	// Update in-memory counters regardless (for query handler accuracy)
-	w.state.TotalCents += signal.AmountCents
-	w.state.ItemCount++
+	w.recordLineItem(ctx, signal)
+	w.processedInRun++
 }
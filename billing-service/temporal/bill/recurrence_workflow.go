@@ -0,0 +1,60 @@
+package bill
+
+import (
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// nextBillNamespace seeds the deterministic UUIDs of recurring bills so that
+// replays and retries always derive the same next bill from the same parent.
+var nextBillNamespace = uuid.MustParse("5b8f3a52-4c1e-4f0a-9d7e-2f6c1b0e8a31")
+
+// NextBillUUID derives the UUID of the bill that follows billUUID in a recurring series.
+func NextBillUUID(billUUID string) string {
+	return uuid.NewSHA1(nextBillNamespace, []byte(billUUID)).String()
+}
+
+// shouldOpenNextPeriod reports whether closing this bill rolls over into a new one.
+// Only the period timer rolls a recurring bill over; a manual close ends the series.
+func (w *billWorkflow) shouldOpenNextPeriod() bool {
+	return w.input.Recurrence != nil && w.closedByTimer
+}
+
+// openNextPeriod inserts the next period's bill and starts its workflow.
+// The child is abandoned on purpose: it outlives this workflow and owns the next bill.
+func (w *billWorkflow) openNextPeriod(ctx workflow.Context) (string, error) {
+	nextStart := w.input.PeriodEnd
+	nextEnd := w.input.Recurrence.NextPeriodEnd(nextStart)
+
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var opened OpenNextBillResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).OpenNextBill, OpenNextBillInput{
+		BillUUID:     NextBillUUID(w.input.BillUUID),
+		CustomerUUID: w.input.CustomerUUID,
+		Currency:     w.input.Currency,
+		PeriodStart:  nextStart,
+		PeriodEnd:    nextEnd,
+		Recurrence:   w.input.Recurrence,
+	}).Get(ctx, &opened)
+	if err != nil {
+		return "", err
+	}
+
+	err = startAbandonedChild(ctx, WorkflowIDPrefix+opened.BillUUID, BillWorkflow, BillWorkflowInput{
+		BillUUID:           opened.BillUUID,
+		CustomerUUID:       opened.CustomerUUID,
+		Currency:           w.input.Currency,
+		PeriodEnd:          nextEnd,
+		Recurrence:         w.input.Recurrence,
+		LargeChargeCents:   w.input.LargeChargeCents,
+		PaymentTermsDays:   w.input.PaymentTermsDays,
+		Settlement:         w.input.Settlement,
+		MaxLineItemsPerRun: w.input.MaxLineItemsPerRun,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return opened.BillUUID, nil
+}
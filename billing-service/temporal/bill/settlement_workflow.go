@@ -0,0 +1,161 @@
+package bill
+
+import (
+	"errors"
+
+	"encore.app/entity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// SettlementWorkflowID builds the ID of a bill's settlement workflow.
+func SettlementWorkflowID(billUUID string) string {
+	return SettlementWorkflowIDPrefix + billUUID
+}
+
+// SettlementCreditKey is the idempotency key of the customer credit a transfer
+// the bill no longer accepted is granted as.
+func SettlementCreditKey(settlementUUID string) string {
+	return "settlement:" + settlementUUID
+}
+
+// startSettlement hands the closed bill over to its settlement workflow.
+func (w *billWorkflow) startSettlement(ctx workflow.Context) error {
+	return startAbandonedChild(ctx, SettlementWorkflowID(w.input.BillUUID), SettlementWorkflow, SettlementWorkflowInput{
+		BillUUID: w.input.BillUUID,
+		Accounts: *w.input.Settlement,
+	})
+}
+
+// SettlementWorkflow collects a closed bill's balance by bank transfer. The
+// transfer runs as a child on the money transfer worker, whose saga refunds the
+// withdrawal when the deposit fails. A completed transfer is recorded as a
+// payment and reported to dunning, a failed one marks the settlement failed and
+// leaves the bill to dunning. A completed transfer the bill no longer accepts,
+// paid or overpaid in the meantime, already moved the money, so it becomes
+// customer credit.
+func SettlementWorkflow(ctx workflow.Context, input SettlementWorkflowInput) (*SettlementWorkflowResult, error) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var balance FetchBillBalanceResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).FetchBillBalance, FetchBillBalanceInput{
+		BillUUID: input.BillUUID,
+	}).Get(ctx, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	amountCents := balance.TotalCents - balance.PaidCents
+	if amountCents <= 0 {
+		return &SettlementWorkflowResult{}, nil
+	}
+
+	var started StartSettlementResult
+	err = workflow.ExecuteActivity(activityCtx, (*BillActivities).StartSettlement, StartSettlementInput{
+		BillUUID:      input.BillUUID,
+		SourceAccount: input.Accounts.SourceAccount,
+		TargetAccount: input.Accounts.TargetAccount,
+		AmountCents:   amountCents,
+	}).Get(ctx, &started)
+	if err != nil {
+		return nil, err
+	}
+
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: TransferWorkflowIDPrefix + input.BillUUID,
+		TaskQueue:  input.Accounts.TaskQueue,
+	})
+
+	var confirmation string
+	err = workflow.ExecuteChildWorkflow(childCtx, MoneyTransferWorkflow, TransferDetails{
+		SourceAccount: input.Accounts.SourceAccount,
+		TargetAccount: input.Accounts.TargetAccount,
+		Amount:        int(amountCents),
+		ReferenceID:   started.SettlementUUID,
+	}).Get(ctx, &confirmation)
+	if err != nil {
+		return failSettlement(ctx, input, amountCents, err)
+	}
+
+	var completed CompleteSettlementResult
+	err = workflow.ExecuteActivity(activityCtx, (*BillActivities).CompleteSettlement, CompleteSettlementInput{
+		BillUUID:       input.BillUUID,
+		SettlementUUID: started.SettlementUUID,
+		AmountCents:    amountCents,
+		Confirmation:   confirmation,
+	}).Get(ctx, &completed)
+	if err != nil {
+		var appErr *temporal.ApplicationError
+		if errors.As(err, &appErr) && appErr.Type() == ErrTypeSettlementNotRecorded {
+			return creditSettlement(ctx, input, started.SettlementUUID, amountCents, err)
+		}
+		return nil, err
+	}
+
+	// dunning reads the payment on its next wake-up anyway, the signal only
+	// saves it the wait
+	err = workflow.SignalExternalWorkflow(ctx, DunningWorkflowID(input.BillUUID), "",
+		SignalPaymentReceived, PaymentReceivedSignal{PaymentUUID: completed.PaymentUUID}).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Info("dunning not signalled of settlement",
+			"bill_uuid", input.BillUUID,
+			"err", err)
+	}
+
+	return &SettlementWorkflowResult{
+		Status:      entity.SettlementStatusSettled,
+		AmountCents: amountCents,
+		PaymentUUID: completed.PaymentUUID,
+	}, nil
+}
+
+// creditSettlement grants the transferred amount to the customer as credit
+// and records why the bill did not take it.
+func creditSettlement(ctx workflow.Context, input SettlementWorkflowInput, settlementUUID string, amountCents int64, cause error) (*SettlementWorkflowResult, error) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var credited CreditSettlementResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).CreditSettlement, CreditSettlementInput{
+		BillUUID:       input.BillUUID,
+		SettlementUUID: settlementUUID,
+		AmountCents:    amountCents,
+		Reason:         cause.Error(),
+	}).Get(ctx, &credited)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow.GetLogger(ctx).Warn("bill settlement granted as customer credit",
+		"bill_uuid", input.BillUUID,
+		"credit_uuid", credited.CreditUUID,
+		"err", cause)
+
+	return &SettlementWorkflowResult{
+		Status:      entity.SettlementStatusCredited,
+		AmountCents: amountCents,
+		CreditUUID:  credited.CreditUUID,
+	}, nil
+}
+
+// failSettlement records why the settlement failed. The bill stays unpaid and
+// dunning keeps chasing it.
+func failSettlement(ctx workflow.Context, input SettlementWorkflowInput, amountCents int64, cause error) (*SettlementWorkflowResult, error) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).FailSettlement, FailSettlementInput{
+		BillUUID: input.BillUUID,
+		Reason:   cause.Error(),
+	}).Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow.GetLogger(ctx).Warn("bill settlement failed",
+		"bill_uuid", input.BillUUID,
+		"err", cause)
+
+	return &SettlementWorkflowResult{
+		Status:      entity.SettlementStatusFailed,
+		AmountCents: amountCents,
+	}, nil
+}
@@ -1,12 +1,22 @@
 package bill
 
+import "encore.app/entity"
+
 // billWorkflowState holds the mutable state of the bill workflow.
 // Encapsulating state in a struct makes it easier to:
 // - Return state in query handlers
 // - Reason about state transitions
 // - Test state management in isolation
 type billWorkflowState struct {
-	Status     string
+	Status     entity.BillStatus
 	TotalCents int64
 	ItemCount  int
+
+	// FailedLineItems is the dead-letter list: line items whose insert exhausted
+	// its retries. They are excluded from TotalCents and ItemCount until replayed.
+	FailedLineItems []FailedLineItem
+
+	// RecentLineItems holds the last persisted line items, newest last, capped
+	// at recentLineItemsLimit.
+	RecentLineItems []ProcessedLineItem
 }
@@ -0,0 +1,40 @@
+package bill
+
+import (
+	"fmt"
+
+	"encore.app/entity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// checkTransition enforces the bill lifecycle before the workflow moves status.
+func (w *billWorkflow) checkTransition(to entity.BillStatus) error {
+	if !w.state.Status.CanTransitionTo(to) {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("bill cannot move from %s to %s", w.state.Status, to),
+			ErrTypeInvalidTransition, nil)
+	}
+	return nil
+}
+
+// transitionStatus persists a status change, recording it in the status history,
+// and applies it to the workflow state once stored.
+func (w *billWorkflow) transitionStatus(ctx workflow.Context, to entity.BillStatus) error {
+	if err := w.checkTransition(to); err != nil {
+		return err
+	}
+
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).UpdateBillStatus, UpdateBillStatusInput{
+		BillUUID: w.input.BillUUID,
+		From:     w.state.Status,
+		To:       to,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	w.state.Status = to
+	return nil
+}
@@ -0,0 +1,26 @@
+package bill
+
+import "encore.app/proration"
+
+// SubscriptionLine is one subscription's fee for the bill period, priced in
+// the bill currency.
+type SubscriptionLine struct {
+	SubscriptionUUID string
+	ProductCode      string
+	ProductName      string
+	Quantity         int64
+	UnitAmountCents  int64
+	AmountCents      int64
+
+	// Share is the part of the period the subscription is charged for
+	Share proration.Result
+}
+
+// ProrationLine is the difference between what the bill charged a subscription
+// and what the subscription's share of the period comes to now. AmountCents is
+// negative when the bill charged too much.
+type ProrationLine struct {
+	Target      SubscriptionLine
+	BilledCents int64
+	AmountCents int64
+}
@@ -0,0 +1,135 @@
+package bill
+
+import (
+	"fmt"
+	"time"
+
+	"encore.app/entity"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// subscriptionLineItemNamespace seeds the deterministic UUIDs of subscription fees.
+var subscriptionLineItemNamespace = uuid.MustParse("0d7c4e2a-93b1-4f6e-a8d5-61e2f9b3c470")
+
+// SubscriptionLineItemUUID derives the UUID of the line item a subscription's
+// fee is billed in on a bill.
+func SubscriptionLineItemUUID(billUUID, subscriptionUUID string) string {
+	return uuid.NewSHA1(subscriptionLineItemNamespace, []byte(billUUID+"/"+subscriptionUUID)).String()
+}
+
+// applySubscriptions adds a MONTHLY_FEE line item per subscription the customer
+// has in the bill period, when the period opens. Subscriptions that start or end
+// within the period pay for the part they cover; changes made once the bill is
+// open are prorated as they come in and again at close.
+func (w *billWorkflow) applySubscriptions(ctx workflow.Context) error {
+	logger := workflow.GetLogger(ctx)
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result PriceSubscriptionsResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).PriceSubscriptions, PriceSubscriptionsInput{
+		BillUUID: w.input.BillUUID,
+	}).Get(ctx, &result)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Unpriced) > 0 {
+		logger.Warn("subscriptions left unbilled, products have no price in the bill currency",
+			"products", result.Unpriced,
+			"currency", w.input.Currency)
+	}
+
+	for _, line := range result.Lines {
+		referenceUUID := line.SubscriptionUUID
+		w.processLineItem(ctx, AddLineItemSignal{
+			UUID:           SubscriptionLineItemUUID(w.input.BillUUID, line.SubscriptionUUID),
+			IdempotencyKey: "system:subscription:" + line.SubscriptionUUID,
+			FeeType:        entity.FeeTypeMonthlyFee.String(),
+			Description:    subscriptionDescription(line),
+			AmountCents:    line.AmountCents,
+			ReferenceUUID:  &referenceUUID,
+		})
+	}
+
+	return nil
+}
+
+func subscriptionDescription(line SubscriptionLine) string {
+	description := fmt.Sprintf("%s x %d", line.ProductName, line.Quantity)
+	if !line.Share.IsFull() {
+		description += ", " + line.Share.Explain()
+	}
+	return description
+}
+
+// prorationLineItemNamespace seeds the deterministic UUIDs of proration line items.
+var prorationLineItemNamespace = uuid.MustParse("7e2b9c15-40da-4b83-9f6e-c5a17d3e0b48")
+
+// ProrationLineItemUUID derives the UUID of the line item that brings a
+// subscription's charges on a bill to targetCents. Each target gets its own
+// line item, so a retried proration does not charge twice.
+func ProrationLineItemUUID(billUUID, subscriptionUUID string, targetCents int64) string {
+	return uuid.NewSHA1(prorationLineItemNamespace, []byte(fmt.Sprintf("%s/%s/%d", billUUID, subscriptionUUID, targetCents))).String()
+}
+
+// onSubscriptionChanged prorates a subscription that started or ended while
+// the bill is open. A failure is only logged, the bill reconciles every
+// subscription again when it closes.
+func (w *billWorkflow) onSubscriptionChanged(ctx workflow.Context, signal SubscriptionChangedSignal) {
+	if err := w.prorateSubscriptions(ctx, w.input.PeriodEnd, signal.SubscriptionUUID); err != nil {
+		workflow.GetLogger(ctx).Error("failed to prorate subscription change",
+			"error", err,
+			"subscription_uuid", signal.SubscriptionUUID)
+	}
+}
+
+// applyProration reconciles all subscription fees at close. A bill closed
+// before its period ends only charges subscriptions up to the close, the rest
+// of what they were charged when the bill opened is credited back.
+func (w *billWorkflow) applyProration(ctx workflow.Context) error {
+	until := workflow.Now(ctx)
+	if until.After(w.input.PeriodEnd) {
+		until = w.input.PeriodEnd
+	}
+	return w.prorateSubscriptions(ctx, until)
+}
+
+// prorateSubscriptions adds a MONTHLY_FEE line item per subscription whose
+// charges on the bill differ from its share of the period up to until, all
+// subscriptions when none are named.
+func (w *billWorkflow) prorateSubscriptions(ctx workflow.Context, until time.Time, subscriptionUUIDs ...string) error {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result ProrateSubscriptionsResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).ProrateSubscriptions, ProrateSubscriptionsInput{
+		BillUUID:          w.input.BillUUID,
+		Until:             until,
+		SubscriptionUUIDs: subscriptionUUIDs,
+	}).Get(ctx, &result)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range result.Lines {
+		referenceUUID := line.Target.SubscriptionUUID
+		w.processLineItem(ctx, AddLineItemSignal{
+			UUID:           ProrationLineItemUUID(w.input.BillUUID, referenceUUID, line.Target.AmountCents),
+			IdempotencyKey: fmt.Sprintf("system:proration:%s:%d", referenceUUID, line.Target.AmountCents),
+			FeeType:        entity.FeeTypeMonthlyFee.String(),
+			Description:    prorationDescription(line),
+			AmountCents:    line.AmountCents,
+			ReferenceUUID:  &referenceUUID,
+		})
+	}
+
+	return nil
+}
+
+// prorationDescription explains the math, e.g. "Pro plan x 2 prorated to 12
+// of 30 days: 3920 less 9800 billed".
+func prorationDescription(line ProrationLine) string {
+	return fmt.Sprintf("%s x %d prorated to %s: %d less %d billed",
+		line.Target.ProductName, line.Target.Quantity, line.Target.Share.Explain(),
+		line.Target.AmountCents, line.BilledCents)
+}
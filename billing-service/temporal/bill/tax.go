@@ -0,0 +1,69 @@
+package bill
+
+import (
+	"context"
+	"sort"
+
+	"encore.app/entity"
+)
+
+// TaxCalculator computes the taxes charged on a bill at close. Subtotals are
+// net of reversals. It must return the same lines for the same subtotals, a
+// retried close relies on it.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, currency string, subtotals map[entity.FeeType]int64) ([]TaxLine, error)
+}
+
+// TaxLine is the tax due on the subtotal of one fee type.
+type TaxLine struct {
+	FeeType     entity.FeeType // the taxed fee type, the line item itself is TAX
+	BasisPoints int64
+	AmountCents int64
+}
+
+// TaxRateTable holds tax rates in basis points keyed by currency, then fee type.
+type TaxRateTable map[string]map[entity.FeeType]int64
+
+// RateTableTaxCalculator taxes each fee type subtotal at the rate configured
+// for the bill currency. Amounts are rounded half up to the cent.
+type RateTableTaxCalculator struct {
+	Rates TaxRateTable
+}
+
+// Ensure RateTableTaxCalculator implements TaxCalculator.
+var _ TaxCalculator = (*RateTableTaxCalculator)(nil)
+
+func (c *RateTableTaxCalculator) Calculate(ctx context.Context, currency string, subtotals map[entity.FeeType]int64) ([]TaxLine, error) {
+	rates := c.Rates[currency]
+
+	var lines []TaxLine
+	for feeType, subtotal := range subtotals {
+		// tax is never charged on tax, nor on a fee type that nets out as a credit
+		if feeType == entity.FeeTypeTax || subtotal <= 0 {
+			continue
+		}
+
+		basisPoints, ok := rates[feeType]
+		if !ok || basisPoints <= 0 {
+			continue
+		}
+
+		amountCents := (subtotal*basisPoints + 5000) / 10000
+		if amountCents == 0 {
+			continue
+		}
+
+		lines = append(lines, TaxLine{
+			FeeType:     feeType,
+			BasisPoints: basisPoints,
+			AmountCents: amountCents,
+		})
+	}
+
+	// map iteration is random, keep the line items in a stable order
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].FeeType < lines[j].FeeType
+	})
+
+	return lines, nil
+}
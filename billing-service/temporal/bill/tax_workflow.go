@@ -0,0 +1,50 @@
+package bill
+
+import (
+	"fmt"
+
+	"encore.app/entity"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// taxLineItemNamespace seeds the deterministic UUIDs of tax line items.
+var taxLineItemNamespace = uuid.MustParse("9e2d4c71-3b5a-4f86-a0c4-7d1e8b2f6a93")
+
+// TaxLineItemUUID derives the UUID of the tax line item charged on feeType.
+func TaxLineItemUUID(billUUID string, feeType entity.FeeType) string {
+	return uuid.NewSHA1(taxLineItemNamespace, []byte(billUUID+"/"+string(feeType))).String()
+}
+
+// taxIdempotencyKey is the idempotency key of the tax line item charged on feeType.
+func taxIdempotencyKey(feeType entity.FeeType) string {
+	return "system:tax:" + string(feeType)
+}
+
+// applyTax adds the bill's taxes as TAX line items before the totals are locked.
+// Tax is computed from the persisted line items, so it must run once nothing
+// else is in flight. The line items go through processLineItem, so a failed
+// insert is dead-lettered and the close waits for it like any other item.
+func (w *billWorkflow) applyTax(ctx workflow.Context) error {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result CalculateTaxResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).CalculateTax, CalculateTaxInput{
+		BillUUID: w.input.BillUUID,
+	}).Get(ctx, &result)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range result.Lines {
+		w.processLineItem(ctx, AddLineItemSignal{
+			UUID:           TaxLineItemUUID(w.input.BillUUID, line.FeeType),
+			IdempotencyKey: taxIdempotencyKey(line.FeeType),
+			FeeType:        entity.FeeTypeTax.String(),
+			Description:    fmt.Sprintf("Tax on %s at %d bps", line.FeeType, line.BasisPoints),
+			AmountCents:    line.AmountCents,
+		})
+	}
+
+	return nil
+}
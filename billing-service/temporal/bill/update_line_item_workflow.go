@@ -0,0 +1,97 @@
+package bill
+
+import (
+	"encore.app/entity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+func (w *billWorkflow) registerUpdateHandlers(ctx workflow.Context) error {
+	err := workflow.SetUpdateHandlerWithOptions(ctx, UpdateAddLineItem, w.handleAddLineItemUpdate,
+		workflow.UpdateHandlerOptions{
+			Validator: w.validateAddLineItemUpdate,
+		})
+	if err != nil {
+		return err
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, UpdateRetryLineItem, w.handleRetryLineItemUpdate,
+		workflow.UpdateHandlerOptions{
+			Validator: w.validateRetryLineItemUpdate,
+		})
+	if err != nil {
+		return err
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, UpdateDiscardLineItem, w.handleDiscardLineItemUpdate,
+		workflow.UpdateHandlerOptions{
+			Validator: w.validateDiscardLineItemUpdate,
+		})
+	if err != nil {
+		return err
+	}
+
+	return workflow.SetUpdateHandlerWithOptions(ctx, UpdateVoidBill, w.handleVoidBillUpdate,
+		workflow.UpdateHandlerOptions{
+			Validator: w.validateVoidBillUpdate,
+		})
+}
+
+// validateAddLineItemUpdate rejects an update before it is written to history.
+// It must not block or mutate state.
+func (w *billWorkflow) validateAddLineItemUpdate(update AddLineItemUpdate) error {
+	if w.closed || !w.state.Status.AcceptsLineItems() {
+		return temporal.NewApplicationError("bill is not accepting line items", ErrTypeBillClosed)
+	}
+	if w.input.Currency != "" && update.Currency != w.input.Currency {
+		return temporal.NewApplicationError("line item currency does not match bill currency", ErrTypeCurrencyMismatch)
+	}
+	if update.UUID == "" || update.IdempotencyKey == "" || update.FeeType == "" || update.AmountCents == 0 {
+		return temporal.NewApplicationError("line item is missing required fields", ErrTypeInvalidLineItem)
+	}
+	return nil
+}
+
+// handleAddLineItemUpdate persists the line item and returns the stored row.
+// In-memory counters only move once the insert succeeded, so the query handler
+// never reports items that are not in the database.
+func (w *billWorkflow) handleAddLineItemUpdate(ctx workflow.Context, update AddLineItemUpdate) (*entity.LineItemEntity, error) {
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	w.trackPending(update.AddLineItemSignal)
+	defer w.untrackPending(update.UUID)
+
+	var result PersistLineItemResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).PersistLineItem, InsertLineItemInput{
+		UUID:           update.UUID,
+		BillUUID:       w.input.BillUUID,
+		IdempotencyKey: update.IdempotencyKey,
+		FeeType:        update.FeeType,
+		Description:    update.Description,
+		AmountCents:    update.AmountCents,
+		ReferenceUUID:  update.ReferenceUUID,
+		FX:             update.FX,
+	}).Get(ctx, &result)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("failed to persist line item", "error", err, "uuid", update.UUID)
+		return nil, err
+	}
+
+	// the idempotency key belongs to another line item, nothing was inserted
+	if result.LineItem.UUID != update.UUID {
+		return nil, temporal.NewApplicationError("idempotency key already used", ErrTypeDuplicateKey, result.LineItem)
+	}
+
+	w.recordLineItem(ctx, update.AddLineItemSignal)
+	w.processedInRun++
+
+	return result.LineItem, nil
+}
+
+// awaitHandlers blocks until in-flight update handlers have finished, so their
+// activities complete before the bill closes or the run continues as new.
+func (w *billWorkflow) awaitHandlers(ctx workflow.Context) error {
+	return workflow.Await(ctx, func() bool {
+		return workflow.AllHandlersFinished(ctx)
+	})
+}
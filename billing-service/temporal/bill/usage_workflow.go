@@ -0,0 +1,51 @@
+package bill
+
+import (
+	"fmt"
+
+	"encore.app/entity"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// usageLineItemNamespace seeds the deterministic UUIDs of usage line items.
+var usageLineItemNamespace = uuid.MustParse("b5e81f3a-6c2d-4e97-8a40-d3f19c7b2e65")
+
+// UsageLineItemUUID derives the UUID of the line item a meter's usage is billed in.
+func UsageLineItemUUID(billUUID, meter string) string {
+	return uuid.NewSHA1(usageLineItemNamespace, []byte(billUUID+"/"+meter)).String()
+}
+
+// applyUsage aggregates the usage recorded on the bill into one USAGE line item
+// per meter, ahead of tax so usage is taxed like any other charge. Usage events
+// never pass through the workflow, only these aggregates do.
+func (w *billWorkflow) applyUsage(ctx workflow.Context) error {
+	logger := workflow.GetLogger(ctx)
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var result AggregateUsageResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).AggregateUsage, AggregateUsageInput{
+		BillUUID: w.input.BillUUID,
+	}).Get(ctx, &result)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Unpriced) > 0 {
+		logger.Warn("usage left unbilled, meters have no price in the bill currency",
+			"meters", result.Unpriced,
+			"currency", w.input.Currency)
+	}
+
+	for _, line := range result.Lines {
+		w.processLineItem(ctx, AddLineItemSignal{
+			UUID:           UsageLineItemUUID(w.input.BillUUID, line.Meter),
+			IdempotencyKey: "system:usage:" + line.Meter,
+			FeeType:        entity.FeeTypeUsage.String(),
+			Description:    fmt.Sprintf("%s: %d %s", line.Meter, line.Quantity, line.Unit),
+			AmountCents:    line.AmountCents,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package bill
+
+import (
+	"encore.app/entity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// onVoidSignal stops the event loop; the bill is voided instead of closed.
+func (w *billWorkflow) onVoidSignal(signal VoidBillSignal) {
+	w.closed = true
+	w.voided = true
+	w.voidReason = signal.Reason
+}
+
+// validateVoidBillUpdate rejects a void once the bill is closing or past OPEN,
+// so the caller learns synchronously that nothing was voided.
+func (w *billWorkflow) validateVoidBillUpdate(update VoidBillUpdate) error {
+	if w.closed || !w.state.Status.CanTransitionTo(entity.BillStatusVoided) {
+		return temporal.NewApplicationError("bill can no longer be voided", ErrTypeBillClosed)
+	}
+	return nil
+}
+
+// handleVoidBillUpdate stops the event loop and voids the bill before
+// returning, so the caller gets the stored status rather than a pending one.
+// Line items whose insert is still running finish first, the void takes them
+// back with the rest.
+func (w *billWorkflow) handleVoidBillUpdate(ctx workflow.Context, update VoidBillUpdate) (entity.BillStatus, error) {
+	w.onVoidSignal(VoidBillSignal(update))
+	w.voidUpdateChan.SendAsync(nil)
+
+	if err := workflow.Await(ctx, func() bool { return len(w.pendingItems) == 0 }); err != nil {
+		return w.state.Status, err
+	}
+	if err := w.void(ctx); err != nil {
+		return w.state.Status, err
+	}
+	return w.state.Status, nil
+}
+
+// voidBill is the compensating path for a bill created by mistake. The period
+// timer is stopped, buffered line items are dropped and the bill is stored as
+// VOIDED with a zero total. It never opens the next period of a recurring bill.
+func (w *billWorkflow) voidBill(ctx workflow.Context) (*BillWorkflowResult, error) {
+	w.timerCancel()
+
+	disconnectedCtx, _ := workflow.NewDisconnectedContext(ctx)
+	if err := w.void(disconnectedCtx); err != nil {
+		return nil, err
+	}
+
+	return &BillWorkflowResult{
+		BillUUID:   w.input.BillUUID,
+		TotalCents: 0,
+		ItemCount:  w.state.ItemCount,
+		ClosedAt:   w.voidedAt,
+		Voided:     true,
+	}, nil
+}
+
+// void stores the bill as VOIDED. It is a no-op once the void update already
+// did so.
+func (w *billWorkflow) void(ctx workflow.Context) error {
+	if w.state.Status == entity.BillStatusVoided {
+		return nil
+	}
+	if err := w.checkTransition(entity.BillStatusVoided); err != nil {
+		return err
+	}
+
+	activityCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	var voidResult VoidBillResult
+	err := workflow.ExecuteActivity(activityCtx, (*BillActivities).VoidBill, VoidBillInput{
+		BillUUID: w.input.BillUUID,
+		Reason:   w.voidReason,
+	}).Get(ctx, &voidResult)
+	if err != nil {
+		return err
+	}
+
+	w.state.Status = entity.BillStatusVoided
+	w.state.TotalCents = 0
+	w.voidedAt = voidResult.VoidedAt
+	// the void takes back the whole bill, dead-lettered line items included
+	w.state.FailedLineItems = nil
+
+	workflow.GetLogger(ctx).Info("bill voided",
+		"bill_uuid", w.input.BillUUID,
+		"reason", w.voidReason)
+
+	return nil
+}
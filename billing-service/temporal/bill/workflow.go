@@ -1,8 +1,12 @@
 package bill
 
 import (
+	"errors"
 	"time"
 
+	"encore.app/entity"
+	"encore.app/notify"
+	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
@@ -23,22 +27,60 @@ type billWorkflow struct {
 	state billWorkflowState
 	input BillWorkflowInput
 
-	addItemChan workflow.ReceiveChannel
-	closeChan   workflow.ReceiveChannel
+	addItemChan      workflow.ReceiveChannel
+	closeChan        workflow.ReceiveChannel
+	voidChan         workflow.ReceiveChannel
+	subscriptionChan workflow.ReceiveChannel
 
-	closed      bool
-	timerCancel workflow.CancelFunc
+	// voidUpdateChan wakes the event loop once the void update was accepted
+	voidUpdateChan workflow.Channel
+
+	closed        bool
+	closedByTimer bool
+	voided        bool
+	voidReason    string
+	voidedAt      time.Time
+	timerCancel   workflow.CancelFunc
+
+	// continue-as-new bookkeeping for the current run
+	continueAsNew  bool
+	processedInRun int
+	pendingSignals []AddLineItemSignal
+
+	// pendingSubscriptionChanges are subscription signals buffered when the
+	// previous run continued as new
+	pendingSubscriptionChanges []SubscriptionChangedSignal
+
+	// pendingItems are line items whose insert is still running
+	pendingItems []AddLineItemSignal
+
+	// background holds the activities this run started without waiting on
+	// them: customer notifications and webhook events
+	background []workflow.Future
 }
 
 func newBillWorkflow(ctx workflow.Context, input BillWorkflowInput) *billWorkflow {
-	return &billWorkflow{
+	w := &billWorkflow{
 		state: billWorkflowState{
-			Status: "OPEN",
+			Status: entity.BillStatusOpen,
 		},
 		input:       input,
 		addItemChan: workflow.GetSignalChannel(ctx, SignalAddLineItem),
 		closeChan:   workflow.GetSignalChannel(ctx, SignalCloseBill),
+		voidChan:    workflow.GetSignalChannel(ctx, SignalVoidBill),
+
+		subscriptionChan: workflow.GetSignalChannel(ctx, SignalSubscriptionChanged),
+		voidUpdateChan:   workflow.NewBufferedChannel(ctx, 1),
 	}
+
+	// resume from the previous run when continuing as new
+	if input.Carry != nil {
+		w.state = input.Carry.State
+		w.pendingSignals = input.Carry.PendingSignals
+		w.pendingSubscriptionChanges = input.Carry.PendingSubscriptionChanges
+	}
+
+	return w
 }
 
 func BillWorkflow(ctx workflow.Context, input BillWorkflowInput) (*BillWorkflowResult, error) {
@@ -50,19 +92,93 @@ func (w *billWorkflow) run(ctx workflow.Context) (*BillWorkflowResult, error) {
 	if err := w.registerQueryHandlers(ctx); err != nil {
 		return nil, err
 	}
+	if err := w.registerUpdateHandlers(ctx); err != nil {
+		return nil, err
+	}
+
+	// later runs of the same bill continue it, only the first announces it and
+	// adds the subscription fees
+	if w.input.Carry == nil {
+		w.notifyCustomer(ctx, notify.EventBillOpened, NotifyCustomerInput{})
+		if err := w.applySubscriptions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// line items buffered by the previous run are processed before new signals
+	w.processCarriedSignals(ctx)
 
 	timerFuture := w.startTimer(ctx)
 	w.eventLoop(ctx, timerFuture)
-	return w.closeBill(ctx)
+
+	if err := w.awaitHandlers(ctx); err != nil {
+		return nil, err
+	}
+	w.awaitBackground(ctx)
+
+	// the waits above can take minutes on notification retries, a close or void
+	// that came in meanwhile still ends the bill in this run
+	if w.continueAsNew && (w.voided || w.receiveBufferedCloseOrVoid()) {
+		w.continueAsNew = false
+	}
+	if w.continueAsNew {
+		return nil, w.continueAsNewError(ctx)
+	}
+
+	if w.voided {
+		return w.voidBill(ctx)
+	}
+
+	result, err := w.closeBill(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.shouldOpenNextPeriod() {
+		nextBillUUID, err := w.openNextPeriod(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.NextBillUUID = nextBillUUID
+	}
+
+	invoiceNumber, err := w.issueInvoice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.InvoiceNumber = invoiceNumber
+
+	// dunning starts once the bill is invoiced, so its status changes follow
+	// FINALIZED. It also moves bills without payment terms to PAID, it only
+	// skips the due date steps for them
+	if err := w.startDunning(ctx); err != nil {
+		return nil, err
+	}
+	if w.input.Settlement != nil {
+		if err := w.startSettlement(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	w.notifyCustomer(ctx, notify.EventBillClosed, NotifyCustomerInput{
+		AmountCents:   result.TotalCents,
+		InvoiceNumber: invoiceNumber,
+	})
+	w.awaitBackground(ctx)
+
+	return result, nil
 }
 
 func (w *billWorkflow) registerQueryHandlers(ctx workflow.Context) error {
-	return workflow.SetQueryHandler(ctx, QueryGetBillState, func() (*BillStateQuery, error) {
-		return &BillStateQuery{
-			Status:     w.state.Status,
-			TotalCents: w.state.TotalCents,
-			ItemCount:  w.state.ItemCount,
-		}, nil
+	err := workflow.SetQueryHandler(ctx, QueryGetBillState, func() (*BillStateQuery, error) {
+		return w.billStateQuery(ctx), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return workflow.SetQueryHandler(ctx, QueryGetFailedLineItems, func() ([]FailedLineItem, error) {
+		return w.state.FailedLineItems, nil
 	})
 }
 
@@ -89,3 +205,25 @@ func (w *billWorkflow) drainPendingSignals(ctx workflow.Context) {
 		w.processLineItem(ctx, signal)
 	}
 }
+
+// startAbandonedChild starts a child workflow that outlives the bill workflow:
+// the next period's bill, dunning and settlement. A child already started by an
+// earlier attempt is left running.
+func startAbandonedChild(ctx workflow.Context, workflowID string, childWorkflow any, input any) error {
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        workflowID,
+		ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+	})
+
+	child := workflow.ExecuteChildWorkflow(childCtx, childWorkflow, input)
+
+	// wait for the child to start, completing before that would cancel the start command
+	var execution workflow.Execution
+	if err := child.GetChildWorkflowExecution().Get(ctx, &execution); err != nil {
+		var alreadyStarted *temporal.ChildWorkflowExecutionAlreadyStartedError
+		if !errors.As(err, &alreadyStarted) {
+			return err
+		}
+	}
+	return nil
+}
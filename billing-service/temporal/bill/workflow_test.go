@@ -2,15 +2,26 @@ package bill
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"encore.app/db/repository/mocks"
 	"encore.app/entity"
+	"encore.app/invoice"
+	"encore.app/notify"
+	"encore.app/proration"
+	"encore.app/usage"
 
+	"encore.dev/storage/sqldb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
 	"go.uber.org/mock/gomock"
 )
 
@@ -29,15 +40,29 @@ func TestBillWorkflow(t *testing.T) {
 
 		testSuite := &testsuite.WorkflowTestSuite{}
 		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
 		env.RegisterActivity(activities.InsertLineItem)
 		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
 
 		billUUID := "bill-123"
 		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
 
 		// Expect close to be called
 		mockBillRepo.EXPECT().
-			Close(gomock.Any(), billUUID, gomock.Any()).
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockBillRepo.EXPECT().
@@ -63,6 +88,238 @@ func TestBillWorkflow(t *testing.T) {
 		assert.Equal(t, 0, result.ItemCount)
 	})
 
+	t.Run("success - closed bill is invoiced and finalized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+		store := &invoice.LocalBlobStore{Dir: t.TempDir()}
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			CustomerRepo: mockCustomerRepo,
+			InvoiceRepo:  mockInvoiceRepo,
+			BlobStore:    store,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+		totalCents := int64(0)
+
+		gomock.InOrder(
+			mockBillRepo.EXPECT().
+				UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+				Return(nil),
+			mockBillRepo.EXPECT().
+				Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+				Return(nil),
+			mockBillRepo.EXPECT().
+				UpdateStatus(gomock.Any(), billUUID, entity.BillStatusClosed, entity.BillStatusFinalized).
+				Return(nil),
+		)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(totalCents, closedAt, nil)
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, CustomerUUID: "cust-456", Currency: "USD", TotalCents: &totalCents}, nil)
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456", Name: "Acme"}, nil)
+		mockLineItemRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID, time.Time{}, int64(0), invoiceLineItemPageSize).
+			Return(nil, nil)
+		mockInvoiceRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, inv *entity.InvoiceEntity) error {
+				inv.Sequence = 1
+				inv.Number = "INV-CUST456-000001"
+				return nil
+			})
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:     billUUID,
+			CustomerUUID: "cust-456",
+			PeriodEnd:    time.Now().Add(-time.Hour),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, "INV-CUST456-000001", result.InvoiceNumber)
+
+		for _, format := range invoice.Formats {
+			data, err := store.Get(context.Background(), invoice.ArtifactKey(billUUID, format))
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "INV-CUST456-000001")
+		}
+	})
+
+	t.Run("success - customer is notified on open, large charge and close", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		var notifications []NotifyCustomerInput
+		env.OnActivity(activities.NotifyCustomer, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input NotifyCustomerInput) error {
+				notifications = append(notifications, input)
+				return nil
+			})
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(150500), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "TRANSACTION",
+				AmountCents:    500,
+			})
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-2",
+				IdempotencyKey: "idem-2",
+				FeeType:        "WIRE_TRANSFER",
+				Description:    "Wire to supplier",
+				AmountCents:    150000,
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*200)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:         billUUID,
+			CustomerUUID:     "cust-456",
+			Currency:         "USD",
+			PeriodEnd:        time.Now().Add(time.Hour * 24),
+			LargeChargeCents: 100000,
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.Len(t, notifications, 3)
+		assert.Equal(t, NotifyCustomerInput{
+			BillUUID:       billUUID,
+			CustomerUUID:   "cust-456",
+			Event:          notify.EventBillOpened,
+			IdempotencyKey: "bill:bill-123:BILL_OPENED",
+			Currency:       "USD",
+		}, notifications[0])
+		assert.Equal(t, notify.EventLargeCharge, notifications[1].Event)
+		assert.Equal(t, "bill:bill-123:LARGE_CHARGE:idem-2", notifications[1].IdempotencyKey)
+		assert.Equal(t, int64(150000), notifications[1].AmountCents)
+		assert.Equal(t, "Wire to supplier", notifications[1].Description)
+		assert.Equal(t, notify.EventBillClosed, notifications[2].Event)
+		assert.Equal(t, int64(150500), notifications[2].AmountCents)
+	})
+
+	t.Run("success - failed notification does not fail the bill", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		env.OnActivity(activities.NotifyCustomer, mock.Anything, mock.Anything).
+			Return(temporal.NewNonRetryableApplicationError("smtp unavailable", "SMTP", nil))
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), "bill-123", entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(0), time.Now(), nil)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:  "bill-123",
+			PeriodEnd: time.Now().Add(-time.Hour),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+	})
+
 	t.Run("success - workflow processes line item and closes", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -77,8 +334,18 @@ func TestBillWorkflow(t *testing.T) {
 
 		testSuite := &testsuite.WorkflowTestSuite{}
 		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
 		env.RegisterActivity(activities.InsertLineItem)
 		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
 
 		billUUID := "bill-123"
 		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
@@ -96,7 +363,11 @@ func TestBillWorkflow(t *testing.T) {
 
 		// Expect close to be called
 		mockBillRepo.EXPECT().
-			Close(gomock.Any(), billUUID, gomock.Any()).
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockBillRepo.EXPECT().
@@ -151,8 +422,18 @@ func TestBillWorkflow(t *testing.T) {
 
 		testSuite := &testsuite.WorkflowTestSuite{}
 		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
 		env.RegisterActivity(activities.InsertLineItem)
 		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
 
 		billUUID := "bill-123"
 		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
@@ -165,7 +446,11 @@ func TestBillWorkflow(t *testing.T) {
 
 		// Expect close to be called
 		mockBillRepo.EXPECT().
-			Close(gomock.Any(), billUUID, gomock.Any()).
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockBillRepo.EXPECT().
@@ -228,8 +513,18 @@ func TestBillWorkflow(t *testing.T) {
 
 		testSuite := &testsuite.WorkflowTestSuite{}
 		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
 		env.RegisterActivity(activities.InsertLineItem)
 		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
 
 		billUUID := "bill-123"
 		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
@@ -243,7 +538,11 @@ func TestBillWorkflow(t *testing.T) {
 
 		// Expect close to be called
 		mockBillRepo.EXPECT().
-			Close(gomock.Any(), billUUID, gomock.Any()).
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockBillRepo.EXPECT().
@@ -308,8 +607,18 @@ func TestBillWorkflow(t *testing.T) {
 
 		testSuite := &testsuite.WorkflowTestSuite{}
 		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
 		env.RegisterActivity(activities.InsertLineItem)
 		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
 
 		billUUID := "bill-123"
 		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
@@ -321,7 +630,11 @@ func TestBillWorkflow(t *testing.T) {
 
 		// Expect close to be called
 		mockBillRepo.EXPECT().
-			Close(gomock.Any(), billUUID, gomock.Any()).
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockBillRepo.EXPECT().
@@ -364,10 +677,8 @@ func TestBillWorkflow(t *testing.T) {
 		require.True(t, env.IsWorkflowCompleted())
 		require.NoError(t, env.GetWorkflowError())
 	})
-}
 
-func TestBillActivities(t *testing.T) {
-	t.Run("InsertLineItem - success", func(t *testing.T) {
+	t.Run("success - workflow continues as new with carried state", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -379,56 +690,72 @@ func TestBillActivities(t *testing.T) {
 			LineItemRepo: mockLineItemRepo,
 		}
 
-		mockLineItemRepo.EXPECT().
-			InsertWithBillUpdate(gomock.Any(), gomock.AssignableToTypeOf(&entity.LineItemEntity{})).
-			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
-				assert.Equal(t, "item-123", li.UUID)
-				assert.Equal(t, "bill-123", li.BillUUID)
-				assert.Equal(t, "TRANSACTION", li.FeeType)
-				assert.Equal(t, int64(1000), li.AmountCents)
-				return nil
-			})
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
 
-		result, err := activities.InsertLineItem(context.Background(), InsertLineItemInput{
-			UUID:           "item-123",
-			BillUUID:       "bill-123",
-			IdempotencyKey: "idem-123",
-			FeeType:        "TRANSACTION",
-			Description:    "Test transaction",
-			AmountCents:    1000,
-		})
+		billUUID := "bill-123"
+		periodEnd := time.Now().Add(time.Hour * 24)
 
-		require.NoError(t, err)
-		assert.Equal(t, "item-123", result.UUID)
-	})
+		// Only the items processed before the threshold are inserted in this run
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
 
-	t.Run("InsertLineItem - error", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		defer ctrl.Finish()
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "TRANSACTION",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
 
-		mockBillRepo := mocks.NewMockBillRepository(ctrl)
-		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-2",
+				IdempotencyKey: "idem-2",
+				FeeType:        "TRANSACTION",
+				AmountCents:    2000,
+			})
+		}, time.Millisecond*200)
 
-		activities := &BillActivities{
-			BillRepo:     mockBillRepo,
-			LineItemRepo: mockLineItemRepo,
+		input := BillWorkflowInput{
+			BillUUID:           billUUID,
+			PeriodEnd:          periodEnd,
+			MaxLineItemsPerRun: 2,
 		}
 
-		mockLineItemRepo.EXPECT().
-			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
-			Return(assert.AnError)
+		env.ExecuteWorkflow(BillWorkflow, input)
 
-		result, err := activities.InsertLineItem(context.Background(), InsertLineItemInput{
-			UUID:        "item-123",
-			BillUUID:    "bill-123",
-			AmountCents: 1000,
-		})
+		require.True(t, env.IsWorkflowCompleted())
 
-		assert.Nil(t, result)
-		assert.Error(t, err)
+		var canErr *workflow.ContinueAsNewError
+		require.ErrorAs(t, env.GetWorkflowError(), &canErr)
+
+		var next BillWorkflowInput
+		require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(canErr.Input, &next))
+
+		assert.Equal(t, billUUID, next.BillUUID)
+		assert.True(t, periodEnd.Equal(next.PeriodEnd))
+		require.NotNil(t, next.Carry)
+		assert.Equal(t, entity.BillStatusOpen, next.Carry.State.Status)
+		assert.Equal(t, int64(3000), next.Carry.State.TotalCents)
+		assert.Equal(t, 2, next.Carry.State.ItemCount)
 	})
 
-	t.Run("CloseBill - success", func(t *testing.T) {
+	t.Run("success - close during the wait before continue-as-new closes the bill", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -440,26 +767,69 @@ func TestBillActivities(t *testing.T) {
 			LineItemRepo: mockLineItemRepo,
 		}
 
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
 		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
 
-		mockBillRepo.EXPECT().
-			Close(gomock.Any(), "bill-123", gomock.Any()).
+		// the opening notification is still retrying when the run wants to continue as new
+		env.OnActivity(activities.NotifyCustomer, mock.Anything, mock.Anything).
+			After(time.Minute).
 			Return(nil)
 
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(nil)
 		mockBillRepo.EXPECT().
-			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
-			Return(int64(5000), closedAt, nil)
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(1000), closedAt, nil)
 
-		result, err := activities.CloseBill(context.Background(), CloseBillInput{
-			BillUUID: "bill-123",
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "TRANSACTION",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Second*10)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:           billUUID,
+			PeriodEnd:          time.Now().Add(time.Hour * 24),
+			MaxLineItemsPerRun: 1,
 		})
 
-		require.NoError(t, err)
-		assert.Equal(t, int64(5000), result.TotalCents)
-		assert.Equal(t, closedAt, result.ClosedAt)
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, int64(1000), result.TotalCents)
 	})
 
-	t.Run("CloseBill - close error", func(t *testing.T) {
+	t.Run("success - subscription change during the wait is carried into the next run", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -471,19 +841,50 @@ func TestBillActivities(t *testing.T) {
 			LineItemRepo: mockLineItemRepo,
 		}
 
-		mockBillRepo.EXPECT().
-			Close(gomock.Any(), "bill-123", gomock.Any()).
-			Return(assert.AnError)
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
 
-		result, err := activities.CloseBill(context.Background(), CloseBillInput{
-			BillUUID: "bill-123",
-		})
+		env.OnActivity(activities.NotifyCustomer, mock.Anything, mock.Anything).
+			After(time.Minute).
+			Return(nil)
 
-		assert.Nil(t, result)
-		assert.Error(t, err)
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "TRANSACTION",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalSubscriptionChanged, SubscriptionChangedSignal{SubscriptionUUID: "sub-1"})
+		}, time.Second*10)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:           "bill-123",
+			PeriodEnd:          time.Now().Add(time.Hour * 24),
+			MaxLineItemsPerRun: 1,
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+
+		var canErr *workflow.ContinueAsNewError
+		require.ErrorAs(t, env.GetWorkflowError(), &canErr)
+
+		var next BillWorkflowInput
+		require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(canErr.Input, &next))
+		require.NotNil(t, next.Carry)
+		assert.Equal(t, []SubscriptionChangedSignal{{SubscriptionUUID: "sub-1"}}, next.Carry.PendingSubscriptionChanges)
 	})
 
-	t.Run("CloseBill - fetch closed error", func(t *testing.T) {
+	t.Run("success - workflow resumes carried state and pending signals", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -495,19 +896,3809 @@ func TestBillActivities(t *testing.T) {
 			LineItemRepo: mockLineItemRepo,
 		}
 
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		// The carried pending signal is persisted by the new run
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.AssignableToTypeOf(&entity.LineItemEntity{})).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				assert.Equal(t, "item-3", li.UUID)
+				return nil
+			})
+
 		mockBillRepo.EXPECT().
-			Close(gomock.Any(), "bill-123", gomock.Any()).
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
 			Return(nil)
 
 		mockBillRepo.EXPECT().
-			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
-			Return(int64(0), time.Time{}, assert.AnError)
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
 
-		result, err := activities.CloseBill(context.Background(), CloseBillInput{
-			BillUUID: "bill-123",
-		})
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(3500), closedAt, nil)
 
-		assert.Nil(t, result)
-		assert.Error(t, err)
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			PeriodEnd: time.Now().Add(-time.Hour), // deadline passed while continuing as new
+			Carry: &BillWorkflowCarry{
+				State: billWorkflowState{
+					Status:     entity.BillStatusOpen,
+					TotalCents: 3000,
+					ItemCount:  2,
+				},
+				PendingSignals: []AddLineItemSignal{
+					{
+						UUID:           "item-3",
+						IdempotencyKey: "idem-3",
+						FeeType:        "TRANSACTION",
+						AmountCents:    500,
+					},
+				},
+			},
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Equal(t, int64(3500), result.TotalCents)
+		assert.Equal(t, 3, result.ItemCount)
+	})
+
+	t.Run("success - recurring bill opens next period on timer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+		env.RegisterActivity(activities.OpenNextBill)
+		env.RegisterWorkflow(BillWorkflow)
+
+		billUUID := "9f1c2d3e-0000-4000-8000-000000000001"
+		nextBillUUID := NextBillUUID(billUUID)
+		periodEnd := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(0), closedAt, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), nextBillUUID).
+			Return(nil, sqldb.ErrNoRows)
+
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.AssignableToTypeOf(&entity.BillEntity{})).
+			DoAndReturn(func(_ context.Context, b *entity.BillEntity) error {
+				assert.Equal(t, nextBillUUID, b.UUID)
+				assert.Equal(t, "customer-123", b.CustomerUUID)
+				assert.Equal(t, "USD", b.Currency)
+				assert.True(t, periodEnd.Equal(b.PeriodStart))
+				assert.True(t, periodEnd.AddDate(0, 1, 0).Equal(b.PeriodEnd))
+				return nil
+			})
+
+		// Workflow mocks match by name, so the bill under test keeps its real implementation
+		// while the next period's workflow, started as an abandoned child, is stubbed out
+		env.OnWorkflow(BillWorkflow, mock.Anything, mock.MatchedBy(func(in BillWorkflowInput) bool {
+			return in.BillUUID == billUUID
+		})).Return(BillWorkflow)
+
+		env.OnWorkflow(BillWorkflow, mock.Anything, mock.MatchedBy(func(in BillWorkflowInput) bool {
+			return in.BillUUID == nextBillUUID
+		})).Return(&BillWorkflowResult{}, nil)
+
+		input := BillWorkflowInput{
+			BillUUID:     billUUID,
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodEnd:    periodEnd,
+			Recurrence:   &entity.BillRecurrence{Cadence: entity.BillCadenceMonthly},
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Equal(t, nextBillUUID, result.NextBillUUID)
+	})
+
+	t.Run("success - recurring bill closed manually ends the series", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+		env.RegisterActivity(activities.OpenNextBill)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(0), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*100)
+
+		input := BillWorkflowInput{
+			BillUUID:   billUUID,
+			PeriodEnd:  time.Now().Add(time.Hour * 24),
+			Recurrence: &entity.BillRecurrence{Cadence: entity.BillCadenceWeekly},
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Empty(t, result.NextBillUUID)
+	})
+
+	t.Run("success - update persists line item and returns entity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.PersistLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), billUUID, "idem-1").
+			Return(&entity.LineItemEntity{
+				UUID:           "item-1",
+				BillUUID:       billUUID,
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			}, nil)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(1000), closedAt, nil)
+
+		var persisted entity.LineItemEntity
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateAddLineItem, "update-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					require.Fail(t, "update should not be rejected", err)
+				},
+				OnAccept: func() {},
+				OnComplete: func(result interface{}, err error) {
+					require.NoError(t, err)
+					persisted = *result.(*entity.LineItemEntity)
+				},
+			}, AddLineItemUpdate{
+				AddLineItemSignal: AddLineItemSignal{
+					UUID:           "item-1",
+					IdempotencyKey: "idem-1",
+					FeeType:        "ACH",
+					AmountCents:    1000,
+				},
+				Currency: "USD",
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*200)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		assert.Equal(t, "item-1", persisted.UUID)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Equal(t, 1, result.ItemCount)
+	})
+
+	t.Run("error - update rejected on currency mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.PersistLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		// No insert expected, the validator rejects the update
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(0), closedAt, nil)
+
+		var rejection error
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateAddLineItem, "update-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					rejection = err
+				},
+				OnAccept: func() {
+					require.Fail(t, "update should be rejected")
+				},
+				OnComplete: func(interface{}, error) {},
+			}, AddLineItemUpdate{
+				AddLineItemSignal: AddLineItemSignal{
+					UUID:           "item-1",
+					IdempotencyKey: "idem-1",
+					FeeType:        "ACH",
+					AmountCents:    1000,
+				},
+				Currency: "GEL",
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*200)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, rejection, &appErr)
+		assert.Equal(t, ErrTypeCurrencyMismatch, appErr.Type())
+	})
+
+	t.Run("success - failed insert is dead-lettered and replayed before close", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.PersistLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		gomock.InOrder(
+			mockLineItemRepo.EXPECT().
+				InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+				Return(errors.New("connection refused")).
+				Times(5),
+			mockLineItemRepo.EXPECT().
+				InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+				Return(nil),
+		)
+
+		mockLineItemRepo.EXPECT().
+			FetchByBillAndKey(gomock.Any(), billUUID, "idem-1").
+			Return(&entity.LineItemEntity{
+				UUID:           "item-1",
+				BillUUID:       billUUID,
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			}, nil)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(1000), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+
+		var failed []FailedLineItem
+		env.RegisterDelayedCallback(func() {
+			val, err := env.QueryWorkflow(QueryGetFailedLineItems)
+			require.NoError(t, err)
+			require.NoError(t, val.Get(&failed))
+
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Minute)
+
+		var closingStatus string
+		env.RegisterDelayedCallback(func() {
+			val, err := env.QueryWorkflow(QueryGetBillState)
+			require.NoError(t, err)
+			var state BillStateQuery
+			require.NoError(t, val.Get(&state))
+			closingStatus = state.Status
+
+			env.UpdateWorkflow(UpdateRetryLineItem, "retry-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					require.Fail(t, "update should not be rejected", err)
+				},
+				OnAccept: func() {},
+				OnComplete: func(result interface{}, err error) {
+					require.NoError(t, err)
+				},
+			}, RetryLineItemUpdate{UUID: "item-1"})
+		}, time.Minute*2)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.Len(t, failed, 1)
+		assert.Equal(t, "item-1", failed[0].Signal.UUID)
+		assert.Equal(t, 1, failed[0].Attempts)
+		assert.NotEmpty(t, failed[0].Error)
+		assert.Equal(t, "CLOSING", closingStatus)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Equal(t, 1, result.ItemCount)
+		assert.Equal(t, int64(1000), result.TotalCents)
+	})
+
+	t.Run("success - discarded dead letter lets a stuck close complete", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		// the insert never succeeds, a retry would fail again
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(errors.New("connection refused")).
+			Times(5)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(0), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Minute)
+
+		var discarded FailedLineItem
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateDiscardLineItem, "discard-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					require.Fail(t, "update should not be rejected", err)
+				},
+				OnAccept: func() {},
+				OnComplete: func(result interface{}, err error) {
+					require.NoError(t, err)
+					discarded = *result.(*FailedLineItem)
+				},
+			}, DiscardLineItemUpdate{UUID: "item-1", Reason: "poison"})
+		}, time.Hour)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		assert.Equal(t, "item-1", discarded.Signal.UUID)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, 0, result.ItemCount)
+	})
+
+	t.Run("success - state query reports pending and recent line items", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		periodEnd := time.Now().Add(time.Hour * 24)
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		// the second insert takes a while, so it is still pending when queried
+		env.OnActivity(activities.InsertLineItem, mock.Anything, mock.MatchedBy(func(input InsertLineItemInput) bool {
+			return input.UUID == "item-1"
+		})).Return(&InsertLineItemResult{UUID: "item-1"}, nil)
+		env.OnActivity(activities.InsertLineItem, mock.Anything, mock.MatchedBy(func(input InsertLineItemInput) bool {
+			return input.UUID == "item-2"
+		})).After(time.Minute).Return(&InsertLineItemResult{UUID: "item-2"}, nil)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(3000), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			})
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-2",
+				IdempotencyKey: "idem-2",
+				FeeType:        "ACH",
+				AmountCents:    2000,
+			})
+		}, time.Millisecond*100)
+
+		var state BillStateQuery
+		env.RegisterDelayedCallback(func() {
+			val, err := env.QueryWorkflow(QueryGetBillState)
+			require.NoError(t, err)
+			require.NoError(t, val.Get(&state))
+		}, time.Second*30)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Minute*2)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: periodEnd,
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		assert.Equal(t, int64(1000), state.TotalCents)
+		assert.Equal(t, 1, state.ItemCount)
+		require.Len(t, state.PendingLineItems, 1)
+		assert.Equal(t, "item-2", state.PendingLineItems[0].UUID)
+		require.Len(t, state.RecentLineItems, 1)
+		assert.Equal(t, "item-1", state.RecentLineItems[0].Signal.UUID)
+		assert.True(t, periodEnd.Equal(state.ScheduledCloseAt))
+		assert.NotEmpty(t, state.WorkflowID)
+		assert.NotEmpty(t, state.RunID)
+	})
+
+	t.Run("error - close fails when the bill cannot move to closing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+
+		// the bill was voided behind the workflow's back, the close must not lock totals
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(entity.ErrInvalidBillTransition).
+			Times(1)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*100)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, env.GetWorkflowError(), &appErr)
+		assert.Equal(t, ErrTypeInvalidTransition, appErr.Type())
+	})
+
+	t.Run("success - void signal voids the bill instead of closing it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.VoidBill)
+		env.RegisterActivity(activities.OpenNextBill)
+
+		billUUID := "bill-123"
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		// no close and no next period, only the void
+		mockBillRepo.EXPECT().
+			Void(gomock.Any(), billUUID, "created by mistake", gomock.Any()).
+			Return(nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalVoidBill, VoidBillSignal{Reason: "created by mistake"})
+		}, time.Millisecond*200)
+
+		input := BillWorkflowInput{
+			BillUUID:   billUUID,
+			Currency:   "USD",
+			PeriodEnd:  time.Now().Add(time.Hour * 24),
+			Recurrence: &entity.BillRecurrence{Cadence: entity.BillCadenceMonthly},
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.True(t, result.Voided)
+		assert.Equal(t, int64(0), result.TotalCents)
+		assert.Empty(t, result.NextBillUUID)
+	})
+
+	t.Run("error - add line item update rejected after void", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.PersistLineItem)
+		env.RegisterActivity(activities.VoidBill)
+
+		billUUID := "bill-123"
+
+		// the void activity takes a while, so the update arrives while it runs
+		env.OnActivity(activities.VoidBill, mock.Anything, mock.Anything).
+			After(time.Minute).
+			Return(&VoidBillResult{VoidedAt: time.Now()}, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalVoidBill, VoidBillSignal{Reason: "created by mistake"})
+		}, time.Millisecond*100)
+
+		var rejection error
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateAddLineItem, "update-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					rejection = err
+				},
+				OnAccept: func() {
+					require.Fail(t, "update should be rejected")
+				},
+				OnComplete: func(interface{}, error) {},
+			}, AddLineItemUpdate{
+				AddLineItemSignal: AddLineItemSignal{
+					UUID:           "item-1",
+					IdempotencyKey: "idem-1",
+					FeeType:        "ACH",
+					AmountCents:    1000,
+				},
+				Currency: "USD",
+			})
+		}, time.Second*30)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, rejection, &appErr)
+		assert.Equal(t, ErrTypeBillClosed, appErr.Type())
+	})
+
+	t.Run("error - retry of a dead letter rejected after void", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.PersistLineItem)
+		env.RegisterActivity(activities.VoidBill)
+
+		billUUID := "bill-123"
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(errors.New("connection refused")).
+			Times(5)
+
+		// the void activity takes a while, so the retry arrives while it runs
+		env.OnActivity(activities.VoidBill, mock.Anything, mock.Anything).
+			After(time.Minute).
+			Return(&VoidBillResult{VoidedAt: time.Now()}, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "ACH",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalVoidBill, VoidBillSignal{Reason: "created by mistake"})
+		}, time.Minute)
+
+		var rejection error
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateRetryLineItem, "retry-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					rejection = err
+				},
+				OnAccept: func() {
+					require.Fail(t, "update should be rejected")
+				},
+				OnComplete: func(interface{}, error) {},
+			}, RetryLineItemUpdate{UUID: "item-1"})
+		}, time.Minute+time.Second*30)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, rejection, &appErr)
+		assert.Equal(t, ErrTypeBillClosed, appErr.Type())
+
+		val, err := env.QueryWorkflow(QueryGetFailedLineItems)
+		require.NoError(t, err)
+		var failed []FailedLineItem
+		require.NoError(t, val.Get(&failed))
+		assert.Empty(t, failed)
+	})
+
+	t.Run("success - void update returns once the bill is voided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{BillRepo: mockBillRepo}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.VoidBill)
+
+		billUUID := "bill-123"
+
+		// voided once, by the update, not again when the workflow completes
+		mockBillRepo.EXPECT().
+			Void(gomock.Any(), billUUID, "created by mistake", gomock.Any()).
+			Return(nil)
+
+		var status entity.BillStatus
+		var updateErr error
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateVoidBill, "void-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					require.Fail(t, "update should be accepted")
+				},
+				OnAccept: func() {},
+				OnComplete: func(result interface{}, err error) {
+					updateErr = err
+					if result != nil {
+						status = result.(entity.BillStatus)
+					}
+				},
+			}, VoidBillUpdate{Reason: "created by mistake"})
+		}, time.Millisecond*100)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		require.NoError(t, updateErr)
+		assert.Equal(t, entity.BillStatusVoided, status)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.True(t, result.Voided)
+	})
+
+	t.Run("error - void update rejected while the bill closes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+		env.RegisterActivity(activities.VoidBill)
+
+		billUUID := "bill-123"
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		// the close takes a while, so the void arrives while it runs
+		env.OnActivity(activities.CloseBill, mock.Anything, mock.Anything).
+			After(time.Minute).
+			Return(&CloseBillResult{ClosedAt: time.Now()}, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*100)
+
+		var rejection error
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateVoidBill, "void-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					rejection = err
+				},
+				OnAccept: func() {
+					require.Fail(t, "update should be rejected")
+				},
+				OnComplete: func(interface{}, error) {},
+			}, VoidBillUpdate{Reason: "created by mistake"})
+		}, time.Second*30)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, rejection, &appErr)
+		assert.Equal(t, ErrTypeBillClosed, appErr.Type())
+	})
+
+	t.Run("success - close adds tax line items before locking totals", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			TaxCalculator: &RateTableTaxCalculator{Rates: TaxRateTable{
+				"GEL": {entity.FeeTypeMonthlyFee: 1800},
+			}},
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				assert.Equal(t, "MONTHLY_FEE", li.FeeType)
+				return nil
+			})
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Currency: "GEL"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), billUUID).
+			Return(map[string]int64{"MONTHLY_FEE": 1000, "ACH": 500}, nil)
+
+		var taxItem *entity.LineItemEntity
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				taxItem = li
+				return nil
+			})
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(1180), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "MONTHLY_FEE",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*200)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "GEL",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.NotNil(t, taxItem)
+		assert.Equal(t, "TAX", taxItem.FeeType)
+		assert.Equal(t, int64(180), taxItem.AmountCents)
+		assert.Equal(t, TaxLineItemUUID(billUUID, entity.FeeTypeMonthlyFee), taxItem.UUID)
+		assert.Equal(t, "system:tax:MONTHLY_FEE", taxItem.IdempotencyKey)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Equal(t, int64(1180), result.TotalCents)
+		assert.Equal(t, 2, result.ItemCount)
+	})
+
+	t.Run("success - close applies discounts then credits after tax", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:      mockBillRepo,
+			LineItemRepo:  mockLineItemRepo,
+			PromotionRepo: mockPromotionRepo,
+			CreditRepo:    mockCreditRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+		percentBps := int64(1000)
+
+		var inserted []*entity.LineItemEntity
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				inserted = append(inserted, li)
+				return nil
+			}).
+			Times(3)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Currency: "USD"}, nil).
+			Times(2)
+
+		mockPromotionRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), billUUID).
+			Return([]*entity.PromotionEntity{
+				{UUID: "promo-1", Code: "TENOFF", Kind: entity.PromotionKindPercent, PercentBps: &percentBps},
+			}, nil)
+
+		gomock.InOrder(
+			mockLineItemRepo.EXPECT().
+				SumByFeeType(gomock.Any(), billUUID).
+				Return(map[string]int64{"MONTHLY_FEE": 1000}, nil),
+			mockLineItemRepo.EXPECT().
+				SumByFeeType(gomock.Any(), billUUID).
+				Return(map[string]int64{"MONTHLY_FEE": 1000, "DISCOUNT": -100}, nil),
+		)
+
+		mockCreditRepo.EXPECT().
+			Apply(gomock.Any(), billUUID, "customer-123", "USD", int64(900)).
+			Return([]*entity.CreditApplicationEntity{
+				{CreditUUID: "credit-1", BillUUID: billUUID, AmountCents: 250},
+			}, nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(650), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalAddLineItem, AddLineItemSignal{
+				UUID:           "item-1",
+				IdempotencyKey: "idem-1",
+				FeeType:        "MONTHLY_FEE",
+				AmountCents:    1000,
+			})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*200)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:     billUUID,
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodEnd:    time.Now().Add(time.Hour * 24),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.Len(t, inserted, 3)
+
+		discount := inserted[1]
+		assert.Equal(t, "DISCOUNT", discount.FeeType)
+		assert.Equal(t, int64(-100), discount.AmountCents)
+		assert.Equal(t, DiscountLineItemUUID(billUUID, "promo-1"), discount.UUID)
+		assert.Equal(t, "system:discount:promo-1", discount.IdempotencyKey)
+		require.NotNil(t, discount.ReferenceUUID)
+		assert.Equal(t, "promo-1", *discount.ReferenceUUID)
+
+		credit := inserted[2]
+		assert.Equal(t, "CREDIT", credit.FeeType)
+		assert.Equal(t, int64(-250), credit.AmountCents)
+		assert.Equal(t, CreditLineItemUUID(billUUID, "credit-1"), credit.UUID)
+		require.NotNil(t, credit.ReferenceUUID)
+		assert.Equal(t, "credit-1", *credit.ReferenceUUID)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+
+		assert.Equal(t, int64(650), result.TotalCents)
+		assert.Equal(t, 3, result.ItemCount)
+	})
+
+	t.Run("success - subscription fees added when the bill opens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:         mockBillRepo,
+			LineItemRepo:     mockLineItemRepo,
+			SubscriptionRepo: mockSubscriptionRepo,
+			PriceRepo:        mockPriceRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		periodStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		periodEnd := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+		closedAt := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{
+				UUID:         billUUID,
+				CustomerUUID: "cust-123",
+				Currency:     "USD",
+				PeriodStart:  periodStart,
+				PeriodEnd:    periodEnd,
+			}, nil).
+			Times(2)
+
+		// once when the bill opens, once more when it reconciles at close
+		mockSubscriptionRepo.EXPECT().
+			FetchActive(gomock.Any(), "cust-123", periodStart, periodEnd).
+			Return([]*entity.SubscriptionEntity{
+				{UUID: "sub-1", ProductUUID: "prod-1", Quantity: 2, StartedAt: periodStart.AddDate(0, -2, 0)},
+			}, nil).
+			Times(2)
+
+		mockPriceRepo.EXPECT().
+			FetchProductByUUID(gomock.Any(), "prod-1").
+			Return(&entity.ProductEntity{UUID: "prod-1", Code: "pro", Name: "Pro plan"}, nil).
+			Times(2)
+
+		mockPriceRepo.EXPECT().
+			FetchEffective(gomock.Any(), "prod-1", "USD", periodStart).
+			Return(&entity.PriceEntity{AmountCents: 4900}, nil).
+			Times(2)
+
+		// the period in the database is over, so the close leaves the full fee
+		mockLineItemRepo.EXPECT().
+			SumByReference(gomock.Any(), billUUID, "MONTHLY_FEE").
+			Return(map[string]int64{"sub-1": 9800}, nil)
+
+		var inserted []*entity.LineItemEntity
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				inserted = append(inserted, li)
+				return nil
+			})
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(9800), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*100)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:     billUUID,
+			CustomerUUID: "cust-123",
+			Currency:     "USD",
+			PeriodEnd:    time.Now().Add(time.Hour * 24),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.Len(t, inserted, 1)
+		assert.Equal(t, SubscriptionLineItemUUID(billUUID, "sub-1"), inserted[0].UUID)
+		assert.Equal(t, "system:subscription:sub-1", inserted[0].IdempotencyKey)
+		assert.Equal(t, "MONTHLY_FEE", inserted[0].FeeType)
+		assert.Equal(t, "Pro plan x 2", inserted[0].Description)
+		assert.Equal(t, int64(9800), inserted[0].AmountCents)
+		require.NotNil(t, inserted[0].ReferenceUUID)
+		assert.Equal(t, "sub-1", *inserted[0].ReferenceUUID)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, int64(9800), result.TotalCents)
+		assert.Equal(t, 1, result.ItemCount)
+	})
+
+	t.Run("success - subscription changes and early close are prorated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:         mockBillRepo,
+			LineItemRepo:     mockLineItemRepo,
+			SubscriptionRepo: mockSubscriptionRepo,
+			PriceRepo:        mockPriceRepo,
+			Proration:        proration.Calculator{Mode: proration.ModeDay},
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		periodStart := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		periodEnd := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		// the close lands late on April 10th, so April 1st to 10th are charged
+		closedAt := periodStart.Add(10*24*time.Hour - time.Hour)
+		env.SetStartTime(periodStart)
+
+		subscribed := &entity.SubscriptionEntity{UUID: "sub-1", ProductUUID: "prod-1", Quantity: 1, StartedAt: periodStart.AddDate(0, -1, 0)}
+		added := &entity.SubscriptionEntity{UUID: "sub-2", ProductUUID: "prod-1", Quantity: 1, StartedAt: periodStart.AddDate(0, 0, 5)}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{
+				UUID:         billUUID,
+				CustomerUUID: "cust-123",
+				Currency:     "USD",
+				PeriodStart:  periodStart,
+				PeriodEnd:    periodEnd,
+			}, nil).
+			AnyTimes()
+
+		// when the bill opens
+		mockSubscriptionRepo.EXPECT().
+			FetchActive(gomock.Any(), "cust-123", periodStart, periodEnd).
+			Return([]*entity.SubscriptionEntity{subscribed}, nil)
+		// when sub-2 is added on April 6th
+		mockSubscriptionRepo.EXPECT().
+			FetchActive(gomock.Any(), "cust-123", periodStart, periodEnd).
+			Return([]*entity.SubscriptionEntity{subscribed, added}, nil)
+		// at close
+		mockSubscriptionRepo.EXPECT().
+			FetchActive(gomock.Any(), "cust-123", periodStart, closedAt).
+			Return([]*entity.SubscriptionEntity{subscribed, added}, nil)
+
+		mockPriceRepo.EXPECT().
+			FetchProductByUUID(gomock.Any(), "prod-1").
+			Return(&entity.ProductEntity{UUID: "prod-1", Code: "pro", Name: "Pro plan"}, nil).
+			AnyTimes()
+		mockPriceRepo.EXPECT().
+			FetchEffective(gomock.Any(), "prod-1", "USD", gomock.Any()).
+			Return(&entity.PriceEntity{AmountCents: 3000}, nil).
+			AnyTimes()
+
+		mockLineItemRepo.EXPECT().
+			SumByReference(gomock.Any(), billUUID, "MONTHLY_FEE").
+			Return(map[string]int64{"sub-1": 3000}, nil)
+		mockLineItemRepo.EXPECT().
+			SumByReference(gomock.Any(), billUUID, "MONTHLY_FEE").
+			Return(map[string]int64{"sub-1": 3000, "sub-2": 2500}, nil)
+
+		var inserted []*entity.LineItemEntity
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				inserted = append(inserted, li)
+				return nil
+			}).
+			Times(4)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(1500), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalSubscriptionChanged, SubscriptionChangedSignal{SubscriptionUUID: "sub-2"})
+		}, 5*24*time.Hour)
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, closedAt.Sub(periodStart))
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:     billUUID,
+			CustomerUUID: "cust-123",
+			Currency:     "USD",
+			PeriodEnd:    periodEnd,
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.Len(t, inserted, 4)
+		assert.Equal(t, "Pro plan x 1", inserted[0].Description)
+		assert.Equal(t, int64(3000), inserted[0].AmountCents)
+
+		assert.Equal(t, "Pro plan x 1 prorated to 25 of 30 days: 2500 less 0 billed", inserted[1].Description)
+		assert.Equal(t, int64(2500), inserted[1].AmountCents)
+		assert.Equal(t, ProrationLineItemUUID(billUUID, "sub-2", 2500), inserted[1].UUID)
+		assert.Equal(t, "system:proration:sub-2:2500", inserted[1].IdempotencyKey)
+
+		assert.Equal(t, "Pro plan x 1 prorated to 10 of 30 days: 1000 less 3000 billed", inserted[2].Description)
+		assert.Equal(t, int64(-2000), inserted[2].AmountCents)
+		assert.Equal(t, "sub-1", *inserted[2].ReferenceUUID)
+
+		assert.Equal(t, "Pro plan x 1 prorated to 5 of 30 days: 500 less 2500 billed", inserted[3].Description)
+		assert.Equal(t, int64(-2000), inserted[3].AmountCents)
+		for _, li := range inserted {
+			assert.Equal(t, "MONTHLY_FEE", li.FeeType)
+		}
+	})
+
+	t.Run("success - close bills aggregated usage before tax", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockUsageRepo := mocks.NewMockUsageRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			UsageRepo:    mockUsageRepo,
+			UsageCatalog: usage.Catalog{"api_calls": {
+				Name:   "api_calls",
+				Unit:   "calls",
+				Prices: map[string]usage.Price{"GEL": {Model: usage.PricingFlat, PerUnits: 1000, UnitPriceCents: 50}},
+			}},
+			TaxCalculator: &RateTableTaxCalculator{Rates: TaxRateTable{
+				"GEL": {entity.FeeTypeUsage: 1800},
+			}},
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.InsertLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockUsageRepo.EXPECT().
+			SumByMeter(gomock.Any(), billUUID).
+			Return(map[string]int64{"api_calls": 20000, "retired_meter": 5}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), billUUID).
+			Return(&entity.BillEntity{UUID: billUUID, Currency: "GEL"}, nil).
+			Times(2)
+
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), billUUID).
+			Return(map[string]int64{"USAGE": 1000}, nil)
+
+		var inserted []*entity.LineItemEntity
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				inserted = append(inserted, li)
+				return nil
+			}).
+			Times(2)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(1180), closedAt, nil)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*100)
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "GEL",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		require.Len(t, inserted, 2)
+		assert.Equal(t, "USAGE", inserted[0].FeeType)
+		assert.Equal(t, int64(1000), inserted[0].AmountCents)
+		assert.Equal(t, "api_calls: 20000 calls", inserted[0].Description)
+		assert.Equal(t, UsageLineItemUUID(billUUID, "api_calls"), inserted[0].UUID)
+		assert.Equal(t, "system:usage:api_calls", inserted[0].IdempotencyKey)
+		assert.Equal(t, "TAX", inserted[1].FeeType)
+		assert.Equal(t, int64(180), inserted[1].AmountCents)
+
+		var result BillWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, int64(1180), result.TotalCents)
+		assert.Equal(t, 2, result.ItemCount)
+	})
+
+	t.Run("error - retry rejected for unknown line item", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.PersistLineItem)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), billUUID, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(0), closedAt, nil)
+
+		var rejection error
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateRetryLineItem, "retry-1", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) {
+					rejection = err
+				},
+				OnAccept: func() {
+					require.Fail(t, "update should be rejected")
+				},
+				OnComplete: func(interface{}, error) {},
+			}, RetryLineItemUpdate{UUID: "item-unknown"})
+		}, time.Millisecond*100)
+
+		env.RegisterDelayedCallback(func() {
+			env.SignalWorkflow(SignalCloseBill, nil)
+		}, time.Millisecond*200)
+
+		input := BillWorkflowInput{
+			BillUUID:  billUUID,
+			Currency:  "USD",
+			PeriodEnd: time.Now().Add(time.Hour * 24),
+		}
+
+		env.ExecuteWorkflow(BillWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, rejection, &appErr)
+		assert.Equal(t, ErrTypeLineItemNotFailed, appErr.Type())
+	})
+}
+
+func TestBillActivities(t *testing.T) {
+	t.Run("InsertLineItem - success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.AssignableToTypeOf(&entity.LineItemEntity{})).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				assert.Equal(t, "item-123", li.UUID)
+				assert.Equal(t, "bill-123", li.BillUUID)
+				assert.Equal(t, "TRANSACTION", li.FeeType)
+				assert.Equal(t, int64(1000), li.AmountCents)
+				return nil
+			})
+
+		result, err := activities.InsertLineItem(context.Background(), InsertLineItemInput{
+			UUID:           "item-123",
+			BillUUID:       "bill-123",
+			IdempotencyKey: "idem-123",
+			FeeType:        "TRANSACTION",
+			Description:    "Test transaction",
+			AmountCents:    1000,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "item-123", result.UUID)
+	})
+
+	t.Run("InsertLineItem - keeps the converted amount's origin", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.AssignableToTypeOf(&entity.LineItemEntity{})).
+			DoAndReturn(func(_ context.Context, li *entity.LineItemEntity) error {
+				assert.Equal(t, int64(1080), li.AmountCents)
+				require.NotNil(t, li.OriginalAmountCents)
+				assert.Equal(t, int64(1000), *li.OriginalAmountCents)
+				require.NotNil(t, li.OriginalCurrency)
+				assert.Equal(t, "EUR", *li.OriginalCurrency)
+				require.NotNil(t, li.FXRate)
+				assert.Equal(t, "1.08", *li.FXRate)
+				return nil
+			})
+
+		result, err := activities.InsertLineItem(context.Background(), InsertLineItemInput{
+			UUID:        "item-123",
+			BillUUID:    "bill-123",
+			FeeType:     "TRANSACTION",
+			AmountCents: 1080,
+			FX: &FXConversion{
+				OriginalAmountCents: 1000,
+				OriginalCurrency:    "EUR",
+				Rate:                "1.08",
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "item-123", result.UUID)
+	})
+
+	t.Run("InsertLineItem - error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		result, err := activities.InsertLineItem(context.Background(), InsertLineItemInput{
+			UUID:        "item-123",
+			BillUUID:    "bill-123",
+			AmountCents: 1000,
+		})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+
+	t.Run("InsertLineItem - reversal beyond the original is not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockLineItemRepo.EXPECT().
+			InsertWithBillUpdate(gomock.Any(), gomock.Any()).
+			Return(entity.ErrReversalExceedsLineItem)
+
+		originalUUID := "item-1"
+		result, err := activities.InsertLineItem(context.Background(), InsertLineItemInput{
+			UUID:          "item-123",
+			BillUUID:      "bill-123",
+			FeeType:       "REVERSAL",
+			AmountCents:   -1000,
+			ReferenceUUID: &originalUUID,
+		})
+
+		assert.Nil(t, result)
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, ErrTypeReversalExceedsLineItem, appErr.Type())
+		assert.True(t, appErr.NonRetryable())
+	})
+
+	t.Run("OpenNextBill - inserts next bill", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-next").
+			Return(nil, sqldb.ErrNoRows)
+
+		recurrence := &entity.BillRecurrence{Cadence: entity.BillCadenceMonthly, AnchorDay: 1}
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, bill *entity.BillEntity) error {
+				assert.Equal(t, recurrence, bill.Recurrence)
+				return nil
+			})
+
+		result, err := activities.OpenNextBill(context.Background(), OpenNextBillInput{
+			BillUUID:     "bill-next",
+			CustomerUUID: "customer-123",
+			Currency:     "USD",
+			PeriodStart:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Recurrence:   recurrence,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "bill-next", result.BillUUID)
+	})
+
+	t.Run("OpenNextBill - customer merged into another", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-next").
+			Return(nil, sqldb.ErrNoRows)
+
+		// the insert opens the bill for the customer the series' customer was merged into
+		mockBillRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, b *entity.BillEntity) error {
+				assert.Equal(t, "customer-dup", b.CustomerUUID)
+				b.CustomerUUID = "customer-123"
+				return nil
+			})
+
+		result, err := activities.OpenNextBill(context.Background(), OpenNextBillInput{
+			BillUUID:     "bill-next",
+			CustomerUUID: "customer-dup",
+			Currency:     "USD",
+			PeriodStart:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "bill-next", result.BillUUID)
+		assert.Equal(t, "customer-123", result.CustomerUUID)
+	})
+
+	t.Run("OpenNextBill - already inserted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		// Retried activity finds its own row and does not insert again
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-next").
+			Return(&entity.BillEntity{UUID: "bill-next"}, nil)
+
+		result, err := activities.OpenNextBill(context.Background(), OpenNextBillInput{
+			BillUUID: "bill-next",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "bill-next", result.BillUUID)
+	})
+
+	t.Run("UpdateBillStatus - success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), "bill-123", entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+
+		err := activities.UpdateBillStatus(context.Background(), UpdateBillStatusInput{
+			BillUUID: "bill-123",
+			From:     entity.BillStatusOpen,
+			To:       entity.BillStatusClosing,
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("UpdateBillStatus - invalid transition is not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), "bill-123", entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(entity.ErrInvalidBillTransition)
+
+		err := activities.UpdateBillStatus(context.Background(), UpdateBillStatusInput{
+			BillUUID: "bill-123",
+			From:     entity.BillStatusOpen,
+			To:       entity.BillStatusClosing,
+		})
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &appErr)
+		assert.True(t, appErr.NonRetryable())
+		assert.Equal(t, ErrTypeInvalidTransition, appErr.Type())
+	})
+
+	t.Run("VoidBill - success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			Void(gomock.Any(), "bill-123", "created by mistake", gomock.Any()).
+			Return(nil)
+
+		result, err := activities.VoidBill(context.Background(), VoidBillInput{
+			BillUUID: "bill-123",
+			Reason:   "created by mistake",
+		})
+
+		require.NoError(t, err)
+		assert.False(t, result.VoidedAt.IsZero())
+	})
+
+	t.Run("VoidBill - closed bill is not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			Void(gomock.Any(), "bill-123", "created by mistake", gomock.Any()).
+			Return(entity.ErrInvalidBillTransition)
+
+		result, err := activities.VoidBill(context.Background(), VoidBillInput{
+			BillUUID: "bill-123",
+			Reason:   "created by mistake",
+		})
+
+		assert.Nil(t, result)
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &appErr)
+		assert.True(t, appErr.NonRetryable())
+	})
+
+	t.Run("CalculateTax - no calculator configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+		}
+
+		result, err := activities.CalculateTax(context.Background(), CalculateTaxInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Lines)
+	})
+
+	t.Run("CalculateTax - reversals are netted against the reversed fee type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			TaxCalculator: &RateTableTaxCalculator{Rates: TaxRateTable{
+				"USD": {entity.FeeTypeMonthlyFee: 1000, entity.FeeTypeReversal: 1000},
+			}},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), "bill-123").
+			Return(map[string]int64{"MONTHLY_FEE": 10000, "REVERSAL": -4000}, nil)
+
+		feeUUID := "item-1"
+		mockLineItemRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), "bill-123", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*entity.LineItemEntity{
+				{UUID: feeUUID, FeeType: "MONTHLY_FEE", AmountCents: 10000},
+				{UUID: "item-2", FeeType: "REVERSAL", AmountCents: -4000, ReferenceUUID: &feeUUID},
+			}, nil)
+
+		result, err := activities.CalculateTax(context.Background(), CalculateTaxInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		require.Len(t, result.Lines, 1)
+		assert.Equal(t, entity.FeeTypeMonthlyFee, result.Lines[0].FeeType)
+		assert.Equal(t, int64(600), result.Lines[0].AmountCents)
+	})
+
+	t.Run("CalculateTax - sum error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:      mockBillRepo,
+			LineItemRepo:  mockLineItemRepo,
+			TaxCalculator: &RateTableTaxCalculator{},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "USD"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), "bill-123").
+			Return(nil, assert.AnError)
+
+		result, err := activities.CalculateTax(context.Background(), CalculateTaxInput{BillUUID: "bill-123"})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+
+	t.Run("PriceSubscriptions - prorates subscriptions covering part of the period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:         mockBillRepo,
+			LineItemRepo:     mocks.NewMockLineItemRepository(ctrl),
+			SubscriptionRepo: mockSubscriptionRepo,
+			PriceRepo:        mockPriceRepo,
+			Proration:        proration.Calculator{Mode: proration.ModeDay},
+		}
+
+		periodStart := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		periodEnd := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		startedMidPeriod := time.Date(2024, 4, 21, 0, 0, 0, 0, time.UTC)
+		endedMidPeriod := time.Date(2024, 4, 11, 0, 0, 0, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{
+				UUID:         "bill-123",
+				CustomerUUID: "cust-123",
+				Currency:     "USD",
+				PeriodStart:  periodStart,
+				PeriodEnd:    periodEnd,
+			}, nil)
+
+		mockSubscriptionRepo.EXPECT().
+			FetchActive(gomock.Any(), "cust-123", periodStart, periodEnd).
+			Return([]*entity.SubscriptionEntity{
+				{UUID: "sub-ended", ProductUUID: "prod-1", Quantity: 1, StartedAt: periodStart.AddDate(0, -1, 0), EndedAt: &endedMidPeriod},
+				{UUID: "sub-started", ProductUUID: "prod-1", Quantity: 3, StartedAt: startedMidPeriod},
+				{UUID: "sub-euro", ProductUUID: "prod-2", Quantity: 1, StartedAt: periodStart},
+			}, nil)
+
+		mockPriceRepo.EXPECT().
+			FetchProductByUUID(gomock.Any(), "prod-1").
+			Return(&entity.ProductEntity{UUID: "prod-1", Code: "pro", Name: "Pro plan"}, nil)
+		mockPriceRepo.EXPECT().
+			FetchProductByUUID(gomock.Any(), "prod-2").
+			Return(&entity.ProductEntity{UUID: "prod-2", Code: "eu-only", Name: "EU plan"}, nil)
+
+		// the price changed on April 15th: the subscription that was running when
+		// the period started keeps the old price, the one starting later gets the new one
+		mockPriceRepo.EXPECT().
+			FetchEffective(gomock.Any(), "prod-1", "USD", periodStart).
+			Return(&entity.PriceEntity{AmountCents: 3000}, nil)
+		mockPriceRepo.EXPECT().
+			FetchEffective(gomock.Any(), "prod-1", "USD", startedMidPeriod).
+			Return(&entity.PriceEntity{AmountCents: 4500}, nil)
+		mockPriceRepo.EXPECT().
+			FetchEffective(gomock.Any(), "prod-2", "USD", periodStart).
+			Return(nil, sqldb.ErrNoRows)
+
+		result, err := activities.PriceSubscriptions(context.Background(), PriceSubscriptionsInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		require.Len(t, result.Lines, 2)
+
+		// 10 of 30 days
+		assert.Equal(t, "sub-ended", result.Lines[0].SubscriptionUUID)
+		assert.Equal(t, int64(1000), result.Lines[0].AmountCents)
+		assert.Equal(t, "Pro plan x 1, 10 of 30 days", subscriptionDescription(result.Lines[0]))
+
+		// 10 of 30 days for 3 units
+		assert.Equal(t, "sub-started", result.Lines[1].SubscriptionUUID)
+		assert.Equal(t, int64(4500), result.Lines[1].AmountCents)
+		assert.Equal(t, "10 of 30 days", result.Lines[1].Share.Explain())
+
+		assert.Equal(t, []string{"eu-only"}, result.Unpriced)
+	})
+
+	t.Run("ProrateSubscriptions - credits a subscription ended before the period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockSubscriptionRepo := mocks.NewMockSubscriptionRepository(ctrl)
+		mockPriceRepo := mocks.NewMockPriceRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:         mockBillRepo,
+			LineItemRepo:     mockLineItemRepo,
+			SubscriptionRepo: mockSubscriptionRepo,
+			PriceRepo:        mockPriceRepo,
+		}
+
+		periodStart := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		periodEnd := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		endedAt := periodStart.AddDate(0, 0, -3)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{
+				UUID:         "bill-123",
+				CustomerUUID: "cust-123",
+				Currency:     "USD",
+				PeriodStart:  periodStart,
+				PeriodEnd:    periodEnd,
+			}, nil)
+
+		mockLineItemRepo.EXPECT().
+			SumByReference(gomock.Any(), "bill-123", "MONTHLY_FEE").
+			Return(map[string]int64{"sub-1": 3000}, nil)
+
+		// backdated cancellation: sub-1 no longer overlaps the period
+		mockSubscriptionRepo.EXPECT().
+			FetchActive(gomock.Any(), "cust-123", periodStart, periodEnd).
+			Return(nil, nil)
+		mockSubscriptionRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "sub-1").
+			Return(&entity.SubscriptionEntity{UUID: "sub-1", ProductUUID: "prod-1", Quantity: 1, StartedAt: periodStart.AddDate(0, -2, 0), EndedAt: &endedAt}, nil)
+		mockPriceRepo.EXPECT().
+			FetchProductByUUID(gomock.Any(), "prod-1").
+			Return(&entity.ProductEntity{UUID: "prod-1", Code: "pro", Name: "Pro plan"}, nil)
+
+		result, err := activities.ProrateSubscriptions(context.Background(), ProrateSubscriptionsInput{
+			BillUUID:          "bill-123",
+			Until:             periodEnd.AddDate(0, 1, 0),
+			SubscriptionUUIDs: []string{"sub-1", "sub-unbilled"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, result.Lines, 1)
+		assert.Equal(t, int64(-3000), result.Lines[0].AmountCents)
+		assert.Equal(t, int64(3000), result.Lines[0].BilledCents)
+		assert.Equal(t, "Pro plan x 1 prorated to 0 of 2592000 seconds: 0 less 3000 billed", prorationDescription(result.Lines[0]))
+	})
+
+	t.Run("PriceSubscriptions - disabled without repositories", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+		}
+
+		result, err := activities.PriceSubscriptions(context.Background(), PriceSubscriptionsInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Lines)
+	})
+
+	t.Run("AggregateUsage - prices each meter in the bill currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockUsageRepo := mocks.NewMockUsageRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+			UsageRepo:    mockUsageRepo,
+			UsageCatalog: usage.Catalog{
+				"api_calls": {Name: "api_calls", Unit: "calls", Prices: map[string]usage.Price{
+					"USD": {Model: usage.PricingTiered, PerUnits: 1000, Tiers: []usage.Tier{
+						{UpTo: 10000, UnitPriceCents: 10},
+						{UnitPriceCents: 5},
+					}},
+				}},
+				"storage_gb": {Name: "storage_gb", Unit: "GB", Prices: map[string]usage.Price{
+					"USD": {Model: usage.PricingFlat, UnitPriceCents: 25},
+				}},
+				"free_meter": {Name: "free_meter", Unit: "units", Prices: map[string]usage.Price{
+					"USD": {Model: usage.PricingFlat},
+				}},
+				"euro_meter": {Name: "euro_meter", Unit: "units", Prices: map[string]usage.Price{
+					"EUR": {Model: usage.PricingFlat, UnitPriceCents: 1},
+				}},
+			},
+		}
+
+		mockUsageRepo.EXPECT().
+			SumByMeter(gomock.Any(), "bill-123").
+			Return(map[string]int64{"storage_gb": 12, "api_calls": 30000, "free_meter": 7, "euro_meter": 3}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "USD"}, nil)
+
+		result, err := activities.AggregateUsage(context.Background(), AggregateUsageInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []UsageLine{
+			{Meter: "api_calls", Unit: "calls", Quantity: 30000, AmountCents: 100 + 100},
+			{Meter: "storage_gb", Unit: "GB", Quantity: 12, AmountCents: 300},
+		}, result.Lines)
+		assert.Equal(t, []string{"euro_meter"}, result.Unpriced)
+	})
+
+	t.Run("AggregateUsage - disabled without a catalog", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+			UsageRepo:    mocks.NewMockUsageRepository(ctrl),
+		}
+
+		result, err := activities.AggregateUsage(context.Background(), AggregateUsageInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Lines)
+	})
+
+	t.Run("CalculateDiscounts - applies to the total without discounts and credits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:      mockBillRepo,
+			LineItemRepo:  mockLineItemRepo,
+			PromotionRepo: mockPromotionRepo,
+		}
+
+		amountCents := int64(300)
+		usd := "USD"
+		mockPromotionRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), "bill-123").
+			Return([]*entity.PromotionEntity{
+				{UUID: "promo-1", Code: "FLAT3", Kind: entity.PromotionKindFixed, AmountCents: &amountCents, Currency: &usd},
+			}, nil)
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "USD"}, nil)
+
+		// a retried calculation sees its own discount and credits, they are left out
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), "bill-123").
+			Return(map[string]int64{"ACH": 1000, "TAX": 100, "DISCOUNT": -300, "CREDIT": -800}, nil)
+
+		result, err := activities.CalculateDiscounts(context.Background(), CalculateDiscountsInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []DiscountLine{
+			{PromotionUUID: "promo-1", Code: "FLAT3", Kind: entity.PromotionKindFixed, AmountCents: 300},
+		}, result.Lines)
+	})
+
+	t.Run("CalculateDiscounts - disabled without a promotion repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+		}
+
+		result, err := activities.CalculateDiscounts(context.Background(), CalculateDiscountsInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Lines)
+	})
+
+	t.Run("ApplyCredits - consumes credit towards the total without credits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			CreditRepo:   mockCreditRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "GEL"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), "bill-123").
+			Return(map[string]int64{"ACH": 1000, "DISCOUNT": -200, "CREDIT": -500}, nil)
+
+		mockCreditRepo.EXPECT().
+			Apply(gomock.Any(), "bill-123", "customer-123", "GEL", int64(800)).
+			Return([]*entity.CreditApplicationEntity{
+				{CreditUUID: "credit-1", BillUUID: "bill-123", AmountCents: 500},
+				{CreditUUID: "credit-2", BillUUID: "bill-123", AmountCents: 300},
+			}, nil)
+
+		result, err := activities.ApplyCredits(context.Background(), ApplyCreditsInput{
+			BillUUID:     "bill-123",
+			CustomerUUID: "customer-123",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []CreditLine{
+			{CreditUUID: "credit-1", AmountCents: 500},
+			{CreditUUID: "credit-2", AmountCents: 300},
+		}, result.Lines)
+	})
+
+	t.Run("ApplyCredits - error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			CreditRepo:   mockCreditRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", Currency: "GEL"}, nil)
+
+		mockLineItemRepo.EXPECT().
+			SumByFeeType(gomock.Any(), "bill-123").
+			Return(map[string]int64{"ACH": 1000}, nil)
+
+		mockCreditRepo.EXPECT().
+			Apply(gomock.Any(), "bill-123", "customer-123", "GEL", int64(1000)).
+			Return(nil, assert.AnError)
+
+		result, err := activities.ApplyCredits(context.Background(), ApplyCreditsInput{
+			BillUUID:     "bill-123",
+			CustomerUUID: "customer-123",
+		})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+
+	t.Run("ApplyCreditNote - grants the note as customer credit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+		mockCreditNoteRepo := mocks.NewMockCreditNoteRepository(ctrl)
+
+		activities := &BillActivities{
+			CreditRepo:     mockCreditRepo,
+			CreditNoteRepo: mockCreditNoteRepo,
+		}
+
+		mockCreditNoteRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "note-123").
+			Return(&entity.CreditNoteEntity{
+				UUID:         "note-123",
+				CustomerUUID: "customer-123",
+				Number:       "CN-CUSTOMER-000001",
+				Currency:     "GEL",
+				AmountCents:  700,
+				Status:       entity.CreditNoteStatusIssued,
+			}, nil)
+
+		mockCreditRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, credit *entity.CreditEntity) error {
+				assert.Equal(t, "customer-123", credit.CustomerUUID)
+				assert.Equal(t, "credit-note:note-123", credit.IdempotencyKey)
+				assert.Equal(t, "GEL", credit.Currency)
+				assert.Equal(t, int64(700), credit.AmountCents)
+				assert.Equal(t, "Credit note CN-CUSTOMER-000001", *credit.Description)
+				credit.UUID = "credit-1"
+				return nil
+			})
+
+		mockCreditNoteRepo.EXPECT().
+			MarkApplied(gomock.Any(), "note-123", "credit-1", gomock.Any()).
+			Return(nil)
+
+		result, err := activities.ApplyCreditNote(context.Background(), ApplyCreditNoteInput{CreditNoteUUID: "note-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, &ApplyCreditNoteResult{CreditUUID: "credit-1", Currency: "GEL", AmountCents: 700}, result)
+	})
+
+	t.Run("ApplyCreditNote - applied note is not granted again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCreditNoteRepo := mocks.NewMockCreditNoteRepository(ctrl)
+
+		activities := &BillActivities{
+			CreditRepo:     mocks.NewMockCreditRepository(ctrl),
+			CreditNoteRepo: mockCreditNoteRepo,
+		}
+
+		creditUUID := "credit-1"
+		mockCreditNoteRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "note-123").
+			Return(&entity.CreditNoteEntity{
+				UUID:        "note-123",
+				Currency:    "GEL",
+				AmountCents: 700,
+				Status:      entity.CreditNoteStatusApplied,
+				CreditUUID:  &creditUUID,
+			}, nil)
+
+		result, err := activities.ApplyCreditNote(context.Background(), ApplyCreditNoteInput{CreditNoteUUID: "note-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "credit-1", result.CreditUUID)
+	})
+
+	t.Run("GenerateInvoice - disabled without a blob store", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+		}
+
+		result, err := activities.GenerateInvoice(context.Background(), GenerateInvoiceInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.InvoiceNumber)
+	})
+
+	t.Run("GenerateInvoice - pages through every line item", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+		store := &invoice.LocalBlobStore{Dir: t.TempDir()}
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			CustomerRepo: mockCustomerRepo,
+			InvoiceRepo:  mockInvoiceRepo,
+			BlobStore:    store,
+		}
+
+		createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		firstPage := make([]*entity.LineItemEntity, invoiceLineItemPageSize)
+		for i := range firstPage {
+			firstPage[i] = &entity.LineItemEntity{ID: int64(i + 1), FeeType: "ACH", AmountCents: 100, CreatedAt: createdAt}
+		}
+		secondPage := []*entity.LineItemEntity{
+			{ID: int64(invoiceLineItemPageSize + 1), FeeType: "TAX", AmountCents: 50, CreatedAt: createdAt},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", CustomerUUID: "cust-456", Currency: "USD"}, nil)
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456", Name: "Acme"}, nil)
+		gomock.InOrder(
+			mockLineItemRepo.EXPECT().
+				FetchByBillUUID(gomock.Any(), "bill-123", time.Time{}, int64(0), invoiceLineItemPageSize).
+				Return(firstPage, nil),
+			mockLineItemRepo.EXPECT().
+				FetchByBillUUID(gomock.Any(), "bill-123", createdAt, int64(invoiceLineItemPageSize), invoiceLineItemPageSize).
+				Return(secondPage, nil),
+		)
+		mockInvoiceRepo.EXPECT().
+			Insert(gomock.Any(), &entity.InvoiceEntity{BillUUID: "bill-123", CustomerUUID: "cust-456"}).
+			DoAndReturn(func(_ context.Context, inv *entity.InvoiceEntity) error {
+				inv.Number = "INV-CUST456-000003"
+				return nil
+			})
+
+		result, err := activities.GenerateInvoice(context.Background(), GenerateInvoiceInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "INV-CUST456-000003", result.InvoiceNumber)
+
+		data, err := store.Get(context.Background(), invoice.ArtifactKey("bill-123", invoice.FormatJSON))
+		require.NoError(t, err)
+		var doc invoice.Document
+		require.NoError(t, json.Unmarshal(data, &doc))
+		assert.Len(t, doc.LineItems, invoiceLineItemPageSize+1)
+		assert.Equal(t, int64(100*invoiceLineItemPageSize+50), doc.TotalCents)
+	})
+
+	t.Run("GenerateInvoice - insert error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockInvoiceRepo := mocks.NewMockInvoiceRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+			CustomerRepo: mockCustomerRepo,
+			InvoiceRepo:  mockInvoiceRepo,
+			BlobStore:    &invoice.LocalBlobStore{Dir: t.TempDir()},
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", CustomerUUID: "cust-456", Currency: "USD"}, nil)
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456"}, nil)
+		mockLineItemRepo.EXPECT().
+			FetchByBillUUID(gomock.Any(), "bill-123", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+		mockInvoiceRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		result, err := activities.GenerateInvoice(context.Background(), GenerateInvoiceInput{BillUUID: "bill-123"})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+
+	t.Run("NotifyCustomer - disabled without a notifier", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		activities := &BillActivities{
+			BillRepo:     mocks.NewMockBillRepository(ctrl),
+			LineItemRepo: mocks.NewMockLineItemRepository(ctrl),
+		}
+
+		err := activities.NotifyCustomer(context.Background(), NotifyCustomerInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("NotifyCustomer - sends and records the delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockNotificationRepo := mocks.NewMockNotificationDeliveryRepository(ctrl)
+		notifier := &recordingNotifier{}
+
+		activities := &BillActivities{
+			CustomerRepo:     mockCustomerRepo,
+			Notifier:         notifier,
+			NotificationRepo: mockNotificationRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456", Name: "Acme", Email: "billing@acme.test"}, nil)
+		mockNotificationRepo.EXPECT().
+			Claim(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, d *entity.NotificationDeliveryEntity) error {
+				assert.Equal(t, "bill:bill-123:BILL_CLOSED", d.IdempotencyKey)
+				assert.Equal(t, "recording", d.Channel)
+				assert.Equal(t, "billing@acme.test", d.Recipient)
+				d.Status = entity.NotificationDeliveryPending
+				return nil
+			})
+		mockNotificationRepo.EXPECT().
+			MarkSent(gomock.Any(), "bill:bill-123:BILL_CLOSED", gomock.Any()).
+			Return(nil)
+
+		err := activities.NotifyCustomer(context.Background(), NotifyCustomerInput{
+			BillUUID:       "bill-123",
+			CustomerUUID:   "cust-456",
+			Event:          notify.EventBillClosed,
+			IdempotencyKey: "bill:bill-123:BILL_CLOSED",
+			Currency:       "USD",
+			AmountCents:    1050,
+			InvoiceNumber:  "INV-CUST456-000001",
+		})
+
+		require.NoError(t, err)
+		require.Len(t, notifier.sent, 1)
+		assert.Equal(t, "billing@acme.test", notifier.sent[0].Recipient)
+		assert.Contains(t, notifier.sent[0].Body, "10.50 USD")
+		assert.Contains(t, notifier.sent[0].Body, "INV-CUST456-000001")
+	})
+
+	t.Run("NotifyCustomer - already delivered is not sent again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockNotificationRepo := mocks.NewMockNotificationDeliveryRepository(ctrl)
+		notifier := &recordingNotifier{}
+
+		activities := &BillActivities{
+			CustomerRepo:     mockCustomerRepo,
+			Notifier:         notifier,
+			NotificationRepo: mockNotificationRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456", Email: "billing@acme.test"}, nil)
+		mockNotificationRepo.EXPECT().
+			Claim(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, d *entity.NotificationDeliveryEntity) error {
+				d.Status = entity.NotificationDeliverySent
+				return nil
+			})
+
+		err := activities.NotifyCustomer(context.Background(), NotifyCustomerInput{
+			BillUUID:       "bill-123",
+			CustomerUUID:   "cust-456",
+			Event:          notify.EventBillOpened,
+			IdempotencyKey: "bill:bill-123:BILL_OPENED",
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, notifier.sent)
+	})
+
+	t.Run("NotifyCustomer - send error is retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockNotificationRepo := mocks.NewMockNotificationDeliveryRepository(ctrl)
+
+		activities := &BillActivities{
+			CustomerRepo:     mockCustomerRepo,
+			Notifier:         &recordingNotifier{err: assert.AnError},
+			NotificationRepo: mockNotificationRepo,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456", Email: "billing@acme.test"}, nil)
+		mockNotificationRepo.EXPECT().
+			Claim(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		err := activities.NotifyCustomer(context.Background(), NotifyCustomerInput{
+			CustomerUUID:   "cust-456",
+			Event:          notify.EventBillOpened,
+			IdempotencyKey: "bill:bill-123:BILL_OPENED",
+		})
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("NotifyCustomer - portal delivery skips the closed bill email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+		notifier := &recordingNotifier{}
+
+		activities := &BillActivities{
+			CustomerRepo:       mockCustomerRepo,
+			BillingProfileRepo: mockBillingProfileRepo,
+			Notifier:           notifier,
+		}
+
+		mockCustomerRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "cust-456").
+			Return(&entity.CustomerEntity{UUID: "cust-456", Email: "billing@acme.test"}, nil)
+		mockBillingProfileRepo.EXPECT().
+			FetchByCustomerUUID(gomock.Any(), "cust-456").
+			Return(&entity.BillingProfileEntity{CustomerUUID: "cust-456", InvoiceDelivery: entity.InvoiceDeliveryPortal}, nil)
+
+		err := activities.NotifyCustomer(context.Background(), NotifyCustomerInput{
+			BillUUID:       "bill-123",
+			CustomerUUID:   "cust-456",
+			Event:          notify.EventBillClosed,
+			IdempotencyKey: "bill:bill-123:BILL_CLOSED",
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, notifier.sent)
+	})
+
+	t.Run("CloseBill - success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		closedAt := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(5000), closedAt, nil)
+
+		result, err := activities.CloseBill(context.Background(), CloseBillInput{
+			BillUUID: "bill-123",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5000), result.TotalCents)
+		assert.Equal(t, closedAt, result.ClosedAt)
+	})
+
+	t.Run("CloseBill - close error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		result, err := activities.CloseBill(context.Background(), CloseBillInput{
+			BillUUID: "bill-123",
+		})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+
+	t.Run("CloseBill - fetch closed error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockLineItemRepo := mocks.NewMockLineItemRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:     mockBillRepo,
+			LineItemRepo: mockLineItemRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(0), time.Time{}, assert.AnError)
+
+		result, err := activities.CloseBill(context.Background(), CloseBillInput{
+			BillUUID: "bill-123",
+		})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+	t.Run("CloseBill - sets due date from payment terms", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, closedAt time.Time, dueDate *time.Time) error {
+				require.NotNil(t, dueDate)
+				assert.True(t, closedAt.AddDate(0, 0, 30).Equal(*dueDate))
+				return nil
+			})
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(0), time.Now(), nil)
+
+		_, err := activities.CloseBill(context.Background(), CloseBillInput{
+			BillUUID:         "bill-123",
+			PaymentTermsDays: 30,
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("CloseBill - billing profile terms take precedence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:           mockBillRepo,
+			BillingProfileRepo: mockBillingProfileRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", CustomerUUID: "cust-456"}, nil)
+		mockBillingProfileRepo.EXPECT().
+			FetchByCustomerUUID(gomock.Any(), "cust-456").
+			Return(&entity.BillingProfileEntity{CustomerUUID: "cust-456", PaymentTerms: entity.PaymentTermsNet15}, nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, closedAt time.Time, dueDate *time.Time) error {
+				require.NotNil(t, dueDate)
+				assert.True(t, closedAt.AddDate(0, 0, 15).Equal(*dueDate))
+				return nil
+			})
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(0), time.Now(), nil)
+
+		result, err := activities.CloseBill(context.Background(), CloseBillInput{
+			BillUUID:         "bill-123",
+			PaymentTermsDays: 30,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 15, result.PaymentTermsDays)
+	})
+
+	t.Run("CloseBill - customer without billing profile keeps workflow terms", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockBillingProfileRepo := mocks.NewMockBillingProfileRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:           mockBillRepo,
+			BillingProfileRepo: mockBillingProfileRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", CustomerUUID: "cust-456"}, nil)
+		mockBillingProfileRepo.EXPECT().
+			FetchByCustomerUUID(gomock.Any(), "cust-456").
+			Return(nil, sqldb.ErrNoRows)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Nil()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(0), time.Now(), nil)
+
+		result, err := activities.CloseBill(context.Background(), CloseBillInput{
+			BillUUID: "bill-123",
+		})
+
+		require.NoError(t, err)
+		assert.Zero(t, result.PaymentTermsDays)
+	})
+
+	t.Run("FetchBillBalance - success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		total := int64(1000)
+		dueDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{
+				UUID:       "bill-123",
+				Status:     string(entity.BillStatusPartiallyPaid),
+				TotalCents: &total,
+				PaidCents:  250,
+				DueDate:    &dueDate,
+			}, nil)
+
+		result, err := activities.FetchBillBalance(context.Background(), FetchBillBalanceInput{BillUUID: "bill-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, entity.BillStatusPartiallyPaid, result.Status)
+		assert.Equal(t, int64(1000), result.TotalCents)
+		assert.Equal(t, int64(250), result.PaidCents)
+		assert.Equal(t, &dueDate, result.DueDate)
+	})
+	t.Run("StartSettlement - returns stored settlement", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSettlementRepo := mocks.NewMockSettlementRepository(ctrl)
+
+		activities := &BillActivities{
+			SettlementRepo: mockSettlementRepo,
+		}
+
+		mockSettlementRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, s *entity.SettlementEntity) error {
+				assert.Equal(t, "bill-123", s.BillUUID)
+				assert.Equal(t, "85-150", s.SourceAccount)
+				assert.Equal(t, int64(750), s.AmountCents)
+				s.UUID = "settlement-existing"
+				return nil
+			})
+
+		result, err := activities.StartSettlement(context.Background(), StartSettlementInput{
+			BillUUID:      "bill-123",
+			SourceAccount: "85-150",
+			TargetAccount: "43-812",
+			AmountCents:   750,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "settlement-existing", result.SettlementUUID)
+	})
+
+	t.Run("CompleteSettlement - records payment and marks settled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPaymentRepo := mocks.NewMockPaymentRepository(ctrl)
+		mockSettlementRepo := mocks.NewMockSettlementRepository(ctrl)
+
+		activities := &BillActivities{
+			PaymentRepo:    mockPaymentRepo,
+			SettlementRepo: mockSettlementRepo,
+		}
+
+		var paymentUUID string
+		mockPaymentRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, p *entity.PaymentEntity) error {
+				assert.Equal(t, "settlement:settlement-1", p.IdempotencyKey)
+				assert.Equal(t, int64(750), p.AmountCents)
+				require.NotNil(t, p.Reference)
+				assert.Equal(t, "Transfer complete", *p.Reference)
+				paymentUUID = p.UUID
+				return nil
+			})
+		mockSettlementRepo.EXPECT().
+			MarkSettled(gomock.Any(), "bill-123", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, uuid string) error {
+				assert.Equal(t, paymentUUID, uuid)
+				return nil
+			})
+
+		result, err := activities.CompleteSettlement(context.Background(), CompleteSettlementInput{
+			BillUUID:       "bill-123",
+			SettlementUUID: "settlement-1",
+			AmountCents:    750,
+			Confirmation:   "Transfer complete",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, paymentUUID, result.PaymentUUID)
+	})
+
+	t.Run("CompleteSettlement - overpayment is not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPaymentRepo := mocks.NewMockPaymentRepository(ctrl)
+
+		activities := &BillActivities{
+			PaymentRepo: mockPaymentRepo,
+		}
+
+		mockPaymentRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			Return(entity.ErrPaymentExceedsBalance)
+
+		result, err := activities.CompleteSettlement(context.Background(), CompleteSettlementInput{
+			BillUUID:       "bill-123",
+			SettlementUUID: "settlement-1",
+			AmountCents:    750,
+		})
+
+		assert.Nil(t, result)
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, ErrTypeSettlementNotRecorded, appErr.Type())
+		assert.True(t, appErr.NonRetryable())
+	})
+
+	t.Run("CreditSettlement - grants the amount as customer credit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+		mockCreditRepo := mocks.NewMockCreditRepository(ctrl)
+		mockSettlementRepo := mocks.NewMockSettlementRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo:       mockBillRepo,
+			CreditRepo:     mockCreditRepo,
+			SettlementRepo: mockSettlementRepo,
+		}
+
+		mockBillRepo.EXPECT().
+			FetchByUUID(gomock.Any(), "bill-123").
+			Return(&entity.BillEntity{UUID: "bill-123", CustomerUUID: "cust-456", Currency: "USD"}, nil)
+
+		var creditUUID string
+		mockCreditRepo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, credit *entity.CreditEntity) error {
+				assert.Equal(t, "cust-456", credit.CustomerUUID)
+				assert.Equal(t, "settlement:settlement-1", credit.IdempotencyKey)
+				assert.Equal(t, "USD", credit.Currency)
+				assert.Equal(t, int64(750), credit.AmountCents)
+				creditUUID = credit.UUID
+				return nil
+			})
+
+		mockSettlementRepo.EXPECT().
+			MarkCredited(gomock.Any(), "bill-123", gomock.Any(), "payment exceeds the bill balance").
+			DoAndReturn(func(_ context.Context, _, uuid, _ string) error {
+				assert.Equal(t, creditUUID, uuid)
+				return nil
+			})
+
+		result, err := activities.CreditSettlement(context.Background(), CreditSettlementInput{
+			BillUUID:       "bill-123",
+			SettlementUUID: "settlement-1",
+			AmountCents:    750,
+			Reason:         "payment exceeds the bill balance",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, creditUUID, result.CreditUUID)
+	})
+}
+
+func TestDunningWorkflow(t *testing.T) {
+	t.Run("success - bill with payment terms starts dunning", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+
+		gomock.InOrder(
+			mockBillRepo.EXPECT().
+				UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+				Return(nil),
+			mockBillRepo.EXPECT().
+				Close(gomock.Any(), billUUID, gomock.Any(), gomock.Not(gomock.Nil())).
+				Return(nil),
+		)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(500), time.Now(), nil)
+
+		var dunningInput DunningWorkflowInput
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).
+			Return(func(_ workflow.Context, input DunningWorkflowInput) (*DunningWorkflowResult, error) {
+				dunningInput = input
+				return &DunningWorkflowResult{}, nil
+			})
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:         billUUID,
+			CustomerUUID:     "cust-456",
+			Currency:         "USD",
+			PeriodEnd:        time.Now().Add(-time.Hour),
+			PaymentTermsDays: 30,
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		assert.Equal(t, DunningWorkflowInput{BillUUID: billUUID, CustomerUUID: "cust-456", Currency: "USD"}, dunningInput)
+	})
+
+	t.Run("success - bill without payment terms starts dunning", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		billUUID := "bill-123"
+
+		gomock.InOrder(
+			mockBillRepo.EXPECT().
+				UpdateStatus(gomock.Any(), billUUID, entity.BillStatusOpen, entity.BillStatusClosing).
+				Return(nil),
+			mockBillRepo.EXPECT().
+				Close(gomock.Any(), billUUID, gomock.Any(), gomock.Nil()).
+				Return(nil),
+		)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), billUUID, gomock.Any()).
+			Return(int64(500), time.Now(), nil)
+
+		// no due date, dunning still moves the bill to PAID once payments cover it
+		var dunningInput DunningWorkflowInput
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).
+			Return(func(_ workflow.Context, input DunningWorkflowInput) (*DunningWorkflowResult, error) {
+				dunningInput = input
+				return &DunningWorkflowResult{}, nil
+			})
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:     billUUID,
+			CustomerUUID: "cust-456",
+			Currency:     "USD",
+			PeriodEnd:    time.Now().Add(-time.Hour),
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		assert.Equal(t, DunningWorkflowInput{BillUUID: billUUID, CustomerUUID: "cust-456", Currency: "USD"}, dunningInput)
+	})
+
+	t.Run("success - payment signal settles the bill", func(t *testing.T) {
+		activities := &BillActivities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+
+		dueDate := time.Now().Add(30 * 24 * time.Hour)
+		paid := int64(0)
+		env.OnActivity(activities.FetchBillBalance, mock.Anything, FetchBillBalanceInput{BillUUID: "bill-123"}).
+			Return(func(_ context.Context, _ FetchBillBalanceInput) (*FetchBillBalanceResult, error) {
+				return &FetchBillBalanceResult{
+					Status:     entity.BillStatusFinalized,
+					TotalCents: 1000,
+					PaidCents:  paid,
+					DueDate:    &dueDate,
+				}, nil
+			})
+
+		var transitions []UpdateBillStatusInput
+		env.OnActivity(activities.UpdateBillStatus, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input UpdateBillStatusInput) error {
+				transitions = append(transitions, input)
+				return nil
+			})
+
+		var notifications []NotifyCustomerInput
+		env.OnActivity(activities.NotifyCustomer, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input NotifyCustomerInput) error {
+				notifications = append(notifications, input)
+				return nil
+			})
+
+		env.RegisterDelayedCallback(func() {
+			paid = 1000
+			env.SignalWorkflow(SignalPaymentReceived, PaymentReceivedSignal{PaymentUUID: "payment-1"})
+		}, time.Hour)
+
+		env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{
+			BillUUID:     "bill-123",
+			CustomerUUID: "cust-456",
+			Currency:     "USD",
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result DunningWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, entity.BillStatusPaid, result.Status)
+		assert.Equal(t, int64(1000), result.PaidCents)
+
+		require.Len(t, transitions, 1)
+		assert.Equal(t, entity.BillStatusFinalized, transitions[0].From)
+		assert.Equal(t, entity.BillStatusPaid, transitions[0].To)
+
+		require.Len(t, notifications, 1)
+		assert.Equal(t, notify.EventBillPaid, notifications[0].Event)
+	})
+
+	t.Run("success - unpaid bill is reminded, goes overdue and is chased", func(t *testing.T) {
+		activities := &BillActivities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+
+		start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		env.SetStartTime(start)
+		dueDate := start.Add(10 * 24 * time.Hour)
+
+		status := entity.BillStatusFinalized
+		paid := int64(0)
+		env.OnActivity(activities.FetchBillBalance, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, _ FetchBillBalanceInput) (*FetchBillBalanceResult, error) {
+				return &FetchBillBalanceResult{
+					Status:     status,
+					TotalCents: 1000,
+					PaidCents:  paid,
+					DueDate:    &dueDate,
+				}, nil
+			})
+		env.OnActivity(activities.UpdateBillStatus, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input UpdateBillStatusInput) error {
+				assert.Equal(t, status, input.From)
+				status = input.To
+				return nil
+			})
+
+		var keys []string
+		env.OnActivity(activities.NotifyCustomer, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input NotifyCustomerInput) error {
+				keys = append(keys, input.IdempotencyKey)
+				return nil
+			})
+
+		// every reminder has gone out by then, only a payment ends dunning
+		env.RegisterDelayedCallback(func() {
+			paid = 1000
+			env.SignalWorkflow(SignalPaymentReceived, PaymentReceivedSignal{PaymentUUID: "payment-1"})
+		}, 60*24*time.Hour)
+
+		env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{BillUUID: "bill-123"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		assert.Equal(t, entity.BillStatusPaid, status)
+
+		reminderKey := notificationKey("bill-123", notify.EventPaymentReminder)
+		assert.Equal(t, []string{
+			reminderKey,
+			notificationKey("bill-123", notify.EventBillOverdue),
+			reminderKey + ":overdue:1",
+			reminderKey + ":overdue:2",
+			reminderKey + ":overdue:3",
+			notificationKey("bill-123", notify.EventBillPaid),
+		}, keys)
+	})
+
+	t.Run("error - balance fetch fails", func(t *testing.T) {
+		activities := &BillActivities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+
+		env.OnActivity(activities.FetchBillBalance, mock.Anything, mock.Anything).
+			Return(nil, temporal.NewNonRetryableApplicationError("db down", "test", nil))
+
+		env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{BillUUID: "bill-123"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		assert.Error(t, env.GetWorkflowError())
+	})
+}
+
+func TestSettlementWorkflow(t *testing.T) {
+	accounts := SettlementAccounts{SourceAccount: "85-150", TargetAccount: "43-812", TaskQueue: "transfers"}
+
+	// newEnv registers a stand-in for the money transfer workflow, which lives
+	// in its own module and is only known here by name
+	newEnv := func(activities *BillActivities) *testsuite.TestWorkflowEnvironment {
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflowWithOptions(func(workflow.Context, TransferDetails) (string, error) {
+			return "", nil
+		}, workflow.RegisterOptions{Name: MoneyTransferWorkflow})
+
+		env.OnActivity(activities.FetchBillBalance, mock.Anything, FetchBillBalanceInput{BillUUID: "bill-123"}).
+			Return(&FetchBillBalanceResult{Status: entity.BillStatusFinalized, TotalCents: 1000, PaidCents: 250}, nil)
+		return env
+	}
+
+	t.Run("success - bill with settlement account starts settlement", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockBillRepo := mocks.NewMockBillRepository(ctrl)
+
+		activities := &BillActivities{
+			BillRepo: mockBillRepo,
+		}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.OnWorkflow(DunningWorkflow, mock.Anything, mock.Anything).Return(&DunningWorkflowResult{}, nil)
+		env.RegisterActivity(activities.NotifyCustomer)
+		env.RegisterActivity(activities.PriceSubscriptions)
+		env.RegisterActivity(activities.ProrateSubscriptions)
+		env.RegisterActivity(activities.CloseBill)
+		env.RegisterActivity(activities.UpdateBillStatus)
+		env.RegisterActivity(activities.AggregateUsage)
+		env.RegisterActivity(activities.CalculateTax)
+		env.RegisterActivity(activities.CalculateDiscounts)
+		env.RegisterActivity(activities.ApplyCredits)
+		env.RegisterActivity(activities.GenerateInvoice)
+
+		mockBillRepo.EXPECT().
+			UpdateStatus(gomock.Any(), "bill-123", entity.BillStatusOpen, entity.BillStatusClosing).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			Close(gomock.Any(), "bill-123", gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockBillRepo.EXPECT().
+			FetchClosed(gomock.Any(), "bill-123", gomock.Any()).
+			Return(int64(1000), time.Now(), nil)
+
+		var settlementInput SettlementWorkflowInput
+		env.OnWorkflow(SettlementWorkflow, mock.Anything, mock.Anything).
+			Return(func(_ workflow.Context, input SettlementWorkflowInput) (*SettlementWorkflowResult, error) {
+				settlementInput = input
+				return &SettlementWorkflowResult{}, nil
+			})
+
+		env.ExecuteWorkflow(BillWorkflow, BillWorkflowInput{
+			BillUUID:   "bill-123",
+			PeriodEnd:  time.Now().Add(-time.Hour),
+			Settlement: &accounts,
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		assert.Equal(t, SettlementWorkflowInput{BillUUID: "bill-123", Accounts: accounts}, settlementInput)
+	})
+
+	t.Run("success - completed transfer is recorded as a payment", func(t *testing.T) {
+		activities := &BillActivities{}
+		env := newEnv(activities)
+
+		env.OnActivity(activities.StartSettlement, mock.Anything, StartSettlementInput{
+			BillUUID:      "bill-123",
+			SourceAccount: "85-150",
+			TargetAccount: "43-812",
+			AmountCents:   750,
+		}).Return(&StartSettlementResult{SettlementUUID: "settlement-1"}, nil)
+
+		env.OnWorkflow(MoneyTransferWorkflow, mock.Anything, TransferDetails{
+			SourceAccount: "85-150",
+			TargetAccount: "43-812",
+			Amount:        750,
+			ReferenceID:   "settlement-1",
+		}).Return("Transfer complete (transaction IDs: W1, D2)", nil)
+
+		env.OnActivity(activities.CompleteSettlement, mock.Anything, CompleteSettlementInput{
+			BillUUID:       "bill-123",
+			SettlementUUID: "settlement-1",
+			AmountCents:    750,
+			Confirmation:   "Transfer complete (transaction IDs: W1, D2)",
+		}).Return(&CompleteSettlementResult{PaymentUUID: "payment-1"}, nil)
+
+		env.OnSignalExternalWorkflow(mock.Anything, DunningWorkflowID("bill-123"), "", SignalPaymentReceived,
+			PaymentReceivedSignal{PaymentUUID: "payment-1"}).Return(nil)
+
+		env.ExecuteWorkflow(SettlementWorkflow, SettlementWorkflowInput{BillUUID: "bill-123", Accounts: accounts})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result SettlementWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, entity.SettlementStatusSettled, result.Status)
+		assert.Equal(t, int64(750), result.AmountCents)
+		assert.Equal(t, "payment-1", result.PaymentUUID)
+		env.AssertExpectations(t)
+	})
+
+	t.Run("success - failed transfer marks the settlement failed", func(t *testing.T) {
+		activities := &BillActivities{}
+		env := newEnv(activities)
+
+		env.OnActivity(activities.StartSettlement, mock.Anything, mock.Anything).
+			Return(&StartSettlementResult{SettlementUUID: "settlement-1"}, nil)
+		env.OnWorkflow(MoneyTransferWorkflow, mock.Anything, mock.Anything).
+			Return("", errors.New("Deposit: failed to deposit money into 43-812: Money returned to 85-150"))
+
+		var failed FailSettlementInput
+		env.OnActivity(activities.FailSettlement, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input FailSettlementInput) error {
+				failed = input
+				return nil
+			})
+
+		env.ExecuteWorkflow(SettlementWorkflow, SettlementWorkflowInput{BillUUID: "bill-123", Accounts: accounts})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result SettlementWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, entity.SettlementStatusFailed, result.Status)
+		assert.Empty(t, result.PaymentUUID)
+		assert.Equal(t, "bill-123", failed.BillUUID)
+		assert.Contains(t, failed.Reason, "Money returned to 85-150")
+	})
+
+	t.Run("success - payment the bill no longer accepts becomes customer credit", func(t *testing.T) {
+		activities := &BillActivities{}
+		env := newEnv(activities)
+
+		env.OnActivity(activities.StartSettlement, mock.Anything, mock.Anything).
+			Return(&StartSettlementResult{SettlementUUID: "settlement-1"}, nil)
+		env.OnWorkflow(MoneyTransferWorkflow, mock.Anything, mock.Anything).
+			Return("Transfer complete", nil)
+		env.OnActivity(activities.CompleteSettlement, mock.Anything, mock.Anything).
+			Return(nil, temporal.NewNonRetryableApplicationError("payment exceeds the bill balance", ErrTypeSettlementNotRecorded, nil))
+
+		// the money already moved, so the settlement is not a plain failure
+		var credited CreditSettlementInput
+		env.OnActivity(activities.CreditSettlement, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input CreditSettlementInput) (*CreditSettlementResult, error) {
+				credited = input
+				return &CreditSettlementResult{CreditUUID: "credit-1"}, nil
+			})
+
+		env.ExecuteWorkflow(SettlementWorkflow, SettlementWorkflowInput{BillUUID: "bill-123", Accounts: accounts})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result SettlementWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, entity.SettlementStatusCredited, result.Status)
+		assert.Equal(t, "credit-1", result.CreditUUID)
+		assert.Equal(t, int64(750), credited.AmountCents)
+		assert.Equal(t, "settlement-1", credited.SettlementUUID)
+		assert.Contains(t, credited.Reason, "payment exceeds the bill balance")
+	})
+
+	t.Run("success - paid bill is not settled", func(t *testing.T) {
+		activities := &BillActivities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+
+		env.OnActivity(activities.FetchBillBalance, mock.Anything, mock.Anything).
+			Return(&FetchBillBalanceResult{Status: entity.BillStatusPaid, TotalCents: 1000, PaidCents: 1000}, nil)
+
+		env.ExecuteWorkflow(SettlementWorkflow, SettlementWorkflowInput{BillUUID: "bill-123", Accounts: accounts})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result SettlementWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Empty(t, result.Status)
+	})
+}
+
+func TestCreditNoteWorkflow(t *testing.T) {
+	t.Run("success - applies the note as customer credit", func(t *testing.T) {
+		activities := &BillActivities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+
+		env.OnActivity(activities.ApplyCreditNote, mock.Anything, ApplyCreditNoteInput{CreditNoteUUID: "note-123"}).
+			Return(&ApplyCreditNoteResult{CreditUUID: "credit-1", Currency: "GEL", AmountCents: 700}, nil)
+
+		env.ExecuteWorkflow(CreditNoteWorkflow, CreditNoteWorkflowInput{CreditNoteUUID: "note-123"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result CreditNoteWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, CreditNoteWorkflowResult{CreditUUID: "credit-1", Currency: "GEL", AmountCents: 700}, result)
+	})
+
+	t.Run("error - apply fails", func(t *testing.T) {
+		activities := &BillActivities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+
+		env.OnActivity(activities.ApplyCreditNote, mock.Anything, mock.Anything).
+			Return(nil, temporal.NewNonRetryableApplicationError("credit note not found", "NotFound", nil))
+
+		env.ExecuteWorkflow(CreditNoteWorkflow, CreditNoteWorkflowInput{CreditNoteUUID: "note-123"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		assert.Error(t, env.GetWorkflowError())
+	})
+}
+
+// recordingNotifier collects the notifications it is asked to send.
+type recordingNotifier struct {
+	sent []notify.Notification
+	err  error
+}
+
+func (n *recordingNotifier) Channel() string {
+	return "recording"
+}
+
+func (n *recordingNotifier) Send(_ context.Context, notification notify.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestRateTableTaxCalculator(t *testing.T) {
+	calculator := &RateTableTaxCalculator{Rates: TaxRateTable{
+		"USD": {
+			entity.FeeTypeACH:          250,
+			entity.FeeTypeWireTransfer: 1000,
+			entity.FeeTypeTax:          1000,
+		},
+	}}
+
+	t.Run("success - taxes configured fee types in order", func(t *testing.T) {
+		lines, err := calculator.Calculate(context.Background(), "USD", map[entity.FeeType]int64{
+			entity.FeeTypeWireTransfer: 5000,
+			entity.FeeTypeACH:          1002,
+			entity.FeeTypeMonthlyFee:   900,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+		assert.Equal(t, TaxLine{FeeType: entity.FeeTypeACH, BasisPoints: 250, AmountCents: 25}, lines[0])
+		assert.Equal(t, TaxLine{FeeType: entity.FeeTypeWireTransfer, BasisPoints: 1000, AmountCents: 500}, lines[1])
+	})
+
+	t.Run("success - skips tax, credits and unknown currencies", func(t *testing.T) {
+		lines, err := calculator.Calculate(context.Background(), "USD", map[entity.FeeType]int64{
+			entity.FeeTypeTax: 500,
+			entity.FeeTypeACH: -1000,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+
+		lines, err = calculator.Calculate(context.Background(), "GEL", map[entity.FeeType]int64{
+			entity.FeeTypeACH: 1000,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+}
+
+func TestCalculateDiscounts(t *testing.T) {
+	percent := func(bps int64) *entity.PromotionEntity {
+		return &entity.PromotionEntity{UUID: "percent", Code: "PCT", Kind: entity.PromotionKindPercent, PercentBps: &bps}
+	}
+	fixed := func(amountCents int64, currency string) *entity.PromotionEntity {
+		return &entity.PromotionEntity{UUID: "fixed", Code: "FIX", Kind: entity.PromotionKindFixed, AmountCents: &amountCents, Currency: &currency}
+	}
+
+	t.Run("success - applies in order to what is left", func(t *testing.T) {
+		lines := calculateDiscounts(10000, "USD", []*entity.PromotionEntity{fixed(1000, "USD"), percent(1000)})
+
+		require.Len(t, lines, 2)
+		assert.Equal(t, int64(1000), lines[0].AmountCents)
+		assert.Equal(t, int64(900), lines[1].AmountCents)
+		assert.Equal(t, int64(1000), lines[1].PercentBps)
+	})
+
+	t.Run("success - rounds percentages half up", func(t *testing.T) {
+		lines := calculateDiscounts(1005, "USD", []*entity.PromotionEntity{percent(1000)})
+
+		require.Len(t, lines, 1)
+		assert.Equal(t, int64(101), lines[0].AmountCents)
+	})
+
+	t.Run("success - never takes the total below zero", func(t *testing.T) {
+		lines := calculateDiscounts(500, "USD", []*entity.PromotionEntity{fixed(800, "USD"), percent(5000)})
+
+		require.Len(t, lines, 1)
+		assert.Equal(t, int64(500), lines[0].AmountCents)
+	})
+
+	t.Run("success - skips fixed discounts in another currency and credit totals", func(t *testing.T) {
+		assert.Empty(t, calculateDiscounts(1000, "GEL", []*entity.PromotionEntity{fixed(100, "USD")}))
+		assert.Empty(t, calculateDiscounts(-100, "USD", []*entity.PromotionEntity{percent(1000)}))
 	})
 }
@@ -1,5 +1,7 @@
 package temporal
 
+import "encore.app/temporal/bill"
+
 const TaskQueue = "billing-task-queue"
 
-const BillWorkflowIDPrefix = "bill-"
+const BillWorkflowIDPrefix = bill.WorkflowIDPrefix
@@ -20,6 +20,9 @@ type WorkflowClient interface {
 
 	// QueryWorkflow queries a workflow's state.
 	QueryWorkflow(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (converter.EncodedValue, error)
+
+	// UpdateWorkflow sends an update to a running workflow and returns a handle to its outcome.
+	UpdateWorkflow(ctx context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error)
 }
 
 // Ensure the real Temporal client satisfies our interface.
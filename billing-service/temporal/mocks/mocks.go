@@ -95,3 +95,18 @@ func (mr *MockWorkflowClientMockRecorder) SignalWorkflow(ctx, workflowID, runID,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignalWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).SignalWorkflow), ctx, workflowID, runID, signalName, arg)
 }
+
+// UpdateWorkflow mocks base method.
+func (m *MockWorkflowClient) UpdateWorkflow(ctx context.Context, options client.UpdateWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkflow", ctx, options)
+	ret0, _ := ret[0].(client.WorkflowUpdateHandle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWorkflow indicates an expected call of UpdateWorkflow.
+func (mr *MockWorkflowClientMockRecorder) UpdateWorkflow(ctx, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).UpdateWorkflow), ctx, options)
+}
@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.app/db/repository"
+)
+
+type Activities struct {
+	Repo  repository.OutboxRepository
+	Sinks []Sink
+}
+
+type RelayInput struct {
+	BatchSize int
+}
+
+type RelayResult struct {
+	Published int
+}
+
+// Relay publishes the oldest unpublished events to every sink, in id order, and
+// marks each one published once all sinks accepted it. Ids are in commit order
+// per aggregate, so each bill's events go out in the order they happened;
+// across bills there is no order. A failing sink stops the batch, so no event
+// is published ahead of an earlier one of its aggregate; the retried
+// activity publishes the failed event again, including to the sinks that
+// already took it.
+func (a *Activities) Relay(ctx context.Context, input RelayInput) (*RelayResult, error) {
+	events, err := a.Repo.FetchUnpublished(ctx, input.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		msg := NewMessage(event)
+		for _, sink := range a.Sinks {
+			if err := sink.Publish(ctx, msg); err != nil {
+				return nil, fmt.Errorf("publish event %s to %s sink: %w", msg.ID, sink.Name(), err)
+			}
+		}
+
+		if err := a.Repo.MarkPublished(ctx, event.ID, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RelayResult{Published: len(events)}, nil
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import "context"
+
+// ChannelSink hands messages to an in-process consumer reading C.
+type ChannelSink struct {
+	C chan Message
+}
+
+// Ensure ChannelSink implements Sink.
+var _ Sink = (*ChannelSink)(nil)
+
+// NewChannelSink creates a channel sink buffering up to size messages.
+func NewChannelSink(size int) *ChannelSink {
+	return &ChannelSink{C: make(chan Message, size)}
+}
+
+func (s *ChannelSink) Name() string {
+	return "channel"
+}
+
+// Publish blocks until the consumer has room for the message, so a slow
+// consumer holds the relay back instead of losing events.
+func (s *ChannelSink) Publish(ctx context.Context, msg Message) error {
+	select {
+	case s.C <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	kafkaTimeout = 10 * time.Second
+
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// KafkaSink produces messages through the REST produce API that Confluent's
+// REST proxy and Redpanda's HTTP proxy both serve. Records are keyed on the
+// aggregate UUID, so the events of one bill stay ordered in one partition.
+type KafkaSink struct {
+	URL   string
+	Topic string
+
+	// Client defaults to an http.Client with a 10 second timeout
+	Client *http.Client
+}
+
+// Ensure KafkaSink implements Sink.
+var _ Sink = (*KafkaSink)(nil)
+
+type kafkaRecord struct {
+	Key   string  `json:"key"`
+	Value Message `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(kafkaProduceRequest{
+		Records: []kafkaRecord{{Key: msg.AggregateUUID, Value: msg}},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimRight(s.URL, "/") + "/topics/" + url.PathEscape(s.Topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: kafkaTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("kafka proxy responded %d", resp.StatusCode)
+	}
+
+	// the proxy answers 200 even when a record was rejected, the offsets say
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return err
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("kafka rejected event %s: %s", msg.ID, offset.Error)
+		}
+	}
+	return nil
+}
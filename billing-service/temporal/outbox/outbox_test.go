@@ -0,0 +1,257 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/entity"
+	temporalmocks "encore.app/temporal/mocks"
+	"encore.app/temporal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+func outboxEvent(id int64, eventUUID string) *entity.OutboxEventEntity {
+	return &entity.OutboxEventEntity{
+		ID:            id,
+		UUID:          eventUUID,
+		AggregateUUID: "bill-123",
+		EventType:     "line_item.persisted",
+		Payload:       []byte(`{"uuid":"item-1"}`),
+		CreatedAt:     time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestActivities_Relay(t *testing.T) {
+	t.Run("publishes in order and marks each event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxRepository(ctrl)
+		first, second := &recordingSink{}, &recordingSink{}
+		activities := &Activities{Repo: mockRepo, Sinks: []Sink{first, second}}
+
+		mockRepo.EXPECT().
+			FetchUnpublished(gomock.Any(), 10).
+			Return([]*entity.OutboxEventEntity{outboxEvent(1, "event-1"), outboxEvent(2, "event-2")}, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().MarkPublished(gomock.Any(), int64(1), gomock.Any()).Return(nil),
+			mockRepo.EXPECT().MarkPublished(gomock.Any(), int64(2), gomock.Any()).Return(nil),
+		)
+
+		result, err := activities.Relay(context.Background(), RelayInput{BatchSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Published)
+		for _, sink := range []*recordingSink{first, second} {
+			require.Len(t, sink.published, 2)
+			assert.Equal(t, "event-1", sink.published[0].ID)
+			assert.Equal(t, "event-2", sink.published[1].ID)
+		}
+	})
+
+	t.Run("a failing sink stops the batch before later events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxRepository(ctrl)
+		sink := &recordingSink{failOn: "event-2"}
+		activities := &Activities{Repo: mockRepo, Sinks: []Sink{sink}}
+
+		mockRepo.EXPECT().
+			FetchUnpublished(gomock.Any(), 10).
+			Return([]*entity.OutboxEventEntity{outboxEvent(1, "event-1"), outboxEvent(2, "event-2"), outboxEvent(3, "event-3")}, nil)
+		mockRepo.EXPECT().MarkPublished(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+
+		result, err := activities.Relay(context.Background(), RelayInput{BatchSize: 10})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, assert.AnError)
+		require.Len(t, sink.published, 1)
+	})
+}
+
+func TestSinks(t *testing.T) {
+	msg := NewMessage(outboxEvent(1, "event-1"))
+
+	t.Run("channel sink hands the message over", func(t *testing.T) {
+		sink := NewChannelSink(1)
+
+		require.NoError(t, sink.Publish(context.Background(), msg))
+		assert.Equal(t, msg, <-sink.C)
+	})
+
+	t.Run("channel sink gives up when the context ends", func(t *testing.T) {
+		sink := NewChannelSink(0)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorIs(t, sink.Publish(ctx, msg), context.Canceled)
+	})
+
+	t.Run("file sink appends json lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		sink := &FileSink{Path: path}
+
+		require.NoError(t, sink.Publish(context.Background(), msg))
+		require.NoError(t, sink.Publish(context.Background(), NewMessage(outboxEvent(2, "event-2"))))
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		var ids []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var decoded Message
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &decoded))
+			ids = append(ids, decoded.ID)
+		}
+		assert.Equal(t, []string{"event-1", "event-2"}, ids)
+	})
+
+	t.Run("kafka sink produces a record keyed on the aggregate", func(t *testing.T) {
+		var (
+			path, contentType string
+			produced          kafkaProduceRequest
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			contentType = r.Header.Get("Content-Type")
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &produced)
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`))
+		}))
+		defer server.Close()
+
+		sink := &KafkaSink{URL: server.URL + "/", Topic: "billing-events"}
+
+		require.NoError(t, sink.Publish(context.Background(), msg))
+		assert.Equal(t, "/topics/billing-events", path)
+		assert.Equal(t, kafkaContentType, contentType)
+		require.Len(t, produced.Records, 1)
+		assert.Equal(t, "bill-123", produced.Records[0].Key)
+		assert.Equal(t, "event-1", produced.Records[0].Value.ID)
+	})
+
+	t.Run("kafka sink fails on a rejected record", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"broker unavailable"}]}`))
+		}))
+		defer server.Close()
+
+		sink := &KafkaSink{URL: server.URL, Topic: "billing-events"}
+
+		assert.ErrorContains(t, sink.Publish(context.Background(), msg), "broker unavailable")
+	})
+
+	t.Run("webhook sink keeps the event id", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		sink := &WebhookSink{Publisher: publisher}
+
+		require.NoError(t, sink.Publish(context.Background(), msg))
+		require.Len(t, publisher.published, 1)
+		assert.Equal(t, "event-1", publisher.published[0].ID)
+		assert.Equal(t, entity.WebhookEventLineItemPersisted, publisher.published[0].Type)
+		assert.Equal(t, json.RawMessage(`{"uuid":"item-1"}`), publisher.published[0].Data)
+	})
+}
+
+func TestNewSink(t *testing.T) {
+	sink, err := NewSink("kafka", SinkConfig{KafkaURL: "http://localhost:8082", KafkaTopic: "billing-events"})
+	require.NoError(t, err)
+	assert.Equal(t, "kafka", sink.Name())
+
+	_, err = NewSink("file", SinkConfig{})
+	assert.Error(t, err)
+
+	_, err = NewSink("queue", SinkConfig{})
+	assert.Error(t, err)
+}
+
+func TestRelayWorkflow(t *testing.T) {
+	t.Run("polls while the outbox is drained and continues as new", func(t *testing.T) {
+		activities := &Activities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.Relay)
+
+		batches := 0
+		env.OnActivity(activities.Relay, mock.Anything, RelayInput{BatchSize: 2}).
+			Return(func(_ context.Context, _ RelayInput) (*RelayResult, error) {
+				batches++
+				// a full batch first, then the outbox is empty
+				if batches == 1 {
+					return &RelayResult{Published: 2}, nil
+				}
+				return &RelayResult{}, nil
+			})
+
+		env.ExecuteWorkflow(RelayWorkflow, RelayWorkflowInput{BatchSize: 2, PollInterval: time.Second})
+
+		require.True(t, env.IsWorkflowCompleted())
+		err := env.GetWorkflowError()
+		var continueAsNew *workflow.ContinueAsNewError
+		require.ErrorAs(t, err, &continueAsNew)
+		assert.Equal(t, relayBatchesPerRun, batches)
+	})
+}
+
+func TestStartRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStarter := temporalmocks.NewMockWorkflowClient(ctrl)
+
+	mockStarter.EXPECT().
+		ExecuteWorkflow(gomock.Any(), client.StartWorkflowOptions{ID: RelayWorkflowID, TaskQueue: "queue"},
+			gomock.Any(), RelayWorkflowInput{}).
+		Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("running", "", ""))
+
+	require.NoError(t, StartRelay(context.Background(), mockStarter, "queue", RelayWorkflowInput{}))
+}
+
+// recordingSink collects the messages it accepts and fails on failOn.
+type recordingSink struct {
+	published []Message
+	failOn    string
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(_ context.Context, msg Message) error {
+	if msg.ID == s.failOn {
+		return assert.AnError
+	}
+	s.published = append(s.published, msg)
+	return nil
+}
+
+// recordingPublisher collects the webhook events it is asked to publish.
+type recordingPublisher struct {
+	published []webhook.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event webhook.Event) error {
+	p.published = append(p.published, event)
+	return nil
+}
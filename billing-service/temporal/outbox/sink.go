@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"encore.app/entity"
+)
+
+// Message is an outbox event as handed to sinks. ID is stable across relays of
+// the same event, consumers use it to drop the duplicates a retried relay sends.
+type Message struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateUUID string          `json:"aggregateUuid"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewMessage builds the message of an outbox row.
+func NewMessage(e *entity.OutboxEventEntity) Message {
+	return Message{
+		ID:            e.UUID,
+		Type:          e.EventType,
+		AggregateUUID: e.AggregateUUID,
+		CreatedAt:     e.CreatedAt.UTC(),
+		Data:          json.RawMessage(e.Payload),
+	}
+}
+
+// Sink receives the relayed events. Publish returns only once the sink has
+// accepted the message; an error makes the relay retry it.
+type Sink interface {
+	// Name identifies the sink in logs and errors
+	Name() string
+	Publish(ctx context.Context, msg Message) error
+}
+
+// SinkConfig holds the settings of the configurable sinks.
+type SinkConfig struct {
+	// KafkaURL is the base URL of a Kafka REST proxy, KafkaTopic the topic written to
+	KafkaURL   string
+	KafkaTopic string
+
+	// FilePath is the file the file sink appends to
+	FilePath string
+}
+
+// NewSink builds a configured sink: kafka or file. The channel sink hands
+// messages to in-process consumers and is wired in code with NewChannelSink.
+func NewSink(kind string, cfg SinkConfig) (Sink, error) {
+	switch kind {
+	case "kafka":
+		if cfg.KafkaURL == "" || cfg.KafkaTopic == "" {
+			return nil, fmt.Errorf("kafka sink requires a URL and a topic")
+		}
+		return &KafkaSink{URL: cfg.KafkaURL, Topic: cfg.KafkaTopic}, nil
+	case "file":
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("file sink requires a path")
+		}
+		return &FileSink{Path: cfg.FilePath}, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", kind)
+	}
+}
@@ -0,0 +1,36 @@
+package outbox
+
+import (
+	"context"
+
+	"encore.app/entity"
+	"encore.app/temporal/webhook"
+)
+
+// EventPublisher fans an event out to webhook subscribers; webhook.Publisher
+// implements it.
+type EventPublisher interface {
+	Publish(ctx context.Context, event webhook.Event) error
+}
+
+// WebhookSink hands messages to the webhook subscriptions. The event ID is the
+// message ID, so a relayed duplicate maps onto the deliveries already recorded.
+type WebhookSink struct {
+	Publisher EventPublisher
+}
+
+// Ensure WebhookSink implements Sink.
+var _ Sink = (*WebhookSink)(nil)
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, msg Message) error {
+	return s.Publisher.Publish(ctx, webhook.Event{
+		ID:        msg.ID,
+		Type:      entity.WebhookEventType(msg.Type),
+		CreatedAt: msg.CreatedAt,
+		Data:      msg.Data,
+	})
+}
@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.app/temporal/webhook"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RelayWorkflowID names the single relay workflow; one reader keeps each
+// aggregate's events published in order.
+const RelayWorkflowID = "billing-outbox-relay"
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+
+	// relayBatchesPerRun bounds the history of one run before it continues as new
+	relayBatchesPerRun = 500
+)
+
+// relayActivityOptions retry a failing batch until the sinks recover; the
+// relay only falls behind, it never skips an event.
+func relayActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+		},
+	}
+}
+
+type RelayWorkflowInput struct {
+	BatchSize    int
+	PollInterval time.Duration
+}
+
+func (in RelayWorkflowInput) withDefaults() RelayWorkflowInput {
+	if in.BatchSize <= 0 {
+		in.BatchSize = defaultBatchSize
+	}
+	if in.PollInterval <= 0 {
+		in.PollInterval = defaultPollInterval
+	}
+	return in
+}
+
+// RelayWorkflow drains the outbox batch by batch, sleeping for the poll
+// interval whenever a batch comes back short of full.
+func RelayWorkflow(ctx workflow.Context, input RelayWorkflowInput) error {
+	input = input.withDefaults()
+	relayCtx := workflow.WithActivityOptions(ctx, relayActivityOptions())
+
+	for i := 0; i < relayBatchesPerRun; i++ {
+		var result RelayResult
+		err := workflow.ExecuteActivity(relayCtx, (*Activities).Relay, RelayInput{
+			BatchSize: input.BatchSize,
+		}).Get(ctx, &result)
+		if err != nil {
+			return err
+		}
+
+		if result.Published < input.BatchSize {
+			if err := workflow.Sleep(ctx, input.PollInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return workflow.NewContinueAsNewError(ctx, RelayWorkflow, input)
+}
+
+// StartRelay starts the relay workflow unless it is already running.
+func StartRelay(ctx context.Context, starter webhook.WorkflowStarter, taskQueue string, input RelayWorkflowInput) error {
+	_, err := starter.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        RelayWorkflowID,
+		TaskQueue: taskQueue,
+	}, RelayWorkflow, input)
+
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/entity"
+	"encore.app/temporal/bill"
+	"github.com/google/uuid"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/converter"
+)
+
+// BillStateQuerier queries bill workflows; temporal.WorkflowClient satisfies it.
+type BillStateQuerier interface {
+	QueryWorkflow(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (converter.EncodedValue, error)
+}
+
+type Activities struct {
+	Repo         repository.ReconciliationRepository
+	LineItemRepo repository.LineItemRepository
+	Workflows    BillStateQuerier
+}
+
+type ReconcileInput struct {
+	AfterID     int64
+	ClosedSince time.Time
+	BatchSize   int
+
+	// AutoRepair sets total_cents to the ledger total where they disagree
+	AutoRepair bool
+}
+
+type ReconcileResult struct {
+	// LastID is the id of the last bill checked, the cursor of the next batch
+	LastID   int64
+	Checked  int
+	Findings int
+	Repaired int
+}
+
+// Reconcile checks the next batch of bills. The ledger is taken as the truth:
+// bills.total_cents and the sum of the line items are compared against the
+// bill's ledger total for every bill, the total the bill workflow holds against
+// the line items for open bills only, as closed bills are no longer tracked by
+// their workflow. Discrepancies are recorded as open findings, findings no
+// longer seen are resolved.
+func (a *Activities) Reconcile(ctx context.Context, input ReconcileInput) (*ReconcileResult, error) {
+	bills, err := a.Repo.FetchBillTotals(ctx, input.AfterID, input.ClosedSince, input.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{LastID: input.AfterID, Checked: len(bills)}
+	for _, totals := range bills {
+		now := time.Now().UTC()
+		if err := a.reconcileBillTotal(ctx, totals, input.AutoRepair, now, result); err != nil {
+			return nil, err
+		}
+		if err := a.reconcileLedger(ctx, totals, now, result); err != nil {
+			return nil, err
+		}
+		if totals.Status == entity.BillStatusOpen {
+			if err := a.reconcileWorkflowTotal(ctx, totals, now, result); err != nil {
+				return nil, err
+			}
+		}
+		result.LastID = totals.ID
+	}
+	return result, nil
+}
+
+func (a *Activities) reconcileBillTotal(ctx context.Context, totals *entity.BillTotalsEntity, autoRepair bool, now time.Time, result *ReconcileResult) error {
+	if totals.TotalCents == totals.LedgerCents {
+		return a.Repo.ResolveFindings(ctx, totals.BillUUID, []entity.ReconciliationFindingKind{entity.FindingBillTotalMismatch}, now)
+	}
+
+	finding := newFinding(totals, entity.FindingBillTotalMismatch, now)
+	if autoRepair {
+		repaired, err := a.Repo.RepairBillTotal(ctx, finding)
+		if err != nil {
+			return err
+		}
+		if repaired {
+			result.Repaired++
+			return nil
+		}
+		// a line item landing since the totals were read made them agree
+		return a.Repo.ResolveFindings(ctx, totals.BillUUID, []entity.ReconciliationFindingKind{entity.FindingBillTotalMismatch}, now)
+	}
+
+	result.Findings++
+	return a.Repo.RecordFinding(ctx, finding)
+}
+
+// reconcileLedger compares the bill's line items with what the ledger posted
+// for them. It is never repaired automatically, the ledger is append-only and
+// a line item missing its posting needs a person to look at it.
+func (a *Activities) reconcileLedger(ctx context.Context, totals *entity.BillTotalsEntity, now time.Time, result *ReconcileResult) error {
+	if totals.LineItemsCents == totals.LedgerCents {
+		return a.Repo.ResolveFindings(ctx, totals.BillUUID, []entity.ReconciliationFindingKind{entity.FindingLedgerMismatch}, now)
+	}
+
+	result.Findings++
+	return a.Repo.RecordFinding(ctx, newFinding(totals, entity.FindingLedgerMismatch, now))
+}
+
+// reconcileWorkflowTotal compares the open bill's workflow total with its line
+// items. Line items in flight make the two differ for a moment, so the check
+// is skipped while the workflow has pending line items or the sum moved since
+// the batch was read; the next run looks again.
+func (a *Activities) reconcileWorkflowTotal(ctx context.Context, totals *entity.BillTotalsEntity, now time.Time, result *ReconcileResult) error {
+	workflowKinds := []entity.ReconciliationFindingKind{entity.FindingWorkflowTotalMismatch, entity.FindingWorkflowNotFound}
+
+	resp, err := a.Workflows.QueryWorkflow(ctx, bill.WorkflowIDPrefix+totals.BillUUID, "", bill.QueryGetBillState)
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+		result.Findings++
+		return a.Repo.RecordFinding(ctx, newFinding(totals, entity.FindingWorkflowNotFound, now))
+	}
+
+	var state bill.BillStateQuery
+	if err := resp.Get(&state); err != nil {
+		return err
+	}
+	if len(state.PendingLineItems) > 0 {
+		return nil
+	}
+
+	sums, err := a.LineItemRepo.SumByFeeType(ctx, totals.BillUUID)
+	if err != nil {
+		return err
+	}
+	var lineItemsCents int64
+	for _, cents := range sums {
+		lineItemsCents += cents
+	}
+	if lineItemsCents != totals.LineItemsCents {
+		return nil
+	}
+
+	if state.TotalCents == totals.LineItemsCents {
+		return a.Repo.ResolveFindings(ctx, totals.BillUUID, workflowKinds, now)
+	}
+
+	// the workflow answered, so it was found again
+	err = a.Repo.ResolveFindings(ctx, totals.BillUUID, []entity.ReconciliationFindingKind{entity.FindingWorkflowNotFound}, now)
+	if err != nil {
+		return err
+	}
+
+	finding := newFinding(totals, entity.FindingWorkflowTotalMismatch, now)
+	finding.WorkflowTotalCents = &state.TotalCents
+	result.Findings++
+	return a.Repo.RecordFinding(ctx, finding)
+}
+
+func newFinding(totals *entity.BillTotalsEntity, kind entity.ReconciliationFindingKind, seenAt time.Time) *entity.ReconciliationFindingEntity {
+	return &entity.ReconciliationFindingEntity{
+		UUID:           uuid.New().String(),
+		BillUUID:       totals.BillUUID,
+		Kind:           kind,
+		Status:         entity.FindingStatusOpen,
+		BillStatus:     totals.Status,
+		BillTotalCents: totals.TotalCents,
+		LineItemsCents: totals.LineItemsCents,
+		LedgerCents:    &totals.LedgerCents,
+		LastSeenAt:     seenAt,
+	}
+}
@@ -0,0 +1,303 @@
+package reconcile
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/entity"
+	"encore.app/temporal/bill"
+	temporalmocks "encore.app/temporal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+// encodedValue hands a query result to QueryWorkflow callers.
+type encodedValue struct {
+	value interface{}
+}
+
+func (v *encodedValue) Get(valuePtr interface{}) error {
+	reflect.ValueOf(valuePtr).Elem().Set(reflect.ValueOf(v.value))
+	return nil
+}
+
+func (v *encodedValue) HasValue() bool {
+	return v.value != nil
+}
+
+func billState(totalCents int64, pending ...bill.AddLineItemSignal) converter.EncodedValue {
+	return &encodedValue{value: bill.BillStateQuery{Status: "OPEN", TotalCents: totalCents, PendingLineItems: pending}}
+}
+
+var closedSince = time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC)
+
+type reconcileMocks struct {
+	repo      *mocks.MockReconciliationRepository
+	lineItems *mocks.MockLineItemRepository
+	workflows *temporalmocks.MockWorkflowClient
+}
+
+func newActivities(ctrl *gomock.Controller) (*Activities, reconcileMocks) {
+	m := reconcileMocks{
+		repo:      mocks.NewMockReconciliationRepository(ctrl),
+		lineItems: mocks.NewMockLineItemRepository(ctrl),
+		workflows: temporalmocks.NewMockWorkflowClient(ctrl),
+	}
+	return &Activities{Repo: m.repo, LineItemRepo: m.lineItems, Workflows: m.workflows}, m
+}
+
+func (m reconcileMocks) expectBills(bills ...*entity.BillTotalsEntity) {
+	m.repo.EXPECT().
+		FetchBillTotals(gomock.Any(), int64(0), closedSince, 10).
+		Return(bills, nil)
+}
+
+func (m reconcileMocks) expectWorkflow(billUUID string, state converter.EncodedValue, lineItemsCents int64) {
+	m.workflows.EXPECT().
+		QueryWorkflow(gomock.Any(), "bill-"+billUUID, "", bill.QueryGetBillState).
+		Return(state, nil)
+	m.lineItems.EXPECT().
+		SumByFeeType(gomock.Any(), billUUID).
+		Return(map[string]int64{"ACH": lineItemsCents}, nil)
+}
+
+func kinds(k ...entity.ReconciliationFindingKind) []entity.ReconciliationFindingKind {
+	return k
+}
+
+func TestActivities_Reconcile(t *testing.T) {
+	input := ReconcileInput{ClosedSince: closedSince, BatchSize: 10}
+
+	t.Run("agreeing totals resolve earlier findings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusOpen, TotalCents: 1500, LineItemsCents: 1500, LedgerCents: 1500})
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingBillTotalMismatch), gomock.Any()).Return(nil)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil)
+		m.expectWorkflow("bill-1", billState(1500), 1500)
+		m.repo.EXPECT().
+			ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingWorkflowTotalMismatch, entity.FindingWorkflowNotFound), gomock.Any()).
+			Return(nil)
+
+		result, err := activities.Reconcile(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, &ReconcileResult{LastID: 7, Checked: 1}, result)
+	})
+
+	t.Run("records a bill total that drifted from its ledger total", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusClosed, TotalCents: 2000, LineItemsCents: 1500, LedgerCents: 1500})
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil)
+		m.repo.EXPECT().
+			RecordFinding(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, finding *entity.ReconciliationFindingEntity) error {
+				assert.Equal(t, "bill-1", finding.BillUUID)
+				assert.Equal(t, entity.FindingBillTotalMismatch, finding.Kind)
+				assert.Equal(t, entity.BillStatusClosed, finding.BillStatus)
+				assert.Equal(t, int64(2000), finding.BillTotalCents)
+				assert.Equal(t, int64(1500), finding.LineItemsCents)
+				require.NotNil(t, finding.LedgerCents)
+				assert.Equal(t, int64(1500), *finding.LedgerCents)
+				assert.Nil(t, finding.WorkflowTotalCents)
+				return nil
+			})
+
+		result, err := activities.Reconcile(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Findings)
+		assert.Equal(t, 0, result.Repaired)
+	})
+
+	t.Run("auto repair sets the bill total", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusClosed, TotalCents: 2000, LineItemsCents: 1500, LedgerCents: 1500})
+		m.repo.EXPECT().RepairBillTotal(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil)
+
+		result, err := activities.Reconcile(context.Background(), ReconcileInput{ClosedSince: closedSince, BatchSize: 10, AutoRepair: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Findings)
+		assert.Equal(t, 1, result.Repaired)
+	})
+
+	t.Run("records line items the ledger disagrees with, without repairing them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		// a voided line item's reversal posted to the ledger, the bill total followed the ledger
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusClosed, TotalCents: 1000, LineItemsCents: 1500, LedgerCents: 1000})
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingBillTotalMismatch), gomock.Any()).Return(nil)
+		m.repo.EXPECT().
+			RecordFinding(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, finding *entity.ReconciliationFindingEntity) error {
+				assert.Equal(t, entity.FindingLedgerMismatch, finding.Kind)
+				assert.Equal(t, int64(1500), finding.LineItemsCents)
+				require.NotNil(t, finding.LedgerCents)
+				assert.Equal(t, int64(1000), *finding.LedgerCents)
+				return nil
+			})
+
+		result, err := activities.Reconcile(context.Background(), ReconcileInput{ClosedSince: closedSince, BatchSize: 10, AutoRepair: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Findings)
+		assert.Equal(t, 0, result.Repaired)
+	})
+
+	t.Run("records a workflow total that drifted from the line items", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusOpen, TotalCents: 1500, LineItemsCents: 1500, LedgerCents: 1500})
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingBillTotalMismatch), gomock.Any()).Return(nil)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil)
+		m.expectWorkflow("bill-1", billState(1750), 1500)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingWorkflowNotFound), gomock.Any()).Return(nil)
+		m.repo.EXPECT().
+			RecordFinding(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, finding *entity.ReconciliationFindingEntity) error {
+				assert.Equal(t, entity.FindingWorkflowTotalMismatch, finding.Kind)
+				require.NotNil(t, finding.WorkflowTotalCents)
+				assert.Equal(t, int64(1750), *finding.WorkflowTotalCents)
+				return nil
+			})
+
+		result, err := activities.Reconcile(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Findings)
+	})
+
+	t.Run("line items in flight skip the workflow check", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(
+			&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusOpen, TotalCents: 1500, LineItemsCents: 1500, LedgerCents: 1500},
+			&entity.BillTotalsEntity{ID: 8, BillUUID: "bill-2", Status: entity.BillStatusOpen, TotalCents: 900, LineItemsCents: 900, LedgerCents: 900},
+		)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), gomock.Any(), kinds(entity.FindingBillTotalMismatch), gomock.Any()).Return(nil).Times(2)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), gomock.Any(), kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil).Times(2)
+		// bill-1 has a line item pending, a line item landed on bill-2 since the batch was read
+		m.workflows.EXPECT().
+			QueryWorkflow(gomock.Any(), "bill-bill-1", "", bill.QueryGetBillState).
+			Return(billState(1500, bill.AddLineItemSignal{UUID: "item-3", AmountCents: 250}), nil)
+		m.expectWorkflow("bill-2", billState(1150), 1150)
+
+		result, err := activities.Reconcile(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, &ReconcileResult{LastID: 8, Checked: 2}, result)
+	})
+
+	t.Run("records an open bill without a workflow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusOpen})
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingBillTotalMismatch), gomock.Any()).Return(nil)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil)
+		m.workflows.EXPECT().
+			QueryWorkflow(gomock.Any(), "bill-bill-1", "", bill.QueryGetBillState).
+			Return(nil, serviceerror.NewNotFound("workflow not found"))
+		m.repo.EXPECT().
+			RecordFinding(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, finding *entity.ReconciliationFindingEntity) error {
+				assert.Equal(t, entity.FindingWorkflowNotFound, finding.Kind)
+				return nil
+			})
+
+		result, err := activities.Reconcile(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Findings)
+	})
+
+	t.Run("a failing query fails the batch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		activities, m := newActivities(ctrl)
+
+		m.expectBills(&entity.BillTotalsEntity{ID: 7, BillUUID: "bill-1", Status: entity.BillStatusOpen})
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingBillTotalMismatch), gomock.Any()).Return(nil)
+		m.repo.EXPECT().ResolveFindings(gomock.Any(), "bill-1", kinds(entity.FindingLedgerMismatch), gomock.Any()).Return(nil)
+		m.workflows.EXPECT().
+			QueryWorkflow(gomock.Any(), "bill-bill-1", "", bill.QueryGetBillState).
+			Return(nil, assert.AnError)
+
+		_, err := activities.Reconcile(context.Background(), input)
+
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestReconciliationWorkflow(t *testing.T) {
+	t.Run("walks the bills batch by batch and continues as new", func(t *testing.T) {
+		activities := &Activities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.Reconcile)
+
+		var cursors []int64
+		env.OnActivity(activities.Reconcile, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, input ReconcileInput) (*ReconcileResult, error) {
+				assert.Equal(t, 2, input.BatchSize)
+				assert.True(t, input.AutoRepair)
+				cursors = append(cursors, input.AfterID)
+				// a full batch, then the last bills of the pass
+				if input.AfterID == 0 {
+					return &ReconcileResult{LastID: 5, Checked: 2}, nil
+				}
+				return &ReconcileResult{LastID: 9, Checked: 1}, nil
+			})
+
+		env.ExecuteWorkflow(ReconciliationWorkflow, ReconciliationWorkflowInput{BatchSize: 2, AutoRepair: true})
+
+		require.True(t, env.IsWorkflowCompleted())
+		var continueAsNew *workflow.ContinueAsNewError
+		require.ErrorAs(t, env.GetWorkflowError(), &continueAsNew)
+		require.Len(t, cursors, reconcileBatchesPerRun)
+		assert.Equal(t, []int64{0, 5, 0, 5}, cursors[:4])
+	})
+}
+
+func TestStartReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStarter := temporalmocks.NewMockWorkflowClient(ctrl)
+
+	mockStarter.EXPECT().
+		ExecuteWorkflow(gomock.Any(), client.StartWorkflowOptions{ID: ReconciliationWorkflowID, TaskQueue: "queue"},
+			gomock.Any(), ReconciliationWorkflowInput{}).
+		Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("running", "", ""))
+
+	require.NoError(t, StartReconciliation(context.Background(), mockStarter, "queue", ReconciliationWorkflowInput{}))
+}
@@ -0,0 +1,128 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ReconciliationWorkflowID names the single reconciliation workflow.
+const ReconciliationWorkflowID = "billing-reconciliation"
+
+const (
+	defaultBatchSize    = 100
+	defaultInterval     = time.Hour
+	defaultClosedWithin = 7 * 24 * time.Hour
+
+	// reconcileBatchesPerRun bounds the history of one run before it continues as new
+	reconcileBatchesPerRun = 500
+)
+
+// reconcileActivityOptions retry a failing batch until the database and
+// Temporal recover; the job only runs late, it never skips bills.
+func reconcileActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+		},
+	}
+}
+
+type ReconciliationWorkflowInput struct {
+	BatchSize int
+
+	// Interval is the pause between the end of one pass and the next
+	Interval time.Duration
+
+	// ClosedWithin is how long after their close bills are still checked
+	ClosedWithin time.Duration
+
+	AutoRepair bool
+
+	// AfterID and ClosedSince carry a pass that is halfway done across
+	// continue-as-new; a zero ClosedSince starts a new pass
+	AfterID     int64
+	ClosedSince time.Time
+}
+
+func (in ReconciliationWorkflowInput) withDefaults() ReconciliationWorkflowInput {
+	if in.BatchSize <= 0 {
+		in.BatchSize = defaultBatchSize
+	}
+	if in.Interval <= 0 {
+		in.Interval = defaultInterval
+	}
+	if in.ClosedWithin <= 0 {
+		in.ClosedWithin = defaultClosedWithin
+	}
+	return in
+}
+
+// ReconciliationWorkflow walks the open and recently closed bills batch by
+// batch, comparing their totals, and sleeps for the interval after each pass.
+func ReconciliationWorkflow(ctx workflow.Context, input ReconciliationWorkflowInput) error {
+	input = input.withDefaults()
+	logger := workflow.GetLogger(ctx)
+	reconcileCtx := workflow.WithActivityOptions(ctx, reconcileActivityOptions())
+
+	for i := 0; i < reconcileBatchesPerRun; i++ {
+		if input.ClosedSince.IsZero() {
+			input.ClosedSince = workflow.Now(ctx).Add(-input.ClosedWithin)
+		}
+
+		var result ReconcileResult
+		err := workflow.ExecuteActivity(reconcileCtx, (*Activities).Reconcile, ReconcileInput{
+			AfterID:     input.AfterID,
+			ClosedSince: input.ClosedSince,
+			BatchSize:   input.BatchSize,
+			AutoRepair:  input.AutoRepair,
+		}).Get(ctx, &result)
+		if err != nil {
+			return err
+		}
+
+		if result.Findings > 0 || result.Repaired > 0 {
+			logger.Warn("bill totals disagree",
+				"findings", result.Findings,
+				"repaired", result.Repaired)
+		}
+
+		if result.Checked < input.BatchSize {
+			input.AfterID, input.ClosedSince = 0, time.Time{}
+			if err := workflow.Sleep(ctx, input.Interval); err != nil {
+				return err
+			}
+			continue
+		}
+		input.AfterID = result.LastID
+	}
+
+	return workflow.NewContinueAsNewError(ctx, ReconciliationWorkflow, input)
+}
+
+// WorkflowStarter starts the reconciliation workflow; temporal.WorkflowClient satisfies it.
+type WorkflowStarter interface {
+	ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error)
+}
+
+// StartReconciliation starts the reconciliation workflow unless it is already running.
+func StartReconciliation(ctx context.Context, starter WorkflowStarter, taskQueue string, input ReconciliationWorkflowInput) error {
+	_, err := starter.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        ReconciliationWorkflowID,
+		TaskQueue: taskQueue,
+	}, ReconciliationWorkflow, input)
+
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"encore.app/db/repository"
+	"encore.app/entity"
+	"encore.dev/storage/sqldb"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrTypeSubscriptionDeleted stops the retries of a delivery whose subscription was deleted.
+const ErrTypeSubscriptionDeleted = "WebhookSubscriptionDeleted"
+
+const (
+	deliveryTimeout = 10 * time.Second
+
+	// maxLoggedResponse bounds the response body kept in the delivery log
+	maxLoggedResponse = 512
+)
+
+type Activities struct {
+	Repo repository.WebhookRepository
+
+	// Client defaults to an http.Client with a 10 second timeout
+	Client *http.Client
+}
+
+type DeliverInput struct {
+	DeliveryUUID string
+}
+
+type MarkFailedInput struct {
+	DeliveryUUID string
+}
+
+// Deliver posts the stored payload to the subscription and logs the attempt.
+// Any response outside 2xx fails the attempt so Temporal retries it.
+func (a *Activities) Deliver(ctx context.Context, input DeliverInput) error {
+	delivery, err := a.Repo.FetchDelivery(ctx, input.DeliveryUUID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == entity.WebhookDeliveryDelivered {
+		return nil
+	}
+
+	sub, err := a.Repo.FetchSubscription(ctx, delivery.SubscriptionUUID)
+	if err != nil {
+		return err
+	}
+	if sub.DeletedAt != nil {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("subscription %s deleted", sub.UUID), ErrTypeSubscriptionDeleted, nil)
+	}
+
+	now := time.Now().UTC()
+	statusCode, postErr := a.post(ctx, sub, delivery, now)
+
+	attempt := &entity.WebhookDeliveryAttemptEntity{
+		DeliveryUUID: delivery.UUID,
+		AttemptedAt:  now,
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	if postErr != nil {
+		msg := postErr.Error()
+		attempt.Error = &msg
+	}
+
+	if err := a.Repo.RecordAttempt(ctx, attempt, postErr == nil); err != nil {
+		return err
+	}
+	return postErr
+}
+
+// MarkFailed records that a delivery ran out of retries. A delivery that is no
+// longer pending, e.g. delivered by a replay, is left as is.
+func (a *Activities) MarkFailed(ctx context.Context, input MarkFailedInput) error {
+	err := a.Repo.UpdateDeliveryStatus(ctx, input.DeliveryUUID, entity.WebhookDeliveryPending, entity.WebhookDeliveryFailed)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func (a *Activities) post(ctx context.Context, sub *entity.WebhookSubscriptionEntity, delivery *entity.WebhookDeliveryEntity, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, now, delivery.Payload))
+	req.Header.Set(EventIDHeader, delivery.EventUUID)
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.UUID)
+
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: deliveryTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponse))
+		return resp.StatusCode, fmt.Errorf("subscriber responded %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, nil
+}
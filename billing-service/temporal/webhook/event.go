@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"encore.app/entity"
+	"github.com/google/uuid"
+)
+
+// eventNamespace seeds the deterministic UUIDs of deliveries.
+var eventNamespace = uuid.MustParse("5c8f3a2e-91d4-4b7a-8e16-2f0d6c4b9a71")
+
+// Event is a billing event as posted to subscribers.
+type Event struct {
+	ID        string                  `json:"id"`
+	Type      entity.WebhookEventType `json:"type"`
+	CreatedAt time.Time               `json:"createdAt"`
+	Data      any                     `json:"data"`
+}
+
+// DeliveryUUID derives the ID of an event's delivery to a subscription.
+func DeliveryUUID(subscriptionUUID, eventUUID string) string {
+	return uuid.NewSHA1(eventNamespace, []byte("delivery/"+subscriptionUUID+"/"+eventUUID)).String()
+}
+
+func (e Event) payload() ([]byte, error) {
+	return json.Marshal(e)
+}
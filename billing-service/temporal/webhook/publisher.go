@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+
+	"encore.app/db/repository"
+	"encore.app/entity"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+// DeliveryWorkflowIDPrefix is prepended to the delivery UUID to build the
+// delivery workflow ID, one running workflow per delivery.
+const DeliveryWorkflowIDPrefix = "webhook-delivery-"
+
+// WorkflowStarter starts delivery workflows; temporal.WorkflowClient satisfies it.
+type WorkflowStarter interface {
+	ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error)
+}
+
+// Publisher fans an event out to the subscriptions that selected it.
+type Publisher struct {
+	Repo      repository.WebhookRepository
+	Starter   WorkflowStarter
+	TaskQueue string
+}
+
+// Publish records a delivery per subscription and starts its workflow. Event
+// and delivery IDs are deterministic, so publishing the same event again only
+// restarts deliveries that are still pending and not running.
+func (p *Publisher) Publish(ctx context.Context, event Event) error {
+	subs, err := p.Repo.FetchActiveSubscriptions(ctx, event.Type.String())
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	payload, err := event.payload()
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		delivery := &entity.WebhookDeliveryEntity{
+			UUID:             DeliveryUUID(sub.UUID, event.ID),
+			SubscriptionUUID: sub.UUID,
+			EventUUID:        event.ID,
+			EventType:        event.Type.String(),
+			Payload:          payload,
+		}
+		if err := p.Repo.InsertDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		if delivery.Status != entity.WebhookDeliveryPending {
+			continue
+		}
+		if err := StartDelivery(ctx, p.Starter, p.TaskQueue, delivery.UUID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartDelivery starts the workflow delivering a recorded delivery. A delivery
+// whose workflow is already running is left to it.
+func StartDelivery(ctx context.Context, starter WorkflowStarter, taskQueue, deliveryUUID string) error {
+	_, err := starter.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        DeliveryWorkflowIDPrefix + deliveryUUID,
+		TaskQueue: taskQueue,
+	}, DeliveryWorkflow, DeliveryWorkflowInput{DeliveryUUID: deliveryUUID})
+
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		return nil
+	}
+	return err
+}
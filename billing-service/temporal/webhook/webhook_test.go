@@ -0,0 +1,336 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"encore.app/db/repository/mocks"
+	"encore.app/entity"
+	temporalmocks "encore.app/temporal/mocks"
+
+	"encore.dev/storage/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+	signedAt := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	header := Sign("whsec_test", signedAt, body)
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, Verify("whsec_test", header, body, signedAt.Add(time.Minute), DefaultTolerance))
+	})
+
+	t.Run("wrong secret or body", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("whsec_other", header, body, signedAt, DefaultTolerance), ErrInvalidSignature)
+		assert.ErrorIs(t, Verify("whsec_test", header, []byte(`{"id":"evt-2"}`), signedAt, DefaultTolerance), ErrInvalidSignature)
+	})
+
+	t.Run("malformed header", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("whsec_test", "v1=abc", body, signedAt, DefaultTolerance), ErrInvalidSignature)
+		assert.ErrorIs(t, Verify("whsec_test", "garbage", body, signedAt, DefaultTolerance), ErrInvalidSignature)
+	})
+
+	t.Run("old signature is rejected unless tolerance is disabled", func(t *testing.T) {
+		later := signedAt.Add(time.Hour)
+		assert.ErrorIs(t, Verify("whsec_test", header, body, later, DefaultTolerance), ErrSignatureExpired)
+		assert.NoError(t, Verify("whsec_test", header, body, later, 0))
+	})
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	event := Event{
+		ID:        "event-1",
+		Type:      entity.WebhookEventBillClosed,
+		CreatedAt: time.Now(),
+		Data:      entity.BillEventData{UUID: "bill-123"},
+	}
+
+	t.Run("records and starts a delivery per subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		mockStarter := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		publisher := &Publisher{Repo: mockRepo, Starter: mockStarter, TaskQueue: "queue"}
+
+		mockRepo.EXPECT().
+			FetchActiveSubscriptions(gomock.Any(), "bill.closed").
+			Return([]*entity.WebhookSubscriptionEntity{{UUID: "sub-1"}, {UUID: "sub-2"}}, nil)
+
+		for _, subUUID := range []string{"sub-1", "sub-2"} {
+			deliveryUUID := DeliveryUUID(subUUID, event.ID)
+			mockRepo.EXPECT().
+				InsertDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, d *entity.WebhookDeliveryEntity) error {
+					assert.Equal(t, deliveryUUID, d.UUID)
+					assert.Equal(t, event.ID, d.EventUUID)
+					assert.JSONEq(t, `{"id":"`+event.ID+`","type":"bill.closed","createdAt":"`+event.CreatedAt.Format(time.RFC3339Nano)+`","data":{"uuid":"bill-123"}}`, string(d.Payload))
+					d.Status = entity.WebhookDeliveryPending
+					return nil
+				})
+			mockStarter.EXPECT().
+				ExecuteWorkflow(gomock.Any(), client.StartWorkflowOptions{ID: DeliveryWorkflowIDPrefix + deliveryUUID, TaskQueue: "queue"}, gomock.Any(), DeliveryWorkflowInput{DeliveryUUID: deliveryUUID}).
+				Return(nil, nil)
+		}
+
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	})
+
+	t.Run("republishing skips finished deliveries and running workflows", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		mockStarter := temporalmocks.NewMockWorkflowClient(ctrl)
+
+		publisher := &Publisher{Repo: mockRepo, Starter: mockStarter, TaskQueue: "queue"}
+
+		mockRepo.EXPECT().
+			FetchActiveSubscriptions(gomock.Any(), "bill.closed").
+			Return([]*entity.WebhookSubscriptionEntity{{UUID: "sub-1"}, {UUID: "sub-2"}}, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().
+				InsertDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, d *entity.WebhookDeliveryEntity) error {
+					d.Status = entity.WebhookDeliveryDelivered
+					return nil
+				}),
+			mockRepo.EXPECT().
+				InsertDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, d *entity.WebhookDeliveryEntity) error {
+					d.Status = entity.WebhookDeliveryPending
+					return nil
+				}),
+		)
+		mockStarter.EXPECT().
+			ExecuteWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("running", "", ""))
+
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	})
+
+	t.Run("no subscriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		publisher := &Publisher{Repo: mockRepo, Starter: temporalmocks.NewMockWorkflowClient(ctrl)}
+
+		mockRepo.EXPECT().
+			FetchActiveSubscriptions(gomock.Any(), "bill.closed").
+			Return(nil, nil)
+
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	})
+}
+
+func TestActivities_Deliver(t *testing.T) {
+	payload := []byte(`{"id":"evt-1","type":"bill.closed"}`)
+	delivery := func() *entity.WebhookDeliveryEntity {
+		return &entity.WebhookDeliveryEntity{
+			UUID:             "delivery-1",
+			SubscriptionUUID: "sub-1",
+			EventUUID:        "evt-1",
+			EventType:        "bill.closed",
+			Payload:          payload,
+			Status:           entity.WebhookDeliveryPending,
+		}
+	}
+
+	t.Run("posts a signed payload and logs the attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, payload, body)
+			assert.NoError(t, Verify("whsec_test", r.Header.Get(SignatureHeader), body, time.Now(), DefaultTolerance))
+			assert.Equal(t, "evt-1", r.Header.Get(EventIDHeader))
+			assert.Equal(t, "bill.closed", r.Header.Get(EventTypeHeader))
+			assert.Equal(t, "delivery-1", r.Header.Get(DeliveryHeader))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		activities := &Activities{Repo: mockRepo, Client: server.Client()}
+
+		mockRepo.EXPECT().FetchDelivery(gomock.Any(), "delivery-1").Return(delivery(), nil)
+		mockRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1", URL: server.URL, Secret: "whsec_test"}, nil)
+		mockRepo.EXPECT().
+			RecordAttempt(gomock.Any(), gomock.Any(), true).
+			DoAndReturn(func(_ context.Context, a *entity.WebhookDeliveryAttemptEntity, _ bool) error {
+				assert.Equal(t, "delivery-1", a.DeliveryUUID)
+				assert.Equal(t, http.StatusNoContent, *a.StatusCode)
+				assert.Nil(t, a.Error)
+				return nil
+			})
+
+		require.NoError(t, activities.Deliver(context.Background(), DeliverInput{DeliveryUUID: "delivery-1"}))
+	})
+
+	t.Run("non-2xx response is logged and retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream down"))
+		}))
+		defer server.Close()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		activities := &Activities{Repo: mockRepo, Client: server.Client()}
+
+		mockRepo.EXPECT().FetchDelivery(gomock.Any(), "delivery-1").Return(delivery(), nil)
+		mockRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1", URL: server.URL, Secret: "whsec_test"}, nil)
+		mockRepo.EXPECT().
+			RecordAttempt(gomock.Any(), gomock.Any(), false).
+			DoAndReturn(func(_ context.Context, a *entity.WebhookDeliveryAttemptEntity, _ bool) error {
+				assert.Equal(t, http.StatusBadGateway, *a.StatusCode)
+				assert.Contains(t, *a.Error, "upstream down")
+				return nil
+			})
+
+		err := activities.Deliver(context.Background(), DeliverInput{DeliveryUUID: "delivery-1"})
+		assert.ErrorContains(t, err, "502")
+	})
+
+	t.Run("already delivered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		activities := &Activities{Repo: mockRepo}
+
+		delivered := delivery()
+		delivered.Status = entity.WebhookDeliveryDelivered
+		mockRepo.EXPECT().FetchDelivery(gomock.Any(), "delivery-1").Return(delivered, nil)
+
+		require.NoError(t, activities.Deliver(context.Background(), DeliverInput{DeliveryUUID: "delivery-1"}))
+	})
+
+	t.Run("deleted subscription is not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		activities := &Activities{Repo: mockRepo}
+
+		deletedAt := time.Now()
+		mockRepo.EXPECT().FetchDelivery(gomock.Any(), "delivery-1").Return(delivery(), nil)
+		mockRepo.EXPECT().
+			FetchSubscription(gomock.Any(), "sub-1").
+			Return(&entity.WebhookSubscriptionEntity{UUID: "sub-1", DeletedAt: &deletedAt}, nil)
+
+		err := activities.Deliver(context.Background(), DeliverInput{DeliveryUUID: "delivery-1"})
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, ErrTypeSubscriptionDeleted, appErr.Type())
+		assert.True(t, appErr.NonRetryable())
+	})
+}
+
+func TestActivities_MarkFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	activities := &Activities{Repo: mockRepo}
+
+	// delivered by a replay in the meantime
+	mockRepo.EXPECT().
+		UpdateDeliveryStatus(gomock.Any(), "delivery-1", entity.WebhookDeliveryPending, entity.WebhookDeliveryFailed).
+		Return(sqldb.ErrNoRows)
+
+	require.NoError(t, activities.MarkFailed(context.Background(), MarkFailedInput{DeliveryUUID: "delivery-1"}))
+}
+
+func TestDeliveryWorkflow(t *testing.T) {
+	t.Run("delivered", func(t *testing.T) {
+		activities := &Activities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.Deliver)
+		env.RegisterActivity(activities.MarkFailed)
+
+		env.OnActivity(activities.Deliver, mock.Anything, DeliverInput{DeliveryUUID: "delivery-1"}).Return(nil)
+
+		env.ExecuteWorkflow(DeliveryWorkflow, DeliveryWorkflowInput{DeliveryUUID: "delivery-1"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result DeliveryWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.True(t, result.Delivered)
+		env.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("retries with backoff then marks failed", func(t *testing.T) {
+		activities := &Activities{}
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(activities.Deliver)
+		env.RegisterActivity(activities.MarkFailed)
+
+		attempts := 0
+		env.OnActivity(activities.Deliver, mock.Anything, mock.Anything).
+			Return(func(context.Context, DeliverInput) error {
+				attempts++
+				return assert.AnError
+			})
+		env.OnActivity(activities.MarkFailed, mock.Anything, MarkFailedInput{DeliveryUUID: "delivery-1"}).
+			Return(nil).
+			Once()
+
+		env.ExecuteWorkflow(DeliveryWorkflow, DeliveryWorkflowInput{DeliveryUUID: "delivery-1"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result DeliveryWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.False(t, result.Delivered)
+		assert.Equal(t, int(deliveryActivityOptions().RetryPolicy.MaximumAttempts), attempts)
+		env.AssertExpectations(t)
+	})
+}
+
+func TestEvent_payload(t *testing.T) {
+	event := Event{
+		ID:        "event-1",
+		Type:      entity.WebhookEventLineItemPersisted,
+		CreatedAt: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+		Data:      json.RawMessage(`{"uuid":"item-1"}`),
+	}
+
+	payload, err := event.payload()
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, "line_item.persisted", decoded["type"])
+	assert.Equal(t, map[string]any{"uuid": "item-1"}, decoded["data"])
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// deliveryActivityOptions retry a delivery with exponential backoff, from ten
+// seconds up to an hour between attempts, for about a day in total.
+func deliveryActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        10 * time.Second,
+			BackoffCoefficient:     2.0,
+			MaximumInterval:        time.Hour,
+			MaximumAttempts:        30,
+			NonRetryableErrorTypes: []string{ErrTypeSubscriptionDeleted},
+		},
+	}
+}
+
+func bookkeepingActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    5,
+		},
+	}
+}
+
+type DeliveryWorkflowInput struct {
+	DeliveryUUID string
+}
+
+type DeliveryWorkflowResult struct {
+	Delivered bool
+}
+
+// DeliveryWorkflow posts one delivery until the subscriber accepts it or the
+// retries run out, in which case the delivery is marked FAILED for replay.
+func DeliveryWorkflow(ctx workflow.Context, input DeliveryWorkflowInput) (*DeliveryWorkflowResult, error) {
+	deliverCtx := workflow.WithActivityOptions(ctx, deliveryActivityOptions())
+	err := workflow.ExecuteActivity(deliverCtx, (*Activities).Deliver, DeliverInput{
+		DeliveryUUID: input.DeliveryUUID,
+	}).Get(ctx, nil)
+	if err == nil {
+		return &DeliveryWorkflowResult{Delivered: true}, nil
+	}
+
+	workflow.GetLogger(ctx).Warn("webhook delivery failed",
+		"delivery_uuid", input.DeliveryUUID,
+		"err", err)
+
+	markCtx := workflow.WithActivityOptions(ctx, bookkeepingActivityOptions())
+	if err := workflow.ExecuteActivity(markCtx, (*Activities).MarkFailed, MarkFailedInput{
+		DeliveryUUID: input.DeliveryUUID,
+	}).Get(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	return &DeliveryWorkflowResult{Delivered: false}, nil
+}
@@ -1,21 +1,28 @@
 package temporal
 
 import (
-	"encore.app/db/repository"
 	"encore.app/temporal/bill"
+	"encore.app/temporal/outbox"
+	"encore.app/temporal/reconcile"
+	"encore.app/temporal/webhook"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 )
 
-func NewWorker(c client.Client, billRepo repository.BillRepository, lineItemRepo repository.LineItemRepository) worker.Worker {
+func NewWorker(c client.Client, billActivities *bill.BillActivities, webhookActivities *webhook.Activities, outboxActivities *outbox.Activities, reconcileActivities *reconcile.Activities) worker.Worker {
 	w := worker.New(c, TaskQueue, worker.Options{})
 
-	billActivities := &bill.BillActivities{
-		BillRepo:     billRepo,
-		LineItemRepo: lineItemRepo,
-	}
 	w.RegisterActivity(billActivities)
+	w.RegisterActivity(webhookActivities)
+	w.RegisterActivity(outboxActivities)
+	w.RegisterActivity(reconcileActivities)
+	w.RegisterWorkflow(webhook.DeliveryWorkflow)
+	w.RegisterWorkflow(outbox.RelayWorkflow)
+	w.RegisterWorkflow(reconcile.ReconciliationWorkflow)
 	w.RegisterWorkflow(bill.BillWorkflow)
+	w.RegisterWorkflow(bill.DunningWorkflow)
+	w.RegisterWorkflow(bill.SettlementWorkflow)
+	w.RegisterWorkflow(bill.CreditNoteWorkflow)
 
 	return w
 }
@@ -0,0 +1,136 @@
+// Package usage prices metered usage, such as API calls or gigabytes stored,
+// that bills aggregate into line items when they close.
+package usage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPrice is returned by Price.Validate for prices that cannot be charged.
+var ErrInvalidPrice = errors.New("invalid usage price")
+
+// PricingModel tells how a quantity is turned into an amount.
+type PricingModel string
+
+const (
+	// PricingFlat charges every unit at UnitPriceCents
+	PricingFlat PricingModel = "FLAT"
+	// PricingTiered charges the units within each tier at that tier's price
+	PricingTiered PricingModel = "TIERED"
+	// PricingVolume charges every unit at the price of the tier the total falls in
+	PricingVolume PricingModel = "VOLUME"
+)
+
+// Tier is a price band of a tiered or volume price. UpTo is the inclusive
+// upper bound of the band, zero for the last, unbounded one.
+type Tier struct {
+	UpTo           int64
+	UnitPriceCents int64
+}
+
+// Price is what a meter costs in one currency. Unit prices are quoted per
+// PerUnits units, so sub-cent prices like 5 cents per 1000 API calls stay
+// exact; zero means per unit.
+type Price struct {
+	Model          PricingModel
+	PerUnits       int64
+	UnitPriceCents int64  // FLAT only
+	Tiers          []Tier // TIERED and VOLUME, ascending by UpTo
+}
+
+// Validate checks the price can be applied to any quantity.
+func (p Price) Validate() error {
+	if p.PerUnits < 0 {
+		return fmt.Errorf("%w: per units must not be negative", ErrInvalidPrice)
+	}
+
+	switch p.Model {
+	case PricingFlat:
+		if p.UnitPriceCents < 0 {
+			return fmt.Errorf("%w: unit price must not be negative", ErrInvalidPrice)
+		}
+	case PricingTiered, PricingVolume:
+		if len(p.Tiers) == 0 {
+			return fmt.Errorf("%w: %s price needs tiers", ErrInvalidPrice, p.Model)
+		}
+		var previous int64
+		for i, tier := range p.Tiers {
+			if tier.UnitPriceCents < 0 {
+				return fmt.Errorf("%w: tier %d unit price must not be negative", ErrInvalidPrice, i)
+			}
+			last := i == len(p.Tiers)-1
+			if tier.UpTo == 0 && !last {
+				return fmt.Errorf("%w: only the last tier may be unbounded", ErrInvalidPrice)
+			}
+			if tier.UpTo != 0 && tier.UpTo <= previous {
+				return fmt.Errorf("%w: tiers must ascend", ErrInvalidPrice)
+			}
+			previous = tier.UpTo
+		}
+	default:
+		return fmt.Errorf("%w: unknown pricing model %q", ErrInvalidPrice, p.Model)
+	}
+
+	return nil
+}
+
+// AmountCents prices quantity units. The total is rounded half up to the cent
+// once, not per tier. Quantities past a bounded last tier are charged at its price.
+func (p Price) AmountCents(quantity int64) int64 {
+	if quantity <= 0 {
+		return 0
+	}
+
+	var total int64 // in cents times PerUnits
+	switch p.Model {
+	case PricingFlat:
+		total = quantity * p.UnitPriceCents
+	case PricingTiered:
+		var priced int64
+		for i, tier := range p.Tiers {
+			upTo := tier.UpTo
+			if upTo == 0 || upTo > quantity || i == len(p.Tiers)-1 {
+				upTo = quantity
+			}
+			if upTo > priced {
+				total += (upTo - priced) * tier.UnitPriceCents
+				priced = upTo
+			}
+			if priced == quantity {
+				break
+			}
+		}
+	case PricingVolume:
+		for i, tier := range p.Tiers {
+			if tier.UpTo == 0 || quantity <= tier.UpTo || i == len(p.Tiers)-1 {
+				total = quantity * tier.UnitPriceCents
+				break
+			}
+		}
+	}
+
+	perUnits := max(p.PerUnits, 1)
+	return (total + perUnits/2) / perUnits
+}
+
+// Meter is a kind of usage, priced per bill currency.
+type Meter struct {
+	Name   string
+	Unit   string // e.g. "call" or "GB", used in line item descriptions
+	Prices map[string]Price
+}
+
+// Catalog holds the meters usage can be recorded against, keyed by name.
+type Catalog map[string]Meter
+
+// Price returns the price of a meter in a currency, false when the meter is
+// unknown or not sold in that currency.
+func (c Catalog) Price(meter, currency string) (Price, bool) {
+	m, ok := c[meter]
+	if !ok {
+		return Price{}, false
+	}
+	price, ok := m.Prices[currency]
+	return price, ok
+}
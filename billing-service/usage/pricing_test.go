@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceAmountCents(t *testing.T) {
+	tiers := []Tier{
+		{UpTo: 1000, UnitPriceCents: 10},
+		{UpTo: 5000, UnitPriceCents: 5},
+		{UnitPriceCents: 2},
+	}
+
+	t.Run("flat", func(t *testing.T) {
+		price := Price{Model: PricingFlat, UnitPriceCents: 250}
+		assert.Equal(t, int64(2500), price.AmountCents(10))
+		assert.Equal(t, int64(0), price.AmountCents(0))
+	})
+
+	t.Run("flat quoted per thousand units rounds half up", func(t *testing.T) {
+		price := Price{Model: PricingFlat, PerUnits: 1000, UnitPriceCents: 5}
+		assert.Equal(t, int64(5), price.AmountCents(1000))
+		assert.Equal(t, int64(1), price.AmountCents(100)) // 0.5 cents
+		assert.Equal(t, int64(0), price.AmountCents(99))
+	})
+
+	t.Run("tiered charges each band at its price", func(t *testing.T) {
+		price := Price{Model: PricingTiered, Tiers: tiers}
+		assert.Equal(t, int64(5000), price.AmountCents(500))
+		assert.Equal(t, int64(10000+4000*5+1000*2), price.AmountCents(6000))
+	})
+
+	t.Run("volume charges every unit at the band the total falls in", func(t *testing.T) {
+		price := Price{Model: PricingVolume, Tiers: tiers}
+		assert.Equal(t, int64(1000*10), price.AmountCents(1000))
+		assert.Equal(t, int64(1001*5), price.AmountCents(1001))
+		assert.Equal(t, int64(6000*2), price.AmountCents(6000))
+	})
+
+	t.Run("bounded last tier prices the overflow", func(t *testing.T) {
+		bounded := []Tier{{UpTo: 10, UnitPriceCents: 3}, {UpTo: 20, UnitPriceCents: 1}}
+		assert.Equal(t, int64(30+20), Price{Model: PricingTiered, Tiers: bounded}.AmountCents(30))
+		assert.Equal(t, int64(30), Price{Model: PricingVolume, Tiers: bounded}.AmountCents(30))
+	})
+}
+
+func TestPriceValidate(t *testing.T) {
+	assert.NoError(t, Price{Model: PricingFlat, UnitPriceCents: 1}.Validate())
+	assert.NoError(t, Price{Model: PricingTiered, Tiers: []Tier{{UpTo: 10, UnitPriceCents: 2}, {UnitPriceCents: 1}}}.Validate())
+
+	assert.ErrorIs(t, Price{Model: "MAGIC"}.Validate(), ErrInvalidPrice)
+	assert.ErrorIs(t, Price{Model: PricingVolume}.Validate(), ErrInvalidPrice)
+	assert.ErrorIs(t, Price{Model: PricingTiered, Tiers: []Tier{{UnitPriceCents: 2}, {UpTo: 10}}}.Validate(), ErrInvalidPrice)
+	assert.ErrorIs(t, Price{Model: PricingTiered, Tiers: []Tier{{UpTo: 10}, {UpTo: 5}}}.Validate(), ErrInvalidPrice)
+}
+
+func TestCatalogPrice(t *testing.T) {
+	catalog := Catalog{"api_calls": {
+		Name:   "api_calls",
+		Unit:   "call",
+		Prices: map[string]Price{"USD": {Model: PricingFlat, UnitPriceCents: 1}},
+	}}
+
+	price, ok := catalog.Price("api_calls", "USD")
+	require.True(t, ok)
+	assert.Equal(t, PricingFlat, price.Model)
+
+	_, ok = catalog.Price("api_calls", "GEL")
+	assert.False(t, ok)
+	_, ok = catalog.Price("storage_gb", "USD")
+	assert.False(t, ok)
+}
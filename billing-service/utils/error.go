@@ -23,8 +23,12 @@ var (
 
 // customer API errors
 var (
-	ErrCustomerNotFoundAPI = &errs.Error{Code: errs.NotFound, Message: "CUSTOMER_NOT_FOUND"}
-	ErrEmailAlreadyUsed    = &errs.Error{Code: errs.InvalidArgument, Message: "EMAIL_USED"}
+	ErrCustomerNotFoundAPI    = &errs.Error{Code: errs.NotFound, Message: "CUSTOMER_NOT_FOUND"}
+	ErrEmailAlreadyUsed       = &errs.Error{Code: errs.InvalidArgument, Message: "EMAIL_USED"}
+	ErrCustomerArchived       = &errs.Error{Code: errs.FailedPrecondition, Message: "CUSTOMER_ARCHIVED"}
+	ErrCustomerMerged         = &errs.Error{Code: errs.FailedPrecondition, Message: "CUSTOMER_MERGED"}
+	ErrCustomerHasOpenBills   = &errs.Error{Code: errs.FailedPrecondition, Message: "CUSTOMER_HAS_OPEN_BILLS"}
+	ErrBillingProfileNotFound = &errs.Error{Code: errs.NotFound, Message: "BILLING_PROFILE_NOT_FOUND"}
 )
 
 // bill API errors
@@ -35,11 +39,66 @@ var (
 	ErrCurrencyMismatch     = &errs.Error{Code: errs.InvalidArgument, Message: "CURRENCY_MISMATCH"}
 )
 
+// invoice API errors
+var (
+	ErrInvoiceNotFound = &errs.Error{Code: errs.NotFound, Message: "INVOICE_NOT_FOUND"}
+)
+
+// payment API errors
+var (
+	ErrBillNotPayable        = &errs.Error{Code: errs.FailedPrecondition, Message: "BILL_NOT_PAYABLE"}
+	ErrPaymentExceedsBalance = &errs.Error{Code: errs.FailedPrecondition, Message: "PAYMENT_EXCEEDS_BALANCE"}
+	ErrSettlementDisabled    = &errs.Error{Code: errs.FailedPrecondition, Message: "SETTLEMENT_DISABLED"}
+)
+
+// promotion and credit API errors
+var (
+	ErrPromotionNotFound  = &errs.Error{Code: errs.NotFound, Message: "PROMOTION_NOT_FOUND"}
+	ErrPromoCodeTaken     = &errs.Error{Code: errs.AlreadyExists, Message: "PROMO_CODE_TAKEN"}
+	ErrPromotionExpired   = &errs.Error{Code: errs.FailedPrecondition, Message: "PROMOTION_EXPIRED"}
+	ErrPromotionExhausted = &errs.Error{Code: errs.FailedPrecondition, Message: "PROMOTION_EXHAUSTED"}
+)
+
+// usage API errors
+var (
+	ErrUsageMeteringDisabled = &errs.Error{Code: errs.FailedPrecondition, Message: "USAGE_METERING_DISABLED"}
+	ErrMeterNotPriced        = &errs.Error{Code: errs.InvalidArgument, Message: "METER_NOT_PRICED_IN_BILL_CURRENCY"}
+)
+
+// catalog and subscription API errors
+var (
+	ErrProductNotFound      = &errs.Error{Code: errs.NotFound, Message: "PRODUCT_NOT_FOUND"}
+	ErrProductCodeTaken     = &errs.Error{Code: errs.AlreadyExists, Message: "PRODUCT_CODE_TAKEN"}
+	ErrPriceExists          = &errs.Error{Code: errs.AlreadyExists, Message: "PRICE_EXISTS"}
+	ErrSubscriptionNotFound = &errs.Error{Code: errs.NotFound, Message: "SUBSCRIPTION_NOT_FOUND"}
+	ErrSubscriptionEnded    = &errs.Error{Code: errs.FailedPrecondition, Message: "SUBSCRIPTION_ENDED"}
+	ErrSubscriptionConflict = &errs.Error{Code: errs.AlreadyExists, Message: "SUBSCRIPTION_UUID_CONFLICT"}
+)
+
+// webhook API errors
+var (
+	ErrWebhookSubscriptionNotFound = &errs.Error{Code: errs.NotFound, Message: "WEBHOOK_SUBSCRIPTION_NOT_FOUND"}
+	ErrWebhookDeliveryNotFound     = &errs.Error{Code: errs.NotFound, Message: "WEBHOOK_DELIVERY_NOT_FOUND"}
+)
+
 // line item API errors
 var (
-	ErrLineItemNotFoundAPI   = &errs.Error{Code: errs.NotFound, Message: "LINE_ITEM_NOT_FOUND"}
-	ErrAlreadyReversedAPI    = &errs.Error{Code: errs.FailedPrecondition, Message: "ALREADY_REVERSED"}
-	ErrCannotReverseReversal = &errs.Error{Code: errs.InvalidArgument, Message: "CANNOT_REVERSE_REVERSAL"}
+	ErrLineItemNotFoundAPI     = &errs.Error{Code: errs.NotFound, Message: "LINE_ITEM_NOT_FOUND"}
+	ErrAlreadyReversedAPI      = &errs.Error{Code: errs.FailedPrecondition, Message: "ALREADY_REVERSED"}
+	ErrCannotReverseReversal   = &errs.Error{Code: errs.InvalidArgument, Message: "CANNOT_REVERSE_REVERSAL"}
+	ErrDuplicateIdempotencyKey = &errs.Error{Code: errs.AlreadyExists, Message: "DUPLICATE_IDEMPOTENCY_KEY"}
+	ErrInvalidLineItem         = &errs.Error{Code: errs.InvalidArgument, Message: "INVALID_LINE_ITEM"}
+	ErrLineItemNotFailed       = &errs.Error{Code: errs.NotFound, Message: "LINE_ITEM_NOT_FAILED"}
+	ErrFXRateUnavailable       = &errs.Error{Code: errs.FailedPrecondition, Message: "FX_RATE_UNAVAILABLE"}
+
+	ErrReversalExceedsRemaining = &errs.Error{Code: errs.FailedPrecondition, Message: "REVERSAL_EXCEEDS_REMAINING"}
+)
+
+// credit note API errors
+var (
+	ErrBillNotClosed         = &errs.Error{Code: errs.FailedPrecondition, Message: "BILL_NOT_CLOSED"}
+	ErrLineItemNotCreditable = &errs.Error{Code: errs.InvalidArgument, Message: "LINE_ITEM_NOT_CREDITABLE"}
+	ErrCreditExceedsLineItem = &errs.Error{Code: errs.FailedPrecondition, Message: "CREDIT_EXCEEDS_LINE_ITEM"}
 )
 
 // workflow API errors
@@ -48,6 +107,7 @@ var (
 	ErrWorkflowQueryFailed  = &errs.Error{Code: errs.Internal, Message: "WORKFLOW_QUERY_FAILED"}
 	ErrWorkflowSignalFailed = &errs.Error{Code: errs.Internal, Message: "WORKFLOW_SIGNAL_FAILED"}
 	ErrWorkflowStartFailed  = &errs.Error{Code: errs.Internal, Message: "WORKFLOW_START_FAILED"}
+	ErrWorkflowUpdateFailed = &errs.Error{Code: errs.Internal, Message: "WORKFLOW_UPDATE_FAILED"}
 )
 
 // pagination errors
@@ -25,10 +25,12 @@ var (
 
 	ErrInvalidCustomerUUID = ValidationError{Code: "INVALID_CUSTOMER_UUID", Message: "Customer UUID is required"}
 	ErrInvalidUUID         = ValidationError{Code: "INVALID_UUID", Message: "UUID is required"}
-	ErrInvalidCurrency     = ValidationError{Code: "INVALID_CURRENCY", Message: "Currency must be USD or GEL"}
+	ErrInvalidCurrency     = ValidationError{Code: "INVALID_CURRENCY", Message: "Currency must be a supported ISO-4217 code"}
 	ErrInvalidPeriodStart  = ValidationError{Code: "INVALID_PERIOD_START", Message: "Period start is required"}
 	ErrInvalidPeriodEnd    = ValidationError{Code: "INVALID_PERIOD_END", Message: "Period end is required"}
 	ErrInvalidPeriod       = ValidationError{Code: "INVALID_PERIOD", Message: "Period end must be after period start"}
+	ErrInvalidCadence      = ValidationError{Code: "INVALID_CADENCE", Message: "Cadence must be MONTHLY, WEEKLY or CUSTOM"}
+	ErrInvalidIntervalDays = ValidationError{Code: "INVALID_INTERVAL_DAYS", Message: "Interval days must be positive for CUSTOM cadence"}
 
 	ErrBillNotFound          = ValidationError{Code: "BILL_NOT_FOUND", Message: "Bill not found"}
 	ErrInvalidAmount         = ValidationError{Code: "INVALID_AMOUNT", Message: "Amount must be positive"}
@@ -37,10 +39,53 @@ var (
 	ErrInvalidBillUUID       = ValidationError{Code: "INVALID_BILL_UUID", Message: "Bill UUID is required"}
 
 	// New validation errors for scaffolded endpoints
-	ErrInvalidStatus       = ValidationError{Code: "INVALID_STATUS", Message: "Status must be OPEN or CLOSED"}
-	ErrInvalidFeeTypeValue = ValidationError{Code: "INVALID_FEE_TYPE_VALUE", Message: "Invalid fee type value"}
-	ErrInvalidLineItemUUID = ValidationError{Code: "INVALID_LINE_ITEM_UUID", Message: "Line item UUID is required"}
-	ErrLineItemNotFound    = ValidationError{Code: "LINE_ITEM_NOT_FOUND", Message: "Line item not found"}
-	ErrAlreadyReversed     = ValidationError{Code: "ALREADY_REVERSED", Message: "Line item already reversed"}
-	ErrBillAlreadyClosed   = ValidationError{Code: "BILL_ALREADY_CLOSED", Message: "Bill is already closed"}
+	ErrInvalidStatus         = ValidationError{Code: "INVALID_STATUS", Message: "Status must be OPEN, CLOSING, CLOSED, FINALIZED, PARTIALLY_PAID, OVERDUE, PAID or VOIDED"}
+	ErrInvalidFeeTypeValue   = ValidationError{Code: "INVALID_FEE_TYPE_VALUE", Message: "Invalid fee type value"}
+	ErrInvalidLineItemUUID   = ValidationError{Code: "INVALID_LINE_ITEM_UUID", Message: "Line item UUID is required"}
+	ErrLineItemNotFound      = ValidationError{Code: "LINE_ITEM_NOT_FOUND", Message: "Line item not found"}
+	ErrAlreadyReversed       = ValidationError{Code: "ALREADY_REVERSED", Message: "Line item already reversed"}
+	ErrBillAlreadyClosed     = ValidationError{Code: "BILL_ALREADY_CLOSED", Message: "Bill is already closed"}
+	ErrInvalidVoidReason     = ValidationError{Code: "INVALID_VOID_REASON", Message: "Void reason is required"}
+	ErrInvalidDiscardReason  = ValidationError{Code: "INVALID_DISCARD_REASON", Message: "Discard reason is required"}
+	ErrInvalidInvoiceFormat  = ValidationError{Code: "INVALID_INVOICE_FORMAT", Message: "Format must be json, html or pdf"}
+	ErrInvalidWebhookURL     = ValidationError{Code: "INVALID_WEBHOOK_URL", Message: "URL must be an absolute http or https URL"}
+	ErrInvalidWebhookEvents  = ValidationError{Code: "INVALID_WEBHOOK_EVENTS", Message: "Events must list bill.created, line_item.persisted, line_item.reversed or bill.closed"}
+	ErrInvalidDeliveryUUID   = ValidationError{Code: "INVALID_DELIVERY_UUID", Message: "Delivery UUID is required"}
+	ErrInvalidReceivedAt     = ValidationError{Code: "INVALID_RECEIVED_AT", Message: "Received at must be an RFC3339 time"}
+	ErrFeeTypeAppliedAtClose = ValidationError{Code: "FEE_TYPE_APPLIED_AT_CLOSE", Message: "USAGE, TAX, DISCOUNT and CREDIT line items are added by the bill at close"}
+
+	ErrInvalidPromoCode      = ValidationError{Code: "INVALID_PROMO_CODE", Message: "Promo code is required"}
+	ErrInvalidDiscount       = ValidationError{Code: "INVALID_DISCOUNT", Message: "Exactly one of percent off (1-10000 bps) or a positive amount off is required"}
+	ErrInvalidMaxRedemptions = ValidationError{Code: "INVALID_MAX_REDEMPTIONS", Message: "Max redemptions must be positive"}
+	ErrInvalidExpiresAt      = ValidationError{Code: "INVALID_EXPIRES_AT", Message: "Expires at must be an RFC3339 time"}
+
+	ErrInvalidUsageEvents = ValidationError{Code: "INVALID_USAGE_EVENTS", Message: "Events must list between 1 and 1000 usage events"}
+	ErrInvalidDedupKey    = ValidationError{Code: "INVALID_DEDUP_KEY", Message: "Dedup key is required"}
+	ErrInvalidMeter       = ValidationError{Code: "INVALID_METER", Message: "Meter must be one of the configured meters"}
+	ErrInvalidQuantity    = ValidationError{Code: "INVALID_QUANTITY", Message: "Quantity must be positive"}
+	ErrInvalidOccurredAt  = ValidationError{Code: "INVALID_OCCURRED_AT", Message: "Occurred at must be an RFC3339 time"}
+
+	ErrInvalidProductCode    = ValidationError{Code: "INVALID_PRODUCT_CODE", Message: "Product code is required"}
+	ErrInvalidProductName    = ValidationError{Code: "INVALID_PRODUCT_NAME", Message: "Product name is required"}
+	ErrInvalidPriceAmount    = ValidationError{Code: "INVALID_PRICE_AMOUNT", Message: "Price amount cannot be negative"}
+	ErrInvalidEffectiveFrom  = ValidationError{Code: "INVALID_EFFECTIVE_FROM", Message: "Effective from must be an RFC3339 time"}
+	ErrInvalidSubscriptionID = ValidationError{Code: "INVALID_SUBSCRIPTION_UUID", Message: "Subscription UUID is required"}
+	ErrInvalidStartsAt       = ValidationError{Code: "INVALID_STARTS_AT", Message: "Starts at must be an RFC3339 time"}
+	ErrInvalidEndsAt         = ValidationError{Code: "INVALID_ENDS_AT", Message: "Ends at must be an RFC3339 time after the subscription start"}
+
+	ErrInvalidCreditNoteLines = ValidationError{Code: "INVALID_CREDIT_NOTE_LINES", Message: "Lines must credit between 1 and 100 distinct line items"}
+	ErrInvalidCreditAmount    = ValidationError{Code: "INVALID_CREDIT_AMOUNT", Message: "Credit amount cannot be negative"}
+	ErrInvalidReversalAmount  = ValidationError{Code: "INVALID_REVERSAL_AMOUNT", Message: "Reversal amount cannot be negative"}
+
+	ErrInvalidFindingStatus = ValidationError{Code: "INVALID_FINDING_STATUS", Message: "Status must be OPEN, RESOLVED or REPAIRED"}
+
+	ErrInvalidCustomerStatus = ValidationError{Code: "INVALID_CUSTOMER_STATUS", Message: "Status must be ACTIVE, ARCHIVED or MERGED"}
+	ErrInvalidSourceUUID     = ValidationError{Code: "INVALID_SOURCE_UUID", Message: "Source customer UUID is required"}
+	ErrInvalidTargetUUID     = ValidationError{Code: "INVALID_TARGET_UUID", Message: "Target customer UUID is required and must differ from the source"}
+
+	ErrInvalidAddress         = ValidationError{Code: "INVALID_ADDRESS", Message: "Address line 1 and city are required"}
+	ErrInvalidCountry         = ValidationError{Code: "INVALID_COUNTRY", Message: "Country must be an ISO 3166-1 alpha-2 code"}
+	ErrInvalidTaxID           = ValidationError{Code: "INVALID_TAX_ID", Message: "Tax ID does not match the format of the address country"}
+	ErrInvalidPaymentTerms    = ValidationError{Code: "INVALID_PAYMENT_TERMS", Message: "Payment terms must be NET15 or NET30"}
+	ErrInvalidInvoiceDelivery = ValidationError{Code: "INVALID_INVOICE_DELIVERY", Message: "Invoice delivery must be EMAIL or PORTAL"}
 )
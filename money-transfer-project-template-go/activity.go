@@ -6,48 +6,49 @@ import (
 	"log"
 )
 
+// Activities move money through the configured bank.
+type Activities struct {
+	Bank BankingService
+}
+
 // @@@SNIPSTART money-transfer-project-template-go-activity-withdraw
-func Withdraw(ctx context.Context, data PaymentDetails) (string, error) {
+func (a *Activities) Withdraw(ctx context.Context, data PaymentDetails) (string, error) {
 	log.Printf("Withdrawing $%d from account %s.\n\n",
 		data.Amount,
 		data.SourceAccount,
 	)
 
 	referenceID := fmt.Sprintf("%s-withdrawal", data.ReferenceID)
-	bank := BankingService{"bank-api.example.com"}
-	confirmation, err := bank.Withdraw(data.SourceAccount, data.Amount, referenceID)
+	confirmation, err := a.Bank.Withdraw(data.SourceAccount, data.Amount, referenceID)
 	return confirmation, err
 }
 
 // @@@SNIPEND
 
 // @@@SNIPSTART money-transfer-project-template-go-activity-deposit
-func Deposit(ctx context.Context, data PaymentDetails) (string, error) {
+func (a *Activities) Deposit(ctx context.Context, data PaymentDetails) (string, error) {
 	log.Printf("Depositing $%d into account %s.\n\n",
 		data.Amount,
 		data.TargetAccount,
 	)
 
 	referenceID := fmt.Sprintf("%s-deposit", data.ReferenceID)
-	bank := BankingService{"bank-api.example.com"}
-	// Uncomment the next line and comment the one after that to simulate an unknown failure
-	// confirmation, err := bank.DepositThatFails(data.TargetAccount, data.Amount, referenceID)
-	confirmation, err := bank.Deposit(data.TargetAccount, data.Amount, referenceID)
+	// Set FailDepositsTo on an InMemoryBank to simulate an unknown failure
+	confirmation, err := a.Bank.Deposit(data.TargetAccount, data.Amount, referenceID)
 	return confirmation, err
 }
 
 // @@@SNIPEND
 
 // @@@SNIPSTART money-transfer-project-template-go-activity-refund
-func Refund(ctx context.Context, data PaymentDetails) (string, error) {
+func (a *Activities) Refund(ctx context.Context, data PaymentDetails) (string, error) {
 	log.Printf("Refunding $%v back into account %v.\n\n",
 		data.Amount,
 		data.SourceAccount,
 	)
 
 	referenceID := fmt.Sprintf("%s-refund", data.ReferenceID)
-	bank := BankingService{"bank-api.example.com"}
-	confirmation, err := bank.Deposit(data.SourceAccount, data.Amount, referenceID)
+	confirmation, err := a.Bank.Deposit(data.SourceAccount, data.Amount, referenceID)
 	return confirmation, err
 }
 
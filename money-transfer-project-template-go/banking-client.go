@@ -9,28 +9,9 @@ package app
 import (
 	"errors"
 	"math/rand"
+	"sync"
 )
 
-type account struct {
-	AccountNumber string
-	Balance       int64
-}
-
-type bank struct {
-	Accounts []account
-}
-
-func (b bank) findAccount(accountNumber string) (account, error) {
-
-	for _, v := range b.Accounts {
-		if v.AccountNumber == accountNumber {
-			return v, nil
-		}
-	}
-
-	return account{}, errors.New("account not found")
-}
-
 // InsufficientFundsError is raised when the account doesn't have enough money.
 type InsufficientFundsError struct{}
 
@@ -45,59 +26,108 @@ func (m *InvalidAccountError) Error() string {
 	return "Account number supplied is invalid"
 }
 
-// our mock bank
-var mockBank = &bank{
-	Accounts: []account{
-		{AccountNumber: "85-150", Balance: 2000},
-		{AccountNumber: "43-812", Balance: 0},
-	},
+// BankingService is the bank the transfer activities move money through.
+// Withdrawals and deposits carry a reference ID for idempotent transaction
+// tracking and return a transaction id when successful.
+type BankingService interface {
+	Withdraw(accountNumber string, amount int, referenceID string) (string, error)
+	Deposit(accountNumber string, amount int, referenceID string) (string, error)
+}
+
+// InMemoryBank is a BankingService that keeps its accounts in memory. It
+// does not make any network calls. A reference ID it has already seen returns
+// the original transaction id, so retried activities move money once.
+type InMemoryBank struct {
+	mu           sync.Mutex
+	balances     map[string]int64
+	transactions map[string]string
+
+	// FailDepositsTo simulates an unknown failure on deposits into this account
+	FailDepositsTo string
+}
+
+// NewInMemoryBank creates a bank holding the given account balances.
+func NewInMemoryBank(balances map[string]int64) *InMemoryBank {
+	b := &InMemoryBank{
+		balances:     make(map[string]int64, len(balances)),
+		transactions: make(map[string]string),
+	}
+	for accountNumber, balance := range balances {
+		b.balances[accountNumber] = balance
+	}
+	return b
 }
 
-// BankingService mocks interaction with a bank API. It supports withdrawals
-// and deposits
-type BankingService struct {
-	// the hostname is to make it more realistic. This code does not
-	// actually make any network calls.
-	Hostname string
+// NewDemoBank creates the bank the sample worker runs against.
+func NewDemoBank() *InMemoryBank {
+	return NewInMemoryBank(map[string]int64{
+		"85-150": 2000,
+		"43-812": 0,
+	})
 }
 
-// Withdraw simulates a Withdrawal from a bank.
-// Accepts the account number (string), amount (int), and a reference ID (string)
-// for idempotent transaction tracking.
-// Returns a transaction id when successful
+// Ensure InMemoryBank implements BankingService.
+var _ BankingService = (*InMemoryBank)(nil)
+
+// Withdraw takes amount out of the account.
 // Returns various errors based on amount and account number.
-func (client BankingService) Withdraw(accountNumber string, amount int, referenceID string) (string, error) {
-	acct, err := mockBank.findAccount(accountNumber)
+func (b *InMemoryBank) Withdraw(accountNumber string, amount int, referenceID string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	if err != nil {
+	if id, ok := b.transactions[referenceID]; ok {
+		return id, nil
+	}
+
+	balance, ok := b.balances[accountNumber]
+	if !ok {
 		return "", &InvalidAccountError{}
 	}
 
-	if amount > int(acct.Balance) {
+	if int64(amount) > balance {
 		return "", &InsufficientFundsError{}
 	}
 
-	return generateTransactionID("W", 10), nil
+	b.balances[accountNumber] = balance - int64(amount)
+	return b.record(referenceID, "W"), nil
 }
 
-// Deposit simulates a Deposit into a bank.
-// Accepts the account number (string), amount (int), and a reference ID (string)
-// for idempotent transaction tracking.
-// Returns a transaction id when successful
+// Deposit puts amount into the account.
 // Returns InvalidAccountError if the account is invalid
-func (client BankingService) Deposit(accountNumber string, amount int, referenceID string) (string, error) {
+func (b *InMemoryBank) Deposit(accountNumber string, amount int, referenceID string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	_, err := mockBank.findAccount(accountNumber)
-	if err != nil {
+	if b.FailDepositsTo != "" && accountNumber == b.FailDepositsTo {
+		return "", errors.New("This deposit has failed.")
+	}
+
+	if id, ok := b.transactions[referenceID]; ok {
+		return id, nil
+	}
+
+	balance, ok := b.balances[accountNumber]
+	if !ok {
 		return "", &InvalidAccountError{}
 	}
 
-	return generateTransactionID("D", 10), nil
+	b.balances[accountNumber] = balance + int64(amount)
+	return b.record(referenceID, "D"), nil
+}
+
+// Balance returns the balance of an account and whether it exists.
+func (b *InMemoryBank) Balance(accountNumber string) (int64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	balance, ok := b.balances[accountNumber]
+	return balance, ok
 }
 
-// DepositThatFails simulates an unknown error.
-func (client BankingService) DepositThatFails(accountNumber string, amount int, referenceID string) (string, error) {
-	return "", errors.New("This deposit has failed.")
+func (b *InMemoryBank) record(referenceID, prefix string) string {
+	id := generateTransactionID(prefix, 10)
+	b.transactions[referenceID] = id
+	return id
 }
 
 func generateTransactionID(prefix string, length int) string {
@@ -22,9 +22,7 @@ func main() {
 
 	// This worker hosts both Workflow and Activity functions.
 	w.RegisterWorkflow(app.MoneyTransfer)
-	w.RegisterActivity(app.Withdraw)
-	w.RegisterActivity(app.Deposit)
-	w.RegisterActivity(app.Refund)
+	w.RegisterActivity(&app.Activities{Bank: app.NewDemoBank()})
 
 	// Start listening to the Task Queue.
 	err = w.Run(worker.InterruptCh())
@@ -31,10 +31,13 @@ func MoneyTransfer(ctx workflow.Context, input PaymentDetails) (string, error) {
 	// Apply the options.
 	ctx = workflow.WithActivityOptions(ctx, options)
 
+	// Activities are referenced through a nil pointer, the worker runs them.
+	var a *Activities
+
 	// Withdraw money.
 	var withdrawOutput string
 
-	withdrawErr := workflow.ExecuteActivity(ctx, Withdraw, input).Get(ctx, &withdrawOutput)
+	withdrawErr := workflow.ExecuteActivity(ctx, a.Withdraw, input).Get(ctx, &withdrawOutput)
 
 	if withdrawErr != nil {
 		return "", withdrawErr
@@ -43,14 +46,14 @@ func MoneyTransfer(ctx workflow.Context, input PaymentDetails) (string, error) {
 	// Deposit money.
 	var depositOutput string
 
-	depositErr := workflow.ExecuteActivity(ctx, Deposit, input).Get(ctx, &depositOutput)
+	depositErr := workflow.ExecuteActivity(ctx, a.Deposit, input).Get(ctx, &depositOutput)
 
 	if depositErr != nil {
 		// The deposit failed; put money back in original account.
 
 		var result string
 
-		refundErr := workflow.ExecuteActivity(ctx, Refund, input).Get(ctx, &result)
+		refundErr := workflow.ExecuteActivity(ctx, a.Refund, input).Get(ctx, &result)
 
 		if refundErr != nil {
 			return "",
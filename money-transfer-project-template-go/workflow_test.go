@@ -13,6 +13,7 @@ import (
 func Test_SuccessfulTransferWorkflow(t *testing.T) {
 	testSuite := &testsuite.WorkflowTestSuite{}
 	env := testSuite.NewTestWorkflowEnvironment()
+	var a *Activities
 
 	testDetails := PaymentDetails{
 		SourceAccount: "85-150",
@@ -22,8 +23,8 @@ func Test_SuccessfulTransferWorkflow(t *testing.T) {
 	}
 
 	// Mock activity implementation
-	env.OnActivity(Withdraw, mock.Anything, testDetails).Return("", nil)
-	env.OnActivity(Deposit, mock.Anything, testDetails).Return("", nil)
+	env.OnActivity(a.Withdraw, mock.Anything, testDetails).Return("", nil)
+	env.OnActivity(a.Deposit, mock.Anything, testDetails).Return("", nil)
 
 	env.ExecuteWorkflow(MoneyTransfer, testDetails)
 	require.True(t, env.IsWorkflowCompleted())
@@ -33,6 +34,7 @@ func Test_SuccessfulTransferWorkflow(t *testing.T) {
 func Test_DepositFailedWorkflow(t *testing.T) {
 	testSuite := &testsuite.WorkflowTestSuite{}
 	env := testSuite.NewTestWorkflowEnvironment()
+	var a *Activities
 
 	testDetails := PaymentDetails{
 		SourceAccount: "85-150",
@@ -42,11 +44,61 @@ func Test_DepositFailedWorkflow(t *testing.T) {
 	}
 
 	// Mock activity implementation
-	env.OnActivity(Withdraw, mock.Anything, testDetails).Return("", nil)
-	env.OnActivity(Deposit, mock.Anything, testDetails).Return("", errors.New("unable to deposit"))
-	env.OnActivity(Refund, mock.Anything, testDetails).Return("", nil)
+	env.OnActivity(a.Withdraw, mock.Anything, testDetails).Return("", nil)
+	env.OnActivity(a.Deposit, mock.Anything, testDetails).Return("", errors.New("unable to deposit"))
+	env.OnActivity(a.Refund, mock.Anything, testDetails).Return("", nil)
 
 	env.ExecuteWorkflow(MoneyTransfer, testDetails)
 	require.True(t, env.IsWorkflowCompleted())
 	require.Error(t, env.GetWorkflowError())
 }
+
+func Test_DepositFailedRefundsWithdrawal(t *testing.T) {
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+
+	bank := NewDemoBank()
+	bank.FailDepositsTo = "43-812"
+	env.RegisterActivity(&Activities{Bank: bank})
+
+	testDetails := PaymentDetails{
+		SourceAccount: "85-150",
+		TargetAccount: "43-812",
+		Amount:        250,
+		ReferenceID:   "12345",
+	}
+
+	env.ExecuteWorkflow(MoneyTransfer, testDetails)
+	require.True(t, env.IsWorkflowCompleted())
+	require.Error(t, env.GetWorkflowError())
+
+	// the withdrawal was put back
+	balance, _ := bank.Balance("85-150")
+	require.Equal(t, int64(2000), balance)
+}
+
+func Test_InMemoryBank(t *testing.T) {
+	bank := NewDemoBank()
+
+	withdrawal, err := bank.Withdraw("85-150", 500, "ref-1-withdrawal")
+	require.NoError(t, err)
+
+	// a retried withdrawal is not taken twice
+	again, err := bank.Withdraw("85-150", 500, "ref-1-withdrawal")
+	require.NoError(t, err)
+	require.Equal(t, withdrawal, again)
+
+	_, err = bank.Deposit("43-812", 500, "ref-1-deposit")
+	require.NoError(t, err)
+
+	source, _ := bank.Balance("85-150")
+	target, _ := bank.Balance("43-812")
+	require.Equal(t, int64(1500), source)
+	require.Equal(t, int64(500), target)
+
+	_, err = bank.Withdraw("85-150", 5000, "ref-2-withdrawal")
+	require.ErrorAs(t, err, new(*InsufficientFundsError))
+
+	_, err = bank.Deposit("00-000", 1, "ref-3-deposit")
+	require.ErrorAs(t, err, new(*InvalidAccountError))
+}